@@ -15,12 +15,16 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	eth2client "github.com/attestantio/go-eth2-client"
 	autoclient "github.com/attestantio/go-eth2-client/auto"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"github.com/wealdtech/chaind/services/governor"
+	standardgovernor "github.com/wealdtech/chaind/services/governor/standard"
+	"github.com/wealdtech/chaind/services/metrics"
 	"github.com/wealdtech/chaind/util"
 )
 
@@ -30,7 +34,8 @@ var (
 )
 
 // fetchClient fetches a client service, instantiating it if required.
-func fetchClient(ctx context.Context, address string) (eth2client.Service, error) {
+// Clients are governed, so that all modules using the same beacon node share its limits.
+func fetchClient(ctx context.Context, monitor metrics.Service, address string) (eth2client.Service, error) {
 	clientsMu.Lock()
 	defer clientsMu.Unlock()
 	if clients == nil {
@@ -52,12 +57,44 @@ func fetchClient(ctx context.Context, address string) (eth2client.Service, error
 		if err := confirmClientInterfaces(client); err != nil {
 			return nil, errors.Wrap(err, "missing required interface")
 		}
+		client, err = governClient(ctx, monitor, client)
+		if err != nil {
+			return nil, err
+		}
 		clients[address] = client
 	}
 
 	return client, nil
 }
 
+// governClient wraps a client with a governor that limits its requests.
+func governClient(ctx context.Context, monitor metrics.Service, client eth2client.Service) (eth2client.Service, error) {
+	params := []standardgovernor.Parameter{
+		standardgovernor.WithLogLevel(util.LogLevel("governor")),
+		standardgovernor.WithMonitor(monitor),
+		standardgovernor.WithETH2Client(client),
+		standardgovernor.WithTimeout(viper.GetDuration("eth2client.timeout")),
+	}
+	for _, class := range governor.Classes {
+		params = append(params, standardgovernor.WithLimit(class, governorLimit(class)))
+	}
+	governed, err := standardgovernor.New(ctx, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start governor")
+	}
+	registerReloadable("governor", governed)
+
+	return governed, nil
+}
+
+// governorLimit returns the configured limit for a class of beacon node request.
+func governorLimit(class governor.Class) *standardgovernor.Limit {
+	return &standardgovernor.Limit{
+		Rate:        viper.GetFloat64(fmt.Sprintf("eth2client.governor.%s.rate", class)),
+		Concurrency: viper.GetInt(fmt.Sprintf("eth2client.governor.%s.concurrency", class)),
+	}
+}
+
 func confirmClientInterfaces(client eth2client.Service) error {
 	if _, isProvider := client.(eth2client.GenesisTimeProvider); !isProvider {
 		return errors.New("client is not a GenesisTimeProvider")
@@ -0,0 +1,463 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/util"
+)
+
+// envPrefix is the prefix for environment variables that configure chaind.
+const envPrefix = "CHAIND"
+
+// envKeyReplacer converts a configuration key to its environment variable suffix.
+var envKeyReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// configKeys are the configuration keys that are not also command-line flags.
+var configKeys = []string{
+	"metrics.prometheus.listen-address",
+	"tracing.address",
+	"tracing.client-cert",
+	"tracing.client-key",
+	"tracing.ca-cert",
+	"majordomo.asm.id",
+	"majordomo.asm.secret",
+	"majordomo.asm.region",
+	"majordomo.gsm.credentials",
+	"majordomo.gsm.project",
+	"spec.address",
+	"blocks.address",
+	"finalizer.address",
+	"validators.address",
+	"beacon-committees.address",
+	"proposer-duties.address",
+	"sync-committees.address",
+	"eth1deposits.confirmations",
+	"summarizer.validators.epoch-retention",
+	"summarizer.validators.balance-retention",
+	"summarizer.retention.attestations",
+	"summarizer.retention.beacon-committees",
+	"summarizer.retention.sync-aggregates",
+	"summarizer.retention.sync-committees",
+}
+
+// logLevelModules are the modules that can have their own log level, set with <module>.log-level.
+var logLevelModules = []string{
+	"admin",
+	"beacon-committees",
+	"blocks",
+	"chaindb",
+	"chaintime",
+	"eth1deposits",
+	"eth2client",
+	"finalizer",
+	"governor",
+	"majordomo",
+	"majordomo.confidants",
+	"majordomo.confidants.asm",
+	"majordomo.confidants.direct",
+	"majordomo.confidants.file",
+	"majordomo.confidants.gsm",
+	"metrics",
+	"metrics.prometheus",
+	"mevrelays",
+	"proposer-duties",
+	"scheduler",
+	"spec",
+	"summarizer",
+	"sync-committees",
+	"validators",
+}
+
+// secretConfigKeys are the configuration keys whose values are not shown.
+var secretConfigKeys = map[string]bool{
+	"admin.token":          true,
+	"majordomo.asm.secret": true,
+}
+
+// retentionConfigKeys are the configuration keys that hold calendar durations.
+var retentionConfigKeys = []string{
+	"summarizer.validators.epoch-retention",
+	"summarizer.validators.balance-retention",
+	"summarizer.retention.attestations",
+	"summarizer.retention.beacon-committees",
+	"summarizer.retention.sync-aggregates",
+	"summarizer.retention.sync-committees",
+}
+
+// knownConfigKeys returns all configuration keys understood by chaind.
+func knownConfigKeys() map[string]bool {
+	keys := make(map[string]bool)
+	pflag.CommandLine.VisitAll(func(flag *pflag.Flag) {
+		keys[flag.Name] = true
+	})
+	for _, key := range configKeys {
+		keys[key] = true
+	}
+	for _, module := range logLevelModules {
+		keys[fmt.Sprintf("%s.log-level", module)] = true
+	}
+
+	return keys
+}
+
+// envName returns the name of the environment variable for a configuration key.
+func envName(key string) string {
+	return fmt.Sprintf("%s_%s", envPrefix, strings.ToUpper(envKeyReplacer.Replace(key)))
+}
+
+// configSource returns the source of the value for a configuration key, in order of precedence.
+// It returns an empty string if the key has no value.
+func configSource(key string) string {
+	if flag := pflag.CommandLine.Lookup(key); flag != nil && flag.Changed {
+		return "flag"
+	}
+	if os.Getenv(envName(key)) != "" {
+		return "environment"
+	}
+	if viper.InConfig(key) {
+		return "file"
+	}
+	if pflag.CommandLine.Lookup(key) != nil {
+		return "default"
+	}
+
+	return ""
+}
+
+// configCommandRequested returns true if a configuration command has been requested.
+func configCommandRequested() bool {
+	return pflag.NArg() > 0 && pflag.Arg(0) == "config"
+}
+
+// runConfigCommand runs the requested configuration command.
+func runConfigCommand() error {
+	if pflag.NArg() != 2 || pflag.Arg(1) != "show" {
+		return errors.New("usage: chaind config show")
+	}
+
+	if viper.ConfigFileUsed() != "" {
+		fmt.Printf("Configuration file: %s\n\n", viper.ConfigFileUsed())
+	}
+
+	keys := make([]string, 0)
+	for key := range knownConfigKeys() {
+		if configSource(key) != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tVALUE\tSOURCE")
+	for _, key := range keys {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", key, configValue(key), configSource(key))
+	}
+	if err := writer.Flush(); err != nil {
+		return errors.Wrap(err, "failed to write configuration")
+	}
+
+	problems := configProblems()
+	if len(problems) == 0 {
+		return nil
+	}
+	fmt.Println()
+	fmt.Println("Configuration problems:")
+	for _, problem := range problems {
+		fmt.Printf("  - %s\n", problem)
+	}
+
+	return errors.New("configuration is invalid")
+}
+
+// configValue returns the resolved value of a configuration key for display, with secrets hidden.
+func configValue(key string) string {
+	var value string
+	flag := pflag.CommandLine.Lookup(key)
+	switch {
+	case flag != nil && flag.Value.Type() == "duration":
+		value = viper.GetDuration(key).String()
+	case flag != nil && flag.Value.Type() == "stringSlice":
+		value = strings.Join(viper.GetStringSlice(key), ",")
+	default:
+		value = fmt.Sprintf("%v", viper.Get(key))
+	}
+
+	if value != "" && secretConfigKeys[key] {
+		return "<hidden>"
+	}
+	// Hide passwords in URLs, such as those for the database.
+	if strings.Contains(value, "://") {
+		values := strings.Split(value, ",")
+		for i := range values {
+			if u, err := url.Parse(values[i]); err == nil {
+				values[i] = u.Redacted()
+			}
+		}
+		value = strings.Join(values, ",")
+	}
+
+	return value
+}
+
+// validateConfig checks the configuration, returning an error describing all problems found.
+func validateConfig() error {
+	problems := configProblems()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+}
+
+// configProblems returns the problems with the configuration.
+func configProblems() []string {
+	problems := make([]string, 0)
+	problems = append(problems, unknownConfigProblems()...)
+	problems = append(problems, typeConfigProblems()...)
+	problems = append(problems, retentionConfigProblems()...)
+	problems = append(problems, valueConfigProblems()...)
+	problems = append(problems, combinationConfigProblems()...)
+
+	return problems
+}
+
+// unknownConfigProblems returns problems with configuration keys and environment variables that are not known.
+func unknownConfigProblems() []string {
+	problems := make([]string, 0)
+
+	known := knownConfigKeys()
+	fileKeys := make([]string, 0)
+	for _, key := range viper.AllKeys() {
+		// Empty sections, for example where all of their keys are commented out, are ignored.
+		if !known[key] && viper.InConfig(key) && viper.Get(key) != nil {
+			fileKeys = append(fileKeys, key)
+		}
+	}
+	sort.Strings(fileKeys)
+	for _, key := range fileKeys {
+		problems = append(problems, fmt.Sprintf("unknown key %q in configuration file%s", key, suggestConfigKey(key, known)))
+	}
+
+	envNames := make(map[string]bool)
+	for key := range known {
+		envNames[envName(key)] = true
+	}
+	unknownEnvNames := make([]string, 0)
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, envPrefix+"_") && !envNames[name] {
+			unknownEnvNames = append(unknownEnvNames, name)
+		}
+	}
+	sort.Strings(unknownEnvNames)
+	for _, name := range unknownEnvNames {
+		problems = append(problems, fmt.Sprintf("unknown environment variable %s", name))
+	}
+
+	return problems
+}
+
+// suggestConfigKey returns a suggestion for a known key close to an unknown key, if there is one.
+func suggestConfigKey(key string, known map[string]bool) string {
+	best := ""
+	bestDistance := 3
+	for candidate := range known {
+		if distance := editDistance(key, candidate); distance < bestDistance ||
+			(distance == bestDistance && candidate < best) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// typeConfigProblems returns problems with values from the configuration file or environment
+// that cannot be converted to the type of their flag.
+func typeConfigProblems() []string {
+	problems := make([]string, 0)
+	pflag.CommandLine.VisitAll(func(flag *pflag.Flag) {
+		source := configSource(flag.Name)
+		if source != "file" && source != "environment" {
+			// Flags and defaults are already of the correct type.
+			return
+		}
+		value := viper.Get(flag.Name)
+		var err error
+		switch flag.Value.Type() {
+		case "bool":
+			_, err = cast.ToBoolE(value)
+		case "int", "int32", "int64":
+			_, err = cast.ToInt64E(value)
+		case "uint", "uint64":
+			_, err = cast.ToUint64E(value)
+		case "float64":
+			_, err = cast.ToFloat64E(value)
+		case "duration":
+			_, err = cast.ToDurationE(value)
+		case "stringSlice":
+			_, err = cast.ToStringSliceE(value)
+		default:
+			_, err = cast.ToStringE(value)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("value %v for %s from %s is not a valid %s", value, flag.Name, source, flag.Value.Type()))
+		}
+	})
+
+	return problems
+}
+
+// retentionConfigProblems returns problems with retentions and periods that are not valid calendar durations.
+func retentionConfigProblems() []string {
+	problems := make([]string, 0)
+	for _, key := range retentionConfigKeys {
+		value := viper.GetString(key)
+		if value == "" {
+			continue
+		}
+		if _, err := util.ParseCalendarDuration(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s is not a valid ISO-8601 duration: %v", key, err))
+		}
+	}
+	for _, value := range viper.GetStringSlice("summarizer.validators.periods") {
+		period, err := util.ParseCalendarDuration(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("summarizer.validators.periods is not a valid ISO-8601 duration: %v", err))
+			continue
+		}
+		if period.Hours() != 0 || period.Minutes() != 0 || period.Seconds() != 0 {
+			problems = append(problems, fmt.Sprintf("summarizer.validators.periods %q must be made up of whole days", value))
+		}
+	}
+
+	return problems
+}
+
+// valueConfigProblems returns problems with values that are out of range.
+func valueConfigProblems() []string {
+	problems := make([]string, 0)
+
+	if viper.GetUint64("summarizer.max-days-per-run") == 0 {
+		problems = append(problems, "summarizer.max-days-per-run must be at least 1")
+	}
+	if viper.GetInt("retry.max-attempts") < 1 {
+		problems = append(problems, "retry.max-attempts must be at least 1")
+	}
+	if viper.GetDuration("retry.initial-delay") < 0 {
+		problems = append(problems, "retry.initial-delay cannot be negative")
+	}
+	if viper.GetDuration("retry.max-delay") < viper.GetDuration("retry.initial-delay") {
+		problems = append(problems, "retry.max-delay cannot be less than retry.initial-delay")
+	}
+	if viper.GetDuration("shutdown.abandon-grace") < 0 {
+		problems = append(problems, "shutdown.abandon-grace cannot be negative")
+	}
+	if viper.GetDuration("shutdown.abandon-grace") >= viper.GetDuration("shutdown.timeout") {
+		problems = append(problems, "shutdown.abandon-grace must be less than shutdown.timeout")
+	}
+	for _, class := range governor.Classes {
+		if viper.GetFloat64(fmt.Sprintf("eth2client.governor.%s.rate", class)) < 0 {
+			problems = append(problems, fmt.Sprintf("eth2client.governor.%s.rate cannot be negative", class))
+		}
+		if viper.GetInt(fmt.Sprintf("eth2client.governor.%s.concurrency", class)) < 0 {
+			problems = append(problems, fmt.Sprintf("eth2client.governor.%s.concurrency cannot be negative", class))
+		}
+	}
+
+	return problems
+}
+
+// combinationConfigProblems returns problems with options that require other options.
+func combinationConfigProblems() []string {
+	problems := make([]string, 0)
+
+	summarizer := viper.GetBool("summarizer.enable")
+	validatorSummaries := summarizer && viper.GetBool("summarizer.validators.enable")
+	if validatorSummaries && !viper.GetBool("validators.balances.enable") {
+		problems = append(problems, "summarizer.validators.enable requires validators.balances.enable")
+	}
+	if summarizer && !validatorSummaries {
+		if len(viper.GetStringSlice("summarizer.validators.periods")) > 0 {
+			problems = append(problems, "summarizer.validators.periods requires summarizer.validators.enable")
+		}
+		if viper.GetUint64("summarizer.validators.provisional-epochs") > 0 {
+			problems = append(problems, "summarizer.validators.provisional-epochs requires summarizer.validators.enable")
+		}
+	}
+	if summarizer && viper.GetBool("summarizer.deposits.enable") && !viper.GetBool("eth1deposits.enable") {
+		problems = append(problems, "summarizer.deposits.enable requires eth1deposits.enable")
+	}
+	if viper.GetBool("validators.inactivity-scores.enable") && !viper.GetBool("validators.balances.enable") {
+		problems = append(problems, "validators.inactivity-scores.enable requires validators.balances.enable")
+	}
+	if viper.GetBool("eth1deposits.enable") && viper.GetString("eth1client.address") == "" {
+		problems = append(problems, "eth1deposits.enable requires eth1client.address")
+	}
+	if viper.GetBool("mevrelays.enable") && len(viper.GetStringSlice("mevrelays.relays")) == 0 {
+		problems = append(problems, "mevrelays.enable requires mevrelays.relays")
+	}
+	if viper.GetString("admin.listen-address") != "" && viper.GetString("admin.token") == "" {
+		problems = append(problems, "admin.listen-address requires admin.token")
+	}
+
+	return problems
+}
@@ -0,0 +1,123 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	postgresqlchaindb "github.com/wealdtech/chaind/services/chaindb/postgresql"
+)
+
+// dbCommandRequested returns true if a database command has been requested.
+func dbCommandRequested() bool {
+	return pflag.NArg() > 0 && pflag.Arg(0) == "db"
+}
+
+// runDBCommand runs the requested database command.
+func runDBCommand(ctx context.Context) error {
+	if pflag.NArg() != 2 {
+		return errors.New("usage: chaind db status|migrate [--dry-run]")
+	}
+
+	chainDB, err := startDatabase(ctx, nil)
+	if err != nil {
+		return err
+	}
+	upgrader, isUpgrader := chainDB.(*postgresqlchaindb.Service)
+	if !isUpgrader {
+		return errors.New("chain database does not support upgrades")
+	}
+
+	switch pflag.Arg(1) {
+	case "status":
+		plan, err := upgrader.UpgradePlan(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to obtain upgrade plan")
+		}
+		printUpgradePlan(plan)
+	case "migrate":
+		if viper.GetBool("dry-run") {
+			plan, err := upgrader.DryRunUpgrade(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to dry run upgrade")
+			}
+			printUpgradeStatements(plan)
+			return nil
+		}
+		requiresRefetch, err := upgrader.Upgrade(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to upgrade chain database")
+		}
+		if requiresRefetch {
+			fmt.Println("Upgrade complete.  Blocks must be refetched: start chaind with --blocks.start-slot=0 --blocks.refetch=true")
+		} else {
+			fmt.Println("Upgrade complete")
+		}
+	default:
+		return fmt.Errorf("unknown database command %q", pflag.Arg(1))
+	}
+
+	return nil
+}
+
+// printUpgradePlan prints the status of the database schema.
+func printUpgradePlan(plan *postgresqlchaindb.UpgradePlan) {
+	if !plan.Initialized {
+		fmt.Printf("Database not initialised; schema version %d will be created\n", plan.RequiredVersion)
+		return
+	}
+
+	fmt.Printf("Current schema version: %d\n", plan.CurrentVersion)
+	fmt.Printf("Required schema version: %d\n", plan.RequiredVersion)
+	if plan.CurrentVersion > plan.RequiredVersion {
+		fmt.Println("The database schema is newer than this release; please upgrade chaind")
+		return
+	}
+	if len(plan.Steps) == 0 {
+		fmt.Println("No upgrade required")
+		return
+	}
+	fmt.Println("Pending upgrade steps:")
+	for _, step := range plan.Steps {
+		fmt.Printf("  %d %s\n", step.Version, step.Name)
+	}
+	if plan.RequiresRefetch {
+		fmt.Println("The upgrade requires blocks to be refetched")
+	} else {
+		fmt.Println("The upgrade does not require blocks to be refetched")
+	}
+}
+
+// printUpgradeStatements prints the SQL statements that would be executed by an upgrade.
+func printUpgradeStatements(plan *postgresqlchaindb.UpgradePlan) {
+	if len(plan.Steps) == 0 {
+		fmt.Println("-- No upgrade required")
+		return
+	}
+	for _, step := range plan.Steps {
+		fmt.Printf("-- Version %d: %s\n", step.Version, step.Name)
+		for _, statement := range step.Statements {
+			fmt.Printf("%s;\n", strings.TrimSuffix(statement, ";"))
+		}
+		fmt.Println()
+	}
+	if plan.RequiresRefetch {
+		fmt.Println("-- The upgrade requires blocks to be refetched")
+	}
+}
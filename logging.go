@@ -26,6 +26,9 @@ import (
 // log.
 var log zerolog.Logger
 
+// logLevel is the log level of the main module, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // initLogging initialises logging.
 func initLogging() error {
 	// We set the global logging level to trace, because if the global log level is higher than the
@@ -42,7 +45,8 @@ func initLogging() error {
 	}
 
 	// Set the local logger from the global logger.
-	log = zerologger.Logger.With().Logger().Level(util.LogLevel(""))
+	logLevel.SetLevel(util.LogLevel(""))
+	log = logLevel.Apply(zerologger.Logger.With().Logger())
 
 	return nil
 }
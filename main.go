@@ -25,7 +25,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
-	"strings"
 	"syscall"
 	"time"
 
@@ -36,6 +35,8 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/wealdtech/chaind/handlers"
+	"github.com/wealdtech/chaind/services/admin"
+	standardadmin "github.com/wealdtech/chaind/services/admin/standard"
 	standardbeaconcommittees "github.com/wealdtech/chaind/services/beaconcommittees/standard"
 	"github.com/wealdtech/chaind/services/blocks"
 	standardblocks "github.com/wealdtech/chaind/services/blocks/standard"
@@ -48,7 +49,9 @@ import (
 	"github.com/wealdtech/chaind/services/metrics"
 	nullmetrics "github.com/wealdtech/chaind/services/metrics/null"
 	prometheusmetrics "github.com/wealdtech/chaind/services/metrics/prometheus"
+	standardmevrelays "github.com/wealdtech/chaind/services/mevrelays/standard"
 	standardproposerduties "github.com/wealdtech/chaind/services/proposerduties/standard"
+	"github.com/wealdtech/chaind/services/scheduler"
 	standardscheduler "github.com/wealdtech/chaind/services/scheduler/standard"
 	standardspec "github.com/wealdtech/chaind/services/spec/standard"
 	"github.com/wealdtech/chaind/services/summarizer"
@@ -69,6 +72,9 @@ func main() {
 func main2() int {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	// The database outlives the root context, so that in-flight work can complete on shutdown.
+	dbCtx, dbCancel := context.WithCancel(context.Background())
+	defer dbCancel()
 
 	if err := fetchConfig(); err != nil {
 		zerologger.Error().Err(err).Msg("Failed to fetch configuration")
@@ -85,6 +91,15 @@ func main2() int {
 		return 1
 	}
 
+	// Check the configuration before anything uses it.  Showing the version or the configuration
+	// does not require a valid configuration, so that problems can be investigated.
+	if !viper.GetBool("version") && !configCommandRequested() {
+		if err := validateConfig(); err != nil {
+			log.Error().Err(err).Msg("Configuration check failed")
+			return 1
+		}
+	}
+
 	// runCommands will not return if a command is run.
 	exit, err := runCommands(ctx)
 	if err != nil {
@@ -119,10 +134,18 @@ func main2() int {
 		log.Error().Err(err).Msg("Failed to register metrics")
 		return 1
 	}
+	if err := util.RegisterRetryMetrics(ctx, monitor); err != nil {
+		log.Error().Err(err).Msg("Failed to register retry metrics")
+		return 1
+	}
+	if err := util.SetRetryPolicy(configuredRetryPolicy()); err != nil {
+		log.Error().Err(err).Msg("Invalid retry configuration")
+		return 1
+	}
 	setRelease(ctx, ReleaseVersion)
 	setReady(ctx, false)
 
-	if err := startServices(ctx, monitor); err != nil {
+	if err := startServices(ctx, dbCtx, monitor); err != nil {
 		log.Error().Err(err).Msg("Failed to initialise services")
 		return 1
 	}
@@ -130,6 +153,8 @@ func main2() int {
 
 	log.Info().Msg("All services operational")
 
+	go reloadOnSignal(ctx)
+
 	// Wait for signal.
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
@@ -140,8 +165,17 @@ func main2() int {
 		}
 	}
 
-	log.Info().Msg("Stopping chaind")
-	return 0
+	log.Info().Dur("timeout", viper.GetDuration("shutdown.timeout")).Msg("Stopping chaind")
+	setReady(ctx, false)
+
+	// Cancelling the root context closes event subscriptions and cancels scheduled jobs, so that no
+	// new work starts while in-flight work drains.
+	cancel()
+	exitCode := shutdown(sigCh)
+	dbCancel()
+
+	log.Info().Int("exit_code", exitCode).Msg("Stopped chaind")
+	return exitCode
 }
 
 // fetchConfig fetches configuration from various sources.
@@ -154,6 +188,12 @@ func fetchConfig() error {
 	pflag.String("tracing-address", "", "Address to which to send tracing data")
 	pflag.String("eth2client.address", "", "Address for beacon node")
 	pflag.Duration("eth2client.timeout", 2*time.Minute, "Timeout for beacon node requests")
+	pflag.Float64("eth2client.governor.blocks.rate", 0, "Maximum block requests per second to the beacon node (0 for no limit)")
+	pflag.Int("eth2client.governor.blocks.concurrency", 4, "Maximum concurrent block requests to the beacon node (0 for no limit)")
+	pflag.Float64("eth2client.governor.duties.rate", 0, "Maximum committee and duty requests per second to the beacon node (0 for no limit)")
+	pflag.Int("eth2client.governor.duties.concurrency", 2, "Maximum concurrent committee and duty requests to the beacon node (0 for no limit)")
+	pflag.Float64("eth2client.governor.state.rate", 0, "Maximum state requests per second to the beacon node (0 for no limit)")
+	pflag.Int("eth2client.governor.state.concurrency", 2, "Maximum concurrent state requests to the beacon node (0 for no limit)")
 	pflag.Bool("blocks.enable", true, "Enable fetching of block-related information")
 	pflag.Int32("blocks.start-slot", -1, "Slot from which to start fetching blocks")
 	pflag.Bool("blocks.refetch", false, "Refetch all blocks even if they are already in the database")
@@ -162,18 +202,48 @@ func fetchConfig() error {
 	pflag.Bool("summarizer.epochs.enable", true, "Enable summary information for epochs")
 	pflag.Bool("summarizer.blocks.enable", true, "Enable summary information for blocks")
 	pflag.Bool("summarizer.validators.enable", false, "Enable summary information for validators (warning: creates a lot of data)")
-	pflag.Uint64("summarizer.max-days-per-run", 28, "Maximum number of days' of data to summarize in a single run (when pruning)")
+	pflag.StringSlice("summarizer.validators.periods", nil, "Periods, as ISO 8601 durations, for which to roll up validator day summaries (e.g. P7D,P1M,P1Y)")
+	pflag.Uint64("summarizer.validators.provisional-epochs", 0, "Number of recent unfinalized epochs for which to generate provisional validator summaries (0 to disable)")
+	pflag.Bool("summarizer.withdrawals.enable", false, "Enable summary information for withdrawals")
+	pflag.Bool("summarizer.deposits.enable", false, "Enable reconciliation of Ethereum 1 and beacon chain deposits (requires eth1deposits)")
+	pflag.Bool("summarizer.eth1votes.enable", false, "Enable summary information for Ethereum 1 data votes")
+	pflag.Int64("resummarize.from-epoch", -1, "Resummarize from this epoch, then exit")
+	pflag.Int64("resummarize.to-epoch", -1, "Resummarize up to and including this epoch, then exit")
+	pflag.String("resummarize.from-date", "", "Resummarize from this date (YYYY-MM-DD), then exit")
+	pflag.String("resummarize.to-date", "", "Resummarize up to and including this date (YYYY-MM-DD), then exit")
+	pflag.Uint64("summarizer.max-days-per-run", 28, "Maximum number of days' of data to process in a single run: limits client and withdrawal day summaries, deposit reconciliation, Ethereum 1 vote summaries, validator period rollups and pruning, epoch and validator epoch summaries when pruning, and the range of a resummarize")
 	pflag.Bool("validators.enable", true, "Enable fetching of validator-related information")
 	pflag.Bool("validators.balances.enable", false, "Enable fetching of validator balances (warning: creates a lot of data)")
+	pflag.Bool("validators.inactivity-scores.enable", false, "Enable fetching of validator inactivity scores along with balances (requires full beacon states)")
 	pflag.Bool("beacon-committees.enable", true, "Enable fetching of beacon committee-related information")
 	pflag.Bool("proposer-duties.enable", true, "Enable fetching of proposer duty-related information")
 	pflag.Bool("sync-committees.enable", true, "Enable fetching of sync committee-related information")
 	pflag.Int32("sync-committees.start-period", -1, "Period from which to start fetching sync committees")
 	pflag.Bool("eth1deposits.enable", false, "Enable fetching of Ethereum 1 deposit information")
 	pflag.String("eth1deposits.start-block", "", "Ethereum 1 block from which to start fetching deposits")
+	pflag.Uint64("eth1deposits.reorg-window", 128, "Number of recent Ethereum 1 blocks to check for reorgs")
+	pflag.Bool("eth1deposits.provisional", false, "Store deposits in Ethereum 1 blocks that are not yet confirmed, marked as provisional")
+	pflag.Bool("mevrelays.enable", false, "Enable fetching of payloads delivered by MEV relays")
+	pflag.StringSlice("mevrelays.relays", nil, "URLs of MEV relays from which to fetch payloads")
+	pflag.Duration("mevrelays.interval", time.Minute, "Interval between polls of MEV relays")
+	pflag.Int64("mevrelays.start-slot", -1, "Slot from which to start fetching payloads for new relays (-1 for recent payloads only)")
+	pflag.Bool("mevrelays.bid-timestamps", true, "Fetch the time at which relays received winning bids from builders")
+	pflag.Duration("mevrelays.bid-timestamps-delay", 100*time.Millisecond, "Minimum time between requests to a relay for bid timestamps")
 	pflag.String("eth1client.address", "", "Address for Ethereum 1 node")
 	pflag.String("chaindb.url", "", "URL for database")
 	pflag.Uint("chaindb.max-connections", 16, "maximum number of concurrent database connections")
+	pflag.StringSlice("chaindb.replica-urls", nil, "URLs for read replicas of the database, used for read-only transactions")
+	pflag.Bool("chaindb.auto-upgrade", true, "Upgrade the database schema automatically on start (if false, use 'chaind db migrate')")
+	pflag.Bool("dry-run", false, "Print the SQL for 'chaind db migrate' rather than running it")
+	pflag.Int("retry.max-attempts", util.DefaultRetryPolicy.MaxAttempts, "Maximum number of attempts for work that fails with a transient error")
+	pflag.Duration("retry.initial-delay", util.DefaultRetryPolicy.InitialDelay, "Delay before retrying work that fails with a transient error; doubles on each retry")
+	pflag.Duration("retry.max-delay", util.DefaultRetryPolicy.MaxDelay, "Maximum delay between retries")
+	pflag.String("admin.listen-address", "", "Address on which to listen for admin API requests (if empty, the admin API is disabled)")
+	pflag.String("admin.token", "", "Bearer token required for admin API requests")
+	pflag.Bool("scheduler.persist", true, "Persist recurring jobs and their history in the database")
+	pflag.Int("scheduler.history-length", 20, "Number of runs kept in the history of each scheduled job")
+	pflag.Duration("shutdown.timeout", time.Minute, "Time to wait for in-flight work to complete when stopping, including the abandon grace period")
+	pflag.Duration("shutdown.abandon-grace", 10*time.Second, "Time at the end of the shutdown timeout for cancelled in-flight work to roll back")
 	pflag.Parse()
 	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
 		return errors.Wrap(err, "failed to bind pflags to viper")
@@ -194,8 +264,8 @@ func fetchConfig() error {
 	}
 
 	// Environment settings.
-	viper.SetEnvPrefix("CHAIND")
-	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err != nil {
@@ -216,9 +286,27 @@ func fetchConfig() error {
 		}
 	}
 
+	// Keep the contents of the configuration file, so that they can be restored if a reload fails.
+	if viper.ConfigFileUsed() != "" {
+		var err error
+		configFileContents, err = os.ReadFile(viper.ConfigFileUsed())
+		if err != nil {
+			return errors.Wrap(err, "failed to read the configuration file")
+		}
+	}
+
 	return nil
 }
 
+// configuredRetryPolicy returns the configured policy for retrying work that fails with a transient error.
+func configuredRetryPolicy() util.RetryPolicy {
+	return util.RetryPolicy{
+		MaxAttempts:  viper.GetInt("retry.max-attempts"),
+		InitialDelay: viper.GetDuration("retry.initial-delay"),
+		MaxDelay:     viper.GetDuration("retry.max-delay"),
+	}
+}
+
 // initProfiling initialises the profiling server.
 func initProfiling() {
 	profileAddress := viper.GetString("profile-address")
@@ -248,6 +336,7 @@ func startMonitor(ctx context.Context) (metrics.Service, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to start prometheus metrics service")
 		}
+		registerReloadable("metrics.prometheus", monitor)
 		log.Info().Str("listen_address", viper.GetString("metrics.prometheus.listen-address")).Msg("Started prometheus metrics service")
 	} else {
 		log.Debug().Msg("No metrics service supplied; monitor not starting")
@@ -256,12 +345,14 @@ func startMonitor(ctx context.Context) (metrics.Service, error) {
 	return monitor, nil
 }
 
-func startDatabase(ctx context.Context) (chaindb.Service, error) {
+func startDatabase(ctx context.Context, monitor metrics.Service) (chaindb.Service, error) {
 	log.Trace().Msg("Starting chain database service")
 	chainDB, err := postgresqlchaindb.New(ctx,
 		postgresqlchaindb.WithLogLevel(util.LogLevel("chaindb")),
+		postgresqlchaindb.WithMonitor(monitor),
 		postgresqlchaindb.WithConnectionURL(viper.GetString("chaindb.url")),
 		postgresqlchaindb.WithMaxConnections(viper.GetUint("chaindb.max-connections")),
+		postgresqlchaindb.WithReplicaURLs(viper.GetStringSlice("chaindb.replica-urls")),
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to start chain database service")
@@ -269,28 +360,40 @@ func startDatabase(ctx context.Context) (chaindb.Service, error) {
 	return chainDB, err
 }
 
-func startServices(ctx context.Context, monitor metrics.Service) error {
+func startServices(ctx context.Context, dbCtx context.Context, monitor metrics.Service) error {
 	log.Trace().Msg("Checking for schema upgrades")
-	chainDB, err := startDatabase(ctx)
+	chainDB, err := startDatabase(dbCtx, monitor)
 	if err != nil {
 		return err
 	}
-
-	if _, isUpgrader := chainDB.(*postgresqlchaindb.Service); isUpgrader {
-		requiresRefetch, err := chainDB.(*postgresqlchaindb.Service).Upgrade(ctx)
-		if err != nil {
-			return errors.Wrap(err, "failed to upgrade chain database")
-		}
-		if requiresRefetch {
-			// The upgrade requires us to refetch blocks, so set up the options accordingly.
-			// These will be picked up by the blocks service.
-			viper.Set("blocks.start-slot", 0)
-			viper.Set("blocks.refetch", true)
+	registerReloadable("chaindb", chainDB)
+
+	if upgrader, isUpgrader := chainDB.(*postgresqlchaindb.Service); isUpgrader {
+		if !viper.GetBool("chaindb.auto-upgrade") {
+			// Upgrades are scheduled separately, so refuse to run against an outdated schema.
+			plan, err := upgrader.UpgradePlan(dbCtx)
+			if err != nil {
+				return errors.Wrap(err, "failed to obtain chain database upgrade plan")
+			}
+			if len(plan.Steps) > 0 {
+				return errors.New("chain database requires upgrade; run 'chaind db migrate'")
+			}
+		} else {
+			requiresRefetch, err := upgrader.Upgrade(dbCtx)
+			if err != nil {
+				return errors.Wrap(err, "failed to upgrade chain database")
+			}
+			if requiresRefetch {
+				// The upgrade requires us to refetch blocks, so set up the options accordingly.
+				// These will be picked up by the blocks service.
+				viper.Set("blocks.start-slot", 0)
+				viper.Set("blocks.refetch", true)
+			}
 		}
 	}
 
 	log.Trace().Msg("Starting Ethereum 2 client service")
-	eth2Client, err := fetchClient(ctx, viper.GetString("eth2client.address"))
+	eth2Client, err := fetchClient(ctx, monitor, viper.GetString("eth2client.address"))
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("eth2client.address")))
 	}
@@ -308,6 +411,13 @@ func startServices(ctx context.Context, monitor metrics.Service) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to start chain time service")
 	}
+	registerReloadable("chaintime", chainTime)
+
+	log.Trace().Msg("Starting scheduler")
+	schedulerSvc, err := startScheduler(ctx, chainDB, monitor)
+	if err != nil {
+		return errors.Wrap(err, "failed to start scheduler")
+	}
 
 	// Wait for chainstart.
 	specServiceStarted := false
@@ -316,7 +426,7 @@ func startServices(ctx context.Context, monitor metrics.Service) error {
 		// See if we can obtain spec before the chain starts.  Not all beacon nodes support this,
 		// so don't worry if it fails but do note it so that the service can be started later.
 		log.Trace().Msg("Starting spec service (speculative pre-chain)")
-		if err := startSpec(ctx, eth2Client, chainDB, monitor); err == nil {
+		if err := startSpec(ctx, eth2Client, chainDB, schedulerSvc, monitor); err == nil {
 			specServiceStarted = true
 		}
 
@@ -349,7 +459,7 @@ func startServices(ctx context.Context, monitor metrics.Service) error {
 	// chaindb so it is accessible to other services.
 	if !specServiceStarted {
 		log.Trace().Msg("Starting spec service")
-		if err := startSpec(ctx, eth2Client, chainDB, monitor); err != nil {
+		if err := startSpec(ctx, eth2Client, chainDB, schedulerSvc, monitor); err != nil {
 			return errors.Wrap(err, "failed to start spec service")
 		}
 	}
@@ -383,7 +493,8 @@ func startServices(ctx context.Context, monitor metrics.Service) error {
 	if summarizerSvc != nil {
 		finalityHandlers = append(finalityHandlers, summarizerSvc.(handlers.FinalityHandler))
 	}
-	if err := startFinalizer(ctx, eth2Client, chainDB, chainTime, blocks, monitor, finalityHandlers, activitySem); err != nil {
+	finalizer, err := startFinalizer(ctx, eth2Client, chainDB, chainTime, blocks, monitor, finalityHandlers, activitySem)
+	if err != nil {
 		return errors.Wrap(err, "failed to start finalizer service")
 	}
 
@@ -393,12 +504,14 @@ func startServices(ctx context.Context, monitor metrics.Service) error {
 	}
 
 	log.Trace().Msg("Starting beacon committees service")
-	if err := startBeaconCommittees(ctx, eth2Client, chainDB, chainTime, monitor); err != nil {
+	beaconCommittees, err := startBeaconCommittees(ctx, eth2Client, chainDB, chainTime, monitor)
+	if err != nil {
 		return errors.Wrap(err, "failed to start beacon committees service")
 	}
 
 	log.Trace().Msg("Starting proposer duties service")
-	if err := startProposerDuties(ctx, eth2Client, chainDB, chainTime, monitor); err != nil {
+	proposerDuties, err := startProposerDuties(ctx, eth2Client, chainDB, chainTime, monitor)
+	if err != nil {
 		return errors.Wrap(err, "failed to start proposer duties service")
 	}
 
@@ -407,6 +520,44 @@ func startServices(ctx context.Context, monitor metrics.Service) error {
 		return errors.Wrap(err, "failed to start Ethereum 1 deposits service")
 	}
 
+	log.Trace().Msg("Starting MEV relays service")
+	if err := startMEVRelays(ctx, chainDB, monitor); err != nil {
+		return errors.Wrap(err, "failed to start MEV relays service")
+	}
+
+	if viper.GetString("admin.listen-address") != "" {
+		log.Trace().Msg("Starting admin service")
+		params := []standardadmin.Parameter{
+			standardadmin.WithScheduler(schedulerSvc),
+			standardadmin.WithConfigReloader(&configReloader{}),
+		}
+		if blocks != nil {
+			params = append(params, standardadmin.WithBlocks(blocks.(admin.SlotRefetcher)))
+		}
+		if summarizerSvc != nil {
+			params = append(params, standardadmin.WithSummarizer(summarizerSvc.(admin.Maintainer)))
+		}
+		if finalizer != nil {
+			params = append(params, standardadmin.WithFinalizer(finalizer))
+		}
+		if beaconCommittees != nil {
+			params = append(params, standardadmin.WithBeaconCommittees(beaconCommittees))
+		}
+		if proposerDuties != nil {
+			params = append(params, standardadmin.WithProposerDuties(proposerDuties))
+		}
+		if err := startAdmin(ctx, monitor, params); err != nil {
+			return errors.Wrap(err, "failed to start admin service")
+		}
+	}
+
+	// All services have scheduled their recurring jobs, so any others are stale.
+	if pruner, isPruner := schedulerSvc.(scheduler.RecurringJobPruner); isPruner {
+		if err := pruner.PruneRecurringJobs(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to prune recurring jobs")
+		}
+	}
+
 	return nil
 }
 
@@ -442,28 +593,48 @@ func resolvePath(path string) string {
 	return filepath.Join(baseDir, path)
 }
 
+func startScheduler(
+	ctx context.Context,
+	chainDB chaindb.Service,
+	monitor metrics.Service,
+) (
+	scheduler.Service,
+	error,
+) {
+	schedulerParams := []standardscheduler.Parameter{
+		standardscheduler.WithLogLevel(util.LogLevel("scheduler")),
+		standardscheduler.WithMonitor(monitor),
+		standardscheduler.WithHistoryLength(viper.GetInt("scheduler.history-length")),
+	}
+	if viper.GetBool("scheduler.persist") {
+		schedulerParams = append(schedulerParams, standardscheduler.WithChainDB(chainDB))
+	}
+	s, err := standardscheduler.New(ctx, schedulerParams...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialise scheduler")
+	}
+	registerStoppable("scheduler", s)
+	registerReloadable("scheduler", s)
+
+	return s, nil
+}
+
 func startSpec(
 	ctx context.Context,
 	eth2Client eth2client.Service,
 	chainDB chaindb.Service,
+	scheduler scheduler.Service,
 	monitor metrics.Service,
 ) error {
 	var err error
 	if viper.GetString("spec.address") != "" {
-		eth2Client, err = fetchClient(ctx, viper.GetString("spec.address"))
+		eth2Client, err = fetchClient(ctx, monitor, viper.GetString("spec.address"))
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("spec.address")))
 		}
 	}
 
-	scheduler, err := standardscheduler.New(ctx,
-		standardscheduler.WithLogLevel(util.LogLevel("scheduler")),
-		standardscheduler.WithMonitor(monitor))
-	if err != nil {
-		return errors.Wrap(err, "failed to initialise scheduler")
-	}
-
-	_, err = standardspec.New(ctx,
+	s, err := standardspec.New(ctx,
 		standardspec.WithLogLevel(util.LogLevel("spec")),
 		standardspec.WithETH2Client(eth2Client),
 		standardspec.WithChainDB(chainDB),
@@ -472,6 +643,7 @@ func startSpec(
 	if err != nil {
 		return errors.Wrap(err, "failed to create spec service")
 	}
+	registerReloadable("spec", s)
 
 	return nil
 }
@@ -493,7 +665,7 @@ func startBlocks(
 
 	var err error
 	if viper.GetString("blocks.address") != "" {
-		eth2Client, err = fetchClient(ctx, viper.GetString("blocks.address"))
+		eth2Client, err = fetchClient(ctx, monitor, viper.GetString("blocks.address"))
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("blocks.address")))
 		}
@@ -512,6 +684,8 @@ func startBlocks(
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create blocks service")
 	}
+	registerStoppable("blocks", s)
+	registerReloadable("blocks", s)
 
 	return s, nil
 }
@@ -525,20 +699,23 @@ func startFinalizer(
 	monitor metrics.Service,
 	finalityHandlers []handlers.FinalityHandler,
 	activitySem *semaphore.Weighted,
-) error {
+) (
+	*standardfinalizer.Service,
+	error,
+) {
 	if !viper.GetBool("finalizer.enable") {
-		return nil
+		return nil, nil
 	}
 
 	var err error
 	if viper.GetString("finalizer.address") != "" {
-		eth2Client, err = fetchClient(ctx, viper.GetString("finalizer.address"))
+		eth2Client, err = fetchClient(ctx, monitor, viper.GetString("finalizer.address"))
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("finalizer.address")))
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("finalizer.address")))
 		}
 	}
 
-	_, err = standardfinalizer.New(ctx,
+	s, err := standardfinalizer.New(ctx,
 		standardfinalizer.WithLogLevel(util.LogLevel("finalizer")),
 		standardfinalizer.WithMonitor(monitor),
 		standardfinalizer.WithETH2Client(eth2Client),
@@ -549,10 +726,12 @@ func startFinalizer(
 		standardfinalizer.WithActivitySem(activitySem),
 	)
 	if err != nil {
-		return errors.Wrap(err, "failed to create finalizer service")
+		return nil, errors.Wrap(err, "failed to create finalizer service")
 	}
+	registerStoppable("finalizer", s)
+	registerReloadable("finalizer", s)
 
-	return nil
+	return s, nil
 }
 
 func startSummarizer(
@@ -578,17 +757,36 @@ func startSummarizer(
 		standardsummarizer.WithEpochSummaries(viper.GetBool("summarizer.epochs.enable")),
 		standardsummarizer.WithBlockSummaries(viper.GetBool("summarizer.blocks.enable")),
 		standardsummarizer.WithValidatorSummaries(viper.GetBool("summarizer.validators.enable")),
+		standardsummarizer.WithWithdrawalSummaries(viper.GetBool("summarizer.withdrawals.enable")),
+		standardsummarizer.WithDepositReconciliation(viper.GetBool("summarizer.deposits.enable")),
+		standardsummarizer.WithETH1VoteSummaries(viper.GetBool("summarizer.eth1votes.enable")),
+		standardsummarizer.WithInactivityScores(viper.GetBool("validators.inactivity-scores.enable")),
 		standardsummarizer.WithMaxDaysPerRun(viper.GetUint64("summarizer.max-days-per-run")),
 		standardsummarizer.WithValidatorEpochRetention(viper.GetString("summarizer.validators.epoch-retention")),
 		standardsummarizer.WithValidatorBalanceRetention(viper.GetString("summarizer.validators.balance-retention")),
+		standardsummarizer.WithValidatorPeriods(viper.GetStringSlice("summarizer.validators.periods")),
+		standardsummarizer.WithProvisionalEpochs(viper.GetUint64("summarizer.validators.provisional-epochs")),
+		standardsummarizer.WithRawRetentions(summarizerRawRetentions()),
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create summarizer service")
 	}
+	registerStoppable("summarizer", standardSummarizer)
+	registerReloadable("summarizer", standardSummarizer)
 
 	return standardSummarizer, nil
 }
 
+// summarizerRawRetentions returns the configured retentions of raw data, keyed by table.
+func summarizerRawRetentions() map[string]string {
+	return map[string]string{
+		"attestations":      viper.GetString("summarizer.retention.attestations"),
+		"beacon-committees": viper.GetString("summarizer.retention.beacon-committees"),
+		"sync-aggregates":   viper.GetString("summarizer.retention.sync-aggregates"),
+		"sync-committees":   viper.GetString("summarizer.retention.sync-committees"),
+	}
+}
+
 func startValidators(
 	ctx context.Context,
 	eth2Client eth2client.Service,
@@ -602,23 +800,26 @@ func startValidators(
 
 	var err error
 	if viper.GetString("validators.address") != "" {
-		eth2Client, err = fetchClient(ctx, viper.GetString("validators.address"))
+		eth2Client, err = fetchClient(ctx, monitor, viper.GetString("validators.address"))
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("validators.address")))
 		}
 	}
 
-	_, err = standardvalidators.New(ctx,
+	s, err := standardvalidators.New(ctx,
 		standardvalidators.WithLogLevel(util.LogLevel("validators")),
 		standardvalidators.WithMonitor(monitor),
 		standardvalidators.WithETH2Client(eth2Client),
 		standardvalidators.WithChainTime(chainTime),
 		standardvalidators.WithChainDB(chainDB),
 		standardvalidators.WithBalances(viper.GetBool("validators.balances.enable")),
+		standardvalidators.WithInactivityScores(viper.GetBool("validators.inactivity-scores.enable")),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to create validators service")
 	}
+	registerStoppable("validators", s)
+	registerReloadable("validators", s)
 
 	return nil
 }
@@ -629,20 +830,23 @@ func startBeaconCommittees(
 	chainDB chaindb.Service,
 	chainTime chaintime.Service,
 	monitor metrics.Service,
-) error {
+) (
+	*standardbeaconcommittees.Service,
+	error,
+) {
 	if !viper.GetBool("beacon-committees.enable") {
-		return nil
+		return nil, nil
 	}
 
 	var err error
 	if viper.GetString("beacon-committees.address") != "" {
-		eth2Client, err = fetchClient(ctx, viper.GetString("beacon-committees.address"))
+		eth2Client, err = fetchClient(ctx, monitor, viper.GetString("beacon-committees.address"))
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("beacon-committees.address")))
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("beacon-committees.address")))
 		}
 	}
 
-	_, err = standardbeaconcommittees.New(ctx,
+	s, err := standardbeaconcommittees.New(ctx,
 		standardbeaconcommittees.WithLogLevel(util.LogLevel("beacon-committees")),
 		standardbeaconcommittees.WithMonitor(monitor),
 		standardbeaconcommittees.WithETH2Client(eth2Client),
@@ -650,10 +854,12 @@ func startBeaconCommittees(
 		standardbeaconcommittees.WithChainDB(chainDB),
 	)
 	if err != nil {
-		return errors.Wrap(err, "failed to create beacon committees service")
+		return nil, errors.Wrap(err, "failed to create beacon committees service")
 	}
+	registerStoppable("beacon-committees", s)
+	registerReloadable("beacon-committees", s)
 
-	return nil
+	return s, nil
 }
 
 func startProposerDuties(
@@ -662,20 +868,23 @@ func startProposerDuties(
 	chainDB chaindb.Service,
 	chainTime chaintime.Service,
 	monitor metrics.Service,
-) error {
+) (
+	*standardproposerduties.Service,
+	error,
+) {
 	if !viper.GetBool("proposer-duties.enable") {
-		return nil
+		return nil, nil
 	}
 
 	var err error
 	if viper.GetString("proposer-duties.address") != "" {
-		eth2Client, err = fetchClient(ctx, viper.GetString("proposer-duties.address"))
+		eth2Client, err = fetchClient(ctx, monitor, viper.GetString("proposer-duties.address"))
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("proposer-duties.address")))
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("proposer-duties.address")))
 		}
 	}
 
-	_, err = standardproposerduties.New(ctx,
+	s, err := standardproposerduties.New(ctx,
 		standardproposerduties.WithLogLevel(util.LogLevel("proposer-duties")),
 		standardproposerduties.WithMonitor(monitor),
 		standardproposerduties.WithETH2Client(eth2Client),
@@ -683,10 +892,12 @@ func startProposerDuties(
 		standardproposerduties.WithChainDB(chainDB),
 	)
 	if err != nil {
-		return errors.Wrap(err, "failed to create proposer duties service")
+		return nil, errors.Wrap(err, "failed to create proposer duties service")
 	}
+	registerStoppable("proposer-duties", s)
+	registerReloadable("proposer-duties", s)
 
-	return nil
+	return s, nil
 }
 
 func startETH1Deposits(
@@ -699,7 +910,7 @@ func startETH1Deposits(
 	}
 
 	log.Trace().Msg("Starting Ethereum 1 deposits service")
-	_, err := getlogseth1deposits.New(ctx,
+	s, err := getlogseth1deposits.New(ctx,
 		getlogseth1deposits.WithLogLevel(util.LogLevel("eth1deposits.log-level")),
 		getlogseth1deposits.WithMonitor(monitor),
 		getlogseth1deposits.WithChainDB(chainDB),
@@ -707,10 +918,43 @@ func startETH1Deposits(
 		getlogseth1deposits.WithStartBlock(viper.GetString("eth1deposits.start-block")),
 		getlogseth1deposits.WithETH1DepositsSetter(chainDB.(chaindb.ETH1DepositsSetter)),
 		getlogseth1deposits.WithETH1Confirmations(viper.GetUint64("eth1deposits.confirmations")),
+		getlogseth1deposits.WithReorgWindow(viper.GetUint64("eth1deposits.reorg-window")),
+		getlogseth1deposits.WithProvisionalDeposits(viper.GetBool("eth1deposits.provisional")),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to start Ethereum 1 deposits service")
 	}
+	registerStoppable("eth1deposits", s)
+	registerReloadable("eth1deposits", s)
+
+	return nil
+}
+
+func startMEVRelays(
+	ctx context.Context,
+	chainDB chaindb.Service,
+	monitor metrics.Service,
+) error {
+	if !viper.GetBool("mevrelays.enable") {
+		return nil
+	}
+
+	s, err := standardmevrelays.New(ctx,
+		standardmevrelays.WithLogLevel(util.LogLevel("mevrelays.log-level")),
+		standardmevrelays.WithMonitor(monitor),
+		standardmevrelays.WithChainDB(chainDB),
+		standardmevrelays.WithRelayPayloadsSetter(chainDB.(chaindb.RelayPayloadsSetter)),
+		standardmevrelays.WithRelays(viper.GetStringSlice("mevrelays.relays")),
+		standardmevrelays.WithInterval(viper.GetDuration("mevrelays.interval")),
+		standardmevrelays.WithStartSlot(viper.GetInt64("mevrelays.start-slot")),
+		standardmevrelays.WithBidTimestamps(viper.GetBool("mevrelays.bid-timestamps")),
+		standardmevrelays.WithBidTimestampsDelay(viper.GetDuration("mevrelays.bid-timestamps-delay")),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to start MEV relays service")
+	}
+	registerStoppable("mevrelays", s)
+	registerReloadable("mevrelays", s)
 
 	return nil
 }
@@ -728,13 +972,13 @@ func startSyncCommittees(
 
 	var err error
 	if viper.GetString("sync-committees.address") != "" {
-		eth2Client, err = fetchClient(ctx, viper.GetString("sync-committees.address"))
+		eth2Client, err = fetchClient(ctx, monitor, viper.GetString("sync-committees.address"))
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("sync-committees.address")))
 		}
 	}
 
-	_, err = standardsynccommittees.New(ctx,
+	s, err := standardsynccommittees.New(ctx,
 		standardsynccommittees.WithLogLevel(util.LogLevel("sync-committees")),
 		standardsynccommittees.WithMonitor(monitor),
 		standardsynccommittees.WithETH2Client(eth2Client),
@@ -746,19 +990,53 @@ func startSyncCommittees(
 	if err != nil {
 		return errors.Wrap(err, "failed to create sync committees service")
 	}
+	registerStoppable("sync-committees", s)
+	registerReloadable("sync-committees", s)
 
 	return nil
 }
 
 // runCommands runs commands if required.
 // Returns true if an exit is required.
-//
-//nolint:unparam
-func runCommands(_ context.Context) (bool, error) {
+func runCommands(ctx context.Context) (bool, error) {
 	if viper.GetBool("version") {
 		fmt.Printf("%s\n", ReleaseVersion)
 		return true, nil
 	}
 
+	if configCommandRequested() {
+		return true, runConfigCommand()
+	}
+
+	if resummarizeRequested() {
+		return true, runResummarize(ctx)
+	}
+
+	if dbCommandRequested() {
+		return true, runDBCommand(ctx)
+	}
+
 	return false, nil
 }
+
+func startAdmin(
+	ctx context.Context,
+	monitor metrics.Service,
+	params []standardadmin.Parameter,
+) error {
+	params = append(params,
+		standardadmin.WithLogLevel(util.LogLevel("admin")),
+		standardadmin.WithMonitor(monitor),
+		standardadmin.WithListenAddress(viper.GetString("admin.listen-address")),
+		standardadmin.WithToken(viper.GetString("admin.token")),
+	)
+	s, err := standardadmin.New(ctx, params...)
+	if err != nil {
+		return errors.Wrap(err, "failed to create admin service")
+	}
+	registerStoppable("admin", s)
+	registerReloadable("admin", s)
+	log.Info().Str("listen_address", viper.GetString("admin.listen-address")).Msg("Started admin service")
+
+	return nil
+}
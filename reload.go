@@ -0,0 +1,250 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/wealdtech/chaind/services/admin"
+	"github.com/wealdtech/chaind/services/governor"
+	standardgovernor "github.com/wealdtech/chaind/services/governor/standard"
+	standardsummarizer "github.com/wealdtech/chaind/services/summarizer/standard"
+	"github.com/wealdtech/chaind/util"
+)
+
+// logLevelSetter is a service whose log level can be changed whilst running.
+type logLevelSetter interface {
+	SetLogLevel(level zerolog.Level)
+}
+
+// reloadable is a running service that can be reconfigured.
+type reloadable struct {
+	// name is the configuration path of the module.
+	name    string
+	service interface{}
+}
+
+var (
+	reloadablesMu sync.Mutex
+	reloadables   []*reloadable
+
+	// reloadMu serializes reloads of the configuration.
+	reloadMu sync.Mutex
+	// configFileContents are the contents of the configuration file that is in use, so that they
+	// can be restored if a reload fails.
+	configFileContents []byte
+)
+
+// registerReloadable registers a service to be reconfigured when the configuration is reloaded.
+func registerReloadable(name string, service interface{}) {
+	reloadablesMu.Lock()
+	reloadables = append(reloadables, &reloadable{
+		name:    name,
+		service: service,
+	})
+	reloadablesMu.Unlock()
+}
+
+// reloadableConfigKey returns true if changes to the configuration key can be applied whilst running.
+func reloadableConfigKey(key string) bool {
+	switch {
+	case key == "log-level", strings.HasSuffix(key, ".log-level"):
+		return true
+	case key == "summarizer.max-days-per-run",
+		key == "summarizer.validators.epoch-retention",
+		key == "summarizer.validators.balance-retention",
+		strings.HasPrefix(key, "summarizer.retention."):
+		return true
+	case strings.HasPrefix(key, "eth2client.governor."),
+		strings.HasPrefix(key, "retry."),
+		key == "shutdown.timeout",
+		key == "shutdown.abandon-grace":
+		return true
+	default:
+		return false
+	}
+}
+
+// reloadOnSignal reloads the configuration each time that SIGHUP is received, until the context is done.
+func reloadOnSignal(ctx context.Context) {
+	hupCh := make(chan os.Signal, 1)
+	// The channel is not stopped, as that would restore the default behaviour of exiting on SIGHUP.
+	signal.Notify(hupCh, syscall.SIGHUP)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupCh:
+			log.Info().Msg("Received SIGHUP; reloading configuration")
+			if _, err := reloadConfig(); err != nil {
+				log.Error().Err(err).Msg("Failed to reload configuration")
+			}
+		}
+	}
+}
+
+// configReloader reloads the configuration on behalf of the admin service.
+type configReloader struct{}
+
+// ReloadConfig reloads the configuration, applying the changes that can be made whilst running.
+func (*configReloader) ReloadConfig(_ context.Context) (*admin.ConfigReload, error) {
+	return reloadConfig()
+}
+
+// reloadConfig re-reads the configuration file, and applies the changes that can be made whilst
+// running.  If the new configuration is invalid it is rejected, and the existing configuration
+// remains in use.  Configuration from flags and the environment cannot change whilst running.
+func reloadConfig() (*admin.ConfigReload, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	filename := viper.ConfigFileUsed()
+	if filename == "" {
+		return nil, errors.New("no configuration file in use")
+	}
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read configuration file")
+	}
+
+	previous := resolvedConfig()
+	if err := viper.ReadConfig(bytes.NewReader(contents)); err != nil {
+		restoreConfig()
+		return nil, errors.Wrap(err, "could not parse the configuration file")
+	}
+	if err := validateConfig(); err != nil {
+		restoreConfig()
+		return nil, err
+	}
+	previousContents := configFileContents
+	configFileContents = contents
+
+	res := &admin.ConfigReload{
+		Applied:         make([]string, 0),
+		RestartRequired: make([]string, 0),
+	}
+	for _, key := range changedConfigKeys(previous, resolvedConfig()) {
+		if reloadableConfigKey(key) {
+			res.Applied = append(res.Applied, key)
+		} else {
+			res.RestartRequired = append(res.RestartRequired, key)
+		}
+	}
+
+	if err := applyConfig(); err != nil {
+		// Return to the previous configuration, which was applied successfully.
+		configFileContents = previousContents
+		restoreConfig()
+		if err := applyConfig(); err != nil {
+			log.Error().Err(err).Msg("Failed to reapply previous configuration")
+		}
+		return nil, err
+	}
+
+	if len(res.RestartRequired) > 0 {
+		log.Warn().Strs("keys", res.RestartRequired).Msg("Configuration changes require a restart to take effect")
+	}
+	log.Info().Strs("applied", res.Applied).Msg("Reloaded configuration")
+
+	return res, nil
+}
+
+// restoreConfig restores the configuration file contents that were in use before a failed reload.
+func restoreConfig() {
+	if err := viper.ReadConfig(bytes.NewReader(configFileContents)); err != nil {
+		// Should not happen, as these contents were accepted previously.
+		log.Error().Err(err).Msg("Failed to restore configuration")
+	}
+}
+
+// resolvedConfig returns the resolved value of each configuration key that has a value.
+func resolvedConfig() map[string]string {
+	res := make(map[string]string)
+	for key := range knownConfigKeys() {
+		if configSource(key) != "" {
+			res[key] = configValue(key)
+		}
+	}
+
+	return res
+}
+
+// changedConfigKeys returns the keys whose values differ between two resolved configurations.
+func changedConfigKeys(previous map[string]string, current map[string]string) []string {
+	keys := make([]string, 0)
+	for key, value := range current {
+		if previousValue, exists := previous[key]; !exists || previousValue != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range previous {
+		if _, exists := current[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// applyConfig applies the settings that can be changed whilst running to the registered services.
+func applyConfig() error {
+	logLevel.SetLevel(util.LogLevel(""))
+	if err := util.SetRetryPolicy(configuredRetryPolicy()); err != nil {
+		return errors.Wrap(err, "invalid retry configuration")
+	}
+
+	reloadablesMu.Lock()
+	services := make([]*reloadable, len(reloadables))
+	copy(services, reloadables)
+	reloadablesMu.Unlock()
+
+	for _, r := range services {
+		if setter, isSetter := r.service.(logLevelSetter); isSetter {
+			setter.SetLogLevel(util.LogLevel(r.name))
+		}
+
+		switch service := r.service.(type) {
+		case *standardsummarizer.Service:
+			if err := service.SetMaxDaysPerRun(viper.GetUint64("summarizer.max-days-per-run")); err != nil {
+				return errors.Wrap(err, "failed to set summarizer max days per run")
+			}
+			if err := service.SetRetentions(viper.GetString("summarizer.validators.epoch-retention"),
+				viper.GetString("summarizer.validators.balance-retention"),
+				summarizerRawRetentions(),
+			); err != nil {
+				return errors.Wrap(err, "failed to set summarizer retentions")
+			}
+		case *standardgovernor.Service:
+			for _, class := range governor.Classes {
+				if err := service.SetLimit(class, governorLimit(class)); err != nil {
+					return errors.Wrap(err, fmt.Sprintf("failed to set governor limit for %s", class))
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	postgresqlchaindb "github.com/wealdtech/chaind/services/chaindb/postgresql"
+	"github.com/wealdtech/chaind/services/chaintime"
+	standardchaintime "github.com/wealdtech/chaind/services/chaintime/standard"
+	nullmetrics "github.com/wealdtech/chaind/services/metrics/null"
+	"github.com/wealdtech/chaind/services/summarizer"
+	"github.com/wealdtech/chaind/util"
+)
+
+// resummarizeRequested returns true if a resummarize command has been requested.
+func resummarizeRequested() bool {
+	return viper.GetInt64("resummarize.from-epoch") != -1 ||
+		viper.GetInt64("resummarize.to-epoch") != -1 ||
+		viper.GetString("resummarize.from-date") != "" ||
+		viper.GetString("resummarize.to-date") != ""
+}
+
+// runResummarize recomputes summaries for the range of epochs or days supplied in configuration.
+func runResummarize(ctx context.Context) error {
+	chainDB, err := startDatabase(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := chainDB.(*postgresqlchaindb.Service).Upgrade(ctx); err != nil {
+		return errors.Wrap(err, "failed to upgrade chain database")
+	}
+
+	eth2Client, err := fetchClient(ctx, nil, viper.GetString("eth2client.address"))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to fetch client %q", viper.GetString("eth2client.address")))
+	}
+
+	chainTime, err := standardchaintime.New(ctx,
+		standardchaintime.WithLogLevel(util.LogLevel("chaintime")),
+		standardchaintime.WithGenesisTimeProvider(eth2Client.(eth2client.GenesisTimeProvider)),
+		standardchaintime.WithSpecProvider(eth2Client.(eth2client.SpecProvider)),
+		standardchaintime.WithForkScheduleProvider(eth2Client.(eth2client.ForkScheduleProvider)),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to start chain time service")
+	}
+
+	fromEpoch, toEpoch, err := resummarizeRange(chainTime)
+	if err != nil {
+		return err
+	}
+
+	summarizerSvc, err := startSummarizer(ctx, eth2Client, chainDB, chainTime, &nullmetrics.Service{})
+	if err != nil {
+		return errors.Wrap(err, "failed to start summarizer service")
+	}
+	if summarizerSvc == nil {
+		return errors.New("summarizer not enabled")
+	}
+	resummarizer, isResummarizer := summarizerSvc.(summarizer.Resummarizer)
+	if !isResummarizer {
+		return errors.New("summarizer does not support resummarizing")
+	}
+
+	log.Info().Uint64("from_epoch", uint64(fromEpoch)).Uint64("to_epoch", uint64(toEpoch)).Msg("Resummarizing")
+	if err := resummarizer.Resummarize(ctx, fromEpoch, toEpoch); err != nil {
+		return errors.Wrap(err, "failed to resummarize")
+	}
+
+	return nil
+}
+
+// resummarizeRange obtains the range of epochs to resummarize from configuration.
+// Dates are inclusive, so the range covers all epochs that start on or after the start of
+// the from date and end on or before the end of the to date.
+func resummarizeRange(chainTime chaintime.Service) (phase0.Epoch, phase0.Epoch, error) {
+	fromEpochSet := viper.GetInt64("resummarize.from-epoch") != -1 || viper.GetInt64("resummarize.to-epoch") != -1
+	fromDateSet := viper.GetString("resummarize.from-date") != "" || viper.GetString("resummarize.to-date") != ""
+
+	switch {
+	case fromEpochSet && fromDateSet:
+		return 0, 0, errors.New("cannot resummarize both epochs and dates")
+	case fromEpochSet:
+		fromEpoch := viper.GetInt64("resummarize.from-epoch")
+		toEpoch := viper.GetInt64("resummarize.to-epoch")
+		if fromEpoch < 0 || toEpoch < 0 {
+			return 0, 0, errors.New("both resummarize.from-epoch and resummarize.to-epoch are required")
+		}
+		if fromEpoch > toEpoch {
+			return 0, 0, errors.New("resummarize.from-epoch must not be after resummarize.to-epoch")
+		}
+		return phase0.Epoch(fromEpoch), phase0.Epoch(toEpoch), nil
+	default:
+		if viper.GetString("resummarize.from-date") == "" || viper.GetString("resummarize.to-date") == "" {
+			return 0, 0, errors.New("both resummarize.from-date and resummarize.to-date are required")
+		}
+		fromDate, err := time.ParseInLocation("2006-01-02", viper.GetString("resummarize.from-date"), time.UTC)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "invalid resummarize.from-date")
+		}
+		toDate, err := time.ParseInLocation("2006-01-02", viper.GetString("resummarize.to-date"), time.UTC)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "invalid resummarize.to-date")
+		}
+		if fromDate.After(toDate) {
+			return 0, 0, errors.New("resummarize.from-date must not be after resummarize.to-date")
+		}
+		fromEpoch := chainTime.TimestampToEpoch(fromDate)
+		toEpoch := chainTime.TimestampToEpoch(toDate.AddDate(0, 0, 1))
+		if toEpoch == 0 {
+			return 0, 0, errors.New("resummarize.to-date is before genesis")
+		}
+		return fromEpoch, toEpoch - 1, nil
+	}
+}
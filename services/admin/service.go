@@ -0,0 +1,91 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// Service is an admin service.
+type Service interface{}
+
+// SlotRefetcher is the interface for a service that can refetch the data for a slot.
+type SlotRefetcher interface {
+	// RefetchSlot refetches the data for the given slot, replacing any existing data.
+	RefetchSlot(ctx context.Context, slot phase0.Slot) error
+}
+
+// EpochRefetcher is the interface for a service that can refetch the data for an epoch.
+type EpochRefetcher interface {
+	// RefetchEpoch refetches the data for the given epoch, replacing any existing data.
+	RefetchEpoch(ctx context.Context, epoch phase0.Epoch) error
+}
+
+// Refinalizer is the interface for a service that can re-run finality processing.
+type Refinalizer interface {
+	// Refinalize re-runs finality processing from the given epoch to the current finalized checkpoint.
+	Refinalize(ctx context.Context, fromEpoch phase0.Epoch) error
+}
+
+// Maintainer is the interface for a service that carries out periodic maintenance.
+type Maintainer interface {
+	// Prune prunes data according to the configured retention policies.
+	Prune(ctx context.Context) error
+
+	// Rollup rolls up summaries in to longer periods.
+	Rollup(ctx context.Context) error
+}
+
+// ConfigReloader is the interface for a service that can reload the configuration whilst running.
+type ConfigReloader interface {
+	// ReloadConfig reloads the configuration, applying the changes that can be made whilst running.
+	ReloadConfig(ctx context.Context) (*ConfigReload, error)
+}
+
+// ConfigReload is the result of reloading the configuration.
+type ConfigReload struct {
+	// Applied are the configuration keys whose changes have been applied.
+	Applied []string `json:"applied"`
+	// RestartRequired are the configuration keys whose changes require a restart to take effect.
+	RestartRequired []string `json:"restart_required"`
+}
+
+// OperationState is the state of an operation.
+type OperationState string
+
+const (
+	// OperationRunning is an operation that is running.
+	OperationRunning OperationState = "running"
+	// OperationSucceeded is an operation that completed successfully.
+	OperationSucceeded OperationState = "succeeded"
+	// OperationFailed is an operation that stopped due to an error.
+	OperationFailed OperationState = "failed"
+	// OperationCancelled is an operation that was cancelled before it completed.
+	OperationCancelled OperationState = "cancelled"
+)
+
+// Operation is a maintenance operation triggered through the admin API.
+type Operation struct {
+	ID       uint64         `json:"id"`
+	Type     string         `json:"type"`
+	State    OperationState `json:"state"`
+	Done     uint64         `json:"done"`
+	Total    uint64         `json:"total"`
+	Started  time.Time      `json:"started"`
+	Finished *time.Time     `json:"finished,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
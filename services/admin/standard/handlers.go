@@ -0,0 +1,335 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/admin"
+	"github.com/wealdtech/chaind/services/scheduler"
+)
+
+// slotRangeRequest is a request for an operation over a range of slots, inclusive.
+type slotRangeRequest struct {
+	FromSlot phase0.Slot `json:"from_slot"`
+	ToSlot   phase0.Slot `json:"to_slot"`
+}
+
+// epochRangeRequest is a request for an operation over a range of epochs, inclusive.
+type epochRangeRequest struct {
+	FromEpoch phase0.Epoch `json:"from_epoch"`
+	ToEpoch   phase0.Epoch `json:"to_epoch"`
+}
+
+// refinalizeRequest is a request to re-run finality processing.
+type refinalizeRequest struct {
+	FromEpoch phase0.Epoch `json:"from_epoch"`
+}
+
+// job is the information returned about a scheduled job.
+type job struct {
+	Name    string              `json:"name"`
+	History []*scheduler.JobRun `json:"history"`
+}
+
+// errorResponse is the response returned for failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Service) handleOperations(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.listOperations())
+}
+
+func (s *Service) handleOperation(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet, http.MethodDelete) {
+		return
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/v1/operations/"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid operation ID")
+		return
+	}
+
+	if r.Method == http.MethodDelete && !s.cancelOperation(id) {
+		writeError(w, http.StatusNotFound, "no such operation")
+		return
+	}
+	op, exists := s.operation(id)
+	if !exists {
+		writeError(w, http.StatusNotFound, "no such operation")
+		return
+	}
+	writeJSON(w, http.StatusOK, op)
+}
+
+func (s *Service) handleBlocksRefetch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if s.blocks == nil {
+		writeError(w, http.StatusServiceUnavailable, "blocks service not running")
+		return
+	}
+	req := &slotRangeRequest{}
+	if !readJSON(w, r, req) {
+		return
+	}
+	if req.ToSlot < req.FromSlot {
+		writeError(w, http.StatusBadRequest, "to_slot cannot be before from_slot")
+		return
+	}
+
+	total := uint64(req.ToSlot-req.FromSlot) + 1
+	s.runOperation(w, "blocks refetch", total, func(ctx context.Context, progress func()) error {
+		for i := uint64(0); i < total; i++ {
+			slot := req.FromSlot + phase0.Slot(i)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.blocks.RefetchSlot(ctx, slot); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to refetch slot %d", slot))
+			}
+			progress()
+		}
+		return nil
+	})
+}
+
+func (s *Service) handleBeaconCommitteesRefetch(w http.ResponseWriter, r *http.Request) {
+	s.handleEpochsRefetch(w, r, "beacon committees refetch", s.beaconCommittees)
+}
+
+func (s *Service) handleProposerDutiesRefetch(w http.ResponseWriter, r *http.Request) {
+	s.handleEpochsRefetch(w, r, "proposer duties refetch", s.proposerDuties)
+}
+
+func (s *Service) handleEpochsRefetch(w http.ResponseWriter,
+	r *http.Request,
+	operationType string,
+	refetcher admin.EpochRefetcher,
+) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if refetcher == nil {
+		writeError(w, http.StatusServiceUnavailable, "service not running")
+		return
+	}
+	req := &epochRangeRequest{}
+	if !readJSON(w, r, req) {
+		return
+	}
+	if req.ToEpoch < req.FromEpoch {
+		writeError(w, http.StatusBadRequest, "to_epoch cannot be before from_epoch")
+		return
+	}
+
+	total := uint64(req.ToEpoch-req.FromEpoch) + 1
+	s.runOperation(w, operationType, total, func(ctx context.Context, progress func()) error {
+		for i := uint64(0); i < total; i++ {
+			epoch := req.FromEpoch + phase0.Epoch(i)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := refetcher.RefetchEpoch(ctx, epoch); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to refetch epoch %d", epoch))
+			}
+			progress()
+		}
+		return nil
+	})
+}
+
+func (s *Service) handleRefinalize(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if s.finalizer == nil {
+		writeError(w, http.StatusServiceUnavailable, "finalizer service not running")
+		return
+	}
+	req := &refinalizeRequest{}
+	if !readJSON(w, r, req) {
+		return
+	}
+
+	s.runOperation(w, "refinalize", 1, func(ctx context.Context, progress func()) error {
+		if err := s.finalizer.Refinalize(ctx, req.FromEpoch); err != nil {
+			return err
+		}
+		progress()
+		return nil
+	})
+}
+
+func (s *Service) handlePrune(w http.ResponseWriter, r *http.Request) {
+	s.handleMaintenance(w, r, "prune", func(ctx context.Context) error {
+		return s.summarizer.Prune(ctx)
+	})
+}
+
+func (s *Service) handleRollup(w http.ResponseWriter, r *http.Request) {
+	s.handleMaintenance(w, r, "rollup", func(ctx context.Context) error {
+		return s.summarizer.Rollup(ctx)
+	})
+}
+
+func (s *Service) handleMaintenance(w http.ResponseWriter,
+	r *http.Request,
+	operationType string,
+	maintenance func(ctx context.Context) error,
+) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if s.summarizer == nil {
+		writeError(w, http.StatusServiceUnavailable, "summarizer service not running")
+		return
+	}
+
+	s.runOperation(w, operationType, 1, func(ctx context.Context, progress func()) error {
+		if err := maintenance(ctx); err != nil {
+			return err
+		}
+		progress()
+		return nil
+	})
+}
+
+func (s *Service) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if s.scheduler == nil {
+		writeError(w, http.StatusServiceUnavailable, "scheduler not running")
+		return
+	}
+
+	names := s.scheduler.ListJobs(r.Context())
+	sort.Strings(names)
+	jobs := make([]*job, 0, len(names))
+	for _, name := range names {
+		jobs = append(jobs, &job{
+			Name:    name,
+			History: s.scheduler.JobHistory(r.Context(), name),
+		})
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Service) handleJob(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+	if s.scheduler == nil {
+		writeError(w, http.StatusServiceUnavailable, "scheduler not running")
+		return
+	}
+	name, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/v1/jobs/"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid job name")
+		return
+	}
+
+	if err := s.scheduler.CancelJob(r.Context(), name); err != nil {
+		if errors.Is(err, scheduler.ErrNoSuchJob) {
+			writeError(w, http.StatusNotFound, "no such job")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	log.Info().Str("job", name).Msg("Job cancelled")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runOperation starts an operation, and responds with its initial state.
+func (s *Service) runOperation(w http.ResponseWriter, operationType string, total uint64, work operationFunc) {
+	op, err := s.startOperation(operationType, total, work)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, op)
+}
+
+// allowMethod returns true if the request uses one of the given methods, otherwise responding with an error.
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// readJSON reads the JSON body of a request, responding with an error if it is invalid.
+func readJSON(w http.ResponseWriter, r *http.Request, data interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Debug().Err(err).Msg("Failed to write response")
+	}
+}
+
+// writeError writes an error response.
+func writeError(w http.ResponseWriter, statusCode int, msg string) {
+	writeJSON(w, statusCode, &errorResponse{
+		Error: msg,
+	})
+}
+
+func (s *Service) handleConfigReload(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if s.configReloader == nil {
+		writeError(w, http.StatusServiceUnavailable, "configuration reload not available")
+		return
+	}
+
+	res, err := s.configReloader.ReloadConfig(r.Context())
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to reload configuration")
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,63 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/admin"
+	"github.com/wealdtech/chaind/services/metrics"
+)
+
+var metricsNamespace = "chaind_admin"
+
+var operationsMetric *prometheus.CounterVec
+
+func registerMetrics(_ context.Context, monitor metrics.Service) error {
+	if operationsMetric != nil {
+		// Already registered.
+		return nil
+	}
+	if monitor == nil {
+		// No monitor.
+		return nil
+	}
+	if monitor.Presenter() == "prometheus" {
+		return registerPrometheusMetrics()
+	}
+	return nil
+}
+
+// skipcq: RVV-B0012
+func registerPrometheusMetrics() error {
+	operationsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "operations_total",
+		Help:      "Number of operations run through the admin API",
+	}, []string{"type", "state"})
+	if err := prometheus.Register(operationsMetric); err != nil {
+		return errors.Wrap(err, "failed to register operations_total")
+	}
+
+	return nil
+}
+
+// monitorOperationFinished is called when an operation finishes.
+func monitorOperationFinished(operationType string, state admin.OperationState) {
+	if operationsMetric != nil {
+		operationsMetric.WithLabelValues(operationType, string(state)).Inc()
+	}
+}
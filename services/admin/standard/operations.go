@@ -0,0 +1,172 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/admin"
+)
+
+// maxFinishedOperations is the number of finished operations that are retained.
+const maxFinishedOperations = 100
+
+// operation is an operation along with its control points.
+type operation struct {
+	admin.Operation
+	cancel context.CancelFunc
+}
+
+// operationFunc carries out an operation, calling progress as each unit of work completes.
+type operationFunc func(ctx context.Context, progress func()) error
+
+// startOperation starts an operation in the background, returning a copy of its initial state.
+func (s *Service) startOperation(operationType string, total uint64, work operationFunc) (*admin.Operation, error) {
+	s.operationsMutex.Lock()
+	id := s.nextID
+	s.nextID++
+	s.operationsMutex.Unlock()
+
+	ctx, done, ok := s.activity.Start(context.Background(), fmt.Sprintf("%s %d", operationType, id))
+	if !ok {
+		return nil, errors.New("service stopping")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	op := &operation{
+		Operation: admin.Operation{
+			ID:      id,
+			Type:    operationType,
+			State:   admin.OperationRunning,
+			Total:   total,
+			Started: time.Now(),
+		},
+		cancel: cancel,
+	}
+	s.operationsMutex.Lock()
+	s.operations = append(s.operations, op)
+	s.pruneOperations()
+	initial := op.Operation
+	s.operationsMutex.Unlock()
+
+	log := log.With().Uint64("id", id).Str("type", operationType).Logger()
+	log.Info().Uint64("total", total).Msg("Operation started")
+	go func() {
+		defer done()
+		defer cancel()
+
+		err := work(ctx, func() {
+			s.operationsMutex.Lock()
+			op.Done++
+			s.operationsMutex.Unlock()
+		})
+
+		s.operationsMutex.Lock()
+		finished := time.Now()
+		op.Finished = &finished
+		switch {
+		case err == nil:
+			op.State = admin.OperationSucceeded
+		case errors.Is(err, context.Canceled):
+			op.State = admin.OperationCancelled
+		default:
+			op.State = admin.OperationFailed
+			op.Error = err.Error()
+		}
+		state := op.State
+		opDone := op.Done
+		s.operationsMutex.Unlock()
+
+		monitorOperationFinished(operationType, state)
+		if state == admin.OperationFailed {
+			log.Error().Err(err).Uint64("done", opDone).Msg("Operation failed")
+		} else {
+			log.Info().Str("state", string(state)).Uint64("done", opDone).Msg("Operation finished")
+		}
+	}()
+
+	return &initial, nil
+}
+
+// pruneOperations removes the oldest finished operations if there are too many.
+// This requires the operations mutex to be held.
+func (s *Service) pruneOperations() {
+	finished := 0
+	for _, op := range s.operations {
+		if op.State != admin.OperationRunning {
+			finished++
+		}
+	}
+	if finished <= maxFinishedOperations {
+		return
+	}
+
+	operations := make([]*operation, 0, len(s.operations))
+	for _, op := range s.operations {
+		if op.State != admin.OperationRunning && finished > maxFinishedOperations {
+			finished--
+			continue
+		}
+		operations = append(operations, op)
+	}
+	s.operations = operations
+}
+
+// listOperations returns copies of all known operations, oldest first.
+func (s *Service) listOperations() []*admin.Operation {
+	s.operationsMutex.Lock()
+	defer s.operationsMutex.Unlock()
+
+	operations := make([]*admin.Operation, 0, len(s.operations))
+	for _, op := range s.operations {
+		opCopy := op.Operation
+		operations = append(operations, &opCopy)
+	}
+
+	return operations
+}
+
+// operation returns a copy of an operation given its ID.
+func (s *Service) operation(id uint64) (*admin.Operation, bool) {
+	s.operationsMutex.Lock()
+	defer s.operationsMutex.Unlock()
+
+	for _, op := range s.operations {
+		if op.ID == id {
+			opCopy := op.Operation
+			return &opCopy, true
+		}
+	}
+
+	return nil, false
+}
+
+// cancelOperation cancels a running operation given its ID.
+// The operation stops once its current unit of work completes.
+func (s *Service) cancelOperation(id uint64) bool {
+	s.operationsMutex.Lock()
+	defer s.operationsMutex.Unlock()
+
+	for _, op := range s.operations {
+		if op.ID == id {
+			op.cancel()
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,145 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/wealdtech/chaind/services/admin"
+	"github.com/wealdtech/chaind/services/metrics"
+	"github.com/wealdtech/chaind/services/scheduler"
+)
+
+type parameters struct {
+	logLevel         zerolog.Level
+	monitor          metrics.Service
+	listenAddress    string
+	token            string
+	blocks           admin.SlotRefetcher
+	beaconCommittees admin.EpochRefetcher
+	proposerDuties   admin.EpochRefetcher
+	finalizer        admin.Refinalizer
+	summarizer       admin.Maintainer
+	configReloader   admin.ConfigReloader
+	scheduler        scheduler.Service
+}
+
+// Parameter is the interface for service parameters.
+type Parameter interface {
+	apply(*parameters)
+}
+
+type parameterFunc func(*parameters)
+
+func (f parameterFunc) apply(p *parameters) {
+	f(p)
+}
+
+// WithLogLevel sets the log level for the module.
+func WithLogLevel(logLevel zerolog.Level) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.logLevel = logLevel
+	})
+}
+
+// WithMonitor sets the monitor for the module.
+func WithMonitor(monitor metrics.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.monitor = monitor
+	})
+}
+
+// WithListenAddress sets the address on which the admin API listens.
+func WithListenAddress(listenAddress string) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.listenAddress = listenAddress
+	})
+}
+
+// WithToken sets the bearer token required to access the admin API.
+func WithToken(token string) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.token = token
+	})
+}
+
+// WithBlocks sets the service used to refetch blocks.
+func WithBlocks(blocks admin.SlotRefetcher) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.blocks = blocks
+	})
+}
+
+// WithBeaconCommittees sets the service used to refetch beacon committees.
+func WithBeaconCommittees(beaconCommittees admin.EpochRefetcher) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.beaconCommittees = beaconCommittees
+	})
+}
+
+// WithProposerDuties sets the service used to refetch proposer duties.
+func WithProposerDuties(proposerDuties admin.EpochRefetcher) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.proposerDuties = proposerDuties
+	})
+}
+
+// WithFinalizer sets the service used to re-run finality processing.
+func WithFinalizer(finalizer admin.Refinalizer) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.finalizer = finalizer
+	})
+}
+
+// WithSummarizer sets the service used to run pruning and rollups.
+func WithSummarizer(summarizer admin.Maintainer) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.summarizer = summarizer
+	})
+}
+
+// WithConfigReloader sets the service used to reload the configuration.
+func WithConfigReloader(configReloader admin.ConfigReloader) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.configReloader = configReloader
+	})
+}
+
+// WithScheduler sets the scheduler whose jobs can be listed and cancelled.
+func WithScheduler(scheduler scheduler.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.scheduler = scheduler
+	})
+}
+
+// parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
+func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
+	parameters := parameters{
+		logLevel: zerolog.GlobalLevel(),
+	}
+	for _, p := range params {
+		if params != nil {
+			p.apply(&parameters)
+		}
+	}
+
+	if parameters.listenAddress == "" {
+		return nil, errors.New("no listen address specified")
+	}
+	if parameters.token == "" {
+		return nil, errors.New("no token specified")
+	}
+
+	return &parameters, nil
+}
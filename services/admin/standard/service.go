@@ -0,0 +1,143 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	zerologger "github.com/rs/zerolog/log"
+	"github.com/wealdtech/chaind/services/admin"
+	"github.com/wealdtech/chaind/services/scheduler"
+	"github.com/wealdtech/chaind/util"
+)
+
+// Service is an admin service, providing an authenticated HTTP API to trigger maintenance operations.
+type Service struct {
+	token            []byte
+	server           *http.Server
+	mux              *http.ServeMux
+	activity         *util.Activity
+	blocks           admin.SlotRefetcher
+	beaconCommittees admin.EpochRefetcher
+	proposerDuties   admin.EpochRefetcher
+	finalizer        admin.Refinalizer
+	summarizer       admin.Maintainer
+	configReloader   admin.ConfigReloader
+	scheduler        scheduler.Service
+
+	operationsMutex sync.Mutex
+	operations      []*operation
+	nextID          uint64
+}
+
+// module-wide log.
+var log zerolog.Logger
+
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
+// New creates a new admin service.
+func New(ctx context.Context, params ...Parameter) (*Service, error) {
+	parameters, err := parseAndCheckParameters(params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem with parameters")
+	}
+
+	// Set logging.
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "admin").Str("impl", "standard").Logger())
+
+	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+		return nil, errors.New("failed to register metrics")
+	}
+
+	s := &Service{
+		token:            []byte(parameters.token),
+		mux:              http.NewServeMux(),
+		activity:         util.NewActivity(),
+		blocks:           parameters.blocks,
+		beaconCommittees: parameters.beaconCommittees,
+		proposerDuties:   parameters.proposerDuties,
+		finalizer:        parameters.finalizer,
+		summarizer:       parameters.summarizer,
+		configReloader:   parameters.configReloader,
+		scheduler:        parameters.scheduler,
+		operations:       make([]*operation, 0),
+		nextID:           1,
+	}
+	s.mux.HandleFunc("/v1/operations", s.handleOperations)
+	s.mux.HandleFunc("/v1/operations/", s.handleOperation)
+	s.mux.HandleFunc("/v1/blocks/refetch", s.handleBlocksRefetch)
+	s.mux.HandleFunc("/v1/beacon-committees/refetch", s.handleBeaconCommitteesRefetch)
+	s.mux.HandleFunc("/v1/proposer-duties/refetch", s.handleProposerDutiesRefetch)
+	s.mux.HandleFunc("/v1/finalizer/refinalize", s.handleRefinalize)
+	s.mux.HandleFunc("/v1/summarizer/prune", s.handlePrune)
+	s.mux.HandleFunc("/v1/summarizer/rollup", s.handleRollup)
+	s.mux.HandleFunc("/v1/jobs", s.handleJobs)
+	s.mux.HandleFunc("/v1/jobs/", s.handleJob)
+	s.mux.HandleFunc("/v1/config/reload", s.handleConfigReload)
+
+	s.server = &http.Server{
+		Addr:              parameters.listenAddress,
+		Handler:           s,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warn().Str("listen_address", parameters.listenAddress).Err(err).Msg("Failed to run admin server")
+		}
+	}()
+
+	return s, nil
+}
+
+// ServeHTTP serves requests to the admin API, ensuring that they are authenticated.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), s.token) != 1 {
+		log.Debug().Str("remote_addr", r.RemoteAddr).Str("path", r.URL.Path).Msg("Unauthorized request")
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	log.Trace().Str("method", r.Method).Str("path", r.URL.Path).Msg("Request received")
+	s.mux.ServeHTTP(w, r)
+}
+
+// Stop stops the service, cancelling running operations and waiting for them to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to shut down admin server")
+	}
+
+	s.operationsMutex.Lock()
+	for _, op := range s.operations {
+		op.cancel()
+	}
+	s.operationsMutex.Unlock()
+
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -0,0 +1,362 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/admin"
+	"github.com/wealdtech/chaind/services/admin/standard"
+	standardscheduler "github.com/wealdtech/chaind/services/scheduler/standard"
+)
+
+const testToken = "secret"
+
+// slotRefetcher records the slots it is asked to refetch.
+type slotRefetcher struct {
+	mutex    sync.Mutex
+	slots    []phase0.Slot
+	failSlot phase0.Slot
+	release  chan struct{}
+}
+
+func (r *slotRefetcher) RefetchSlot(_ context.Context, slot phase0.Slot) error {
+	if r.release != nil {
+		<-r.release
+	}
+	if slot == r.failSlot {
+		return errors.New("beacon node unavailable")
+	}
+	r.mutex.Lock()
+	r.slots = append(r.slots, slot)
+	r.mutex.Unlock()
+	return nil
+}
+
+// configReloader returns a fixed result for configuration reloads.
+type configReloader struct {
+	err error
+}
+
+func (r *configReloader) ReloadConfig(_ context.Context) (*admin.ConfigReload, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &admin.ConfigReload{
+		Applied:         []string{"blocks.log-level"},
+		RestartRequired: []string{"chaindb.url"},
+	}, nil
+}
+
+func newService(t *testing.T, params ...standard.Parameter) *standard.Service {
+	t.Helper()
+	params = append([]standard.Parameter{
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithListenAddress("127.0.0.1:0"),
+		standard.WithToken(testToken),
+	}, params...)
+	s, err := standard.New(context.Background(), params...)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Stop(context.Background()) })
+	return s
+}
+
+func request(s *standard.Service, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+// waitForOperation waits for an operation to finish, returning its final state.
+func waitForOperation(t *testing.T, s *standard.Service, id string) *admin.Operation {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		rec := request(s, http.MethodGet, "/v1/operations/"+id, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		op := &admin.Operation{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), op))
+		if op.State != admin.OperationRunning {
+			return op
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Fail(t, "operation did not finish")
+	return nil
+}
+
+func TestService(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []standard.Parameter
+		err    string
+	}{
+		{
+			name: "ListenAddressMissing",
+			params: []standard.Parameter{
+				standard.WithLogLevel(zerolog.Disabled),
+				standard.WithToken(testToken),
+			},
+			err: "problem with parameters: no listen address specified",
+		},
+		{
+			name: "TokenMissing",
+			params: []standard.Parameter{
+				standard.WithLogLevel(zerolog.Disabled),
+				standard.WithListenAddress("127.0.0.1:0"),
+			},
+			err: "problem with parameters: no token specified",
+		},
+		{
+			name: "Good",
+			params: []standard.Parameter{
+				standard.WithLogLevel(zerolog.Disabled),
+				standard.WithListenAddress("127.0.0.1:0"),
+				standard.WithToken(testToken),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := standard.New(context.Background(), test.params...)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				s.Stop(context.Background())
+			}
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	s := newService(t)
+
+	tests := []struct {
+		name          string
+		authorization string
+		code          int
+	}{
+		{
+			name: "Missing",
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:          "Incorrect",
+			authorization: "Bearer wrong",
+			code:          http.StatusUnauthorized,
+		},
+		{
+			name:          "NotBearer",
+			authorization: testToken,
+			code:          http.StatusUnauthorized,
+		},
+		{
+			name:          "Good",
+			authorization: "Bearer " + testToken,
+			code:          http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/operations", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			require.Equal(t, test.code, rec.Code)
+		})
+	}
+}
+
+func TestRequests(t *testing.T) {
+	s := newService(t, standard.WithBlocks(&slotRefetcher{}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{
+			name:   "WrongMethod",
+			method: http.MethodGet,
+			path:   "/v1/blocks/refetch",
+			code:   http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "InvalidBody",
+			method: http.MethodPost,
+			path:   "/v1/blocks/refetch",
+			body:   `{"from_slot":"x"}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "UnknownField",
+			method: http.MethodPost,
+			path:   "/v1/blocks/refetch",
+			body:   `{"from_slot":1,"to_slot":2,"refetch":true}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "RangeReversed",
+			method: http.MethodPost,
+			path:   "/v1/blocks/refetch",
+			body:   `{"from_slot":2,"to_slot":1}`,
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "ServiceNotRunning",
+			method: http.MethodPost,
+			path:   "/v1/proposer-duties/refetch",
+			body:   `{"from_epoch":1,"to_epoch":2}`,
+			code:   http.StatusServiceUnavailable,
+		},
+		{
+			name:   "UnknownOperation",
+			method: http.MethodGet,
+			path:   "/v1/operations/1000",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "InvalidOperation",
+			method: http.MethodGet,
+			path:   "/v1/operations/x",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "Good",
+			method: http.MethodPost,
+			path:   "/v1/blocks/refetch",
+			body:   `{"from_slot":1,"to_slot":2}`,
+			code:   http.StatusAccepted,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := request(s, test.method, test.path, test.body)
+			require.Equal(t, test.code, rec.Code)
+		})
+	}
+}
+
+func TestBlocksRefetch(t *testing.T) {
+	refetcher := &slotRefetcher{}
+	s := newService(t, standard.WithBlocks(refetcher))
+
+	rec := request(s, http.MethodPost, "/v1/blocks/refetch", `{"from_slot":5,"to_slot":9}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	op := &admin.Operation{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), op))
+	require.Equal(t, "blocks refetch", op.Type)
+	require.Equal(t, uint64(5), op.Total)
+
+	op = waitForOperation(t, s, "1")
+	require.Equal(t, admin.OperationSucceeded, op.State)
+	require.Equal(t, uint64(5), op.Done)
+	require.NotNil(t, op.Finished)
+	require.Equal(t, []phase0.Slot{5, 6, 7, 8, 9}, refetcher.slots)
+}
+
+func TestOperationFailed(t *testing.T) {
+	refetcher := &slotRefetcher{failSlot: 7}
+	s := newService(t, standard.WithBlocks(refetcher))
+
+	rec := request(s, http.MethodPost, "/v1/blocks/refetch", `{"from_slot":5,"to_slot":9}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	op := waitForOperation(t, s, "1")
+	require.Equal(t, admin.OperationFailed, op.State)
+	require.Equal(t, uint64(2), op.Done)
+	require.Equal(t, "failed to refetch slot 7: beacon node unavailable", op.Error)
+}
+
+func TestCancelOperation(t *testing.T) {
+	refetcher := &slotRefetcher{release: make(chan struct{})}
+	s := newService(t, standard.WithBlocks(refetcher))
+
+	rec := request(s, http.MethodPost, "/v1/blocks/refetch", `{"from_slot":1,"to_slot":100}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	refetcher.release <- struct{}{}
+
+	// Cancel whilst the second slot is being refetched; it completes, but no more are started.
+	rec = request(s, http.MethodDelete, "/v1/operations/1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	close(refetcher.release)
+
+	op := waitForOperation(t, s, "1")
+	require.Equal(t, admin.OperationCancelled, op.State)
+	require.Equal(t, uint64(2), op.Done)
+
+	rec = request(s, http.MethodGet, "/v1/operations", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	ops := make([]*admin.Operation, 0)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ops))
+	require.Len(t, ops, 1)
+}
+
+func TestJobs(t *testing.T) {
+	ctx := context.Background()
+	scheduler, err := standardscheduler.New(ctx, standardscheduler.WithLogLevel(zerolog.Disabled))
+	require.NoError(t, err)
+	require.NoError(t, scheduler.ScheduleJob(ctx, "Test", "test/job", time.Now().Add(time.Hour), func(context.Context, interface{}) {}, nil))
+
+	s := newService(t, standard.WithScheduler(scheduler))
+
+	rec := request(s, http.MethodGet, "/v1/jobs", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `[{"name":"test/job","history":[]}]`, rec.Body.String())
+
+	rec = request(s, http.MethodDelete, "/v1/jobs/test%2Fjob", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, scheduler.ListJobs(ctx))
+
+	rec = request(s, http.MethodDelete, "/v1/jobs/test%2Fjob", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestConfigReload(t *testing.T) {
+	s := newService(t)
+	rec := request(s, http.MethodPost, "/v1/config/reload", "")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	s = newService(t, standard.WithConfigReloader(&configReloader{}))
+	rec = request(s, http.MethodGet, "/v1/config/reload", "")
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	rec = request(s, http.MethodPost, "/v1/config/reload", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"applied":["blocks.log-level"],"restart_required":["chaindb.url"]}`, rec.Body.String())
+
+	s = newService(t, standard.WithConfigReloader(&configReloader{err: errors.New("invalid configuration")}))
+	rec = request(s, http.MethodPost, "/v1/config/reload", "")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.JSONEq(t, `{"error":"invalid configuration"}`, rec.Body.String())
+}
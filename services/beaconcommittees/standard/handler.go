@@ -21,6 +21,8 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/util"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -60,6 +62,8 @@ func (s *Service) OnBeaconChainHeadUpdated(
 		Str("state_root", fmt.Sprintf("%#x", stateRoot)).
 		Bool("epoch_transition", epochTransition).
 		Msg("Handler called")
+	// Requests made whilst following the head of the chain take priority over catchup.
+	ctx = governor.WithPriority(ctx, governor.PriorityHead)
 
 	md, err := s.getMetadata(ctx)
 	if err != nil {
@@ -72,8 +76,20 @@ func (s *Service) OnBeaconChainHeadUpdated(
 
 // catchup is the general-purpose catchup system.
 func (s *Service) catchup(ctx context.Context, md *metadata) {
+	ctx, done, ok := s.activity.Start(ctx, "catchup")
+	if !ok {
+		log.Debug().Msg("Service stopping; not catching up")
+		return
+	}
+	defer done()
+
 	for epoch := phase0.Epoch(md.LatestEpoch + 1); epoch <= s.chainTime.CurrentEpoch(); epoch++ {
-		if err := s.UpdateEpoch(ctx, md, epoch); err != nil {
+		if s.activity.Stopping() {
+			return
+		}
+		if err := util.Retry(ctx, "beacon committees", func(ctx context.Context) error {
+			return s.UpdateEpoch(ctx, md, epoch)
+		}); err != nil {
 			log.Error().Uint64("epoch", uint64(epoch)).Err(err).Msg("Failed to catchup")
 			return
 		}
@@ -114,6 +130,31 @@ func (s *Service) UpdateEpoch(ctx context.Context, md *metadata, epoch phase0.Ep
 	return nil
 }
 
+// RefetchEpoch refetches the beacon committees for the given epoch, replacing any existing data.
+func (s *Service) RefetchEpoch(ctx context.Context, epoch phase0.Epoch) error {
+	ctx, done, ok := s.activity.Start(ctx, "refetch")
+	if !ok {
+		return errors.New("service stopping")
+	}
+	defer done()
+
+	return util.Retry(ctx, "beacon committees", func(ctx context.Context) error {
+		ctx, cancel, err := s.chainDB.BeginTx(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to begin transaction")
+		}
+		if err := s.updateBeaconCommitteesForEpoch(ctx, epoch); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to update beacon committees")
+		}
+		if err := s.chainDB.CommitTx(ctx); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to commit transaction")
+		}
+		return nil
+	})
+}
+
 // updateBeaconCommitteesForEpoch sets the beacon committee information for the given epoch.
 // This assumes that a database transaction is already in progress.
 func (s *Service) updateBeaconCommitteesForEpoch(ctx context.Context, epoch phase0.Epoch) error {
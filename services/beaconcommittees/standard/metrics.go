@@ -19,18 +19,20 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/chaintime"
 	"github.com/wealdtech/chaind/services/metrics"
+	"go.uber.org/atomic"
 )
 
 var metricsNamespace = "chaind_beaconcommittees"
 
 var (
-	highestEpoch    phase0.Epoch
+	highestEpoch    atomic.Uint64
 	latestEpoch     prometheus.Gauge
 	epochsProcessed prometheus.Gauge
 )
 
-func registerMetrics(_ context.Context, monitor metrics.Service) error {
+func registerMetrics(_ context.Context, monitor metrics.Service, chainTime chaintime.Service) error {
 	if latestEpoch != nil {
 		// Already registered.
 		return nil
@@ -40,13 +42,13 @@ func registerMetrics(_ context.Context, monitor metrics.Service) error {
 		return nil
 	}
 	if monitor.Presenter() == "prometheus" {
-		return registerPrometheusMetrics()
+		return registerPrometheusMetrics(chainTime)
 	}
 	return nil
 }
 
 // skipcq: RVV-B0012
-func registerPrometheusMetrics() error {
+func registerPrometheusMetrics(chainTime chaintime.Service) error {
 	latestEpoch = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Name:      "latest_epoch",
@@ -65,6 +67,21 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register epochs_processed")
 	}
 
+	if err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "lag_epochs",
+		Help:      "Number of epochs between the current epoch and the latest epoch processed",
+	}, func() float64 {
+		current := uint64(chainTime.CurrentEpoch())
+		highest := highestEpoch.Load()
+		if current < highest {
+			return 0
+		}
+		return float64(current - highest)
+	})); err != nil {
+		return errors.Wrap(err, "failed to register lag_epochs")
+	}
+
 	return nil
 }
 
@@ -72,7 +89,7 @@ func registerPrometheusMetrics() error {
 // increase in blocks processed.  This does not usually need to be
 // called directly, as it is called as part of monitorEpochProcessed.
 func monitorLatestEpoch(epoch phase0.Epoch) {
-	highestEpoch = epoch
+	highestEpoch.Store(uint64(epoch))
 	if latestEpoch != nil {
 		latestEpoch.Set(float64(epoch))
 	}
@@ -81,7 +98,7 @@ func monitorLatestEpoch(epoch phase0.Epoch) {
 func monitorEpochProcessed(epoch phase0.Epoch) {
 	if epochsProcessed != nil {
 		epochsProcessed.Inc()
-		if epoch > highestEpoch {
+		if uint64(epoch) > highestEpoch.Load() {
 			monitorLatestEpoch(epoch)
 		}
 	}
@@ -18,11 +18,13 @@ import (
 
 	eth2client "github.com/attestantio/go-eth2-client"
 	api "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/chaintime"
+	"github.com/wealdtech/chaind/util"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -33,11 +35,15 @@ type Service struct {
 	beaconCommitteesSetter chaindb.BeaconCommitteesSetter
 	chainTime              chaintime.Service
 	activitySem            *semaphore.Weighted
+	activity               *util.Activity
 }
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -46,9 +52,10 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "beaconcommittees").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "beaconcommittees").Str("impl", "standard").Logger())
 
-	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+	if err := registerMetrics(ctx, parameters.monitor, parameters.chainTime); err != nil {
 		return nil, errors.New("failed to register metrics")
 	}
 
@@ -62,6 +69,7 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		beaconCommitteesSetter: beaconCommitteesSetter,
 		chainTime:              parameters.chainTime,
 		activitySem:            semaphore.NewWeighted(1),
+		activity:               util.NewActivity(),
 	}
 
 	// Update to current epoch before starting (in the background).
@@ -81,6 +89,10 @@ func (s *Service) updateAfterRestart(ctx context.Context, startEpoch int64) {
 		md.LatestEpoch = startEpoch - 1
 	}
 
+	if md.LatestEpoch >= 0 {
+		monitorLatestEpoch(phase0.Epoch(md.LatestEpoch))
+	}
+
 	log.Info().Int64("epoch", md.LatestEpoch+1).Msg("Catching up from epoch")
 	// Only allow 1 handler to be active.
 	acquired := s.activitySem.TryAcquire(1)
@@ -93,9 +105,23 @@ func (s *Service) updateAfterRestart(ctx context.Context, startEpoch int64) {
 
 	// Set up the handler for new chain head updates.
 	if err := s.eth2Client.(eth2client.EventsProvider).Events(ctx, []string{"head"}, func(event *api.Event) {
+		if event.Data == nil {
+			// Happens when the channel shuts down, nothing to worry about.
+			return
+		}
 		eventData := event.Data.(*api.HeadEvent)
 		s.OnBeaconChainHeadUpdated(ctx, eventData.Slot, eventData.Block, eventData.State, eventData.EpochTransition)
 	}); err != nil {
 		log.Fatal().Err(err).Msg("Failed to add beacon chain head updated handler")
 	}
 }
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -27,6 +27,8 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/util"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -57,6 +59,15 @@ func (s *Service) OnBeaconChainHeadUpdated(
 	}
 	defer s.activitySem.Release(1)
 
+	ctx, done, ok := s.activity.Start(ctx, "head")
+	if !ok {
+		log.Debug().Msg("Service stopping; not handling")
+		return
+	}
+	defer done()
+	// Requests made whilst following the head of the chain take priority over catchup.
+	ctx = governor.WithPriority(ctx, governor.PriorityHead)
+
 	log.Trace().
 		Str("state_root", fmt.Sprintf("%#x", stateRoot)).
 		Bool("epoch_transition", epochTransition).
@@ -81,7 +92,13 @@ func (s *Service) OnBeaconChainHeadUpdated(
 // catchup is the general-purpose catchup system.
 func (s *Service) catchup(ctx context.Context, md *metadata) {
 	for slot := phase0.Slot(md.LatestSlot + 1); slot <= s.chainTime.CurrentSlot(); slot++ {
-		if err := s.UpdateSlot(ctx, md, slot); err != nil {
+		if s.activity.Stopping() {
+			log.Debug().Uint64("slot", uint64(slot)).Msg("Service stopping; halting catchup")
+			return
+		}
+		if err := util.Retry(ctx, "blocks", func(ctx context.Context) error {
+			return s.UpdateSlot(ctx, md, slot)
+		}); err != nil {
 			log.Error().Uint64("slot", uint64(slot)).Err(err).Msg("Failed to catchup")
 			return
 		}
@@ -143,6 +160,49 @@ func (s *Service) updateBlockForSlot(ctx context.Context, slot phase0.Slot) erro
 	}
 	span.AddEvent("Checked for block")
 
+	return s.fetchBlockForSlot(ctx, slot)
+}
+
+// RefetchSlot refetches the block for the given slot, replacing any existing data.
+func (s *Service) RefetchSlot(ctx context.Context, slot phase0.Slot) error {
+	ctx, done, ok := s.activity.Start(ctx, "refetch")
+	if !ok {
+		return errors.New("service stopping")
+	}
+	defer done()
+
+	// Wait for any other handler to finish.
+	if err := s.activitySem.Acquire(ctx, 1); err != nil {
+		return errors.Wrap(err, "failed to acquire activity semaphore")
+	}
+	defer s.activitySem.Release(1)
+
+	return util.Retry(ctx, "blocks", func(ctx context.Context) error {
+		ctx, cancel, err := s.chainDB.BeginTx(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to begin transaction")
+		}
+		if err := s.fetchBlockForSlot(ctx, slot); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to refetch block")
+		}
+		if err := s.chainDB.CommitTx(ctx); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to commit transaction")
+		}
+		return nil
+	})
+}
+
+// fetchBlockForSlot fetches the block for the given slot from the beacon node and stores it.
+func (s *Service) fetchBlockForSlot(ctx context.Context, slot phase0.Slot) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.blocks.standard").Start(ctx, "fetchBlockForSlot",
+		trace.WithAttributes(
+			attribute.Int64("slot", int64(slot)),
+		))
+	defer span.End()
+	log := log.With().Uint64("slot", uint64(slot)).Logger()
+
 	log.Trace().Msg("Updating block for slot")
 	signedBlock, err := s.eth2Client.(eth2client.SignedBeaconBlockProvider).SignedBeaconBlock(ctx, fmt.Sprintf("%d", slot))
 	if err != nil {
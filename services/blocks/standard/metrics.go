@@ -19,18 +19,20 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/chaintime"
 	"github.com/wealdtech/chaind/services/metrics"
+	"go.uber.org/atomic"
 )
 
 var metricsNamespace = "chaind_blocks"
 
 var (
-	highestSlot    phase0.Slot
+	highestSlot    atomic.Uint64
 	latestSlot     prometheus.Gauge
 	slotsProcessed prometheus.Gauge
 )
 
-func registerMetrics(_ context.Context, monitor metrics.Service) error {
+func registerMetrics(_ context.Context, monitor metrics.Service, chainTime chaintime.Service) error {
 	if latestSlot != nil {
 		// Already registered.
 		return nil
@@ -40,13 +42,13 @@ func registerMetrics(_ context.Context, monitor metrics.Service) error {
 		return nil
 	}
 	if monitor.Presenter() == "prometheus" {
-		return registerPrometheusMetrics()
+		return registerPrometheusMetrics(chainTime)
 	}
 	return nil
 }
 
 // skipcq: RVV-B0012
-func registerPrometheusMetrics() error {
+func registerPrometheusMetrics(chainTime chaintime.Service) error {
 	latestSlot = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Name:      "latest_slot",
@@ -65,6 +67,21 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register slots_processed")
 	}
 
+	if err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "lag_slots",
+		Help:      "Number of slots between the current slot and the latest slot processed",
+	}, func() float64 {
+		current := uint64(chainTime.CurrentSlot())
+		highest := highestSlot.Load()
+		if current < highest {
+			return 0
+		}
+		return float64(current - highest)
+	})); err != nil {
+		return errors.Wrap(err, "failed to register lag_slots")
+	}
+
 	return nil
 }
 
@@ -72,7 +89,7 @@ func registerPrometheusMetrics() error {
 // increase in slots processed.  This does not usually need to be
 // called directly, as it is called as part of monitorSlotProcessed.
 func monitorLatestSlot(slot phase0.Slot) {
-	highestSlot = slot
+	highestSlot.Store(uint64(slot))
 	if latestSlot != nil {
 		latestSlot.Set(float64(slot))
 	}
@@ -81,7 +98,7 @@ func monitorLatestSlot(slot phase0.Slot) {
 func monitorSlotProcessed(slot phase0.Slot) {
 	if slotsProcessed != nil {
 		slotsProcessed.Inc()
-		if slot > highestSlot {
+		if uint64(slot) > highestSlot.Load() {
 			monitorLatestSlot(slot)
 		}
 	}
@@ -24,6 +24,7 @@ import (
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/chaintime"
+	"github.com/wealdtech/chaind/util"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -45,11 +46,15 @@ type Service struct {
 	lastHandledBlockRoot     phase0.Root
 	activitySem              *semaphore.Weighted
 	syncCommittees           map[uint64]*chaindb.SyncCommittee
+	activity                 *util.Activity
 }
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -58,9 +63,10 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "blocks").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "blocks").Str("impl", "standard").Logger())
 
-	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+	if err := registerMetrics(ctx, parameters.monitor, parameters.chainTime); err != nil {
 		return nil, errors.New("failed to register metrics")
 	}
 
@@ -125,6 +131,7 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		refetch:                  parameters.refetch,
 		activitySem:              parameters.activitySem,
 		syncCommittees:           make(map[uint64]*chaindb.SyncCommittee),
+		activity:                 util.NewActivity(),
 	}
 
 	// Note the current highest processed block for the monitor.
@@ -149,8 +156,15 @@ func (s *Service) updateAfterRestart(ctx context.Context, startSlot int64) {
 	}
 	defer s.activitySem.Release(1)
 
+	workCtx, done, ok := s.activity.Start(ctx, "catchup")
+	if !ok {
+		log.Debug().Msg("Service stopping; not catching up")
+		return
+	}
+	defer done()
+
 	// Work out the slot from which to start.
-	md, err := s.getMetadata(ctx)
+	md, err := s.getMetadata(workCtx)
 	if err != nil {
 		// This will exit so not release the semaphore, but it's exiting so we don't care.
 		//nolint:gocritic
@@ -162,7 +176,7 @@ func (s *Service) updateAfterRestart(ctx context.Context, startSlot int64) {
 	}
 
 	log.Info().Uint64("slot", uint64(md.LatestSlot)).Msg("Catching up from slot")
-	s.catchup(ctx, md)
+	s.catchup(workCtx, md)
 	log.Info().Msg("Caught up")
 
 	// Set up the handler for new chain head updates.
@@ -177,3 +191,13 @@ func (s *Service) updateAfterRestart(ctx context.Context, startSlot int64) {
 		log.Fatal().Err(err).Msg("Failed to add beacon chain head updated handler")
 	}
 }
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -81,6 +81,36 @@ type ValidatorDaySummaryFilter struct {
 	ValidatorIndices *[]phase0.ValidatorIndex
 }
 
+// ValidatorPeriodSummaryFilter defines a filter for fetching validator period summaries.
+// Filter elements are ANDed together.
+// Results are always returned in ascending (start timestamp, validator index) order.
+type ValidatorPeriodSummaryFilter struct {
+	// Period is the period, as an ISO 8601 duration, for which to fetch summaries.
+	// This is required.
+	Period string
+
+	// Limit is the maximum number of summaries to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest start timestamp from which to fetch summaries.
+	// If nil then there is no earliest timestamp.
+	From *time.Time
+
+	// To is the latest start timestamp from which to fetch summaries.
+	// If nil then there is no latest timestamp.
+	To *time.Time
+
+	// ValidatorIndices is the list of validator indices for which to obtain summaries.
+	// If nil then no filter is applied
+	ValidatorIndices *[]phase0.ValidatorIndex
+}
+
 // BeaconCommitteeFilter defines a filter for fetching beacon committees.
 // Filter elements are ANDed together.
 // Results are always returned in ascending (slot, committee index) order.
@@ -185,4 +215,270 @@ type WithdrawalFilter struct {
 	// ValidatorIndices is the list of validator indices for which to obtain items.
 	// If nil then no filter is applied
 	ValidatorIndices []phase0.ValidatorIndex
+
+	// Addresses is the list of execution addresses for which to obtain items.
+	// If nil then no filter is applied
+	Addresses [][20]byte
+
+	// Type is the type of withdrawal to obtain.
+	// If nil then no filter is applied
+	Type *WithdrawalType
+
+	// MinAmount is the minimum amount of withdrawal to obtain.
+	// If nil then no filter is applied
+	MinAmount *phase0.Gwei
+}
+
+// WithdrawalDaySummaryFilter defines a filter for fetching withdrawal day summaries.
+// Filter elements are ANDed together.
+// Results are always returned in ascending (start timestamp, validator index) order, or
+// ascending (start timestamp, address) order for address summaries.
+type WithdrawalDaySummaryFilter struct {
+	// Limit is the maximum number of summaries to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest timestamp from which to fetch summaries.
+	// If nil then there is no earliest timestamp.
+	From *time.Time
+
+	// To is the latest timestamp from which to fetch summaries.
+	// If nil then there is no latest timestamp.
+	To *time.Time
+
+	// ValidatorIndices is the list of validator indices for which to obtain summaries.
+	// If nil then no filter is applied
+	ValidatorIndices []phase0.ValidatorIndex
+
+	// Addresses is the list of execution addresses for which to obtain summaries.
+	// If nil then no filter is applied
+	Addresses [][20]byte
+}
+
+// BlockFilter defines a filter for fetching blocks.
+// Filter elements are ANDed together.
+// Results are always returned in ascending (slot, root) order.
+type BlockFilter struct {
+	// Limit is the maximum number of blocks to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest slot from which to fetch blocks.
+	// If nil then there is no earliest slot.
+	From *phase0.Slot
+
+	// To is the latest slot to which to fetch blocks.
+	// If nil then there is no latest slot.
+	To *phase0.Slot
+
+	// ProposerIndices is the list of proposer indices for which to obtain blocks.
+	// If nil then no filter is applied
+	ProposerIndices []phase0.ValidatorIndex
+
+	// Canonical is the canonical status of blocks to obtain.
+	// If nil then no filter is applied
+	Canonical *bool
+
+	// Graffiti is a case-insensitive substring to search for in the graffiti.
+	// If nil then no filter is applied
+	Graffiti *string
+
+	// GraffitiRegex is a case-insensitive regular expression, in PostgreSQL syntax,
+	// to match against the graffiti.
+	// If nil then no filter is applied
+	GraffitiRegex *string
+}
+
+// ClientEpochSummaryFilter defines a filter for fetching client epoch summaries.
+// Filter elements are ANDed together.
+// Results are always returned in ascending (epoch, consensus client, execution client) order.
+type ClientEpochSummaryFilter struct {
+	// Limit is the maximum number of summaries to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest epoch from which to fetch summaries.
+	// If nil then there is no earliest epoch.
+	From *phase0.Epoch
+
+	// To is the latest epoch to which to fetch summaries.
+	// If nil then there is no latest epoch.
+	To *phase0.Epoch
+
+	// ConsensusClients is the list of consensus clients for which to obtain summaries.
+	// If nil then no filter is applied
+	ConsensusClients []string
+
+	// ExecutionClients is the list of execution clients for which to obtain summaries.
+	// If nil then no filter is applied
+	ExecutionClients []string
+}
+
+// ClientDaySummaryFilter defines a filter for fetching client day summaries.
+// Filter elements are ANDed together.
+// Results are always returned in ascending (start timestamp, consensus client, execution client) order.
+type ClientDaySummaryFilter struct {
+	// Limit is the maximum number of summaries to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest timestamp from which to fetch summaries.
+	// If nil then there is no earliest timestamp.
+	From *time.Time
+
+	// To is the latest timestamp from which to fetch summaries.
+	// If nil then there is no latest timestamp.
+	To *time.Time
+
+	// ConsensusClients is the list of consensus clients for which to obtain summaries.
+	// If nil then no filter is applied
+	ConsensusClients []string
+
+	// ExecutionClients is the list of execution clients for which to obtain summaries.
+	// If nil then no filter is applied
+	ExecutionClients []string
+}
+
+// ReconciledDepositFilter defines a filter for fetching reconciled deposits.
+// Filter elements are ANDed together.
+// Results are always returned in ascending deposit index order.
+type ReconciledDepositFilter struct {
+	// Limit is the maximum number of items to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest deposit index from which to fetch items.
+	// If nil then there is no earliest deposit index.
+	From *uint64
+
+	// To is the latest deposit index to which to fetch items.
+	// If nil then there is no latest deposit index.
+	To *uint64
+
+	// PubKeys is the list of validator public keys for which to obtain items.
+	// If nil then no filter is applied.
+	PubKeys []phase0.BLSPubKey
+
+	// Types is the list of deposit types for which to obtain items.
+	// If nil then no filter is applied.
+	Types []DepositType
+}
+
+// PendingDepositFilter defines a filter for fetching pending deposits.
+// Filter elements are ANDed together.
+// Results are always returned in ascending deposit index order.
+type PendingDepositFilter struct {
+	// Limit is the maximum number of items to return.
+	Limit uint32
+
+	// PubKeys is the list of validator public keys for which to obtain items.
+	// If nil then no filter is applied.
+	PubKeys []phase0.BLSPubKey
+}
+
+// ETH1VotePeriodFilter defines a filter for fetching Ethereum 1 vote periods.
+// Filter elements are ANDed together.
+// Results are always returned in ascending period order.
+type ETH1VotePeriodFilter struct {
+	// Limit is the maximum number of items to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest period from which to fetch items.
+	// If nil then there is no earliest period.
+	From *uint64
+
+	// To is the latest period to which to fetch items.
+	// If nil then there is no latest period.
+	To *uint64
+}
+
+// RelayPayloadFilter defines a filter for fetching relay payloads.
+// Filter elements are ANDed together.
+// Results are always returned in ascending (slot,relay) order.
+type RelayPayloadFilter struct {
+	// Limit is the maximum number of items to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest slot from which to fetch items.
+	// If nil then there is no earliest slot.
+	From *phase0.Slot
+
+	// To is the latest slot to which to fetch items.
+	// If nil then there is no latest slot.
+	To *phase0.Slot
+
+	// Relays are the relays for which to fetch items.
+	// If nil then there is no relay filter.
+	Relays []string
+
+	// BuilderPubKeys are the builder public keys for which to fetch items.
+	// If nil then there is no builder public key filter.
+	BuilderPubKeys []phase0.BLSPubKey
+
+	// BlockHashes are the execution block hashes for which to fetch items.
+	// If nil then there is no block hash filter.
+	BlockHashes [][32]byte
+}
+
+// BlockProductionFilter defines a filter for fetching block productions.
+// Filter elements are ANDed together.
+// Results are always returned in ascending slot order.
+type BlockProductionFilter struct {
+	// Limit is the maximum number of items to return.
+	Limit uint32
+
+	// Order is either OrderEarliest, in which case the earliest results
+	// that match the filter are returned, or OrderLatest, in which case the
+	// latest results that match the filter are returned.
+	// The default is OrderEarliest.
+	Order Order
+
+	// From is the earliest slot from which to fetch items.
+	// If nil then there is no earliest slot.
+	From *phase0.Slot
+
+	// To is the latest slot to which to fetch items.
+	// If nil then there is no latest slot.
+	To *phase0.Slot
+
+	// ProposerIndices are the proposer indices for which to fetch items.
+	// If nil then there is no proposer index filter.
+	ProposerIndices []phase0.ValidatorIndex
 }
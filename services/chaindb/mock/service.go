@@ -95,6 +95,11 @@ func (s *service) SetBeaconCommittee(ctx context.Context, beaconCommittee *chain
 	return nil
 }
 
+// Blocks provides blocks according to the filter.
+func (s *service) Blocks(ctx context.Context, filter *chaindb.BlockFilter) ([]*chaindb.Block, error) {
+	return nil, nil
+}
+
 // BlocksBySlot fetches all blocks with the given slot.
 func (s *service) BlocksBySlot(ctx context.Context, slot phase0.Slot) ([]*chaindb.Block, error) {
 	return nil, nil
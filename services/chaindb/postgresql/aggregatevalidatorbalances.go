@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -36,6 +37,7 @@ func (s *Service) AggregateValidatorBalancesByIndexAndEpoch(
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AggregateValidatorBalancesByIndexAndEpoch")
 	defer span.End()
+	defer monitorQuery("AggregateValidatorBalancesByIndexAndEpoch", time.Now())
 
 	if len(validatorIndices) == 0 {
 		return &chaindb.AggregateValidatorBalance{}, nil
@@ -95,6 +97,7 @@ func (s *Service) AggregateValidatorBalancesByIndexAndEpochRange(
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AggregateValidatorBalancesByIndexAndEpochRange")
 	defer span.End()
+	defer monitorQuery("AggregateValidatorBalancesByIndexAndEpochRange", time.Now())
 
 	if len(validatorIndices) == 0 {
 		return []*chaindb.AggregateValidatorBalance{}, nil
@@ -158,6 +161,7 @@ func (s *Service) AggregateValidatorBalancesByIndexAndEpochs(
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AggregateValidatorBalancesByIndexAndEpochs")
 	defer span.End()
+	defer monitorQuery("AggregateValidatorBalancesByIndexAndEpochs", time.Now())
 
 	if len(validatorIndices) == 0 {
 		return []*chaindb.AggregateValidatorBalance{}, nil
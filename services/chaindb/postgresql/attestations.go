@@ -16,6 +16,7 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/jackc/pgx/v4"
@@ -28,6 +29,7 @@ import (
 func (s *Service) SetAttestation(ctx context.Context, attestation *chaindb.Attestation) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetAttestation")
 	defer span.End()
+	defer monitorQuery("SetAttestation", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -106,6 +108,7 @@ func (s *Service) SetAttestation(ctx context.Context, attestation *chaindb.Attes
 func (s *Service) SetAttestations(ctx context.Context, attestations []*chaindb.Attestation) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetAttestations")
 	defer span.End()
+	defer monitorQuery("SetAttestations", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -172,6 +175,7 @@ func (s *Service) SetAttestations(ctx context.Context, attestations []*chaindb.A
 func (s *Service) AttestationsForBlock(ctx context.Context, blockRoot phase0.Root) ([]*chaindb.Attestation, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttestationsForBlock")
 	defer span.End()
+	defer monitorQuery("AttestationsForBlock", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -272,6 +276,7 @@ func (s *Service) AttestationsForBlock(ctx context.Context, blockRoot phase0.Roo
 func (s *Service) AttestationsInBlock(ctx context.Context, blockRoot phase0.Root) ([]*chaindb.Attestation, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttestationsInBlock")
 	defer span.End()
+	defer monitorQuery("AttestationsInBlock", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -374,6 +379,7 @@ func (s *Service) AttestationsInBlock(ctx context.Context, blockRoot phase0.Root
 func (s *Service) AttestationsForSlotRange(ctx context.Context, startSlot phase0.Slot, endSlot phase0.Slot) ([]*chaindb.Attestation, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttestationsForSlotRange")
 	defer span.End()
+	defer monitorQuery("AttestationsForSlotRange", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -478,6 +484,7 @@ func (s *Service) AttestationsForSlotRange(ctx context.Context, startSlot phase0
 func (s *Service) AttestationsInSlotRange(ctx context.Context, startSlot phase0.Slot, endSlot phase0.Slot) ([]*chaindb.Attestation, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttestationsInSlotRange")
 	defer span.End()
+	defer monitorQuery("AttestationsInSlotRange", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -580,6 +587,7 @@ func (s *Service) AttestationsInSlotRange(ctx context.Context, startSlot phase0.
 func (s *Service) IndeterminateAttestationSlots(ctx context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]phase0.Slot, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "IndeterminateAttestationSlots")
 	defer span.End()
+	defer monitorQuery("IndeterminateAttestationSlots", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -619,3 +627,26 @@ func (s *Service) IndeterminateAttestationSlots(ctx context.Context, minSlot pha
 
 	return slots, nil
 }
+
+// PruneAttestations prunes attestations for slots up to (but not including) the given slot.
+func (s *Service) PruneAttestations(ctx context.Context, to phase0.Slot) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneAttestations")
+	defer span.End()
+	defer monitorQuery("PruneAttestations", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM t_attestations
+WHERE f_slot < $1
+`,
+		to,
+	); err != nil {
+		return errors.Wrap(err, "failed to prune attestations")
+	}
+
+	return nil
+}
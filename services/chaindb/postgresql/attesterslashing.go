@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetAttesterSlashing(ctx context.Context, attesterSlashing *chaindb.AttesterSlashing) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetAttesterSlashing")
 	defer span.End()
+	defer monitorQuery("SetAttesterSlashing", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -108,6 +110,7 @@ func (s *Service) SetAttesterSlashing(ctx context.Context, attesterSlashing *cha
 func (s *Service) AttesterSlashingsForSlotRange(ctx context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]*chaindb.AttesterSlashing, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttesterSlashingsForSlotRange")
 	defer span.End()
+	defer monitorQuery("AttesterSlashingsForSlotRange", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -224,6 +227,7 @@ func (s *Service) AttesterSlashingsForSlotRange(ctx context.Context, minSlot pha
 func (s *Service) AttesterSlashingsForValidator(ctx context.Context, index phase0.ValidatorIndex) ([]*chaindb.AttesterSlashing, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttesterSlashingsForValidator")
 	defer span.End()
+	defer monitorQuery("AttesterSlashingsForValidator", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
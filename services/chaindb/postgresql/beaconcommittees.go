@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -31,6 +32,7 @@ import (
 func (s *Service) SetBeaconCommittee(ctx context.Context, beaconCommittee *chaindb.BeaconCommittee) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetBeaconCommittee")
 	defer span.End()
+	defer monitorQuery("SetBeaconCommittee", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -63,6 +65,7 @@ func (s *Service) BeaconCommittees(ctx context.Context,
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BeaconCommittees")
 	defer span.End()
+	defer monitorQuery("BeaconCommittees", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -175,6 +178,7 @@ LIMIT $%d`, len(queryVals)))
 func (s *Service) BeaconCommitteeBySlotAndIndex(ctx context.Context, slot phase0.Slot, index phase0.CommitteeIndex) (*chaindb.BeaconCommittee, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BeaconCommitteeBySlotAndIndex")
 	defer span.End()
+	defer monitorQuery("BeaconCommitteeBySlotAndIndex", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -217,6 +221,7 @@ func (s *Service) BeaconCommitteeBySlotAndIndex(ctx context.Context, slot phase0
 func (s *Service) AttesterDuties(ctx context.Context, startSlot phase0.Slot, endSlot phase0.Slot, validatorIndices []phase0.ValidatorIndex) ([]*chaindb.AttesterDuty, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttesterDuties")
 	defer span.End()
+	defer monitorQuery("AttesterDuties", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -281,3 +286,26 @@ func (s *Service) AttesterDuties(ctx context.Context, startSlot phase0.Slot, end
 
 	return res, nil
 }
+
+// PruneBeaconCommittees prunes beacon committees for slots up to (but not including) the given slot.
+func (s *Service) PruneBeaconCommittees(ctx context.Context, to phase0.Slot) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneBeaconCommittees")
+	defer span.End()
+	defer monitorQuery("PruneBeaconCommittees", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM t_beacon_committees
+WHERE f_slot < $1
+`,
+		to,
+	); err != nil {
+		return errors.Wrap(err, "failed to prune beacon committees")
+	}
+
+	return nil
+}
@@ -14,8 +14,13 @@
 package postgresql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -27,6 +32,7 @@ import (
 func (s *Service) SetBlock(ctx context.Context, block *chaindb.Block) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetBlock")
 	defer span.End()
+	defer monitorQuery("SetBlock", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -96,10 +102,195 @@ func (s *Service) SetBlock(ctx context.Context, block *chaindb.Block) error {
 	return nil
 }
 
+// graffitiTextFunction creates the graffiti_text function, which decodes graffiti as UTF-8 text
+// with trailing padding removed.  Graffiti that is not valid UTF-8 is returned in PostgreSQL's
+// escape format.
+const graffitiTextFunction = `
+CREATE OR REPLACE FUNCTION graffiti_text(graffiti BYTEA) RETURNS TEXT AS $$
+DECLARE
+  trimmed BYTEA := substring(graffiti FROM 1 FOR (length(rtrim(encode(graffiti,'hex'),'0'))+1)/2);
+BEGIN
+  RETURN convert_from(trimmed,'UTF8');
+EXCEPTION WHEN character_not_in_repertoire OR untranslatable_character THEN
+  RETURN encode(trimmed,'escape');
+END;
+$$ LANGUAGE plpgsql IMMUTABLE STRICT PARALLEL SAFE
+`
+
+// Blocks provides blocks according to the filter.
+func (s *Service) Blocks(ctx context.Context, filter *chaindb.BlockFilter) ([]*chaindb.Block, error) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "Blocks")
+	defer span.End()
+	defer monitorQuery("Blocks", time.Now())
+
+	var err error
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err = s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		tx = s.tx(ctx)
+		defer s.CommitROTx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_slot
+      ,f_proposer_index
+      ,f_root
+      ,f_graffiti
+      ,f_randao_reveal
+      ,f_body_root
+      ,f_parent_root
+      ,f_state_root
+      ,f_canonical
+      ,f_eth1_block_hash
+      ,f_eth1_deposit_count
+      ,f_eth1_deposit_root
+FROM t_blocks`)
+
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_slot >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_slot <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.ProposerIndices) > 0 {
+		queryVals = append(queryVals, filter.ProposerIndices)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_proposer_index = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.Canonical != nil {
+		queryVals = append(queryVals, *filter.Canonical)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_canonical = $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.Graffiti != nil {
+		queryVals = append(queryVals, *filter.Graffiti)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s STRPOS(LOWER(graffiti_text(f_graffiti)),LOWER($%d)) > 0`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.GraffitiRegex != nil {
+		queryVals = append(queryVals, *filter.GraffitiRegex)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s graffiti_text(f_graffiti) ~* $%d`, wherestr, len(queryVals)))
+	}
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_slot,f_root`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_slot DESC,f_root DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make([]*chaindb.Block, 0)
+	for rows.Next() {
+		block := &chaindb.Block{}
+		var blockRoot []byte
+		var randaoReveal []byte
+		var bodyRoot []byte
+		var parentRoot []byte
+		var stateRoot []byte
+		var canonical sql.NullBool
+		var eth1DepositRoot []byte
+		err := rows.Scan(
+			&block.Slot,
+			&block.ProposerIndex,
+			&blockRoot,
+			&block.Graffiti,
+			&randaoReveal,
+			&bodyRoot,
+			&parentRoot,
+			&stateRoot,
+			&canonical,
+			&block.ETH1BlockHash,
+			&block.ETH1DepositCount,
+			&eth1DepositRoot,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		copy(block.Root[:], blockRoot)
+		copy(block.RANDAOReveal[:], randaoReveal)
+		copy(block.BodyRoot[:], bodyRoot)
+		copy(block.ParentRoot[:], parentRoot)
+		copy(block.StateRoot[:], stateRoot)
+		if canonical.Valid {
+			val := canonical.Bool
+			block.Canonical = &val
+		}
+		copy(block.ETH1DepositRoot[:], eth1DepositRoot)
+		blocks = append(blocks, block)
+	}
+
+	// Add execution payload to the blocks where available.
+	if err := s.addExecutionPayloads(ctx, tx, blocks); err != nil {
+		return nil, err
+	}
+
+	// Always return order of slot then root.
+	sort.Slice(blocks, func(i int, j int) bool {
+		if blocks[i].Slot != blocks[j].Slot {
+			return blocks[i].Slot < blocks[j].Slot
+		}
+		return bytes.Compare(blocks[i].Root[:], blocks[j].Root[:]) < 0
+	})
+	return blocks, nil
+}
+
 // BlocksBySlot fetches all blocks with the given slot.
 func (s *Service) BlocksBySlot(ctx context.Context, slot phase0.Slot) ([]*chaindb.Block, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BlocksBySlot")
 	defer span.End()
+	defer monitorQuery("BlocksBySlot", time.Now())
 
 	var err error
 
@@ -177,11 +368,8 @@ func (s *Service) BlocksBySlot(ctx context.Context, slot phase0.Slot) ([]*chaind
 	}
 
 	// Add execution payload to the blocks where available.
-	for _, block := range blocks {
-		block.ExecutionPayload, err = s.executionPayload(ctx, tx, block.Root)
-		if err != nil {
-			return nil, err
-		}
+	if err := s.addExecutionPayloads(ctx, tx, blocks); err != nil {
+		return nil, err
 	}
 
 	return blocks, nil
@@ -193,6 +381,7 @@ func (s *Service) BlocksBySlot(ctx context.Context, slot phase0.Slot) ([]*chaind
 func (s *Service) BlocksForSlotRange(ctx context.Context, startSlot phase0.Slot, endSlot phase0.Slot) ([]*chaindb.Block, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BlocksForSlotRange")
 	defer span.End()
+	defer monitorQuery("BlocksForSlotRange", time.Now())
 
 	var err error
 
@@ -272,11 +461,8 @@ func (s *Service) BlocksForSlotRange(ctx context.Context, startSlot phase0.Slot,
 	}
 
 	// Add execution payload to the blocks where available.
-	for _, block := range blocks {
-		block.ExecutionPayload, err = s.executionPayload(ctx, tx, block.Root)
-		if err != nil {
-			return nil, err
-		}
+	if err := s.addExecutionPayloads(ctx, tx, blocks); err != nil {
+		return nil, err
 	}
 
 	return blocks, nil
@@ -286,6 +472,7 @@ func (s *Service) BlocksForSlotRange(ctx context.Context, startSlot phase0.Slot,
 func (s *Service) BlockByRoot(ctx context.Context, root phase0.Root) (*chaindb.Block, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BlockByroot")
 	defer span.End()
+	defer monitorQuery("BlockByroot", time.Now())
 
 	var err error
 
@@ -368,6 +555,7 @@ func (s *Service) BlockByRoot(ctx context.Context, root phase0.Root) (*chaindb.B
 func (s *Service) CanonicalBlockPresenceForSlotRange(ctx context.Context, startSlot phase0.Slot, endSlot phase0.Slot) ([]bool, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "CanonicalBlockPresenceForSlotRange")
 	defer span.End()
+	defer monitorQuery("CanonicalBlockPresenceForSlotRange", time.Now())
 
 	var err error
 
@@ -415,6 +603,7 @@ func (s *Service) CanonicalBlockPresenceForSlotRange(ctx context.Context, startS
 func (s *Service) BlocksByParentRoot(ctx context.Context, parentRoot phase0.Root) ([]*chaindb.Block, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BlocksByParentRoot")
 	defer span.End()
+	defer monitorQuery("BlocksByParentRoot", time.Now())
 
 	var err error
 
@@ -491,11 +680,8 @@ func (s *Service) BlocksByParentRoot(ctx context.Context, parentRoot phase0.Root
 	}
 
 	// Add execution payload to the blocks where available.
-	for _, block := range blocks {
-		block.ExecutionPayload, err = s.executionPayload(ctx, tx, block.Root)
-		if err != nil {
-			return nil, err
-		}
+	if err := s.addExecutionPayloads(ctx, tx, blocks); err != nil {
+		return nil, err
 	}
 
 	return blocks, nil
@@ -505,6 +691,7 @@ func (s *Service) BlocksByParentRoot(ctx context.Context, parentRoot phase0.Root
 func (s *Service) EmptySlots(ctx context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]phase0.Slot, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "EmptySlots")
 	defer span.End()
+	defer monitorQuery("EmptySlots", time.Now())
 
 	var err error
 
@@ -549,6 +736,7 @@ func (s *Service) EmptySlots(ctx context.Context, minSlot phase0.Slot, maxSlot p
 func (s *Service) IndeterminateBlocks(ctx context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]phase0.Root, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "IndeterminateBlocks")
 	defer span.End()
+	defer monitorQuery("IndeterminateBlocks", time.Now())
 
 	var err error
 
@@ -596,6 +784,7 @@ func (s *Service) IndeterminateBlocks(ctx context.Context, minSlot phase0.Slot,
 func (s *Service) LatestBlocks(ctx context.Context) ([]*chaindb.Block, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "LatestBlocks")
 	defer span.End()
+	defer monitorQuery("LatestBlocks", time.Now())
 
 	var err error
 
@@ -674,11 +863,8 @@ func (s *Service) LatestBlocks(ctx context.Context) ([]*chaindb.Block, error) {
 	}
 
 	// Add execution payload to the blocks where available.
-	for _, block := range blocks {
-		block.ExecutionPayload, err = s.executionPayload(ctx, tx, block.Root)
-		if err != nil {
-			return nil, err
-		}
+	if err := s.addExecutionPayloads(ctx, tx, blocks); err != nil {
+		return nil, err
 	}
 
 	return blocks, nil
@@ -688,6 +874,7 @@ func (s *Service) LatestBlocks(ctx context.Context) ([]*chaindb.Block, error) {
 func (s *Service) LatestCanonicalBlock(ctx context.Context) (phase0.Slot, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "LatestCanonicalBlock")
 	defer span.End()
+	defer monitorQuery("LatestCanonicalBlock", time.Now())
 
 	var err error
 
@@ -722,6 +909,7 @@ func (s *Service) LatestCanonicalBlock(ctx context.Context) (phase0.Slot, error)
 func (s *Service) ProposalCount(ctx context.Context, validatorIndices []phase0.ValidatorIndex, startSlot phase0.Slot, endSlot phase0.Slot) (uint64, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ProposalCount")
 	defer span.End()
+	defer monitorQuery("ProposalCount", time.Now())
 
 	var err error
 
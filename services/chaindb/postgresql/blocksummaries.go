@@ -15,6 +15,8 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,31 +28,105 @@ import (
 func (s *Service) SetBlockSummary(ctx context.Context, summary *chaindb.BlockSummary) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetBlockSummary")
 	defer span.End()
+	defer monitorQuery("SetBlockSummary", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
 		return ErrNoTransaction
 	}
 
+	var consensusReward sql.NullInt64
+	var attestationsReward sql.NullInt64
+	var syncAggregateReward sql.NullInt64
+	var proposerSlashingsReward sql.NullInt64
+	var attesterSlashingsReward sql.NullInt64
+	if summary.ConsensusReward != nil {
+		consensusReward.Valid = true
+		consensusReward.Int64 = int64(summary.ConsensusReward.Total)
+		attestationsReward.Valid = true
+		attestationsReward.Int64 = int64(summary.ConsensusReward.Attestations)
+		syncAggregateReward.Valid = true
+		syncAggregateReward.Int64 = int64(summary.ConsensusReward.SyncAggregate)
+		proposerSlashingsReward.Valid = true
+		proposerSlashingsReward.Int64 = int64(summary.ConsensusReward.ProposerSlashings)
+		attesterSlashingsReward.Valid = true
+		attesterSlashingsReward.Int64 = int64(summary.ConsensusReward.AttesterSlashings)
+	}
+	var newVotes sql.NullInt32
+	if summary.NewVotes != nil {
+		newVotes.Valid = true
+		newVotes.Int32 = int32(*summary.NewVotes)
+	}
+	var availableVotes sql.NullInt32
+	if summary.AvailableVotes != nil {
+		availableVotes.Valid = true
+		availableVotes.Int32 = int32(*summary.AvailableVotes)
+	}
+	var redundantAggregates sql.NullInt32
+	if summary.RedundantAggregates != nil {
+		redundantAggregates.Valid = true
+		redundantAggregates.Int32 = int32(*summary.RedundantAggregates)
+	}
+	var consensusClient sql.NullString
+	if summary.ConsensusClient != "" {
+		consensusClient.Valid = true
+		consensusClient.String = summary.ConsensusClient
+	}
+	var executionClient sql.NullString
+	if summary.ExecutionClient != "" {
+		executionClient.Valid = true
+		executionClient.String = summary.ExecutionClient
+	}
+
 	_, err := tx.Exec(ctx, `
       INSERT INTO t_block_summaries(f_slot
                                    ,f_attestations_for_block
                                    ,f_duplicate_attestations_for_block
                                    ,f_votes_for_block
-                                   ,f_parent_distance)
-      VALUES($1,$2,$3,$4,$5)
+                                   ,f_parent_distance
+                                   ,f_consensus_reward
+                                   ,f_attestations_reward
+                                   ,f_sync_aggregate_reward
+                                   ,f_proposer_slashings_reward
+                                   ,f_attester_slashings_reward
+                                   ,f_consensus_client
+                                   ,f_execution_client
+                                   ,f_new_votes
+                                   ,f_available_votes
+                                   ,f_redundant_aggregates)
+      VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
       ON CONFLICT (f_slot) DO
       UPDATE
       SET f_attestations_for_block = excluded.f_attestations_for_block
          ,f_duplicate_attestations_for_block = excluded.f_duplicate_attestations_for_block
          ,f_votes_for_block = excluded.f_votes_for_block
          ,f_parent_distance = excluded.f_parent_distance
+         ,f_consensus_reward = excluded.f_consensus_reward
+         ,f_attestations_reward = excluded.f_attestations_reward
+         ,f_sync_aggregate_reward = excluded.f_sync_aggregate_reward
+         ,f_proposer_slashings_reward = excluded.f_proposer_slashings_reward
+         ,f_attester_slashings_reward = excluded.f_attester_slashings_reward
+         ,f_consensus_client = excluded.f_consensus_client
+         ,f_execution_client = excluded.f_execution_client
+         ,f_new_votes = excluded.f_new_votes
+         ,f_available_votes = excluded.f_available_votes
+         ,f_redundant_aggregates = excluded.f_redundant_aggregates
 		 `,
 		summary.Slot,
 		summary.AttestationsForBlock,
 		summary.DuplicateAttestationsForBlock,
 		summary.VotesForBlock,
 		summary.ParentDistance,
+		consensusReward,
+		attestationsReward,
+		syncAggregateReward,
+		proposerSlashingsReward,
+		attesterSlashingsReward,
+		consensusClient,
+		executionClient,
+		newVotes,
+		availableVotes,
+		redundantAggregates,
 	)
 
 	return err
@@ -60,6 +136,7 @@ func (s *Service) SetBlockSummary(ctx context.Context, summary *chaindb.BlockSum
 func (s *Service) BlockSummaryForSlot(ctx context.Context, slot phase0.Slot) (*chaindb.BlockSummary, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BlockSummaryForSlot")
 	defer span.End()
+	defer monitorQuery("BlockSummaryForSlot", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -74,11 +151,31 @@ func (s *Service) BlockSummaryForSlot(ctx context.Context, slot phase0.Slot) (*c
 	summary := &chaindb.BlockSummary{
 		Slot: slot,
 	}
+	var consensusReward sql.NullInt64
+	var attestationsReward sql.NullInt64
+	var syncAggregateReward sql.NullInt64
+	var proposerSlashingsReward sql.NullInt64
+	var attesterSlashingsReward sql.NullInt64
+	var consensusClient sql.NullString
+	var executionClient sql.NullString
+	var newVotes sql.NullInt32
+	var availableVotes sql.NullInt32
+	var redundantAggregates sql.NullInt32
 	err := tx.QueryRow(ctx, `
 SELECT f_attestations_for_block
       ,f_duplicate_attestations_for_block
       ,f_votes_for_block
       ,f_parent_distance
+      ,f_consensus_reward
+      ,f_attestations_reward
+      ,f_sync_aggregate_reward
+      ,f_proposer_slashings_reward
+      ,f_attester_slashings_reward
+      ,f_consensus_client
+      ,f_execution_client
+      ,f_new_votes
+      ,f_available_votes
+      ,f_redundant_aggregates
 FROM t_block_summaries
 WHERE f_slot = $1
 `,
@@ -88,10 +185,43 @@ WHERE f_slot = $1
 		&summary.DuplicateAttestationsForBlock,
 		&summary.VotesForBlock,
 		&summary.ParentDistance,
+		&consensusReward,
+		&attestationsReward,
+		&syncAggregateReward,
+		&proposerSlashingsReward,
+		&attesterSlashingsReward,
+		&consensusClient,
+		&executionClient,
+		&newVotes,
+		&availableVotes,
+		&redundantAggregates,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to scan row")
 	}
+	if consensusReward.Valid {
+		summary.ConsensusReward = &chaindb.BlockReward{
+			Total:             phase0.Gwei(consensusReward.Int64),
+			Attestations:      phase0.Gwei(attestationsReward.Int64),
+			SyncAggregate:     phase0.Gwei(syncAggregateReward.Int64),
+			ProposerSlashings: phase0.Gwei(proposerSlashingsReward.Int64),
+			AttesterSlashings: phase0.Gwei(attesterSlashingsReward.Int64),
+		}
+	}
+	summary.ConsensusClient = consensusClient.String
+	summary.ExecutionClient = executionClient.String
+	if newVotes.Valid {
+		val := int(newVotes.Int32)
+		summary.NewVotes = &val
+	}
+	if availableVotes.Valid {
+		val := int(availableVotes.Int32)
+		summary.AvailableVotes = &val
+	}
+	if redundantAggregates.Valid {
+		val := int(redundantAggregates.Int32)
+		summary.RedundantAggregates = &val
+	}
 
 	return summary, nil
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -30,6 +31,7 @@ import (
 func (s *Service) setBLSToExecutionChanges(ctx context.Context, block *chaindb.Block) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "setBLSToExecutionChanges")
 	defer span.End()
+	defer monitorQuery("setBLSToExecutionChanges", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -77,6 +79,7 @@ SET f_validator_index = excluded.f_validator_index
 func (s *Service) BLSToExecutionChanges(ctx context.Context, filter *chaindb.BLSToExecutionChangeFilter) ([]*chaindb.BLSToExecutionChange, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BLSToExecutionChanges")
 	defer span.End()
+	defer monitorQuery("BLSToExecutionChanges", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
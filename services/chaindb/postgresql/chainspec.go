@@ -30,6 +30,7 @@ import (
 func (s *Service) SetChainSpecValue(ctx context.Context, key string, value interface{}) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetChainSpecValue")
 	defer span.End()
+	defer monitorQuery("SetChainSpecValue", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -68,6 +69,7 @@ func (s *Service) SetChainSpecValue(ctx context.Context, key string, value inter
 func (s *Service) ChainSpec(ctx context.Context) (map[string]interface{}, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ChainSpec")
 	defer span.End()
+	defer monitorQuery("ChainSpec", time.Now())
 
 	var err error
 
@@ -113,6 +115,7 @@ func (s *Service) ChainSpec(ctx context.Context) (map[string]interface{}, error)
 func (s *Service) ChainSpecValue(ctx context.Context, key string) (interface{}, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ChainSpecValue")
 	defer span.End()
+	defer monitorQuery("ChainSpecValue", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
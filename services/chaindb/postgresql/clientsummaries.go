@@ -0,0 +1,354 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"go.opentelemetry.io/otel"
+)
+
+// SetClientEpochSummaries sets the client summaries for an epoch, replacing any existing summaries.
+func (s *Service) SetClientEpochSummaries(ctx context.Context, epoch phase0.Epoch, summaries []*chaindb.ClientEpochSummary) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetClientEpochSummaries")
+	defer span.End()
+	defer monitorQuery("SetClientEpochSummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM t_client_epoch_summaries
+WHERE f_epoch = $1
+`,
+		epoch,
+	); err != nil {
+		return errors.Wrap(err, "failed to remove existing client epoch summaries")
+	}
+
+	for _, summary := range summaries {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO t_client_epoch_summaries(f_epoch
+                                    ,f_consensus_client
+                                    ,f_execution_client
+                                    ,f_proposals)
+VALUES($1,$2,$3,$4)
+`,
+			summary.Epoch,
+			summary.ConsensusClient,
+			summary.ExecutionClient,
+			summary.Proposals,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetClientDaySummaries sets the client summaries for a day, replacing any existing summaries.
+func (s *Service) SetClientDaySummaries(ctx context.Context, startTimestamp time.Time, summaries []*chaindb.ClientDaySummary) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetClientDaySummaries")
+	defer span.End()
+	defer monitorQuery("SetClientDaySummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM t_client_day_summaries
+WHERE f_start_timestamp = $1
+`,
+		startTimestamp,
+	); err != nil {
+		return errors.Wrap(err, "failed to remove existing client day summaries")
+	}
+
+	for _, summary := range summaries {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO t_client_day_summaries(f_start_timestamp
+                                  ,f_consensus_client
+                                  ,f_execution_client
+                                  ,f_proposals)
+VALUES($1,$2,$3,$4)
+`,
+			summary.StartTimestamp,
+			summary.ConsensusClient,
+			summary.ExecutionClient,
+			summary.Proposals,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ClientEpochSummaries provides client epoch summaries according to the filter.
+func (s *Service) ClientEpochSummaries(ctx context.Context,
+	filter *chaindb.ClientEpochSummaryFilter,
+) (
+	[]*chaindb.ClientEpochSummary,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ClientEpochSummaries")
+	defer span.End()
+	defer monitorQuery("ClientEpochSummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_epoch
+      ,f_consensus_client
+      ,f_execution_client
+      ,f_proposals
+FROM t_client_epoch_summaries`)
+
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_epoch >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_epoch <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	queryVals = clientFilterSQL(&queryBuilder, queryVals, wherestr, filter.ConsensusClients, filter.ExecutionClients)
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_epoch,f_consensus_client,f_execution_client`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_epoch DESC,f_consensus_client DESC,f_execution_client DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make([]*chaindb.ClientEpochSummary, 0)
+	for rows.Next() {
+		summary := &chaindb.ClientEpochSummary{}
+		err := rows.Scan(
+			&summary.Epoch,
+			&summary.ConsensusClient,
+			&summary.ExecutionClient,
+			&summary.Proposals,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		summaries = append(summaries, summary)
+	}
+
+	// Always return order of epoch then clients.
+	sort.Slice(summaries, func(i int, j int) bool {
+		if summaries[i].Epoch != summaries[j].Epoch {
+			return summaries[i].Epoch < summaries[j].Epoch
+		}
+		if summaries[i].ConsensusClient != summaries[j].ConsensusClient {
+			return summaries[i].ConsensusClient < summaries[j].ConsensusClient
+		}
+		return summaries[i].ExecutionClient < summaries[j].ExecutionClient
+	})
+	return summaries, nil
+}
+
+// ClientDaySummaries provides client day summaries according to the filter.
+func (s *Service) ClientDaySummaries(ctx context.Context,
+	filter *chaindb.ClientDaySummaryFilter,
+) (
+	[]*chaindb.ClientDaySummary,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ClientDaySummaries")
+	defer span.End()
+	defer monitorQuery("ClientDaySummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_start_timestamp
+      ,f_consensus_client
+      ,f_execution_client
+      ,f_proposals
+FROM t_client_day_summaries`)
+
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_start_timestamp >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_start_timestamp <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	queryVals = clientFilterSQL(&queryBuilder, queryVals, wherestr, filter.ConsensusClients, filter.ExecutionClients)
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp,f_consensus_client,f_execution_client`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp DESC,f_consensus_client DESC,f_execution_client DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make([]*chaindb.ClientDaySummary, 0)
+	for rows.Next() {
+		summary := &chaindb.ClientDaySummary{}
+		err := rows.Scan(
+			&summary.StartTimestamp,
+			&summary.ConsensusClient,
+			&summary.ExecutionClient,
+			&summary.Proposals,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		summaries = append(summaries, summary)
+	}
+
+	// Always return order of start timestamp then clients.
+	sort.Slice(summaries, func(i int, j int) bool {
+		if !summaries[i].StartTimestamp.Equal(summaries[j].StartTimestamp) {
+			return summaries[i].StartTimestamp.Before(summaries[j].StartTimestamp)
+		}
+		if summaries[i].ConsensusClient != summaries[j].ConsensusClient {
+			return summaries[i].ConsensusClient < summaries[j].ConsensusClient
+		}
+		return summaries[i].ExecutionClient < summaries[j].ExecutionClient
+	})
+	return summaries, nil
+}
+
+// clientFilterSQL adds the client clauses of a client summary filter to a query.
+func clientFilterSQL(queryBuilder *strings.Builder,
+	queryVals []interface{},
+	wherestr string,
+	consensusClients []string,
+	executionClients []string,
+) []interface{} {
+	if len(consensusClients) > 0 {
+		queryVals = append(queryVals, consensusClients)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_consensus_client = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(executionClients) > 0 {
+		queryVals = append(queryVals, executionClients)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_execution_client = ANY($%d)`, wherestr, len(queryVals)))
+	}
+
+	return queryVals
+}
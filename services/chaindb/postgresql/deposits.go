@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetDeposit(ctx context.Context, deposit *chaindb.Deposit) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetDeposit")
 	defer span.End()
+	defer monitorQuery("SetDeposit", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -61,6 +63,7 @@ func (s *Service) SetDeposit(ctx context.Context, deposit *chaindb.Deposit) erro
 func (s *Service) DepositsByPublicKey(ctx context.Context, pubKeys []phase0.BLSPubKey) (map[phase0.BLSPubKey][]*chaindb.Deposit, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "DepositsByPublicKey")
 	defer span.End()
+	defer monitorQuery("DepositsByPublicKey", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -129,6 +132,7 @@ func (s *Service) DepositsByPublicKey(ctx context.Context, pubKeys []phase0.BLSP
 func (s *Service) DepositsForSlotRange(ctx context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]*chaindb.Deposit, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "DepositsForSlotRange")
 	defer span.End()
+	defer monitorQuery("DepositsForSlotRange", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/wealdtech/chaind/services/chaindb"
 	"go.opentelemetry.io/otel"
@@ -24,6 +25,7 @@ import (
 func (s *Service) SetEpochSummary(ctx context.Context, summary *chaindb.EpochSummary) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetEpochSummary")
 	defer span.End()
+	defer monitorQuery("SetEpochSummary", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -50,8 +52,9 @@ func (s *Service) SetEpochSummary(ctx context.Context, summary *chaindb.EpochSum
                                    ,f_attester_slashings
                                    ,f_deposits
                                    ,f_exiting_validators
-                                   ,f_canonical_blocks)
-      VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
+                                   ,f_canonical_blocks
+                                   ,f_inactivity_leak)
+      VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21)
       ON CONFLICT (f_epoch) DO
       UPDATE
       SET f_activation_queue_length = excluded.f_activation_queue_length
@@ -73,6 +76,7 @@ func (s *Service) SetEpochSummary(ctx context.Context, summary *chaindb.EpochSum
          ,f_deposits = excluded.f_deposits
          ,f_exiting_validators = excluded.f_exiting_validators
          ,f_canonical_blocks = excluded.f_canonical_blocks
+         ,f_inactivity_leak = excluded.f_inactivity_leak
 		 `,
 		summary.Epoch,
 		summary.ActivationQueueLength,
@@ -94,6 +98,7 @@ func (s *Service) SetEpochSummary(ctx context.Context, summary *chaindb.EpochSum
 		summary.Deposits,
 		summary.ExitingValidators,
 		summary.CanonicalBlocks,
+		summary.InactivityLeak,
 	)
 
 	return err
@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetETH1Deposit(ctx context.Context, deposit *chaindb.ETH1Deposit) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetETH1Deposit")
 	defer span.End()
+	defer monitorQuery("SetETH1Deposit", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -46,8 +48,9 @@ func (s *Service) SetETH1Deposit(ctx context.Context, deposit *chaindb.ETH1Depos
                                  ,f_validator_pubkey
                                  ,f_withdrawal_credentials
                                  ,f_signature
-                                 ,f_amount)
-      VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+                                 ,f_amount
+                                 ,f_provisional)
+      VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
       ON CONFLICT (f_deposit_index) DO
       UPDATE
       SET f_eth1_block_number = excluded.f_eth1_block_number
@@ -62,6 +65,7 @@ func (s *Service) SetETH1Deposit(ctx context.Context, deposit *chaindb.ETH1Depos
          ,f_withdrawal_credentials = excluded.f_withdrawal_credentials
          ,f_signature = excluded.f_signature
          ,f_amount = excluded.f_amount
+         ,f_provisional = excluded.f_provisional
       `,
 		deposit.ETH1BlockNumber,
 		deposit.ETH1BlockHash,
@@ -77,6 +81,7 @@ func (s *Service) SetETH1Deposit(ctx context.Context, deposit *chaindb.ETH1Depos
 		deposit.WithdrawalCredentials,
 		deposit.Signature[:],
 		deposit.Amount,
+		deposit.Provisional,
 	)
 
 	return err
@@ -86,6 +91,7 @@ func (s *Service) SetETH1Deposit(ctx context.Context, deposit *chaindb.ETH1Depos
 func (s *Service) ETH1DepositsByPublicKey(ctx context.Context, pubKeys []phase0.BLSPubKey) ([]*chaindb.ETH1Deposit, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ETH1DepositsByPublicKey")
 	defer span.End()
+	defer monitorQuery("ETH1DepositsByPublicKey", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -116,6 +122,7 @@ func (s *Service) ETH1DepositsByPublicKey(ctx context.Context, pubKeys []phase0.
             ,f_withdrawal_credentials
             ,f_signature
             ,f_amount
+            ,f_provisional
       FROM t_eth1_deposits
       WHERE f_validator_pubkey = ANY($1)
       ORDER BY f_eth1_block_number
@@ -148,6 +155,7 @@ func (s *Service) ETH1DepositsByPublicKey(ctx context.Context, pubKeys []phase0.
 			&deposit.WithdrawalCredentials,
 			&signature,
 			&deposit.Amount,
+			&deposit.Provisional,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
@@ -159,3 +167,43 @@ func (s *Service) ETH1DepositsByPublicKey(ctx context.Context, pubKeys []phase0.
 
 	return deposits, nil
 }
+
+// PruneETH1DepositsFromBlock prunes Ethereum 1 deposits from (and including) the given block number.
+func (s *Service) PruneETH1DepositsFromBlock(ctx context.Context, blockNumber uint64) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneETH1DepositsFromBlock")
+	defer span.End()
+	defer monitorQuery("PruneETH1DepositsFromBlock", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	_, err := tx.Exec(ctx, `
+      DELETE FROM t_eth1_deposits
+      WHERE f_eth1_block_number >= $1
+	  `,
+		blockNumber,
+	)
+
+	return err
+}
+
+// PruneProvisionalETH1Deposits prunes all provisional Ethereum 1 deposits.
+func (s *Service) PruneProvisionalETH1Deposits(ctx context.Context) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneProvisionalETH1Deposits")
+	defer span.End()
+	defer monitorQuery("PruneProvisionalETH1Deposits", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	_, err := tx.Exec(ctx, `
+      DELETE FROM t_eth1_deposits
+      WHERE f_provisional = true
+	  `)
+
+	return err
+}
@@ -0,0 +1,230 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"go.opentelemetry.io/otel"
+)
+
+// SetETH1VotePeriod sets an Ethereum 1 vote period.
+func (s *Service) SetETH1VotePeriod(ctx context.Context, period *chaindb.ETH1VotePeriod) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetETH1VotePeriod")
+	defer span.End()
+	defer monitorQuery("SetETH1VotePeriod", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	var winningDepositCount sql.NullInt64
+	var winningDepositRoot []byte
+	if period.WinningETH1BlockHash != nil {
+		winningDepositCount.Valid = true
+		winningDepositCount.Int64 = int64(period.WinningETH1DepositCount)
+		winningDepositRoot = period.WinningETH1DepositRoot[:]
+	}
+	var majorityEpoch sql.NullInt64
+	if period.MajorityEpoch != nil {
+		majorityEpoch.Valid = true
+		majorityEpoch.Int64 = int64(*period.MajorityEpoch)
+	}
+	minorityProposers := period.MinorityProposers
+	if minorityProposers == nil {
+		minorityProposers = make([]phase0.ValidatorIndex, 0)
+	}
+	staleProposers := period.StaleProposers
+	if staleProposers == nil {
+		staleProposers = make([]phase0.ValidatorIndex, 0)
+	}
+
+	_, err := tx.Exec(ctx, `
+INSERT INTO t_eth1_vote_periods(f_period
+                               ,f_start_epoch
+                               ,f_blocks
+                               ,f_distinct_votes
+                               ,f_winning_eth1_block_hash
+                               ,f_winning_eth1_deposit_count
+                               ,f_winning_eth1_deposit_root
+                               ,f_winning_votes
+                               ,f_majority_epoch
+                               ,f_minority_proposers
+                               ,f_stale_proposers)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+ON CONFLICT (f_period) DO
+UPDATE
+SET f_start_epoch = excluded.f_start_epoch
+   ,f_blocks = excluded.f_blocks
+   ,f_distinct_votes = excluded.f_distinct_votes
+   ,f_winning_eth1_block_hash = excluded.f_winning_eth1_block_hash
+   ,f_winning_eth1_deposit_count = excluded.f_winning_eth1_deposit_count
+   ,f_winning_eth1_deposit_root = excluded.f_winning_eth1_deposit_root
+   ,f_winning_votes = excluded.f_winning_votes
+   ,f_majority_epoch = excluded.f_majority_epoch
+   ,f_minority_proposers = excluded.f_minority_proposers
+   ,f_stale_proposers = excluded.f_stale_proposers
+`,
+		period.Period,
+		period.StartEpoch,
+		period.Blocks,
+		period.DistinctVotes,
+		period.WinningETH1BlockHash,
+		winningDepositCount,
+		winningDepositRoot,
+		period.WinningVotes,
+		majorityEpoch,
+		minorityProposers,
+		staleProposers,
+	)
+
+	return err
+}
+
+// ETH1VotePeriods provides Ethereum 1 vote periods according to the filter.
+func (s *Service) ETH1VotePeriods(ctx context.Context,
+	filter *chaindb.ETH1VotePeriodFilter,
+) (
+	[]*chaindb.ETH1VotePeriod,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ETH1VotePeriods")
+	defer span.End()
+	defer monitorQuery("ETH1VotePeriods", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_period
+      ,f_start_epoch
+      ,f_blocks
+      ,f_distinct_votes
+      ,f_winning_eth1_block_hash
+      ,f_winning_eth1_deposit_count
+      ,f_winning_eth1_deposit_root
+      ,f_winning_votes
+      ,f_majority_epoch
+      ,f_minority_proposers
+      ,f_stale_proposers
+FROM t_eth1_vote_periods`)
+
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_period >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_period <= $%d`, wherestr, len(queryVals)))
+	}
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_period`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_period DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := make([]*chaindb.ETH1VotePeriod, 0)
+	for rows.Next() {
+		period := &chaindb.ETH1VotePeriod{}
+		var winningDepositCount sql.NullInt64
+		var winningDepositRoot []byte
+		var majorityEpoch sql.NullInt64
+		err := rows.Scan(
+			&period.Period,
+			&period.StartEpoch,
+			&period.Blocks,
+			&period.DistinctVotes,
+			&period.WinningETH1BlockHash,
+			&winningDepositCount,
+			&winningDepositRoot,
+			&period.WinningVotes,
+			&majorityEpoch,
+			&period.MinorityProposers,
+			&period.StaleProposers,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		if winningDepositCount.Valid {
+			period.WinningETH1DepositCount = uint64(winningDepositCount.Int64)
+		}
+		copy(period.WinningETH1DepositRoot[:], winningDepositRoot)
+		if majorityEpoch.Valid {
+			epoch := phase0.Epoch(majorityEpoch.Int64)
+			period.MajorityEpoch = &epoch
+		}
+		periods = append(periods, period)
+	}
+
+	// Always return order of period.
+	sort.Slice(periods, func(i int, j int) bool {
+		return periods[i].Period < periods[j].Period
+	})
+	return periods, nil
+}
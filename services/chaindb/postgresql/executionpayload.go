@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/jackc/pgx/v4"
@@ -28,6 +29,7 @@ import (
 func (s *Service) setExecutionPayload(ctx context.Context, block *chaindb.Block) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "setExecutionPayload")
 	defer span.End()
+	defer monitorQuery("setExecutionPayload", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -121,21 +123,60 @@ func (s *Service) executionPayload(ctx context.Context,
 	*chaindb.ExecutionPayload,
 	error,
 ) {
-	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "executionPayload")
+	payloads, err := s.executionPayloads(ctx, tx, []phase0.Root{root})
+	if err != nil {
+		return nil, err
+	}
+
+	// A missing execution payload is fine.
+	return payloads[root], nil
+}
+
+// addExecutionPayloads adds the execution payloads to the blocks where available.
+func (s *Service) addExecutionPayloads(ctx context.Context,
+	tx pgx.Tx,
+	blocks []*chaindb.Block,
+) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	roots := make([]phase0.Root, len(blocks))
+	for i := range blocks {
+		roots[i] = blocks[i].Root
+	}
+	payloads, err := s.executionPayloads(ctx, tx, roots)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		block.ExecutionPayload = payloads[block.Root]
+	}
+
+	return nil
+}
+
+// executionPayloads fetches the execution payloads of the given blocks, keyed by block root.
+// Blocks without execution payloads are not present in the result.
+func (s *Service) executionPayloads(ctx context.Context,
+	tx pgx.Tx,
+	roots []phase0.Root,
+) (
+	map[phase0.Root]*chaindb.ExecutionPayload,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "executionPayloads")
 	defer span.End()
+	defer monitorQuery("executionPayloads", time.Now())
+
+	blockRoots := make([][]byte, len(roots))
+	for i := range roots {
+		blockRoots[i] = roots[i][:]
+	}
 
-	payload := &chaindb.ExecutionPayload{}
-	var blockHash []byte
-	var parentHash []byte
-	var feeRecipient []byte
-	var stateRoot []byte
-	var receiptsRoot []byte
-	var logsBloom []byte
-	var prevRandao []byte
-	var baseFeePerGas decimal.Decimal
-
-	err := tx.QueryRow(ctx, `
-SELECT f_block_number
+	rows, err := tx.Query(ctx, `
+SELECT f_block_root
+      ,f_block_number
       ,f_block_hash
       ,f_parent_hash
       ,f_fee_recipient
@@ -149,38 +190,60 @@ SELECT f_block_number
       ,f_timestamp
       ,f_extra_data
 FROM t_block_execution_payloads
-WHERE f_block_root = $1`,
-		root[:],
-	).Scan(
-		&payload.BlockNumber,
-		&blockHash,
-		&parentHash,
-		&feeRecipient,
-		&stateRoot,
-		&receiptsRoot,
-		&logsBloom,
-		&prevRandao,
-		&payload.GasLimit,
-		&payload.GasUsed,
-		&baseFeePerGas,
-		&payload.Timestamp,
-		&payload.ExtraData,
+WHERE f_block_root = ANY($1)`,
+		blockRoots,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			// Means there is no execution payload; this is fine.
-			return nil, nil
+		return nil, err
+	}
+	defer rows.Close()
+
+	payloads := make(map[phase0.Root]*chaindb.ExecutionPayload, len(roots))
+	for rows.Next() {
+		payload := &chaindb.ExecutionPayload{}
+		var blockRoot []byte
+		var blockHash []byte
+		var parentHash []byte
+		var feeRecipient []byte
+		var stateRoot []byte
+		var receiptsRoot []byte
+		var logsBloom []byte
+		var prevRandao []byte
+		var baseFeePerGas decimal.Decimal
+		err := rows.Scan(
+			&blockRoot,
+			&payload.BlockNumber,
+			&blockHash,
+			&parentHash,
+			&feeRecipient,
+			&stateRoot,
+			&receiptsRoot,
+			&logsBloom,
+			&prevRandao,
+			&payload.GasLimit,
+			&payload.GasUsed,
+			&baseFeePerGas,
+			&payload.Timestamp,
+			&payload.ExtraData,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
 		}
+		copy(payload.BlockHash[:], blockHash)
+		copy(payload.ParentHash[:], parentHash)
+		copy(payload.FeeRecipient[:], feeRecipient)
+		copy(payload.StateRoot[:], stateRoot)
+		copy(payload.ReceiptsRoot[:], receiptsRoot)
+		copy(payload.LogsBloom[:], logsBloom)
+		copy(payload.PrevRandao[:], prevRandao)
+		payload.BaseFeePerGas = baseFeePerGas.BigInt()
+		var root phase0.Root
+		copy(root[:], blockRoot)
+		payloads[root] = payload
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	copy(payload.BlockHash[:], blockHash)
-	copy(payload.ParentHash[:], parentHash)
-	copy(payload.FeeRecipient[:], feeRecipient)
-	copy(payload.StateRoot[:], stateRoot)
-	copy(payload.ReceiptsRoot[:], receiptsRoot)
-	copy(payload.LogsBloom[:], logsBloom)
-	copy(payload.PrevRandao[:], prevRandao)
-	payload.BaseFeePerGas = baseFeePerGas.BigInt()
 
-	return payload, nil
+	return payloads, nil
 }
@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetForkSchedule(ctx context.Context, schedule []*phase0.Fork) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetForkSchedule")
 	defer span.End()
+	defer monitorQuery("SetForkSchedule", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -63,6 +65,7 @@ VALUES($1,$2,$3)
 func (s *Service) ForkSchedule(ctx context.Context) ([]*phase0.Fork, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ForkSchedule")
 	defer span.End()
+	defer monitorQuery("ForkSchedule", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
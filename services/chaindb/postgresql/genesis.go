@@ -26,6 +26,7 @@ import (
 func (s *Service) SetGenesis(ctx context.Context, genesis *api.Genesis) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetGenesis")
 	defer span.End()
+	defer monitorQuery("SetGenesis", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -54,6 +55,7 @@ func (s *Service) SetGenesis(ctx context.Context, genesis *api.Genesis) error {
 func (s *Service) Genesis(ctx context.Context) (*api.Genesis, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "Genesis")
 	defer span.End()
+	defer monitorQuery("Genesis", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -91,6 +93,7 @@ func (s *Service) Genesis(ctx context.Context) (*api.Genesis, error) {
 func (s *Service) GenesisTime(ctx context.Context) (time.Time, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "GenesisTime")
 	defer span.End()
+	defer monitorQuery("GenesisTime", time.Now())
 
 	genesis, err := s.Genesis(ctx)
 	if err != nil {
@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetMetadata(ctx context.Context, key string, value []byte) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetMetadata")
 	defer span.End()
+	defer monitorQuery("SetMetadata", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -50,6 +52,7 @@ func (s *Service) SetMetadata(ctx context.Context, key string, value []byte) err
 func (s *Service) Metadata(ctx context.Context, key string) ([]byte, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "Metadata")
 	defer span.End()
+	defer monitorQuery("Metadata", time.Now())
 
 	var err error
 
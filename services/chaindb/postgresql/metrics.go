@@ -0,0 +1,146 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/metrics"
+)
+
+var metricsNamespace = "chaind_chaindb"
+
+var (
+	txDurationMetric    *prometheus.HistogramVec
+	queryDurationMetric *prometheus.HistogramVec
+)
+
+func registerMetrics(_ context.Context, monitor metrics.Service, pools map[string]*pgxpool.Pool) error {
+	if txDurationMetric != nil {
+		// Already registered.
+		return nil
+	}
+	if monitor == nil {
+		// No monitor.
+		return nil
+	}
+	if monitor.Presenter() == "prometheus" {
+		return registerPrometheusMetrics(pools)
+	}
+	return nil
+}
+
+// skipcq: RVV-B0012
+func registerPrometheusMetrics(pools map[string]*pgxpool.Pool) error {
+	txDurationMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "transaction_duration_seconds",
+		Help:      "Time from the start to the end of database transactions",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+	}, []string{"type", "outcome"})
+	if err := prometheus.Register(txDurationMetric); err != nil {
+		return errors.Wrap(err, "failed to register transaction_duration_seconds")
+	}
+
+	queryDurationMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "query_duration_seconds",
+		Help:      "Time taken by database provider and setter methods",
+		Buckets:   []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+	}, []string{"method"})
+	if err := prometheus.Register(queryDurationMetric); err != nil {
+		return errors.Wrap(err, "failed to register query_duration_seconds")
+	}
+
+	if err := prometheus.Register(newPoolCollector(pools)); err != nil {
+		return errors.Wrap(err, "failed to register connection pool metrics")
+	}
+
+	return nil
+}
+
+// monitorTxFinished is called when a transaction finishes.
+func monitorTxFinished(ctx context.Context, txType string, outcome string) {
+	if txDurationMetric == nil {
+		return
+	}
+	if started, ok := ctx.Value(&txStarted{}).(time.Time); ok {
+		txDurationMetric.WithLabelValues(txType, outcome).Observe(time.Since(started).Seconds())
+	}
+}
+
+// monitorQuery is called when a provider or setter method finishes.
+func monitorQuery(method string, started time.Time) {
+	if queryDurationMetric != nil {
+		queryDurationMetric.WithLabelValues(method).Observe(time.Since(started).Seconds())
+	}
+}
+
+// poolCollector provides the utilisation of the database connection pools when metrics are gathered.
+type poolCollector struct {
+	pools              map[string]*pgxpool.Pool
+	connections        *prometheus.Desc
+	maxConnections     *prometheus.Desc
+	acquires           *prometheus.Desc
+	emptyAcquires      *prometheus.Desc
+	acquireWaitSeconds *prometheus.Desc
+}
+
+func newPoolCollector(pools map[string]*pgxpool.Pool) *poolCollector {
+	return &poolCollector{
+		pools: pools,
+		connections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "connections"),
+			"Number of connections in the pool",
+			[]string{"pool", "state"}, nil),
+		maxConnections: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "max_connections"),
+			"Maximum number of connections in the pool",
+			[]string{"pool"}, nil),
+		acquires: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "acquires_total"),
+			"Number of connections acquired from the pool",
+			[]string{"pool"}, nil),
+		emptyAcquires: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "empty_acquires_total"),
+			"Number of connections acquired from the pool that had to wait because the pool was empty",
+			[]string{"pool"}, nil),
+		acquireWaitSeconds: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "acquire_wait_seconds_total"),
+			"Total time spent acquiring connections from the pool",
+			[]string{"pool"}, nil),
+	}
+}
+
+// Describe sends the descriptions of the pool metrics.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.maxConnections
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.acquireWaitSeconds
+}
+
+// Collect sends the current values of the pool metrics.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, pool := range c.pools {
+		stat := pool.Stat()
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stat.AcquiredConns()), name, "acquired")
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stat.IdleConns()), name, "idle")
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stat.ConstructingConns()), name, "constructing")
+		ch <- prometheus.MustNewConstMetric(c.maxConnections, prometheus.GaugeValue, float64(stat.MaxConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()), name)
+		ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()), name)
+		ch <- prometheus.MustNewConstMetric(c.acquireWaitSeconds, prometheus.CounterValue, stat.AcquireDuration().Seconds(), name)
+	}
+}
@@ -17,10 +17,12 @@ import (
 	"errors"
 
 	"github.com/rs/zerolog"
+	"github.com/wealdtech/chaind/services/metrics"
 )
 
 type parameters struct {
 	logLevel       zerolog.Level
+	monitor        metrics.Service
 	connectionURL  string
 	server         string
 	port           int32
@@ -30,6 +32,7 @@ type parameters struct {
 	clientKey      []byte
 	caCert         []byte
 	maxConnections uint
+	replicaURLs    []string
 }
 
 // Parameter is the interface for service parameters.
@@ -50,6 +53,13 @@ func WithLogLevel(logLevel zerolog.Level) Parameter {
 	})
 }
 
+// WithMonitor sets the monitor for the module.
+func WithMonitor(monitor metrics.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.monitor = monitor
+	})
+}
+
 // WithConnectionURL sets the connection URL for this module.
 // Deprecated.  Use the individual Server/User/Port/... functions.
 func WithConnectionURL(connectionURL string) Parameter {
@@ -114,6 +124,13 @@ func WithMaxConnections(maxConnections uint) Parameter {
 	})
 }
 
+// WithReplicaURLs sets the URLs of read replicas, to which read-only transactions are sent.
+func WithReplicaURLs(urls []string) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.replicaURLs = urls
+	})
+}
+
 // parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
 func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	parameters := parameters{
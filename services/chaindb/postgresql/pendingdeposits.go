@@ -0,0 +1,313 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+	"go.opentelemetry.io/otel"
+)
+
+// PendingDeposits provides Ethereum 1 deposits that have yet to be included in the beacon chain, according to the filter.
+func (s *Service) PendingDeposits(ctx context.Context,
+	filter *chaindb.PendingDepositFilter,
+) (
+	[]*chaindb.PendingDeposit,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PendingDeposits")
+	defer span.End()
+	defer monitorQuery("PendingDeposits", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	params, err := s.pendingDepositParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Obtain the deposit count from the latest block.
+	var latestSlot uint64
+	var eth1DepositCount uint64
+	err = tx.QueryRow(ctx, `
+SELECT f_slot
+      ,f_eth1_deposit_count
+FROM t_blocks
+WHERE f_canonical IS NULL OR f_canonical = true
+ORDER BY f_slot DESC
+LIMIT 1`).Scan(
+		&latestSlot,
+		&eth1DepositCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// No blocks, so cannot provide pending deposits.
+			return []*chaindb.PendingDeposit{}, nil
+		}
+		return nil, errors.Wrap(err, "failed to obtain latest block")
+	}
+
+	lastIncludedIndex, err := s.lastIncludedDepositIndex(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_eth1_block_number
+      ,f_eth1_block_hash
+      ,f_eth1_block_timestamp
+      ,f_eth1_tx_hash
+      ,f_eth1_log_index
+      ,f_eth1_sender
+      ,f_eth1_recipient
+      ,f_eth1_gas_used
+      ,f_eth1_gas_price
+      ,f_deposit_index
+      ,f_validator_pubkey
+      ,f_withdrawal_credentials
+      ,f_signature
+      ,f_amount
+      ,f_provisional
+FROM t_eth1_deposits`)
+
+	queryVals = append(queryVals, lastIncludedIndex)
+	queryBuilder.WriteString(fmt.Sprintf(`
+WHERE f_deposit_index > $%d`, len(queryVals)))
+
+	if lastIncludedIndex == -1 {
+		// Nothing included yet; ignore deposits that were part of the genesis state.
+		queryVals = append(queryVals, params.GenesisTime)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND f_eth1_block_timestamp >= $%d`, len(queryVals)))
+	}
+
+	if len(filter.PubKeys) > 0 {
+		pubKeys := make([][]byte, len(filter.PubKeys))
+		for i := range filter.PubKeys {
+			pubKeys[i] = filter.PubKeys[i][:]
+		}
+		queryVals = append(queryVals, pubKeys)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND f_validator_pubkey = ANY($%d)`, len(queryVals)))
+	}
+
+	queryBuilder.WriteString(`
+ORDER BY f_deposit_index`)
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latestBlockTime := params.GenesisTime.Add(time.Duration(latestSlot) * params.SlotDuration)
+	deposits := make([]*chaindb.PendingDeposit, 0)
+	for rows.Next() {
+		deposit := &chaindb.ETH1Deposit{}
+		var validatorPubKey []byte
+		var signature []byte
+		err := rows.Scan(
+			&deposit.ETH1BlockNumber,
+			&deposit.ETH1BlockHash,
+			&deposit.ETH1BlockTimestamp,
+			&deposit.ETH1TxHash,
+			&deposit.ETH1LogIndex,
+			&deposit.ETH1Sender,
+			&deposit.ETH1Recipient,
+			&deposit.ETH1GasUsed,
+			&deposit.ETH1GasPrice,
+			&deposit.DepositIndex,
+			&validatorPubKey,
+			&deposit.WithdrawalCredentials,
+			&signature,
+			&deposit.Amount,
+			&deposit.Provisional,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		copy(deposit.ValidatorPubKey[:], validatorPubKey)
+		copy(deposit.Signature[:], signature)
+		deposits = append(deposits, &chaindb.PendingDeposit{
+			ETH1Deposit:      deposit,
+			ETH1DepositCount: eth1DepositCount,
+			EstimatedInclusion: util.EstimateDepositInclusion(params,
+				deposit.DepositIndex,
+				deposit.ETH1BlockTimestamp,
+				uint64(lastIncludedIndex+1),
+				eth1DepositCount,
+				latestBlockTime,
+			),
+		})
+	}
+
+	return deposits, nil
+}
+
+// lastIncludedDepositIndex returns the index of the latest Ethereum 1 deposit included in the
+// beacon chain, or -1 if no deposits have been included.
+func (*Service) lastIncludedDepositIndex(ctx context.Context, tx pgx.Tx) (int64, error) {
+	// Reconciled deposits provide the index directly.  Deposits are processed in order, so those
+	// included after the latest reconciled deposit follow on from it.
+	var reconciledIndex int64
+	var reconciledSlot int64
+	err := tx.QueryRow(ctx, `
+SELECT f_deposit_index
+      ,f_inclusion_slot
+FROM t_reconciled_deposits
+ORDER BY f_deposit_index DESC
+LIMIT 1`).Scan(
+		&reconciledIndex,
+		&reconciledSlot,
+	)
+	switch {
+	case err == nil:
+		var laterDeposits int64
+		err = tx.QueryRow(ctx, `
+SELECT COUNT(*)
+FROM t_deposits
+JOIN t_blocks ON t_blocks.f_root = t_deposits.f_inclusion_block_root
+WHERE t_deposits.f_inclusion_slot > $1
+  AND (t_blocks.f_canonical IS NULL OR t_blocks.f_canonical = true)`,
+			reconciledSlot,
+		).Scan(&laterDeposits)
+		if err != nil {
+			return -1, errors.Wrap(err, "failed to obtain deposits since last reconciled deposit")
+		}
+		return reconciledIndex + laterDeposits, nil
+	case errors.Is(err, pgx.ErrNoRows):
+		// Deposits have not been reconciled.
+	default:
+		return -1, errors.Wrap(err, "failed to obtain last reconciled deposit")
+	}
+
+	// Beacon chain deposits are not indexed, so match them with Ethereum 1 deposits by their
+	// contents.  Deposits are processed in order, so the highest matched index tells us which
+	// deposits have been processed.
+	var lastIncludedIndex int64
+	err = tx.QueryRow(ctx, `
+WITH included AS (
+  SELECT t_deposits.f_validator_pubkey
+        ,t_deposits.f_withdrawal_credentials
+        ,t_deposits.f_amount
+        ,COUNT(*) AS f_count
+  FROM t_deposits
+  JOIN t_blocks ON t_blocks.f_root = t_deposits.f_inclusion_block_root
+  WHERE t_blocks.f_canonical IS NULL OR t_blocks.f_canonical = true
+  GROUP BY t_deposits.f_validator_pubkey
+          ,t_deposits.f_withdrawal_credentials
+          ,t_deposits.f_amount
+), ranked AS (
+  SELECT f_deposit_index
+        ,f_validator_pubkey
+        ,f_withdrawal_credentials
+        ,f_amount
+        ,ROW_NUMBER() OVER (PARTITION BY f_validator_pubkey,f_withdrawal_credentials,f_amount ORDER BY f_deposit_index) AS f_rank
+  FROM t_eth1_deposits
+)
+SELECT COALESCE(MAX(ranked.f_deposit_index),-1)
+FROM ranked
+JOIN included USING (f_validator_pubkey,f_withdrawal_credentials,f_amount)
+WHERE ranked.f_rank <= included.f_count`).Scan(&lastIncludedIndex)
+	if err != nil {
+		return -1, errors.Wrap(err, "failed to obtain last included deposit")
+	}
+
+	return lastIncludedIndex, nil
+}
+
+// pendingDepositParams obtains the chain parameters required to estimate deposit inclusion.
+func (s *Service) pendingDepositParams(ctx context.Context) (*util.DepositInclusionParams, error) {
+	genesis, err := s.Genesis(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain genesis")
+	}
+	if genesis == nil {
+		return nil, errors.New("genesis not available")
+	}
+
+	spec, err := s.ChainSpec(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain chain spec")
+	}
+
+	slotDuration, exists := spec["SECONDS_PER_SLOT"].(time.Duration)
+	if !exists {
+		return nil, errors.New("SECONDS_PER_SLOT not found in spec")
+	}
+	slotsPerEpoch, exists := spec["SLOTS_PER_EPOCH"].(uint64)
+	if !exists {
+		return nil, errors.New("SLOTS_PER_EPOCH not found in spec")
+	}
+	epochsPerVotingPeriod, exists := spec["EPOCHS_PER_ETH1_VOTING_PERIOD"].(uint64)
+	if !exists {
+		return nil, errors.New("EPOCHS_PER_ETH1_VOTING_PERIOD not found in spec")
+	}
+	eth1BlockDuration, exists := spec["SECONDS_PER_ETH1_BLOCK"].(time.Duration)
+	if !exists {
+		return nil, errors.New("SECONDS_PER_ETH1_BLOCK not found in spec")
+	}
+	followDistance, exists := spec["ETH1_FOLLOW_DISTANCE"].(uint64)
+	if !exists {
+		return nil, errors.New("ETH1_FOLLOW_DISTANCE not found in spec")
+	}
+	maxDeposits, exists := spec["MAX_DEPOSITS"].(uint64)
+	if !exists || maxDeposits == 0 {
+		return nil, errors.New("MAX_DEPOSITS not found in spec")
+	}
+
+	return &util.DepositInclusionParams{
+		GenesisTime:         genesis.GenesisTime,
+		SlotDuration:        slotDuration,
+		VotingPeriod:        time.Duration(epochsPerVotingPeriod*slotsPerEpoch) * slotDuration,
+		FollowDistance:      time.Duration(followDistance) * eth1BlockDuration,
+		MaxDepositsPerBlock: maxDeposits,
+	}, nil
+}
@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetProposerDuty(ctx context.Context, proposerDuty *chaindb.ProposerDuty) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetProposerDuty")
 	defer span.End()
+	defer monitorQuery("SetProposerDuty", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -57,6 +59,7 @@ func (s *Service) ProposerDutiesForSlotRange(ctx context.Context,
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ProposerDutiesForSlotRange")
 	defer span.End()
+	defer monitorQuery("ProposerDutiesForSlotRange", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -104,6 +107,7 @@ func (s *Service) ProposerDutiesForSlotRange(ctx context.Context,
 func (s *Service) ProposerDutiesForValidator(ctx context.Context, proposer phase0.ValidatorIndex) ([]*chaindb.ProposerDuty, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ProposerDutiesForValidator")
 	defer span.End()
+	defer monitorQuery("ProposerDutiesForValidator", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetProposerSlashing(ctx context.Context, proposerSlashing *chaindb.ProposerSlashing) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetProposerSlashing")
 	defer span.End()
+	defer monitorQuery("SetProposerSlashing", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -96,6 +98,7 @@ func (s *Service) SetProposerSlashing(ctx context.Context, proposerSlashing *cha
 func (s *Service) ProposerSlashingsForSlotRange(ctx context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]*chaindb.ProposerSlashing, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ProposerSlashingsForSlotRange")
 	defer span.End()
+	defer monitorQuery("ProposerSlashingsForSlotRange", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -198,6 +201,7 @@ func (s *Service) ProposerSlashingsForSlotRange(ctx context.Context, minSlot pha
 func (s *Service) ProposerSlashingsForValidator(ctx context.Context, index phase0.ValidatorIndex) ([]*chaindb.ProposerSlashing, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ProposerSlashingsForValidator")
 	defer span.End()
+	defer monitorQuery("ProposerSlashingsForValidator", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -0,0 +1,226 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"go.opentelemetry.io/otel"
+)
+
+// SetReconciledDeposits sets multiple reconciled deposits.
+func (s *Service) SetReconciledDeposits(ctx context.Context, deposits []*chaindb.ReconciledDeposit) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetReconciledDeposits")
+	defer span.End()
+	defer monitorQuery("SetReconciledDeposits", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, deposit := range deposits {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO t_reconciled_deposits(f_deposit_index
+                                 ,f_validator_pubkey
+                                 ,f_amount
+                                 ,f_eth1_block_number
+                                 ,f_eth1_tx_hash
+                                 ,f_eth1_log_index
+                                 ,f_inclusion_slot
+                                 ,f_inclusion_block_root
+                                 ,f_inclusion_index
+                                 ,f_signature_valid
+                                 ,f_type)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+ON CONFLICT (f_deposit_index) DO
+UPDATE
+SET f_validator_pubkey = excluded.f_validator_pubkey
+   ,f_amount = excluded.f_amount
+   ,f_eth1_block_number = excluded.f_eth1_block_number
+   ,f_eth1_tx_hash = excluded.f_eth1_tx_hash
+   ,f_eth1_log_index = excluded.f_eth1_log_index
+   ,f_inclusion_slot = excluded.f_inclusion_slot
+   ,f_inclusion_block_root = excluded.f_inclusion_block_root
+   ,f_inclusion_index = excluded.f_inclusion_index
+   ,f_signature_valid = excluded.f_signature_valid
+   ,f_type = excluded.f_type
+`,
+			deposit.DepositIndex,
+			deposit.ValidatorPubKey[:],
+			deposit.Amount,
+			deposit.ETH1BlockNumber,
+			deposit.ETH1TxHash,
+			deposit.ETH1LogIndex,
+			deposit.InclusionSlot,
+			deposit.InclusionBlockRoot[:],
+			deposit.InclusionIndex,
+			deposit.SignatureValid,
+			deposit.Type,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReconciledDeposits provides reconciled deposits according to the filter.
+func (s *Service) ReconciledDeposits(ctx context.Context,
+	filter *chaindb.ReconciledDepositFilter,
+) (
+	[]*chaindb.ReconciledDeposit,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ReconciledDeposits")
+	defer span.End()
+	defer monitorQuery("ReconciledDeposits", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_deposit_index
+      ,f_validator_pubkey
+      ,f_amount
+      ,f_eth1_block_number
+      ,f_eth1_tx_hash
+      ,f_eth1_log_index
+      ,f_inclusion_slot
+      ,f_inclusion_block_root
+      ,f_inclusion_index
+      ,f_signature_valid
+      ,f_type
+FROM t_reconciled_deposits`)
+
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_deposit_index >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_deposit_index <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.PubKeys) > 0 {
+		pubKeys := make([][]byte, len(filter.PubKeys))
+		for i := range filter.PubKeys {
+			pubKeys[i] = filter.PubKeys[i][:]
+		}
+		queryVals = append(queryVals, pubKeys)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_validator_pubkey = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.Types) > 0 {
+		types := make([]int16, len(filter.Types))
+		for i := range filter.Types {
+			types[i] = int16(filter.Types[i])
+		}
+		queryVals = append(queryVals, types)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_type = ANY($%d)`, wherestr, len(queryVals)))
+	}
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_deposit_index`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_deposit_index DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deposits := make([]*chaindb.ReconciledDeposit, 0)
+	var validatorPubKey []byte
+	var inclusionBlockRoot []byte
+	for rows.Next() {
+		deposit := &chaindb.ReconciledDeposit{}
+		err := rows.Scan(
+			&deposit.DepositIndex,
+			&validatorPubKey,
+			&deposit.Amount,
+			&deposit.ETH1BlockNumber,
+			&deposit.ETH1TxHash,
+			&deposit.ETH1LogIndex,
+			&deposit.InclusionSlot,
+			&inclusionBlockRoot,
+			&deposit.InclusionIndex,
+			&deposit.SignatureValid,
+			&deposit.Type,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		copy(deposit.ValidatorPubKey[:], validatorPubKey)
+		copy(deposit.InclusionBlockRoot[:], inclusionBlockRoot)
+		deposits = append(deposits, deposit)
+	}
+
+	// Always return order of deposit index.
+	sort.Slice(deposits, func(i int, j int) bool {
+		return deposits[i].DepositIndex < deposits[j].DepositIndex
+	})
+	return deposits, nil
+}
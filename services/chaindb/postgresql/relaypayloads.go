@@ -0,0 +1,499 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"go.opentelemetry.io/otel"
+)
+
+// SetRelayPayloads sets multiple relay payloads.
+func (s *Service) SetRelayPayloads(ctx context.Context, payloads []*chaindb.RelayPayload) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetRelayPayloads")
+	defer span.End()
+	defer monitorQuery("SetRelayPayloads", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, payload := range payloads {
+		value := decimal.Zero
+		if payload.Value != nil {
+			value = decimal.NewFromBigInt(payload.Value, 0)
+		}
+		var bidTimestamp sql.NullTime
+		if payload.BidTimestamp != nil {
+			bidTimestamp.Valid = true
+			bidTimestamp.Time = *payload.BidTimestamp
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO t_relay_payloads(f_relay
+                            ,f_slot
+                            ,f_block_hash
+                            ,f_block_number
+                            ,f_parent_hash
+                            ,f_builder_pubkey
+                            ,f_proposer_pubkey
+                            ,f_proposer_fee_recipient
+                            ,f_gas_limit
+                            ,f_gas_used
+                            ,f_value
+                            ,f_num_tx
+                            ,f_bid_timestamp)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+ON CONFLICT (f_block_hash,f_relay) DO
+UPDATE
+SET f_slot = excluded.f_slot
+   ,f_block_number = excluded.f_block_number
+   ,f_parent_hash = excluded.f_parent_hash
+   ,f_builder_pubkey = excluded.f_builder_pubkey
+   ,f_proposer_pubkey = excluded.f_proposer_pubkey
+   ,f_proposer_fee_recipient = excluded.f_proposer_fee_recipient
+   ,f_gas_limit = excluded.f_gas_limit
+   ,f_gas_used = excluded.f_gas_used
+   ,f_value = excluded.f_value
+   ,f_num_tx = excluded.f_num_tx
+   ,f_bid_timestamp = COALESCE(excluded.f_bid_timestamp,t_relay_payloads.f_bid_timestamp)
+`,
+			payload.Relay,
+			payload.Slot,
+			payload.BlockHash[:],
+			payload.BlockNumber,
+			payload.ParentHash[:],
+			payload.BuilderPubKey[:],
+			payload.ProposerPubKey[:],
+			payload.ProposerFeeRecipient[:],
+			payload.GasLimit,
+			payload.GasUsed,
+			value,
+			payload.NumTx,
+			bidTimestamp,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RelayPayloads provides relay payloads according to the filter.
+func (s *Service) RelayPayloads(ctx context.Context,
+	filter *chaindb.RelayPayloadFilter,
+) (
+	[]*chaindb.RelayPayload,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "RelayPayloads")
+	defer span.End()
+	defer monitorQuery("RelayPayloads", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_relay
+      ,f_slot
+      ,f_block_hash
+      ,f_block_number
+      ,f_parent_hash
+      ,f_builder_pubkey
+      ,f_proposer_pubkey
+      ,f_proposer_fee_recipient
+      ,f_gas_limit
+      ,f_gas_used
+      ,f_value
+      ,f_num_tx
+      ,f_bid_timestamp
+FROM t_relay_payloads`)
+
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_slot >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_slot <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.Relays) > 0 {
+		queryVals = append(queryVals, filter.Relays)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_relay = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.BuilderPubKeys) > 0 {
+		builderPubKeys := make([][]byte, len(filter.BuilderPubKeys))
+		for i := range filter.BuilderPubKeys {
+			builderPubKeys[i] = filter.BuilderPubKeys[i][:]
+		}
+		queryVals = append(queryVals, builderPubKeys)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_builder_pubkey = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.BlockHashes) > 0 {
+		blockHashes := make([][]byte, len(filter.BlockHashes))
+		for i := range filter.BlockHashes {
+			blockHashes[i] = filter.BlockHashes[i][:]
+		}
+		queryVals = append(queryVals, blockHashes)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_block_hash = ANY($%d)`, wherestr, len(queryVals)))
+	}
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_slot,f_relay`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_slot DESC,f_relay DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payloads := make([]*chaindb.RelayPayload, 0)
+	for rows.Next() {
+		var relay sql.NullString
+		var slot sql.NullInt64
+		var blockHash []byte
+		var blockNumber sql.NullInt64
+		var parentHash []byte
+		var builderPubKey []byte
+		var proposerPubKey []byte
+		var proposerFeeRecipient []byte
+		var gasLimit sql.NullInt64
+		var gasUsed sql.NullInt64
+		var value decimal.NullDecimal
+		var numTx sql.NullInt64
+		var bidTimestamp sql.NullTime
+		err := rows.Scan(
+			&relay,
+			&slot,
+			&blockHash,
+			&blockNumber,
+			&parentHash,
+			&builderPubKey,
+			&proposerPubKey,
+			&proposerFeeRecipient,
+			&gasLimit,
+			&gasUsed,
+			&value,
+			&numTx,
+			&bidTimestamp,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		payloads = append(payloads, relayPayloadFromColumns(relay.String,
+			slot,
+			blockHash,
+			blockNumber,
+			parentHash,
+			builderPubKey,
+			proposerPubKey,
+			proposerFeeRecipient,
+			gasLimit,
+			gasUsed,
+			value,
+			numTx,
+			bidTimestamp,
+		))
+	}
+
+	// Always return order of slot then relay.
+	sort.Slice(payloads, func(i int, j int) bool {
+		if payloads[i].Slot != payloads[j].Slot {
+			return payloads[i].Slot < payloads[j].Slot
+		}
+		return payloads[i].Relay < payloads[j].Relay
+	})
+	return payloads, nil
+}
+
+// BlockProductions provides canonical blocks with execution payloads, linked to any relay
+// payloads that provided them, according to the filter.
+func (s *Service) BlockProductions(ctx context.Context,
+	filter *chaindb.BlockProductionFilter,
+) (
+	[]*chaindb.BlockProduction,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "BlockProductions")
+	defer span.End()
+	defer monitorQuery("BlockProductions", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.  The limit applies to blocks, so is applied before joining with
+	// relay payloads, of which there can be more than one per block.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+WITH blocks AS (
+SELECT t_blocks.f_slot
+      ,t_blocks.f_root
+      ,t_blocks.f_proposer_index
+      ,t_block_execution_payloads.f_block_hash
+FROM t_blocks
+JOIN t_block_execution_payloads ON t_block_execution_payloads.f_block_root = t_blocks.f_root
+WHERE (t_blocks.f_canonical IS NULL OR t_blocks.f_canonical = true)`)
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND t_blocks.f_slot >= $%d`, len(queryVals)))
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND t_blocks.f_slot <= $%d`, len(queryVals)))
+	}
+
+	if len(filter.ProposerIndices) > 0 {
+		queryVals = append(queryVals, filter.ProposerIndices)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND t_blocks.f_proposer_index = ANY($%d)`, len(queryVals)))
+	}
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY t_blocks.f_slot`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY t_blocks.f_slot DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	queryBuilder.WriteString(`
+)
+SELECT blocks.f_slot
+      ,blocks.f_root
+      ,blocks.f_proposer_index
+      ,blocks.f_block_hash
+      ,t_relay_payloads.f_relay
+      ,t_relay_payloads.f_slot
+      ,t_relay_payloads.f_block_number
+      ,t_relay_payloads.f_parent_hash
+      ,t_relay_payloads.f_builder_pubkey
+      ,t_relay_payloads.f_proposer_pubkey
+      ,t_relay_payloads.f_proposer_fee_recipient
+      ,t_relay_payloads.f_gas_limit
+      ,t_relay_payloads.f_gas_used
+      ,t_relay_payloads.f_value
+      ,t_relay_payloads.f_num_tx
+      ,t_relay_payloads.f_bid_timestamp
+FROM blocks
+LEFT JOIN t_relay_payloads ON t_relay_payloads.f_block_hash = blocks.f_block_hash
+ORDER BY blocks.f_slot
+        ,t_relay_payloads.f_relay`)
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	productions := make([]*chaindb.BlockProduction, 0)
+	var production *chaindb.BlockProduction
+	for rows.Next() {
+		var slot phase0.Slot
+		var root []byte
+		var proposerIndex phase0.ValidatorIndex
+		var executionBlockHash []byte
+		var relay sql.NullString
+		var relaySlot sql.NullInt64
+		var blockNumber sql.NullInt64
+		var parentHash []byte
+		var builderPubKey []byte
+		var proposerPubKey []byte
+		var proposerFeeRecipient []byte
+		var gasLimit sql.NullInt64
+		var gasUsed sql.NullInt64
+		var value decimal.NullDecimal
+		var numTx sql.NullInt64
+		var bidTimestamp sql.NullTime
+		err := rows.Scan(
+			&slot,
+			&root,
+			&proposerIndex,
+			&executionBlockHash,
+			&relay,
+			&relaySlot,
+			&blockNumber,
+			&parentHash,
+			&builderPubKey,
+			&proposerPubKey,
+			&proposerFeeRecipient,
+			&gasLimit,
+			&gasUsed,
+			&value,
+			&numTx,
+			&bidTimestamp,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		if production == nil || production.Slot != slot || !bytes.Equal(production.Root[:], root) {
+			production = &chaindb.BlockProduction{
+				Slot:          slot,
+				ProposerIndex: proposerIndex,
+				RelayPayloads: make([]*chaindb.RelayPayload, 0),
+			}
+			copy(production.Root[:], root)
+			copy(production.ExecutionBlockHash[:], executionBlockHash)
+			productions = append(productions, production)
+		}
+		if relay.Valid {
+			production.RelayPayloads = append(production.RelayPayloads, relayPayloadFromColumns(relay.String,
+				relaySlot,
+				executionBlockHash,
+				blockNumber,
+				parentHash,
+				builderPubKey,
+				proposerPubKey,
+				proposerFeeRecipient,
+				gasLimit,
+				gasUsed,
+				value,
+				numTx,
+				bidTimestamp,
+			))
+		}
+	}
+
+	return productions, nil
+}
+
+// relayPayloadFromColumns creates a relay payload from its database columns.
+func relayPayloadFromColumns(relay string,
+	slot sql.NullInt64,
+	blockHash []byte,
+	blockNumber sql.NullInt64,
+	parentHash []byte,
+	builderPubKey []byte,
+	proposerPubKey []byte,
+	proposerFeeRecipient []byte,
+	gasLimit sql.NullInt64,
+	gasUsed sql.NullInt64,
+	value decimal.NullDecimal,
+	numTx sql.NullInt64,
+	bidTimestamp sql.NullTime,
+) *chaindb.RelayPayload {
+	payload := &chaindb.RelayPayload{
+		Relay:       relay,
+		Slot:        phase0.Slot(slot.Int64),
+		BlockNumber: uint64(blockNumber.Int64),
+		GasLimit:    uint64(gasLimit.Int64),
+		GasUsed:     uint64(gasUsed.Int64),
+		Value:       value.Decimal.BigInt(),
+		NumTx:       uint64(numTx.Int64),
+	}
+	copy(payload.BlockHash[:], blockHash)
+	copy(payload.ParentHash[:], parentHash)
+	copy(payload.BuilderPubKey[:], builderPubKey)
+	copy(payload.ProposerPubKey[:], proposerPubKey)
+	copy(payload.ProposerFeeRecipient[:], proposerFeeRecipient)
+	if bidTimestamp.Valid {
+		timestamp := bidTimestamp.Time
+		payload.BidTimestamp = &timestamp
+	}
+
+	return payload
+}
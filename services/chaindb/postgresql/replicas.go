@@ -0,0 +1,144 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+)
+
+// replicaPollInterval is the interval between checks of replica replay positions.
+const replicaPollInterval = time.Second
+
+// replica is a read replica of the primary database.
+type replica struct {
+	name string
+	pool *pgxpool.Pool
+	// replayLSN is the WAL position up to which the replica has replayed.
+	replayLSN atomic.Uint64
+}
+
+// startReplicas connects to the read replicas and starts tracking their replay positions.
+func (s *Service) startReplicas(ctx context.Context, parameters *parameters) error {
+	for i, url := range parameters.replicaURLs {
+		replicaParameters := *parameters
+		replicaParameters.connectionURL = url
+		pool, err := newFromURL(ctx, &replicaParameters)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to connect to replica %d", i))
+		}
+		go func() {
+			<-ctx.Done()
+			log.Trace().Msg("Context done; closing replica pool")
+			pool.Close()
+		}()
+		s.replicas = append(s.replicas, &replica{
+			name: fmt.Sprintf("%s:%d", pool.Config().ConnConfig.Host, pool.Config().ConnConfig.Port),
+			pool: pool,
+		})
+	}
+
+	// Replicas must have caught up with the primary as it is now before they are used, as
+	// writes may have been made by a previous instance.
+	if err := s.updateWriteLSN(ctx); err != nil {
+		return err
+	}
+	s.updateReplayLSNs(ctx)
+
+	go func(ctx context.Context) {
+		ticker := time.NewTicker(replicaPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.updateReplayLSNs(ctx)
+			}
+		}
+	}(ctx)
+
+	log.Info().Int("replicas", len(s.replicas)).Msg("Read-only transactions will use replicas")
+
+	return nil
+}
+
+// updateWriteLSN records the current WAL position of the primary, which replicas must reach
+// before they can serve reads.
+func (s *Service) updateWriteLSN(ctx context.Context) error {
+	var lsn int64
+	if err := s.pool.QueryRow(ctx, `SELECT (pg_current_wal_lsn() - '0/0'::pg_lsn)::BIGINT`).Scan(&lsn); err != nil {
+		return errors.Wrap(err, "failed to obtain primary WAL position")
+	}
+
+	// Concurrent reads may obtain the position in any order, so only move forwards.
+	for {
+		current := s.writeLSN.Load()
+		if uint64(lsn) <= current || s.writeLSN.CompareAndSwap(current, uint64(lsn)) {
+			return nil
+		}
+	}
+}
+
+// updateReplayLSNs updates the replay positions of the replicas.
+func (s *Service) updateReplayLSNs(ctx context.Context) {
+	for _, replica := range s.replicas {
+		var lsn int64
+		err := replica.pool.QueryRow(ctx, `SELECT (COALESCE(pg_last_wal_replay_lsn(), pg_current_wal_lsn()) - '0/0'::pg_lsn)::BIGINT`).Scan(&lsn)
+		if err != nil {
+			// Leave the position as-is; the replica will be avoided if it falls behind.
+			log.Debug().Str("replica", replica.name).Err(err).Msg("Failed to obtain replica replay position")
+			continue
+		}
+		replica.replayLSN.Store(uint64(lsn))
+	}
+}
+
+// readPool returns the pool to use for a read-only transaction.
+// This is a replica that has replayed all writes committed on the primary if one is available,
+// otherwise the primary.
+func (s *Service) readPool(ctx context.Context) (*pgxpool.Pool, string) {
+	if len(s.replicas) == 0 {
+		return s.pool, "primary"
+	}
+
+	// The position of the primary is only obtained when there have been writes since it was
+	// last obtained, rather than on every commit.
+	writes := s.writes.Load()
+	if writes != s.writeLSNWrites.Load() {
+		if err := s.updateWriteLSN(ctx); err != nil {
+			// Replicas could serve stale data, so avoid them until the position is known.
+			log.Debug().Err(err).Msg("Failed to obtain write position; using primary")
+			return s.pool, "primary"
+		}
+		s.writeLSNWrites.Store(writes)
+	}
+
+	required := s.writeLSN.Load()
+	start := s.replicaIndex.Inc()
+	for i := range s.replicas {
+		replica := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]
+		if replica.replayLSN.Load() >= required {
+			return replica.pool, replica.name
+		}
+	}
+
+	log.Trace().Uint64("required_lsn", required).Msg("No replica is up to date; using primary")
+	return s.pool, "primary"
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAfterWrite(t *testing.T) {
+	connectionURL := os.Getenv("CHAINDB_URL")
+	if connectionURL == "" {
+		t.Skip("CHAINDB_URL not set")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	parameters := &parameters{
+		connectionURL:  connectionURL,
+		maxConnections: 4,
+	}
+	pool, err := newFromURL(ctx, parameters)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// The replica is the primary itself, with its replay position under the control of the test.
+	s := &Service{
+		pool: pool,
+		replicas: []*replica{
+			{
+				name: "replica",
+				pool: pool,
+			},
+		},
+	}
+	require.NoError(t, s.updateWriteLSN(ctx))
+	s.updateReplayLSNs(ctx)
+
+	// Replica is up to date, so reads use it.
+	_, source := s.readPool(ctx)
+	require.Equal(t, "replica", source)
+
+	// Write, and hold the replica's replay position before the write.
+	txCtx, txCancel, err := s.BeginTx(ctx)
+	require.NoError(t, err)
+	defer txCancel()
+	require.NoError(t, s.SetMetadata(txCtx, "test.replicas", []byte(`{}`)))
+	require.NoError(t, s.CommitTx(txCtx))
+	s.replicas[0].replayLSN.Store(0)
+
+	// Read immediately after the write uses the primary.
+	_, source = s.readPool(ctx)
+	require.Equal(t, "primary", source)
+
+	// Replica catches up, so reads use it again.
+	s.updateReplayLSNs(ctx)
+	_, source = s.readPool(ctx)
+	require.Equal(t, "replica", source)
+}
@@ -27,16 +27,34 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
+	"github.com/wealdtech/chaind/util"
+	"go.uber.org/atomic"
 )
 
 // Service is a chain database service.
 type Service struct {
-	pool *pgxpool.Pool
+	pool     *pgxpool.Pool
+	replicas []*replica
+	// writeLSN is the WAL position of the primary when it was last obtained.
+	writeLSN atomic.Uint64
+	// writes is the number of transactions committed on the primary.
+	writes atomic.Uint64
+	// writeLSNWrites is the number of transactions committed on the primary when writeLSN was obtained.
+	writeLSNWrites atomic.Uint64
+	// replicaIndex is used to spread read-only transactions across replicas.
+	replicaIndex atomic.Uint64
 }
 
+// applicationName is the name that chaind gives its connections to the database, unless
+// one is supplied in the connection URL.
+const applicationName = "chaind"
+
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -45,7 +63,8 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "chaindb").Str("impl", "postgresql").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "chaindb").Str("impl", "postgresql").Logger())
 
 	var pool *pgxpool.Pool
 	if parameters.connectionURL != "" {
@@ -67,6 +86,22 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		pool: pool,
 	}
 
+	if len(parameters.replicaURLs) > 0 {
+		if err := s.startReplicas(ctx, parameters); err != nil {
+			return nil, err
+		}
+	}
+
+	pools := map[string]*pgxpool.Pool{
+		"primary": s.pool,
+	}
+	for _, replica := range s.replicas {
+		pools[replica.name] = replica.pool
+	}
+	if err := registerMetrics(ctx, parameters.monitor, pools); err != nil {
+		return nil, errors.New("failed to register metrics")
+	}
+
 	return s, nil
 }
 
@@ -81,6 +116,9 @@ func newFromURL(ctx context.Context,
 		return nil, errors.Wrap(err, "invalid connection URL")
 	}
 	config.MaxConns = int32(parameters.maxConnections)
+	if _, exists := config.ConnConfig.RuntimeParams["application_name"]; !exists {
+		config.ConnConfig.RuntimeParams["application_name"] = applicationName
+	}
 	pool, err := pgxpool.ConnectConfig(ctx, config)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to connect to database")
@@ -130,6 +168,7 @@ func newFromComponents(ctx context.Context,
 	}
 
 	dsnItems = append(dsnItems, fmt.Sprintf("pool_max_conns=%d", parameters.maxConnections))
+	dsnItems = append(dsnItems, fmt.Sprintf("application_name=%s", applicationName))
 
 	config, err := pgxpool.ParseConfig(strings.Join(dsnItems, " "))
 	if err != nil {
@@ -155,3 +194,8 @@ func registerCustomTypes(ctx context.Context, conn *pgx.Conn) error {
 	})
 	return nil
 }
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
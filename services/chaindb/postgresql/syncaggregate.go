@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -29,6 +30,7 @@ import (
 func (s *Service) SetSyncAggregate(ctx context.Context, syncAggregate *chaindb.SyncAggregate) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetSyncAggregate")
 	defer span.End()
+	defer monitorQuery("SetSyncAggregate", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -60,6 +62,7 @@ func (s *Service) SetSyncAggregate(ctx context.Context, syncAggregate *chaindb.S
 func (s *Service) SyncAggregates(ctx context.Context, filter *chaindb.SyncAggregateFilter) ([]*chaindb.SyncAggregate, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SyncAggregates")
 	defer span.End()
+	defer monitorQuery("SyncAggregates", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -159,3 +162,26 @@ LIMIT $%d`, len(queryVals)))
 	})
 	return aggregates, nil
 }
+
+// PruneSyncAggregates prunes sync aggregates included in slots up to (but not including) the given slot.
+func (s *Service) PruneSyncAggregates(ctx context.Context, to phase0.Slot) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneSyncAggregates")
+	defer span.End()
+	defer monitorQuery("PruneSyncAggregates", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM t_sync_aggregates
+WHERE f_inclusion_slot < $1
+`,
+		to,
+	); err != nil {
+		return errors.Wrap(err, "failed to prune sync aggregates")
+	}
+
+	return nil
+}
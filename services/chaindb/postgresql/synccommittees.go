@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
@@ -26,6 +27,7 @@ import (
 func (s *Service) SetSyncCommittee(ctx context.Context, syncCommittee *chaindb.SyncCommittee) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetSyncCommittee")
 	defer span.End()
+	defer monitorQuery("SetSyncCommittee", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -51,6 +53,7 @@ func (s *Service) SetSyncCommittee(ctx context.Context, syncCommittee *chaindb.S
 func (s *Service) SyncCommittee(ctx context.Context, period uint64) (*chaindb.SyncCommittee, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SyncCommittee")
 	defer span.End()
+	defer monitorQuery("SyncCommittee", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -85,3 +88,26 @@ func (s *Service) SyncCommittee(ctx context.Context, period uint64) (*chaindb.Sy
 	}
 	return committee, nil
 }
+
+// PruneSyncCommittees prunes sync committees for periods up to (but not including) the given period.
+func (s *Service) PruneSyncCommittees(ctx context.Context, to uint64) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneSyncCommittees")
+	defer span.End()
+	defer monitorQuery("PruneSyncCommittees", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM t_sync_committees
+WHERE f_period < $1
+`,
+		to,
+	); err != nil {
+		return errors.Wrap(err, "failed to prune sync committees")
+	}
+
+	return nil
+}
@@ -33,6 +33,15 @@ type Tx struct{}
 // TxID is a context tag for the transaction TxID.
 type TxID struct{}
 
+// nestedTx is a context tag for a nested transaction.
+type nestedTx struct{}
+
+// nestingTx is a context tag for a transaction within which transactions are nested.
+type nestingTx struct{}
+
+// txStarted is a context tag for the time at which the transaction started.
+type txStarted struct{}
+
 func init() {
 	// We seed math.rand here so that we can obtain different IDs for requests.
 	// This is purely used as a way to match request and response entries in logs, so there is no
@@ -42,13 +51,36 @@ func init() {
 
 // BeginTx begins a transaction on the database.
 // The transaction can be rolled back by invoking the cancel function.
+// If the context contains a transaction started by BeginNestedTx then a nested transaction
+// is created within it, and will only be persisted when the outer transaction is committed.
 func (s *Service) BeginTx(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	return s.beginTx(ctx, false)
+}
+
+// BeginNestedTx begins a transaction on the database within which calls to BeginTx create
+// nested transactions rather than independent transactions.
+// The transaction can be rolled back by invoking the cancel function.
+func (s *Service) BeginNestedTx(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	return s.beginTx(ctx, true)
+}
+
+func (s *Service) beginTx(ctx context.Context, nesting bool) (context.Context, context.CancelFunc, error) {
 	// #nosec G404
 	id := fmt.Sprintf("%02x", rand.Int31())
 	log := log.With().Str("id", id).Logger()
 
 	ctx, cancel := context.WithCancel(ctx)
-	tx, err := s.pool.Begin(ctx)
+	var tx pgx.Tx
+	var err error
+	var parentTx pgx.Tx
+	if parentNesting, ok := ctx.Value(&nestingTx{}).(bool); ok && parentNesting {
+		parentTx = s.tx(ctx)
+	}
+	if parentTx != nil {
+		tx, err = parentTx.Begin(ctx)
+	} else {
+		tx, err = s.pool.Begin(ctx)
+	}
 	if err != nil {
 		log.Trace().Err(err).Str("trace", fmt.Sprintf("+%v", errors.Wrap(err, "stack"))).Msg("Failed to begin transaction")
 		cancel()
@@ -57,12 +89,19 @@ func (s *Service) BeginTx(ctx context.Context) (context.Context, context.CancelF
 
 	ctx = context.WithValue(ctx, &Tx{}, tx)
 	ctx = context.WithValue(ctx, &TxID{}, id)
+	ctx = context.WithValue(ctx, &nestedTx{}, parentTx != nil)
+	if nesting {
+		ctx = context.WithValue(ctx, &nestingTx{}, true)
+	}
+	ctx = context.WithValue(ctx, &txStarted{}, time.Now())
 
 	log.Trace().Str("trace", fmt.Sprintf("%+v", errors.New("stack"))).Msg("Transaction started")
 	return ctx, func() {
 		if err := tx.Rollback(ctx); err != nil {
 			log.Debug().Err(err).Str("trace", fmt.Sprintf("%+v", errors.Wrap(err, "stack"))).Msg("Failed to rollback transaction")
 			log.Warn().Err(err).Msg("Failed to rollback transaction")
+		} else {
+			monitorTxFinished(ctx, "read_write", "rolled_back")
 		}
 		log.Debug().Str("trace", fmt.Sprintf("%+v", errors.New("stack"))).Msg("Rolled back transaction")
 		cancel()
@@ -76,7 +115,13 @@ func (s *Service) BeginROTx(ctx context.Context) (context.Context, error) {
 	id := fmt.Sprintf("%02x", rand.Int31())
 	log := log.With().Str("id", id).Logger()
 
-	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	pool, source := s.readPool(ctx)
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil && pool != s.pool {
+		log.Debug().Str("replica", source).Err(err).Msg("Failed to begin read-only transaction on replica; using primary")
+		source = "primary"
+		tx, err = s.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	}
 	if err != nil {
 		log.Trace().Err(err).Str("trace", fmt.Sprintf("+%v", errors.Wrap(err, "stack"))).Msg("Failed to begin read-only transaction")
 		return nil, errors.Wrap(err, "failed to begin read-only transaction")
@@ -84,8 +129,9 @@ func (s *Service) BeginROTx(ctx context.Context) (context.Context, error) {
 
 	ctx = context.WithValue(ctx, &Tx{}, tx)
 	ctx = context.WithValue(ctx, &TxID{}, id)
+	ctx = context.WithValue(ctx, &txStarted{}, time.Now())
 
-	log.Trace().Str("trace", fmt.Sprintf("%+v", errors.New("stack"))).Msg("Read-only transaction started")
+	log.Trace().Str("source", source).Str("trace", fmt.Sprintf("%+v", errors.New("stack"))).Msg("Read-only transaction started")
 	return ctx, nil
 }
 
@@ -130,8 +176,18 @@ func (s *Service) CommitTx(ctx context.Context) error {
 
 	if err := tx.Commit(ctx); err != nil {
 		log.Debug().Err(err).Str("trace", fmt.Sprintf("%+v", errors.Wrap(err, "stack"))).Msg("Failed to commit")
+		monitorTxFinished(ctx, "read_write", "failed")
 		return err
 	}
+	monitorTxFinished(ctx, "read_write", "committed")
+
+	if len(s.replicas) > 0 {
+		if nested, ok := ctx.Value(&nestedTx{}).(bool); ok && !nested {
+			// Note the write, so that the next read obtains the position of the primary before
+			// choosing a replica.
+			s.writes.Inc()
+		}
+	}
 
 	log.Trace().Str("trace", fmt.Sprintf("%+v", errors.New("stack"))).Msg("Transaction committed")
 	return nil
@@ -154,8 +210,10 @@ func (s *Service) CommitROTx(ctx context.Context) {
 
 	if err := tx.Commit(ctx); err != nil {
 		log.Debug().Err(err).Str("trace", fmt.Sprintf("%+v", errors.Wrap(err, "stack"))).Msg("Failed to commit")
+		monitorTxFinished(ctx, "read_only", "failed")
 		return
 	}
+	monitorTxFinished(ctx, "read_only", "committed")
 
 	log.Trace().Str("trace", fmt.Sprintf("%+v", errors.New("stack"))).Msg("Transaction committed")
 }
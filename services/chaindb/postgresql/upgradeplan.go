@@ -0,0 +1,242 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// UpgradeStep is a single step in an upgrade of the database schema.
+type UpgradeStep struct {
+	// Version is the schema version to which the step belongs.
+	Version uint64
+	// Name is the name of the step.
+	Name string
+	// Statements are the SQL statements executed by the step.  This is only populated by a dry run.
+	Statements []string
+}
+
+// UpgradePlan describes the upgrade required to bring the database schema up to date.
+type UpgradePlan struct {
+	// Initialized is true if the database schema has been created.
+	Initialized bool
+	// CurrentVersion is the version of the schema in the database.
+	CurrentVersion uint64
+	// RequiredVersion is the version of the schema required by this release.
+	RequiredVersion uint64
+	// Steps are the steps required to upgrade the schema.
+	Steps []*UpgradeStep
+	// RequiresRefetch is true if the upgrade requires blocks to be refetched.
+	RequiresRefetch bool
+}
+
+// UpgradePlan returns the upgrade required to bring the database schema up to date.
+func (s *Service) UpgradePlan(ctx context.Context) (*UpgradePlan, error) {
+	plan := &UpgradePlan{
+		RequiredVersion: currentVersion,
+		Steps:           make([]*UpgradeStep, 0),
+	}
+
+	tableExists, err := s.tableExists(ctx, "t_metadata")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check presence of tables")
+	}
+	if !tableExists {
+		plan.Steps = append(plan.Steps, &UpgradeStep{
+			Version: currentVersion,
+			Name:    "Init",
+		})
+		return plan, nil
+	}
+	plan.Initialized = true
+
+	plan.CurrentVersion, err = s.version(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain version")
+	}
+
+	for version := plan.CurrentVersion + 1; version <= currentVersion; version++ {
+		upgrade, exists := upgrades[version]
+		if !exists {
+			continue
+		}
+		for _, upgradeFunc := range upgrade.funcs {
+			plan.Steps = append(plan.Steps, &UpgradeStep{
+				Version: version,
+				Name:    upgradeFuncName(upgradeFunc),
+			})
+		}
+		plan.RequiresRefetch = plan.RequiresRefetch || upgrade.requiresRefetch
+	}
+
+	return plan, nil
+}
+
+// dryRunLockTimeout is the time for which a dry run will wait to obtain a lock.
+const dryRunLockTimeout = "5s"
+
+// DryRunUpgrade returns the upgrade required to bring the database schema up to date, along with
+// the SQL statements that each step executes.
+// The upgrade is run inside a transaction that is rolled back, so the statements are those that
+// would be executed against this database but no changes are made.  However, the statements are
+// executed, so until the transaction is rolled back the dry run holds the same locks as the upgrade
+// itself, including exclusive locks on any tables that are altered, and any data migrations run in
+// full.  As such, a dry run is refused if another instance of chaind is connected to the database.
+func (s *Service) DryRunUpgrade(ctx context.Context) (*UpgradePlan, error) {
+	plan, err := s.UpgradePlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Steps) == 0 {
+		return plan, nil
+	}
+
+	connections, err := s.otherChaindConnections(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain connections to the database")
+	}
+	if connections > 0 {
+		return nil, fmt.Errorf("chaind has %d connection(s) to the database; stop it before running a dry run", connections)
+	}
+
+	ctx, cancel, err := s.BeginTx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin dry run transaction")
+	}
+	// The dry run is always rolled back.
+	defer cancel()
+
+	// Fail rather than wait if a lock is held elsewhere, for example by a client that has started
+	// since the check above.
+	if _, err := s.tx(ctx).Exec(ctx, fmt.Sprintf("SET LOCAL lock_timeout = '%s'", dryRunLockTimeout)); err != nil {
+		return nil, errors.Wrap(err, "failed to set lock timeout")
+	}
+
+	statements := make([]string, 0)
+	ctx = context.WithValue(ctx, &Tx{}, &recordingTx{
+		Tx:         s.tx(ctx),
+		statements: &statements,
+	})
+
+	if !plan.Initialized {
+		if err := s.Init(ctx); err != nil {
+			return nil, errors.Wrap(err, "failed to run initialisation")
+		}
+		plan.Steps[0].Statements = statements
+		return plan, nil
+	}
+
+	i := 0
+	for version := plan.CurrentVersion + 1; version <= currentVersion; version++ {
+		upgrade, exists := upgrades[version]
+		if !exists {
+			continue
+		}
+		for _, upgradeFunc := range upgrade.funcs {
+			statements = statements[:0]
+			if err := upgradeFunc(ctx, s); err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("failed to run upgrade step %s", plan.Steps[i].Name))
+			}
+			plan.Steps[i].Statements = append([]string{}, statements...)
+			i++
+		}
+	}
+
+	return plan, nil
+}
+
+// otherChaindConnections returns the number of connections to the database from instances of
+// chaind other than this one.
+func (s *Service) otherChaindConnections(ctx context.Context) (int, error) {
+	// Connections from this instance are excluded.
+	pids := make([]int32, 0)
+	for _, conn := range s.pool.AcquireAllIdle(ctx) {
+		pids = append(pids, int32(conn.Conn().PgConn().PID()))
+		conn.Release()
+	}
+
+	var connections int
+	err := s.pool.QueryRow(ctx, `
+SELECT COUNT(*)
+FROM pg_stat_activity
+WHERE datname = current_database()
+  AND application_name = $1
+  AND pid <> ALL($2)`,
+		applicationName,
+		pids,
+	).Scan(&connections)
+	if err != nil {
+		return 0, err
+	}
+
+	return connections, nil
+}
+
+// upgradeFuncName returns the name of an upgrade function.
+func upgradeFuncName(upgradeFunc func(context.Context, *Service) error) string {
+	name := runtime.FuncForPC(reflect.ValueOf(upgradeFunc).Pointer()).Name()
+	if index := strings.LastIndex(name, "."); index != -1 {
+		name = name[index+1:]
+	}
+
+	return name
+}
+
+// recordingTx is a transaction that records the statements that modify the database.
+type recordingTx struct {
+	pgx.Tx
+	statements *[]string
+}
+
+// Begin starts a nested transaction that records to the same list of statements.
+func (t *recordingTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordingTx{
+		Tx:         tx,
+		statements: t.statements,
+	}, nil
+}
+
+// Exec records and executes a statement.
+func (t *recordingTx) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	*t.statements = append(*t.statements, strings.TrimSpace(sql))
+
+	return t.Tx.Exec(ctx, sql, arguments...)
+}
+
+// CopyFrom records and executes a bulk copy.
+func (t *recordingTx) CopyFrom(ctx context.Context,
+	tableName pgx.Identifier,
+	columnNames []string,
+	rowSrc pgx.CopyFromSource,
+) (
+	int64,
+	error,
+) {
+	*t.statements = append(*t.statements, fmt.Sprintf("COPY %s(%s) FROM STDIN", tableName.Sanitize(), strings.Join(columnNames, ",")))
+
+	return t.Tx.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
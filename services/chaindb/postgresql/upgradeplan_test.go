@@ -0,0 +1,52 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/chaindb/postgresql"
+)
+
+func TestUpgradePlan(t *testing.T) {
+	ctx := context.Background()
+	s, err := postgresql.New(ctx,
+		postgresql.WithLogLevel(zerolog.Disabled),
+		postgresql.WithConnectionURL(os.Getenv("CHAINDB_URL")),
+	)
+	require.NoError(t, err)
+
+	plan, err := s.UpgradePlan(ctx)
+	require.NoError(t, err)
+	if plan.Initialized {
+		require.LessOrEqual(t, plan.CurrentVersion, plan.RequiredVersion)
+	}
+
+	// A dry run should report the same steps, and leave the schema untouched.
+	dryRunPlan, err := s.DryRunUpgrade(ctx)
+	require.NoError(t, err)
+	require.Equal(t, len(plan.Steps), len(dryRunPlan.Steps))
+	for i := range plan.Steps {
+		require.Equal(t, plan.Steps[i].Name, dryRunPlan.Steps[i].Name)
+	}
+
+	postPlan, err := s.UpgradePlan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, plan.Initialized, postPlan.Initialized)
+	require.Equal(t, plan.CurrentVersion, postPlan.CurrentVersion)
+}
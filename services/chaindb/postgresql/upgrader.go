@@ -27,7 +27,7 @@ type schemaMetadata struct {
 	Version uint64 `json:"version"`
 }
 
-var currentVersion = uint64(12)
+var currentVersion = uint64(25)
 
 type upgrade struct {
 	requiresRefetch bool
@@ -111,6 +111,72 @@ var upgrades = map[uint64]*upgrade{
 			addValidatorIndexToChanges,
 		},
 	},
+	13: {
+		funcs: []func(context.Context, *Service) error{
+			createWithdrawalDaySummaries,
+		},
+	},
+	14: {
+		funcs: []func(context.Context, *Service) error{
+			createReconciledDeposits,
+		},
+	},
+	15: {
+		funcs: []func(context.Context, *Service) error{
+			addETH1DepositsProvisional,
+		},
+	},
+	16: {
+		funcs: []func(context.Context, *Service) error{
+			createETH1VotePeriods,
+		},
+	},
+	17: {
+		funcs: []func(context.Context, *Service) error{
+			addBlockSummaryConsensusRewards,
+		},
+	},
+	18: {
+		funcs: []func(context.Context, *Service) error{
+			createRelayPayloads,
+		},
+	},
+	19: {
+		funcs: []func(context.Context, *Service) error{
+			addBlockSummaryClients,
+			createClientSummaries,
+		},
+	},
+	20: {
+		funcs: []func(context.Context, *Service) error{
+			addAttestationEffectiveness,
+		},
+	},
+	21: {
+		funcs: []func(context.Context, *Service) error{
+			addBlockSummaryPacking,
+		},
+	},
+	22: {
+		funcs: []func(context.Context, *Service) error{
+			createValidatorPeriodSummaries,
+		},
+	},
+	23: {
+		funcs: []func(context.Context, *Service) error{
+			addValidatorEpochSummaryProvisional,
+		},
+	},
+	24: {
+		funcs: []func(context.Context, *Service) error{
+			addInactivity,
+		},
+	},
+	25: {
+		funcs: []func(context.Context, *Service) error{
+			createGraffitiText,
+		},
+	},
 }
 
 // Upgrade upgrades the database.
@@ -1040,6 +1106,7 @@ CREATE TABLE t_eth1_deposits (
  ,f_withdrawal_credentials BYTEA NOT NULL
  ,f_signature              BYTEA NOT NULL
  ,f_amount                 BIGINT NOT NULL
+ ,f_provisional            BOOL NOT NULL DEFAULT false
 );
 CREATE UNIQUE INDEX i_eth1_deposits_1 ON t_eth1_deposits(f_eth1_block_hash, f_eth1_tx_hash, f_eth1_log_index);
 CREATE INDEX i_eth1_deposits_2 ON t_eth1_deposits(f_validator_pubkey);
@@ -1053,6 +1120,7 @@ CREATE TABLE t_validator_balances (
  ,f_epoch             BIGINT NOT NULL
  ,f_balance           BIGINT NOT NULL
  ,f_effective_balance BIGINT NOT NULL
+ ,f_inactivity_score  BIGINT
 );
 CREATE UNIQUE INDEX i_validator_balances_1 ON t_validator_balances(f_validator_index, f_epoch);
 CREATE INDEX i_validator_balances_2 ON t_validator_balances(f_epoch);
@@ -1069,8 +1137,12 @@ CREATE TABLE t_validator_epoch_summaries (
  ,f_attestation_head_correct    BOOL
  ,f_attestation_head_timely     BOOL
  ,f_attestation_inclusion_delay INTEGER
+ ,f_attestation_effectiveness   FLOAT(4)
+ ,f_inactivity_penalty          BIGINT
+ ,f_provisional                 BOOL NOT NULL DEFAULT false
 );
 CREATE UNIQUE INDEX IF NOT EXISTS i_validator_epoch_summaries_1 ON t_validator_epoch_summaries(f_validator_index, f_epoch);
+CREATE INDEX IF NOT EXISTS i_validator_epoch_summaries_2 ON t_validator_epoch_summaries(f_epoch) WHERE f_provisional;
 
 CREATE TABLE t_block_summaries (
   f_slot                             BIGINT NOT NULL
@@ -1078,6 +1150,16 @@ CREATE TABLE t_block_summaries (
  ,f_duplicate_attestations_for_block INTEGER NOT NULL
  ,f_votes_for_block                  INTEGER NOT NULL
  ,f_parent_distance                  INTEGER NOT NULL
+ ,f_consensus_reward                 BIGINT
+ ,f_attestations_reward              BIGINT
+ ,f_sync_aggregate_reward            BIGINT
+ ,f_proposer_slashings_reward        BIGINT
+ ,f_attester_slashings_reward        BIGINT
+ ,f_consensus_client                 TEXT
+ ,f_execution_client                 TEXT
+ ,f_new_votes                        INTEGER
+ ,f_available_votes                  INTEGER
+ ,f_redundant_aggregates             INTEGER
 );
 CREATE UNIQUE INDEX IF NOT EXISTS i_block_summaries_1 ON t_block_summaries(f_slot);
 
@@ -1102,6 +1184,7 @@ CREATE TABLE t_epoch_summaries (
  ,f_deposits                         BIGINT NOT NULL
  ,f_exiting_validators               BIGINT NOT NULL
  ,f_canonical_blocks                 BIGINT NOT NULL
+ ,f_inactivity_leak                  BOOL
 );
 
 CREATE TABLE t_fork_schedule (
@@ -1136,10 +1219,40 @@ CREATE TABLE t_validator_day_summaries (
  ,f_attestations_inclusion_delay     FLOAT(4) NOT NULL
  ,f_sync_committee_messages          INTEGER NOT NULL
  ,f_sync_committee_messages_included INTEGER NOT NULL
+ ,f_attestations_effectiveness       FLOAT(4)
+ ,f_inactivity_penalties             BIGINT NOT NULL DEFAULT 0
 );
 CREATE UNIQUE INDEX IF NOT EXISTS i_validator_day_summaries_1 ON t_validator_day_summaries(f_validator_index, f_start_timestamp);
 CREATE INDEX IF NOT EXISTS i_validator_day_summaries_2 ON t_validator_day_summaries(f_start_timestamp);
 
+CREATE TABLE t_validator_period_summaries (
+  f_period                           TEXT NOT NULL
+ ,f_validator_index                  BIGINT NOT NULL
+ ,f_start_timestamp                  TIMESTAMPTZ NOT NULL
+ ,f_end_timestamp                    TIMESTAMPTZ NOT NULL
+ ,f_start_balance                    BIGINT NOT NULL
+ ,f_start_effective_balance          BIGINT NOT NULL
+ ,f_capital_change                   BIGINT NOT NULL
+ ,f_reward_change                    BIGINT NOT NULL
+ ,f_effective_balance_change         BIGINT NOT NULL
+ ,f_proposals                        INTEGER NOT NULL
+ ,f_proposals_included               INTEGER NOT NULL
+ ,f_attestations                     INTEGER NOT NULL
+ ,f_attestations_included            INTEGER NOT NULL
+ ,f_attestations_source_timely       INTEGER NOT NULL
+ ,f_attestations_target_correct      INTEGER NOT NULL
+ ,f_attestations_target_timely       INTEGER NOT NULL
+ ,f_attestations_head_correct        INTEGER NOT NULL
+ ,f_attestations_head_timely         INTEGER NOT NULL
+ ,f_attestations_inclusion_delay     FLOAT(4) NOT NULL
+ ,f_sync_committee_messages          INTEGER NOT NULL
+ ,f_sync_committee_messages_included INTEGER NOT NULL
+ ,f_attestations_effectiveness       FLOAT(4)
+ ,f_inactivity_penalties             BIGINT NOT NULL DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS i_validator_period_summaries_1 ON t_validator_period_summaries(f_period,f_validator_index,f_start_timestamp);
+CREATE INDEX IF NOT EXISTS i_validator_period_summaries_2 ON t_validator_period_summaries(f_period,f_start_timestamp);
+
 CREATE TABLE t_block_bls_to_execution_changes (
   f_block_root            BYTEA   NOT NULL REFERENCES t_blocks(f_root) ON DELETE CASCADE
  ,f_block_number          BIGINT  NOT NULL
@@ -1167,11 +1280,100 @@ CREATE UNIQUE INDEX IF NOT EXISTS i_block_withdrawals_1 ON t_block_withdrawals(f
 CREATE INDEX IF NOT EXISTS i_block_withdrawals_2 ON t_block_withdrawals(f_block_number);
 CREATE INDEX IF NOT EXISTS i_block_withdrawals_3 ON t_block_withdrawals(f_validator_index);
 CREATE INDEX IF NOT EXISTS i_block_withdrawals_4 ON t_block_withdrawals(f_address);
+
+-- t_withdrawal_day_summaries contains per-validator withdrawal aggregates for each day.
+CREATE TABLE t_withdrawal_day_summaries (
+  f_validator_index     BIGINT      NOT NULL
+ ,f_start_timestamp     TIMESTAMPTZ NOT NULL
+ ,f_address             BYTEA       NOT NULL
+ ,f_partial_withdrawals INTEGER     NOT NULL
+ ,f_partial_amount      BIGINT      NOT NULL
+ ,f_full_withdrawals    INTEGER     NOT NULL
+ ,f_full_amount         BIGINT      NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS i_withdrawal_day_summaries_1 ON t_withdrawal_day_summaries(f_validator_index,f_start_timestamp);
+CREATE INDEX IF NOT EXISTS i_withdrawal_day_summaries_2 ON t_withdrawal_day_summaries(f_start_timestamp);
+CREATE INDEX IF NOT EXISTS i_withdrawal_day_summaries_3 ON t_withdrawal_day_summaries(f_address,f_start_timestamp);
+
+-- t_reconciled_deposits links Ethereum 1 deposits with their inclusion in the beacon chain.
+CREATE TABLE t_reconciled_deposits (
+  f_deposit_index        BIGINT   NOT NULL PRIMARY KEY
+ ,f_validator_pubkey     BYTEA    NOT NULL
+ ,f_amount               BIGINT   NOT NULL
+ ,f_eth1_block_number    BIGINT   NOT NULL
+ ,f_eth1_tx_hash         BYTEA    NOT NULL
+ ,f_eth1_log_index       BIGINT   NOT NULL
+ ,f_inclusion_slot       BIGINT   NOT NULL
+ ,f_inclusion_block_root BYTEA    NOT NULL REFERENCES t_blocks(f_root) ON DELETE CASCADE
+ ,f_inclusion_index      BIGINT   NOT NULL
+ ,f_signature_valid      BOOL     NOT NULL
+ ,f_type                 SMALLINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS i_reconciled_deposits_1 ON t_reconciled_deposits(f_validator_pubkey,f_deposit_index);
+CREATE INDEX IF NOT EXISTS i_reconciled_deposits_2 ON t_reconciled_deposits(f_inclusion_slot);
+
+-- t_eth1_vote_periods contains the results of Ethereum 1 data voting per voting period.
+CREATE TABLE t_eth1_vote_periods (
+  f_period                     BIGINT   NOT NULL PRIMARY KEY
+ ,f_start_epoch                BIGINT   NOT NULL
+ ,f_blocks                     INTEGER  NOT NULL
+ ,f_distinct_votes             INTEGER  NOT NULL
+ ,f_winning_eth1_block_hash    BYTEA
+ ,f_winning_eth1_deposit_count BIGINT
+ ,f_winning_eth1_deposit_root  BYTEA
+ ,f_winning_votes              INTEGER  NOT NULL
+ ,f_majority_epoch             BIGINT
+ ,f_minority_proposers         BIGINT[] NOT NULL
+ ,f_stale_proposers            BIGINT[] NOT NULL
+);
+
+-- t_relay_payloads contains execution payloads delivered to proposers by MEV relays.
+CREATE TABLE t_relay_payloads (
+  f_relay                  TEXT    NOT NULL
+ ,f_slot                   BIGINT  NOT NULL
+ ,f_block_hash             BYTEA   NOT NULL
+ ,f_block_number           BIGINT  NOT NULL
+ ,f_parent_hash            BYTEA   NOT NULL
+ ,f_builder_pubkey         BYTEA   NOT NULL
+ ,f_proposer_pubkey        BYTEA   NOT NULL
+ ,f_proposer_fee_recipient BYTEA   NOT NULL
+ ,f_gas_limit              BIGINT  NOT NULL
+ ,f_gas_used               BIGINT  NOT NULL
+ ,f_value                  NUMERIC NOT NULL
+ ,f_num_tx                 BIGINT  NOT NULL
+ ,f_bid_timestamp          TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS i_relay_payloads_1 ON t_relay_payloads(f_block_hash,f_relay);
+CREATE INDEX IF NOT EXISTS i_relay_payloads_2 ON t_relay_payloads(f_slot);
+CREATE INDEX IF NOT EXISTS i_relay_payloads_3 ON t_relay_payloads(f_builder_pubkey);
+
+-- t_client_epoch_summaries contains the number of canonical blocks proposed by each client per epoch.
+CREATE TABLE t_client_epoch_summaries (
+  f_epoch            BIGINT  NOT NULL
+ ,f_consensus_client TEXT    NOT NULL
+ ,f_execution_client TEXT    NOT NULL
+ ,f_proposals        INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS i_client_epoch_summaries_1 ON t_client_epoch_summaries(f_epoch,f_consensus_client,f_execution_client);
+
+-- t_client_day_summaries contains the number of canonical blocks proposed by each client per day.
+CREATE TABLE t_client_day_summaries (
+  f_start_timestamp  TIMESTAMPTZ NOT NULL
+ ,f_consensus_client TEXT        NOT NULL
+ ,f_execution_client TEXT        NOT NULL
+ ,f_proposals        INTEGER     NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS i_client_day_summaries_1 ON t_client_day_summaries(f_start_timestamp,f_consensus_client,f_execution_client);
 `); err != nil {
 		cancel()
 		return errors.Wrap(err, "failed to create initial tables")
 	}
 
+	if _, err := tx.Exec(ctx, graffitiTextFunction); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to create graffiti text function")
+	}
+
 	if err := s.setVersion(ctx, currentVersion); err != nil {
 		cancel()
 		return errors.Wrap(err, "failed to set initial schema version")
@@ -1581,3 +1783,456 @@ ADD COLUMN f_validator_index BIGINT
 
 	return nil
 }
+
+// createWithdrawalDaySummaries adds t_withdrawal_day_summaries.
+func createWithdrawalDaySummaries(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE TABLE t_withdrawal_day_summaries (
+  f_validator_index     BIGINT      NOT NULL
+ ,f_start_timestamp     TIMESTAMPTZ NOT NULL
+ ,f_address             BYTEA       NOT NULL
+ ,f_partial_withdrawals INTEGER     NOT NULL
+ ,f_partial_amount      BIGINT      NOT NULL
+ ,f_full_withdrawals    INTEGER     NOT NULL
+ ,f_full_amount         BIGINT      NOT NULL
+)
+`); err != nil {
+		return errors.Wrap(err, "failed to create withdrawal day summaries table")
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE UNIQUE INDEX IF NOT EXISTS i_withdrawal_day_summaries_1 ON t_withdrawal_day_summaries(f_validator_index,f_start_timestamp)
+`); err != nil {
+		return errors.Wrap(err, "failed to create withdrawal day summaries index 1")
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE INDEX IF NOT EXISTS i_withdrawal_day_summaries_2 ON t_withdrawal_day_summaries(f_start_timestamp)
+`); err != nil {
+		return errors.Wrap(err, "failed to create withdrawal day summaries index 2")
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE INDEX IF NOT EXISTS i_withdrawal_day_summaries_3 ON t_withdrawal_day_summaries(f_address,f_start_timestamp)
+`); err != nil {
+		return errors.Wrap(err, "failed to create withdrawal day summaries index 3")
+	}
+
+	return nil
+}
+
+// createReconciledDeposits adds t_reconciled_deposits.
+func createReconciledDeposits(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE TABLE t_reconciled_deposits (
+  f_deposit_index        BIGINT   NOT NULL PRIMARY KEY
+ ,f_validator_pubkey     BYTEA    NOT NULL
+ ,f_amount               BIGINT   NOT NULL
+ ,f_eth1_block_number    BIGINT   NOT NULL
+ ,f_eth1_tx_hash         BYTEA    NOT NULL
+ ,f_eth1_log_index       BIGINT   NOT NULL
+ ,f_inclusion_slot       BIGINT   NOT NULL
+ ,f_inclusion_block_root BYTEA    NOT NULL REFERENCES t_blocks(f_root) ON DELETE CASCADE
+ ,f_inclusion_index      BIGINT   NOT NULL
+ ,f_signature_valid      BOOL     NOT NULL
+ ,f_type                 SMALLINT NOT NULL
+)
+`); err != nil {
+		return errors.Wrap(err, "failed to create reconciled deposits table")
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE INDEX IF NOT EXISTS i_reconciled_deposits_1 ON t_reconciled_deposits(f_validator_pubkey,f_deposit_index)
+`); err != nil {
+		return errors.Wrap(err, "failed to create reconciled deposits index 1")
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE INDEX IF NOT EXISTS i_reconciled_deposits_2 ON t_reconciled_deposits(f_inclusion_slot)
+`); err != nil {
+		return errors.Wrap(err, "failed to create reconciled deposits index 2")
+	}
+
+	return nil
+}
+
+// addETH1DepositsProvisional adds the f_provisional column to t_eth1_deposits.
+func addETH1DepositsProvisional(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+ALTER TABLE t_eth1_deposits
+ADD COLUMN f_provisional BOOL NOT NULL DEFAULT false
+`); err != nil {
+		return errors.Wrap(err, "failed to add f_provisional to t_eth1_deposits")
+	}
+
+	return nil
+}
+
+// createETH1VotePeriods adds t_eth1_vote_periods.
+func createETH1VotePeriods(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE TABLE t_eth1_vote_periods (
+  f_period                     BIGINT   NOT NULL PRIMARY KEY
+ ,f_start_epoch                BIGINT   NOT NULL
+ ,f_blocks                     INTEGER  NOT NULL
+ ,f_distinct_votes             INTEGER  NOT NULL
+ ,f_winning_eth1_block_hash    BYTEA
+ ,f_winning_eth1_deposit_count BIGINT
+ ,f_winning_eth1_deposit_root  BYTEA
+ ,f_winning_votes              INTEGER  NOT NULL
+ ,f_majority_epoch             BIGINT
+ ,f_minority_proposers         BIGINT[] NOT NULL
+ ,f_stale_proposers            BIGINT[] NOT NULL
+)
+`); err != nil {
+		return errors.Wrap(err, "failed to create Ethereum 1 vote periods table")
+	}
+
+	return nil
+}
+
+// addBlockSummaryConsensusRewards adds consensus reward columns to the t_block_summaries table.
+func addBlockSummaryConsensusRewards(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, column := range []string{
+		"f_consensus_reward",
+		"f_attestations_reward",
+		"f_sync_aggregate_reward",
+		"f_proposer_slashings_reward",
+		"f_attester_slashings_reward",
+	} {
+		alreadyPresent, err := s.columnExists(ctx, "t_block_summaries", column)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to check if %s is present in t_block_summaries", column))
+		}
+		if alreadyPresent {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+ALTER TABLE t_block_summaries
+ADD COLUMN %s BIGINT
+`, column)); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to add %s to block summaries table", column))
+		}
+	}
+
+	return nil
+}
+
+// createRelayPayloads adds t_relay_payloads.
+func createRelayPayloads(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE TABLE t_relay_payloads (
+  f_relay                  TEXT    NOT NULL
+ ,f_slot                   BIGINT  NOT NULL
+ ,f_block_hash             BYTEA   NOT NULL
+ ,f_block_number           BIGINT  NOT NULL
+ ,f_parent_hash            BYTEA   NOT NULL
+ ,f_builder_pubkey         BYTEA   NOT NULL
+ ,f_proposer_pubkey        BYTEA   NOT NULL
+ ,f_proposer_fee_recipient BYTEA   NOT NULL
+ ,f_gas_limit              BIGINT  NOT NULL
+ ,f_gas_used               BIGINT  NOT NULL
+ ,f_value                  NUMERIC NOT NULL
+ ,f_num_tx                 BIGINT  NOT NULL
+ ,f_bid_timestamp          TIMESTAMPTZ
+)
+`); err != nil {
+		return errors.Wrap(err, "failed to create relay payloads table")
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE UNIQUE INDEX IF NOT EXISTS i_relay_payloads_1 ON t_relay_payloads(f_block_hash,f_relay)"); err != nil {
+		return errors.Wrap(err, "failed to create relay payloads index (1)")
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE INDEX IF NOT EXISTS i_relay_payloads_2 ON t_relay_payloads(f_slot)"); err != nil {
+		return errors.Wrap(err, "failed to create relay payloads index (2)")
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE INDEX IF NOT EXISTS i_relay_payloads_3 ON t_relay_payloads(f_builder_pubkey)"); err != nil {
+		return errors.Wrap(err, "failed to create relay payloads index (3)")
+	}
+
+	return nil
+}
+
+// addBlockSummaryClients adds client information to block summaries.
+func addBlockSummaryClients(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, column := range []string{
+		"f_consensus_client",
+		"f_execution_client",
+	} {
+		alreadyPresent, err := s.columnExists(ctx, "t_block_summaries", column)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to check if %s is present in t_block_summaries", column))
+		}
+		if alreadyPresent {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+ALTER TABLE t_block_summaries
+ADD COLUMN %s TEXT
+`, column)); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to add %s to block summaries table", column))
+		}
+	}
+
+	return nil
+}
+
+// createClientSummaries adds t_client_epoch_summaries and t_client_day_summaries.
+func createClientSummaries(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE TABLE t_client_epoch_summaries (
+  f_epoch            BIGINT  NOT NULL
+ ,f_consensus_client TEXT    NOT NULL
+ ,f_execution_client TEXT    NOT NULL
+ ,f_proposals        INTEGER NOT NULL
+)
+`); err != nil {
+		return errors.Wrap(err, "failed to create client epoch summaries table")
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE UNIQUE INDEX IF NOT EXISTS i_client_epoch_summaries_1 ON t_client_epoch_summaries(f_epoch,f_consensus_client,f_execution_client)"); err != nil {
+		return errors.Wrap(err, "failed to create client epoch summaries index (1)")
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE TABLE t_client_day_summaries (
+  f_start_timestamp  TIMESTAMPTZ NOT NULL
+ ,f_consensus_client TEXT        NOT NULL
+ ,f_execution_client TEXT        NOT NULL
+ ,f_proposals        INTEGER     NOT NULL
+)
+`); err != nil {
+		return errors.Wrap(err, "failed to create client day summaries table")
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE UNIQUE INDEX IF NOT EXISTS i_client_day_summaries_1 ON t_client_day_summaries(f_start_timestamp,f_consensus_client,f_execution_client)"); err != nil {
+		return errors.Wrap(err, "failed to create client day summaries index (1)")
+	}
+
+	return nil
+}
+
+// addAttestationEffectiveness adds attestation effectiveness to validator epoch and day summaries.
+func addAttestationEffectiveness(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, tableColumn := range [][2]string{
+		{"t_validator_epoch_summaries", "f_attestation_effectiveness"},
+		{"t_validator_day_summaries", "f_attestations_effectiveness"},
+	} {
+		table, column := tableColumn[0], tableColumn[1]
+		alreadyPresent, err := s.columnExists(ctx, table, column)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to check if %s is present in %s", column, table))
+		}
+		if alreadyPresent {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+ALTER TABLE %s
+ADD COLUMN %s FLOAT(4)
+`, table, column)); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to add %s to %s", column, table))
+		}
+	}
+
+	return nil
+}
+
+// addBlockSummaryPacking adds attestation packing information to block summaries.
+func addBlockSummaryPacking(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, column := range []string{
+		"f_new_votes",
+		"f_available_votes",
+		"f_redundant_aggregates",
+	} {
+		alreadyPresent, err := s.columnExists(ctx, "t_block_summaries", column)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to check if %s is present in t_block_summaries", column))
+		}
+		if alreadyPresent {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+ALTER TABLE t_block_summaries
+ADD COLUMN %s INTEGER
+`, column)); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to add %s to block summaries table", column))
+		}
+	}
+
+	return nil
+}
+
+// createValidatorPeriodSummaries creates the validator period summaries table.
+func createValidatorPeriodSummaries(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE TABLE t_validator_period_summaries (
+  f_period                           TEXT NOT NULL
+ ,f_validator_index                  BIGINT NOT NULL
+ ,f_start_timestamp                  TIMESTAMPTZ NOT NULL
+ ,f_end_timestamp                    TIMESTAMPTZ NOT NULL
+ ,f_start_balance                    BIGINT NOT NULL
+ ,f_start_effective_balance          BIGINT NOT NULL
+ ,f_capital_change                   BIGINT NOT NULL
+ ,f_reward_change                    BIGINT NOT NULL
+ ,f_effective_balance_change         BIGINT NOT NULL
+ ,f_proposals                        INTEGER NOT NULL
+ ,f_proposals_included               INTEGER NOT NULL
+ ,f_attestations                     INTEGER NOT NULL
+ ,f_attestations_included            INTEGER NOT NULL
+ ,f_attestations_source_timely       INTEGER NOT NULL
+ ,f_attestations_target_correct      INTEGER NOT NULL
+ ,f_attestations_target_timely       INTEGER NOT NULL
+ ,f_attestations_head_correct        INTEGER NOT NULL
+ ,f_attestations_head_timely         INTEGER NOT NULL
+ ,f_attestations_inclusion_delay     FLOAT(4) NOT NULL
+ ,f_sync_committee_messages          INTEGER NOT NULL
+ ,f_sync_committee_messages_included INTEGER NOT NULL
+ ,f_attestations_effectiveness       FLOAT(4)
+)
+`); err != nil {
+		return errors.Wrap(err, "failed to create validator period summaries table")
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE UNIQUE INDEX IF NOT EXISTS i_validator_period_summaries_1 ON t_validator_period_summaries(f_period,f_validator_index,f_start_timestamp)"); err != nil {
+		return errors.Wrap(err, "failed to create validator period summaries index (1)")
+	}
+
+	if _, err := tx.Exec(ctx, "CREATE INDEX IF NOT EXISTS i_validator_period_summaries_2 ON t_validator_period_summaries(f_period,f_start_timestamp)"); err != nil {
+		return errors.Wrap(err, "failed to create validator period summaries index (2)")
+	}
+
+	return nil
+}
+
+// addValidatorEpochSummaryProvisional adds the f_provisional column to t_validator_epoch_summaries.
+func addValidatorEpochSummaryProvisional(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	alreadyPresent, err := s.columnExists(ctx, "t_validator_epoch_summaries", "f_provisional")
+	if err != nil {
+		return errors.Wrap(err, "failed to check if f_provisional is present in t_validator_epoch_summaries")
+	}
+	if !alreadyPresent {
+		if _, err := tx.Exec(ctx, `
+ALTER TABLE t_validator_epoch_summaries
+ADD COLUMN f_provisional BOOL NOT NULL DEFAULT false
+`); err != nil {
+			return errors.Wrap(err, "failed to add f_provisional to t_validator_epoch_summaries")
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+CREATE INDEX IF NOT EXISTS i_validator_epoch_summaries_2 ON t_validator_epoch_summaries(f_epoch) WHERE f_provisional
+`); err != nil {
+		return errors.Wrap(err, "failed to create validator epoch summaries index (2)")
+	}
+
+	return nil
+}
+
+// addInactivity adds inactivity scores, penalties and leaks to the relevant tables.
+func addInactivity(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, tableColumn := range [][3]string{
+		{"t_validator_balances", "f_inactivity_score", "BIGINT"},
+		{"t_validator_epoch_summaries", "f_inactivity_penalty", "BIGINT"},
+		{"t_validator_day_summaries", "f_inactivity_penalties", "BIGINT NOT NULL DEFAULT 0"},
+		{"t_validator_period_summaries", "f_inactivity_penalties", "BIGINT NOT NULL DEFAULT 0"},
+		{"t_epoch_summaries", "f_inactivity_leak", "BOOL"},
+	} {
+		table, column, definition := tableColumn[0], tableColumn[1], tableColumn[2]
+		alreadyPresent, err := s.columnExists(ctx, table, column)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to check if %s is present in %s", column, table))
+		}
+		if alreadyPresent {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`
+ALTER TABLE %s
+ADD COLUMN %s %s
+`, table, column, definition)); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to add %s to %s", column, table))
+		}
+	}
+
+	return nil
+}
+
+// createGraffitiText adds the graffiti_text function.
+func createGraffitiText(ctx context.Context, s *Service) error {
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, graffitiTextFunction); err != nil {
+		return errors.Wrap(err, "failed to create graffiti text function")
+	}
+
+	return nil
+}
@@ -15,9 +15,11 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
@@ -29,6 +31,7 @@ import (
 func (s *Service) SetValidatorDaySummaries(ctx context.Context, summaries []*chaindb.ValidatorDaySummary) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidatorDaySummaries")
 	defer span.End()
+	defer monitorQuery("SetValidatorDaySummaries", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -63,6 +66,8 @@ func (s *Service) SetValidatorDaySummaries(ctx context.Context, summaries []*cha
 			"f_attestations_inclusion_delay",
 			"f_sync_committee_messages",
 			"f_sync_committee_messages_included",
+			"f_attestations_effectiveness",
+			"f_inactivity_penalties",
 		},
 		pgx.CopyFromSlice(len(summaries), func(i int) ([]interface{}, error) {
 			return []interface{}{
@@ -85,6 +90,8 @@ func (s *Service) SetValidatorDaySummaries(ctx context.Context, summaries []*cha
 				summaries[i].AttestationsInclusionDelay,
 				summaries[i].SyncCommitteeMessages,
 				summaries[i].SyncCommitteeMessagesIncluded,
+				summaries[i].AttestationsEffectiveness,
+				summaries[i].InactivityPenalties,
 			}, nil
 		}))
 
@@ -116,12 +123,19 @@ func (s *Service) SetValidatorDaySummaries(ctx context.Context, summaries []*cha
 func (s *Service) SetValidatorDaySummary(ctx context.Context, summary *chaindb.ValidatorDaySummary) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidatorDaySummary")
 	defer span.End()
+	defer monitorQuery("SetValidatorDaySummary", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
 		return ErrNoTransaction
 	}
 
+	var attestationsEffectiveness sql.NullFloat64
+	if summary.AttestationsEffectiveness != nil {
+		attestationsEffectiveness.Valid = true
+		attestationsEffectiveness.Float64 = *summary.AttestationsEffectiveness
+	}
+
 	_, err := tx.Exec(ctx, `
 INSERT INTO t_validator_day_summaries(f_validator_index
                                      ,f_start_timestamp
@@ -141,8 +155,10 @@ INSERT INTO t_validator_day_summaries(f_validator_index
                                      ,f_attestations_head_timely
                                      ,f_attestations_inclusion_delay
                                      ,f_sync_committee_messages
-                                     ,f_sync_committee_messages_included)
-VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)
+                                     ,f_sync_committee_messages_included
+                                     ,f_attestations_effectiveness
+                                     ,f_inactivity_penalties)
+VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21)
 ON CONFLICT (f_validator_index,f_start_timestamp) DO
 UPDATE
 SET f_start_balance = excluded.f_start_balance
@@ -162,6 +178,8 @@ SET f_start_balance = excluded.f_start_balance
    ,f_attestations_inclusion_delay = excluded.f_attestations_inclusion_delay
    ,f_sync_committee_messages = excluded.f_sync_committee_messages
    ,f_sync_committee_messages_included = excluded.f_sync_committee_messages_included
+   ,f_attestations_effectiveness = excluded.f_attestations_effectiveness
+   ,f_inactivity_penalties = excluded.f_inactivity_penalties
      `,
 		summary.Index,
 		summary.StartTimestamp,
@@ -182,6 +200,8 @@ SET f_start_balance = excluded.f_start_balance
 		summary.AttestationsInclusionDelay,
 		summary.SyncCommitteeMessages,
 		summary.SyncCommitteeMessagesIncluded,
+		attestationsEffectiveness,
+		summary.InactivityPenalties,
 	)
 
 	return err
@@ -191,6 +211,7 @@ SET f_start_balance = excluded.f_start_balance
 func (s *Service) ValidatorDaySummaries(ctx context.Context, filter *chaindb.ValidatorDaySummaryFilter) ([]*chaindb.ValidatorDaySummary, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorDaySummaries")
 	defer span.End()
+	defer monitorQuery("ValidatorDaySummaries", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -226,6 +247,8 @@ SELECT f_validator_index
       ,f_attestations_inclusion_delay
       ,f_sync_committee_messages
       ,f_sync_committee_messages_included
+      ,f_attestations_effectiveness
+      ,f_inactivity_penalties
 FROM t_validator_day_summaries`)
 
 	wherestr := "WHERE"
@@ -286,6 +309,7 @@ LIMIT $%d`, len(queryVals)))
 	summaries := make([]*chaindb.ValidatorDaySummary, 0)
 	for rows.Next() {
 		summary := &chaindb.ValidatorDaySummary{}
+		var attestationsEffectiveness sql.NullFloat64
 		err := rows.Scan(
 			&summary.Index,
 			&summary.StartTimestamp,
@@ -306,10 +330,16 @@ LIMIT $%d`, len(queryVals)))
 			&summary.AttestationsInclusionDelay,
 			&summary.SyncCommitteeMessages,
 			&summary.SyncCommitteeMessagesIncluded,
+			&attestationsEffectiveness,
+			&summary.InactivityPenalties,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
 		}
+		if attestationsEffectiveness.Valid {
+			val := attestationsEffectiveness.Float64
+			summary.AttestationsEffectiveness = &val
+		}
 		summaries = append(summaries, summary)
 	}
 
@@ -322,3 +352,89 @@ LIMIT $%d`, len(queryVals)))
 	})
 	return summaries, nil
 }
+
+// AttestationEffectiveness provides the aggregate attestation effectiveness of the validators and days matching the filter.
+// The limit and order of the filter are ignored.
+func (s *Service) AttestationEffectiveness(ctx context.Context,
+	filter *chaindb.ValidatorDaySummaryFilter,
+) (
+	*chaindb.AttestationEffectivenessSummary,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AttestationEffectiveness")
+	defer span.End()
+	defer monitorQuery("AttestationEffectiveness", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	// Effectiveness is weighted by the number of included attestations it covers.
+	queryBuilder.WriteString(`
+SELECT COUNT(DISTINCT f_validator_index)
+      ,COALESCE(SUM(f_attestations),0)
+      ,COALESCE(SUM(f_attestations_included),0)
+      ,SUM(f_attestations_effectiveness * f_attestations_included) / NULLIF(SUM(CASE WHEN f_attestations_effectiveness IS NULL THEN 0 ELSE f_attestations_included END),0)
+FROM t_validator_day_summaries`)
+
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_start_timestamp >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_start_timestamp <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.ValidatorIndices != nil && len(*filter.ValidatorIndices) > 0 {
+		queryVals = append(queryVals, *filter.ValidatorIndices)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_validator_index = ANY($%d)`, wherestr, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	summary := &chaindb.AttestationEffectivenessSummary{}
+	var effectiveness sql.NullFloat64
+	err := tx.QueryRow(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	).Scan(
+		&summary.Validators,
+		&summary.Attestations,
+		&summary.AttestationsIncluded,
+		&effectiveness,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan row")
+	}
+	if effectiveness.Valid {
+		val := effectiveness.Float64
+		summary.Effectiveness = &val
+	}
+
+	return summary, nil
+}
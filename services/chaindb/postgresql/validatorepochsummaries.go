@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/jackc/pgx/v4"
@@ -31,6 +32,7 @@ import (
 func (s *Service) SetValidatorEpochSummaries(ctx context.Context, summaries []*chaindb.ValidatorEpochSummary) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidatorEpochSummaries")
 	defer span.End()
+	defer monitorQuery("SetValidatorEpochSummaries", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -57,6 +59,9 @@ func (s *Service) SetValidatorEpochSummaries(ctx context.Context, summaries []*c
 			"f_attestation_source_timely",
 			"f_attestation_target_timely",
 			"f_attestation_head_timely",
+			"f_attestation_effectiveness",
+			"f_inactivity_penalty",
+			"f_provisional",
 		},
 		pgx.CopyFromSlice(len(summaries), func(i int) ([]interface{}, error) {
 			return []interface{}{
@@ -71,6 +76,9 @@ func (s *Service) SetValidatorEpochSummaries(ctx context.Context, summaries []*c
 				summaries[i].AttestationSourceTimely,
 				summaries[i].AttestationTargetTimely,
 				summaries[i].AttestationHeadTimely,
+				summaries[i].AttestationEffectiveness,
+				summaries[i].InactivityPenalty,
+				summaries[i].Provisional,
 			}, nil
 		}))
 
@@ -102,6 +110,7 @@ func (s *Service) SetValidatorEpochSummaries(ctx context.Context, summaries []*c
 func (s *Service) SetValidatorEpochSummary(ctx context.Context, summary *chaindb.ValidatorEpochSummary) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidatorEpochSummary")
 	defer span.End()
+	defer monitorQuery("SetValidatorEpochSummary", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -114,6 +123,8 @@ func (s *Service) SetValidatorEpochSummary(ctx context.Context, summary *chaindb
 	var attestationSourceTimely sql.NullBool
 	var attestationTargetTimely sql.NullBool
 	var attestationHeadTimely sql.NullBool
+	var attestationEffectiveness sql.NullFloat64
+	var inactivityPenalty sql.NullInt64
 
 	if summary.AttestationTargetCorrect != nil {
 		attestationTargetCorrect.Valid = true
@@ -139,6 +150,14 @@ func (s *Service) SetValidatorEpochSummary(ctx context.Context, summary *chaindb
 		attestationHeadTimely.Valid = true
 		attestationHeadTimely.Bool = *summary.AttestationHeadTimely
 	}
+	if summary.AttestationEffectiveness != nil {
+		attestationEffectiveness.Valid = true
+		attestationEffectiveness.Float64 = *summary.AttestationEffectiveness
+	}
+	if summary.InactivityPenalty != nil {
+		inactivityPenalty.Valid = true
+		inactivityPenalty.Int64 = int64(*summary.InactivityPenalty)
+	}
 
 	_, err := tx.Exec(ctx, `
       INSERT INTO t_validator_epoch_summaries(f_validator_index
@@ -151,8 +170,11 @@ func (s *Service) SetValidatorEpochSummary(ctx context.Context, summary *chaindb
                               ,f_attestation_inclusion_delay
                               ,f_attestation_source_timely
                               ,f_attestation_target_timely
-                              ,f_attestation_head_timely)
-      VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+                              ,f_attestation_head_timely
+                              ,f_attestation_effectiveness
+                              ,f_inactivity_penalty
+                              ,f_provisional)
+      VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
       ON CONFLICT (f_validator_index,f_epoch) DO
       UPDATE
       SET f_proposer_duties = excluded.f_proposer_duties
@@ -164,6 +186,9 @@ func (s *Service) SetValidatorEpochSummary(ctx context.Context, summary *chaindb
          ,f_attestation_source_timely = excluded.f_attestation_source_timely
          ,f_attestation_target_timely = excluded.f_attestation_target_timely
          ,f_attestation_head_timely = excluded.f_attestation_head_timely
+         ,f_attestation_effectiveness = excluded.f_attestation_effectiveness
+         ,f_inactivity_penalty = excluded.f_inactivity_penalty
+         ,f_provisional = excluded.f_provisional
 		 `,
 		summary.Index,
 		summary.Epoch,
@@ -176,6 +201,9 @@ func (s *Service) SetValidatorEpochSummary(ctx context.Context, summary *chaindb
 		attestationSourceTimely,
 		attestationTargetTimely,
 		attestationHeadTimely,
+		attestationEffectiveness,
+		inactivityPenalty,
+		summary.Provisional,
 	)
 
 	return err
@@ -185,6 +213,7 @@ func (s *Service) SetValidatorEpochSummary(ctx context.Context, summary *chaindb
 func (s *Service) ValidatorSummaries(ctx context.Context, filter *chaindb.ValidatorSummaryFilter) ([]*chaindb.ValidatorEpochSummary, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorSummaries")
 	defer span.End()
+	defer monitorQuery("ValidatorSummaries", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -212,6 +241,9 @@ SELECT f_validator_index
       ,f_attestation_source_timely
       ,f_attestation_target_timely
       ,f_attestation_head_timely
+      ,f_attestation_effectiveness
+      ,f_inactivity_penalty
+      ,f_provisional
 FROM t_validator_epoch_summaries`)
 
 	wherestr := "WHERE"
@@ -278,6 +310,8 @@ LIMIT $%d`, len(queryVals)))
 		var attestationSourceTimely sql.NullBool
 		var attestationTargetTimely sql.NullBool
 		var attestationHeadTimely sql.NullBool
+		var attestationEffectiveness sql.NullFloat64
+		var inactivityPenalty sql.NullInt64
 		err := rows.Scan(
 			&summary.Index,
 			&summary.Epoch,
@@ -290,6 +324,9 @@ LIMIT $%d`, len(queryVals)))
 			&attestationSourceTimely,
 			&attestationTargetTimely,
 			&attestationHeadTimely,
+			&attestationEffectiveness,
+			&inactivityPenalty,
+			&summary.Provisional,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
@@ -318,6 +355,14 @@ LIMIT $%d`, len(queryVals)))
 			val := attestationHeadTimely.Bool
 			summary.AttestationHeadTimely = &val
 		}
+		if attestationEffectiveness.Valid {
+			val := attestationEffectiveness.Float64
+			summary.AttestationEffectiveness = &val
+		}
+		if inactivityPenalty.Valid {
+			val := phase0.Gwei(inactivityPenalty.Int64)
+			summary.InactivityPenalty = &val
+		}
 		summaries = append(summaries, summary)
 	}
 
@@ -335,6 +380,7 @@ LIMIT $%d`, len(queryVals)))
 func (s *Service) ValidatorSummariesForEpoch(ctx context.Context, epoch phase0.Epoch) ([]*chaindb.ValidatorEpochSummary, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorSummariesForEpoch")
 	defer span.End()
+	defer monitorQuery("ValidatorSummariesForEpoch", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -358,6 +404,9 @@ SELECT f_validator_index
       ,f_attestation_source_timely
       ,f_attestation_target_timely
       ,f_attestation_head_timely
+      ,f_attestation_effectiveness
+      ,f_inactivity_penalty
+      ,f_provisional
 FROM t_validator_epoch_summaries
 WHERE f_epoch = $1
 ORDER BY f_validator_index
@@ -379,6 +428,8 @@ ORDER BY f_validator_index
 		var attestationSourceTimely sql.NullBool
 		var attestationTargetTimely sql.NullBool
 		var attestationHeadTimely sql.NullBool
+		var attestationEffectiveness sql.NullFloat64
+		var inactivityPenalty sql.NullInt64
 		err := rows.Scan(
 			&summary.Index,
 			&summary.Epoch,
@@ -391,6 +442,9 @@ ORDER BY f_validator_index
 			&attestationSourceTimely,
 			&attestationTargetTimely,
 			&attestationHeadTimely,
+			&attestationEffectiveness,
+			&inactivityPenalty,
+			&summary.Provisional,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
@@ -419,6 +473,14 @@ ORDER BY f_validator_index
 			val := attestationHeadTimely.Bool
 			summary.AttestationHeadTimely = &val
 		}
+		if attestationEffectiveness.Valid {
+			val := attestationEffectiveness.Float64
+			summary.AttestationEffectiveness = &val
+		}
+		if inactivityPenalty.Valid {
+			val := phase0.Gwei(inactivityPenalty.Int64)
+			summary.InactivityPenalty = &val
+		}
 		summaries = append(summaries, summary)
 	}
 
@@ -435,6 +497,7 @@ func (s *Service) ValidatorSummaryForEpoch(ctx context.Context,
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorSummaryForEpoch")
 	defer span.End()
+	defer monitorQuery("ValidatorSummaryForEpoch", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -453,6 +516,8 @@ func (s *Service) ValidatorSummaryForEpoch(ctx context.Context,
 	var attestationSourceTimely sql.NullBool
 	var attestationTargetTimely sql.NullBool
 	var attestationHeadTimely sql.NullBool
+	var attestationEffectiveness sql.NullFloat64
+	var inactivityPenalty sql.NullInt64
 
 	err := tx.QueryRow(ctx, `
 SELECT f_validator_index
@@ -466,6 +531,9 @@ SELECT f_validator_index
       ,f_attestation_source_timely
       ,f_attestation_target_timely
       ,f_attestation_head_timely
+      ,f_attestation_effectiveness
+      ,f_inactivity_penalty
+      ,f_provisional
 FROM t_validator_epoch_summaries
 WHERE f_validator_index = $1
   AND f_epoch = $2
@@ -484,6 +552,9 @@ WHERE f_validator_index = $1
 		&attestationSourceTimely,
 		&attestationTargetTimely,
 		&attestationHeadTimely,
+		&attestationEffectiveness,
+		&inactivityPenalty,
+		&summary.Provisional,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to scan row")
@@ -513,6 +584,14 @@ WHERE f_validator_index = $1
 		val := attestationHeadTimely.Bool
 		summary.AttestationHeadTimely = &val
 	}
+	if attestationEffectiveness.Valid {
+		val := attestationEffectiveness.Float64
+		summary.AttestationEffectiveness = &val
+	}
+	if inactivityPenalty.Valid {
+		val := phase0.Gwei(inactivityPenalty.Int64)
+		summary.InactivityPenalty = &val
+	}
 
 	return summary, nil
 }
@@ -521,6 +600,7 @@ WHERE f_validator_index = $1
 func (s *Service) PruneValidatorEpochSummaries(ctx context.Context, to phase0.Epoch, retain []phase0.ValidatorIndex) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneValidatorEpochSummaries")
 	defer span.End()
+	defer monitorQuery("PruneValidatorEpochSummaries", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -559,3 +639,25 @@ AND  f_validator_index NOT IN($2)
 
 	return err
 }
+
+// PruneProvisionalValidatorEpochSummaries prunes provisional validator epoch summaries up to and including the given epoch.
+func (s *Service) PruneProvisionalValidatorEpochSummaries(ctx context.Context, to phase0.Epoch) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneProvisionalValidatorEpochSummaries")
+	defer span.End()
+	defer monitorQuery("PruneProvisionalValidatorEpochSummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	_, err := tx.Exec(ctx, `
+DELETE FROM t_validator_epoch_summaries
+WHERE f_epoch <= $1
+  AND f_provisional
+`,
+		to,
+	)
+
+	return err
+}
@@ -0,0 +1,274 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"go.opentelemetry.io/otel"
+)
+
+// SetValidatorPeriodSummaries sets the validator summaries for a period, replacing any existing summaries.
+func (s *Service) SetValidatorPeriodSummaries(ctx context.Context,
+	period string,
+	startTimestamp time.Time,
+	summaries []*chaindb.ValidatorPeriodSummary,
+) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidatorPeriodSummaries")
+	defer span.End()
+	defer monitorQuery("SetValidatorPeriodSummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM t_validator_period_summaries
+WHERE f_period = $1
+  AND f_start_timestamp = $2
+`,
+		period,
+		startTimestamp,
+	); err != nil {
+		return errors.Wrap(err, "failed to remove existing validator period summaries")
+	}
+
+	if _, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"t_validator_period_summaries"},
+		[]string{
+			"f_period",
+			"f_validator_index",
+			"f_start_timestamp",
+			"f_end_timestamp",
+			"f_start_balance",
+			"f_start_effective_balance",
+			"f_capital_change",
+			"f_reward_change",
+			"f_effective_balance_change",
+			"f_proposals",
+			"f_proposals_included",
+			"f_attestations",
+			"f_attestations_included",
+			"f_attestations_target_correct",
+			"f_attestations_head_correct",
+			"f_attestations_source_timely",
+			"f_attestations_target_timely",
+			"f_attestations_head_timely",
+			"f_attestations_inclusion_delay",
+			"f_sync_committee_messages",
+			"f_sync_committee_messages_included",
+			"f_attestations_effectiveness",
+			"f_inactivity_penalties",
+		},
+		pgx.CopyFromSlice(len(summaries), func(i int) ([]interface{}, error) {
+			return []interface{}{
+				summaries[i].Period,
+				summaries[i].Index,
+				summaries[i].StartTimestamp,
+				summaries[i].EndTimestamp,
+				summaries[i].StartBalance,
+				summaries[i].StartEffectiveBalance,
+				summaries[i].CapitalChange,
+				summaries[i].RewardChange,
+				summaries[i].EffectiveBalanceChange,
+				summaries[i].Proposals,
+				summaries[i].ProposalsIncluded,
+				summaries[i].Attestations,
+				summaries[i].AttestationsIncluded,
+				summaries[i].AttestationsTargetCorrect,
+				summaries[i].AttestationsHeadCorrect,
+				summaries[i].AttestationsSourceTimely,
+				summaries[i].AttestationsTargetTimely,
+				summaries[i].AttestationsHeadTimely,
+				summaries[i].AttestationsInclusionDelay,
+				summaries[i].SyncCommitteeMessages,
+				summaries[i].SyncCommitteeMessagesIncluded,
+				summaries[i].AttestationsEffectiveness,
+				summaries[i].InactivityPenalties,
+			}, nil
+		})); err != nil {
+		return errors.Wrap(err, "failed to copy validator period summaries")
+	}
+
+	return nil
+}
+
+// ValidatorPeriodSummaries provides summaries according to the filter.
+func (s *Service) ValidatorPeriodSummaries(ctx context.Context,
+	filter *chaindb.ValidatorPeriodSummaryFilter,
+) (
+	[]*chaindb.ValidatorPeriodSummary,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorPeriodSummaries")
+	defer span.End()
+	defer monitorQuery("ValidatorPeriodSummaries", time.Now())
+
+	if filter.Period == "" {
+		return nil, errors.New("no period specified")
+	}
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_period
+      ,f_validator_index
+      ,f_start_timestamp
+      ,f_end_timestamp
+      ,f_start_balance
+      ,f_start_effective_balance
+      ,f_capital_change
+      ,f_reward_change
+      ,f_effective_balance_change
+      ,f_proposals
+      ,f_proposals_included
+      ,f_attestations
+      ,f_attestations_included
+      ,f_attestations_target_correct
+      ,f_attestations_head_correct
+      ,f_attestations_source_timely
+      ,f_attestations_target_timely
+      ,f_attestations_head_timely
+      ,f_attestations_inclusion_delay
+      ,f_sync_committee_messages
+      ,f_sync_committee_messages_included
+      ,f_attestations_effectiveness
+      ,f_inactivity_penalties
+FROM t_validator_period_summaries`)
+
+	queryVals = append(queryVals, filter.Period)
+	queryBuilder.WriteString(fmt.Sprintf(`
+WHERE f_period = $%d`, len(queryVals)))
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND f_start_timestamp >= $%d`, len(queryVals)))
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND f_start_timestamp <= $%d`, len(queryVals)))
+	}
+
+	if filter.ValidatorIndices != nil && len(*filter.ValidatorIndices) > 0 {
+		queryVals = append(queryVals, *filter.ValidatorIndices)
+		queryBuilder.WriteString(fmt.Sprintf(`
+  AND f_validator_index = ANY($%d)`, len(queryVals)))
+	}
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp,f_validator_index`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp DESC,f_validator_index DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make([]*chaindb.ValidatorPeriodSummary, 0)
+	for rows.Next() {
+		summary := &chaindb.ValidatorPeriodSummary{}
+		var attestationsEffectiveness sql.NullFloat64
+		err := rows.Scan(
+			&summary.Period,
+			&summary.Index,
+			&summary.StartTimestamp,
+			&summary.EndTimestamp,
+			&summary.StartBalance,
+			&summary.StartEffectiveBalance,
+			&summary.CapitalChange,
+			&summary.RewardChange,
+			&summary.EffectiveBalanceChange,
+			&summary.Proposals,
+			&summary.ProposalsIncluded,
+			&summary.Attestations,
+			&summary.AttestationsIncluded,
+			&summary.AttestationsTargetCorrect,
+			&summary.AttestationsHeadCorrect,
+			&summary.AttestationsSourceTimely,
+			&summary.AttestationsTargetTimely,
+			&summary.AttestationsHeadTimely,
+			&summary.AttestationsInclusionDelay,
+			&summary.SyncCommitteeMessages,
+			&summary.SyncCommitteeMessagesIncluded,
+			&attestationsEffectiveness,
+			&summary.InactivityPenalties,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		if attestationsEffectiveness.Valid {
+			val := attestationsEffectiveness.Float64
+			summary.AttestationsEffectiveness = &val
+		}
+		summaries = append(summaries, summary)
+	}
+
+	// Always return order of start timestamp then validator index.
+	sort.Slice(summaries, func(i int, j int) bool {
+		if !summaries[i].StartTimestamp.Equal(summaries[j].StartTimestamp) {
+			return summaries[i].StartTimestamp.Before(summaries[j].StartTimestamp)
+		}
+		return summaries[i].Index < summaries[j].Index
+	})
+	return summaries, nil
+}
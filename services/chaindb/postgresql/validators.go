@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/jackc/pgx/v4"
@@ -33,6 +34,7 @@ var farFutureEpoch = phase0.Epoch(0xffffffffffffffff)
 func (s *Service) SetValidator(ctx context.Context, validator *chaindb.Validator) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidator")
 	defer span.End()
+	defer monitorQuery("SetValidator", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -101,6 +103,7 @@ func (s *Service) SetValidator(ctx context.Context, validator *chaindb.Validator
 func (s *Service) SetValidatorBalance(ctx context.Context, balance *chaindb.ValidatorBalance) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidatorBalance")
 	defer span.End()
+	defer monitorQuery("SetValidatorBalance", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -111,17 +114,20 @@ func (s *Service) SetValidatorBalance(ctx context.Context, balance *chaindb.Vali
       INSERT INTO t_validator_balances(f_validator_index
                                       ,f_epoch
                                       ,f_balance
-                                      ,f_effective_balance)
-      VALUES($1,$2,$3,$4)
+                                      ,f_effective_balance
+                                      ,f_inactivity_score)
+      VALUES($1,$2,$3,$4,$5)
       ON CONFLICT (f_validator_index, f_epoch) DO
       UPDATE
       SET f_balance = excluded.f_balance
          ,f_effective_balance = excluded.f_effective_balance
+         ,f_inactivity_score = excluded.f_inactivity_score
 		 `,
 		balance.Index,
 		balance.Epoch,
 		balance.Balance,
 		balance.EffectiveBalance,
+		balance.InactivityScore,
 	)
 
 	return err
@@ -131,6 +137,7 @@ func (s *Service) SetValidatorBalance(ctx context.Context, balance *chaindb.Vali
 func (s *Service) SetValidatorBalances(ctx context.Context, balances []*chaindb.ValidatorBalance) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetValidatorBalances")
 	defer span.End()
+	defer monitorQuery("SetValidatorBalances", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -144,6 +151,7 @@ func (s *Service) SetValidatorBalances(ctx context.Context, balances []*chaindb.
 			"f_epoch",
 			"f_balance",
 			"f_effective_balance",
+			"f_inactivity_score",
 		},
 		pgx.CopyFromSlice(len(balances), func(i int) ([]interface{}, error) {
 			return []interface{}{
@@ -151,6 +159,7 @@ func (s *Service) SetValidatorBalances(ctx context.Context, balances []*chaindb.
 				balances[i].Epoch,
 				balances[i].Balance,
 				balances[i].EffectiveBalance,
+				balances[i].InactivityScore,
 			}, nil
 		}))
 	return err
@@ -160,6 +169,7 @@ func (s *Service) SetValidatorBalances(ctx context.Context, balances []*chaindb.
 func (s *Service) Validators(ctx context.Context) ([]*chaindb.Validator, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "Validators")
 	defer span.End()
+	defer monitorQuery("Validators", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -207,6 +217,7 @@ func (s *Service) Validators(ctx context.Context) ([]*chaindb.Validator, error)
 func (s *Service) ValidatorsByPublicKey(ctx context.Context, pubKeys []phase0.BLSPubKey) (map[phase0.BLSPubKey]*chaindb.Validator, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorsByPublicKey")
 	defer span.End()
+	defer monitorQuery("ValidatorsByPublicKey", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -260,6 +271,7 @@ func (s *Service) ValidatorsByPublicKey(ctx context.Context, pubKeys []phase0.BL
 func (s *Service) ValidatorsByIndex(ctx context.Context, indices []phase0.ValidatorIndex) (map[phase0.ValidatorIndex]*chaindb.Validator, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorsByIndex")
 	defer span.End()
+	defer monitorQuery("ValidatorsByIndex", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -308,6 +320,7 @@ func (s *Service) ValidatorsByIndex(ctx context.Context, indices []phase0.Valida
 func (s *Service) ValidatorsByWithdrawalCredential(ctx context.Context, withdrawalCredentials []byte) ([]*chaindb.Validator, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorsByWithdrawalCredential")
 	defer span.End()
+	defer monitorQuery("ValidatorsByWithdrawalCredential", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -365,6 +378,7 @@ func (s *Service) ValidatorBalancesByEpoch(
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorBalancesByEpoch")
 	defer span.End()
+	defer monitorQuery("ValidatorBalancesByEpoch", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -381,6 +395,7 @@ func (s *Service) ValidatorBalancesByEpoch(
             ,f_epoch
             ,f_balance
             ,f_effective_balance
+            ,f_inactivity_score
       FROM t_validator_balances
       WHERE f_epoch = $1::BIGINT
       ORDER BY f_validator_index`,
@@ -418,6 +433,7 @@ func (s *Service) ValidatorBalancesByIndexAndEpoch(
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorBalancesByIndexAndEpoch")
 	defer span.End()
+	defer monitorQuery("ValidatorBalancesByIndexAndEpoch", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -434,6 +450,7 @@ func (s *Service) ValidatorBalancesByIndexAndEpoch(
             ,f_epoch
             ,f_balance
             ,f_effective_balance
+            ,f_inactivity_score
       FROM t_validator_balances
       WHERE f_epoch = $2::BIGINT
         AND f_validator_index = ANY($1)
@@ -473,6 +490,7 @@ func (s *Service) ValidatorBalancesByIndexAndEpochRange(
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorBalancesByIndexAndEpochRange")
 	defer span.End()
+	defer monitorQuery("ValidatorBalancesByIndexAndEpochRange", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -500,6 +518,7 @@ func (s *Service) ValidatorBalancesByIndexAndEpochRange(
             ,f_epoch
             ,f_balance
             ,f_effective_balance
+            ,f_inactivity_score
       FROM t_validator_balances
       JOIN (VALUES %s)
         AS x(id)
@@ -549,6 +568,7 @@ func (s *Service) ValidatorBalancesByIndexAndEpochs(
 ) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "ValidatorBalancesByIndexAndEpochs")
 	defer span.End()
+	defer monitorQuery("ValidatorBalancesByIndexAndEpochs", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -580,6 +600,7 @@ func (s *Service) ValidatorBalancesByIndexAndEpochs(
             ,f_epoch
             ,f_balance
             ,f_effective_balance
+            ,f_inactivity_score
       FROM t_validator_balances
 	  JOIN (VALUES %s)
 	           AS x(id)
@@ -687,15 +708,21 @@ func validatorFromRow(rows pgx.Rows) (*chaindb.Validator, error) {
 // validatorBalanceFromRow converts a SQL row in to a validator balance.
 func validatorBalanceFromRow(rows pgx.Rows) (*chaindb.ValidatorBalance, error) {
 	validatorBalance := &chaindb.ValidatorBalance{}
+	var inactivityScore sql.NullInt64
 	err := rows.Scan(
 		&validatorBalance.Index,
 		&validatorBalance.Epoch,
 		&validatorBalance.Balance,
 		&validatorBalance.EffectiveBalance,
+		&inactivityScore,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to scan row")
 	}
+	if inactivityScore.Valid {
+		val := uint64(inactivityScore.Int64)
+		validatorBalance.InactivityScore = &val
+	}
 	return validatorBalance, nil
 }
 
@@ -703,6 +730,7 @@ func validatorBalanceFromRow(rows pgx.Rows) (*chaindb.ValidatorBalance, error) {
 func (s *Service) PruneValidatorBalances(ctx context.Context, to phase0.Epoch, retain []phase0.ValidatorIndex) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "PruneValidatorBalances")
 	defer span.End()
+	defer monitorQuery("PruneValidatorBalances", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -15,6 +15,7 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	"github.com/wealdtech/chaind/services/chaindb"
 	"go.opentelemetry.io/otel"
@@ -24,6 +25,7 @@ import (
 func (s *Service) SetVoluntaryExit(ctx context.Context, voluntaryExit *chaindb.VoluntaryExit) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetVoluntaryExit")
 	defer span.End()
+	defer monitorQuery("SetVoluntaryExit", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -0,0 +1,317 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"go.opentelemetry.io/otel"
+)
+
+// SetWithdrawalDaySummaries sets multiple withdrawal day summaries.
+func (s *Service) SetWithdrawalDaySummaries(ctx context.Context, summaries []*chaindb.WithdrawalDaySummary) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "SetWithdrawalDaySummaries")
+	defer span.End()
+	defer monitorQuery("SetWithdrawalDaySummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		return ErrNoTransaction
+	}
+
+	for _, summary := range summaries {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO t_withdrawal_day_summaries(f_validator_index
+                                      ,f_start_timestamp
+                                      ,f_address
+                                      ,f_partial_withdrawals
+                                      ,f_partial_amount
+                                      ,f_full_withdrawals
+                                      ,f_full_amount)
+VALUES($1,$2,$3,$4,$5,$6,$7)
+ON CONFLICT (f_validator_index,f_start_timestamp) DO
+UPDATE
+SET f_address = excluded.f_address
+   ,f_partial_withdrawals = excluded.f_partial_withdrawals
+   ,f_partial_amount = excluded.f_partial_amount
+   ,f_full_withdrawals = excluded.f_full_withdrawals
+   ,f_full_amount = excluded.f_full_amount
+`,
+			summary.ValidatorIndex,
+			summary.StartTimestamp,
+			summary.Address[:],
+			summary.PartialWithdrawals,
+			summary.PartialAmount,
+			summary.FullWithdrawals,
+			summary.FullAmount,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WithdrawalDaySummaries provides per-validator withdrawal day summaries according to the filter.
+func (s *Service) WithdrawalDaySummaries(ctx context.Context,
+	filter *chaindb.WithdrawalDaySummaryFilter,
+) (
+	[]*chaindb.WithdrawalDaySummary,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "WithdrawalDaySummaries")
+	defer span.End()
+	defer monitorQuery("WithdrawalDaySummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_validator_index
+      ,f_start_timestamp
+      ,f_address
+      ,f_partial_withdrawals
+      ,f_partial_amount
+      ,f_full_withdrawals
+      ,f_full_amount
+FROM t_withdrawal_day_summaries`)
+
+	queryVals = withdrawalDaySummaryFilterSQL(&queryBuilder, queryVals, filter)
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp, f_validator_index`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp DESC,f_validator_index DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make([]*chaindb.WithdrawalDaySummary, 0)
+	address := make([]byte, bellatrix.ExecutionAddressLength)
+	for rows.Next() {
+		summary := &chaindb.WithdrawalDaySummary{}
+		err := rows.Scan(
+			&summary.ValidatorIndex,
+			&summary.StartTimestamp,
+			&address,
+			&summary.PartialWithdrawals,
+			&summary.PartialAmount,
+			&summary.FullWithdrawals,
+			&summary.FullAmount,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		copy(summary.Address[:], address)
+		summaries = append(summaries, summary)
+	}
+
+	// Always return order of start timestamp then validator index.
+	sort.Slice(summaries, func(i int, j int) bool {
+		if !summaries[i].StartTimestamp.Equal(summaries[j].StartTimestamp) {
+			return summaries[i].StartTimestamp.Before(summaries[j].StartTimestamp)
+		}
+		return summaries[i].ValidatorIndex < summaries[j].ValidatorIndex
+	})
+	return summaries, nil
+}
+
+// AddressWithdrawalDaySummaries provides per-address withdrawal day summaries according to the filter.
+func (s *Service) AddressWithdrawalDaySummaries(ctx context.Context,
+	filter *chaindb.WithdrawalDaySummaryFilter,
+) (
+	[]*chaindb.AddressWithdrawalDaySummary,
+	error,
+) {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "AddressWithdrawalDaySummaries")
+	defer span.End()
+	defer monitorQuery("AddressWithdrawalDaySummaries", time.Now())
+
+	tx := s.tx(ctx)
+	if tx == nil {
+		ctx, err := s.BeginROTx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to begin transaction")
+		}
+		defer s.CommitROTx(ctx)
+		tx = s.tx(ctx)
+	}
+
+	// Build the query.
+	queryBuilder := strings.Builder{}
+	queryVals := make([]interface{}, 0)
+
+	queryBuilder.WriteString(`
+SELECT f_address
+      ,f_start_timestamp
+      ,COUNT(*)
+      ,SUM(f_partial_withdrawals)::BIGINT
+      ,SUM(f_partial_amount)::BIGINT
+      ,SUM(f_full_withdrawals)::BIGINT
+      ,SUM(f_full_amount)::BIGINT
+FROM t_withdrawal_day_summaries`)
+
+	queryVals = withdrawalDaySummaryFilterSQL(&queryBuilder, queryVals, filter)
+
+	queryBuilder.WriteString(`
+GROUP BY f_address,f_start_timestamp`)
+
+	switch filter.Order {
+	case chaindb.OrderEarliest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp, f_address`)
+	case chaindb.OrderLatest:
+		queryBuilder.WriteString(`
+ORDER BY f_start_timestamp DESC,f_address DESC`)
+	default:
+		return nil, errors.New("no order specified")
+	}
+
+	if filter.Limit > 0 {
+		queryVals = append(queryVals, filter.Limit)
+		queryBuilder.WriteString(fmt.Sprintf(`
+LIMIT $%d`, len(queryVals)))
+	}
+
+	if e := log.Trace(); e.Enabled() {
+		params := make([]string, len(queryVals))
+		for i := range queryVals {
+			params[i] = fmt.Sprintf("%v", queryVals[i])
+		}
+		e.Str("query", strings.ReplaceAll(queryBuilder.String(), "\n", " ")).Strs("params", params).Msg("SQL query")
+	}
+
+	rows, err := tx.Query(ctx,
+		queryBuilder.String(),
+		queryVals...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make([]*chaindb.AddressWithdrawalDaySummary, 0)
+	address := make([]byte, bellatrix.ExecutionAddressLength)
+	for rows.Next() {
+		summary := &chaindb.AddressWithdrawalDaySummary{}
+		err := rows.Scan(
+			&address,
+			&summary.StartTimestamp,
+			&summary.Validators,
+			&summary.PartialWithdrawals,
+			&summary.PartialAmount,
+			&summary.FullWithdrawals,
+			&summary.FullAmount,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan row")
+		}
+		copy(summary.Address[:], address)
+		summaries = append(summaries, summary)
+	}
+
+	// Always return order of start timestamp then address.
+	sort.Slice(summaries, func(i int, j int) bool {
+		if !summaries[i].StartTimestamp.Equal(summaries[j].StartTimestamp) {
+			return summaries[i].StartTimestamp.Before(summaries[j].StartTimestamp)
+		}
+		return string(summaries[i].Address[:]) < string(summaries[j].Address[:])
+	})
+	return summaries, nil
+}
+
+// withdrawalDaySummaryFilterSQL adds the WHERE clause for a withdrawal day summary filter to a query.
+func withdrawalDaySummaryFilterSQL(queryBuilder *strings.Builder,
+	queryVals []interface{},
+	filter *chaindb.WithdrawalDaySummaryFilter,
+) []interface{} {
+	wherestr := "WHERE"
+
+	if filter.From != nil {
+		queryVals = append(queryVals, *filter.From)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_start_timestamp >= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.To != nil {
+		queryVals = append(queryVals, *filter.To)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_start_timestamp <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.ValidatorIndices) > 0 {
+		queryVals = append(queryVals, filter.ValidatorIndices)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_validator_index = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.Addresses) > 0 {
+		addresses := make([][]byte, len(filter.Addresses))
+		for i := range filter.Addresses {
+			addresses[i] = filter.Addresses[i][:]
+		}
+		queryVals = append(queryVals, addresses)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s f_address = ANY($%d)`, wherestr, len(queryVals)))
+	}
+
+	return queryVals
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -26,10 +27,26 @@ import (
 	"go.opentelemetry.io/otel"
 )
 
+// withdrawalTypeSQL is the SQL expression that classifies a withdrawal, given the number of slots
+// per epoch as the first parameter.
+// A withdrawal is full if it was made at or after the validator's withdrawable epoch, and partial otherwise.
+var withdrawalTypeSQL = fmt.Sprintf(`
+CASE
+  WHEN t_validators.f_index IS NULL THEN %d
+  WHEN t_validators.f_withdrawable_epoch IS NOT NULL
+   AND t_block_withdrawals.f_block_number >= t_validators.f_withdrawable_epoch * $1 THEN %d
+  ELSE %d
+END`,
+	chaindb.WithdrawalTypeUnknown,
+	chaindb.WithdrawalTypeFull,
+	chaindb.WithdrawalTypePartial,
+)
+
 // setWithdrawals sets the withdrawals of a block.
 func (s *Service) setWithdrawals(ctx context.Context, block *chaindb.Block) error {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "setWithdrawals")
 	defer span.End()
+	defer monitorQuery("setWithdrawals", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -91,6 +108,7 @@ SET f_withdrawal_index = excluded.f_withdrawal_index
 func (s *Service) Withdrawals(ctx context.Context, filter *chaindb.WithdrawalFilter) ([]*chaindb.Withdrawal, error) {
 	ctx, span := otel.Tracer("wealdtech.chaind.services.chaindb.postgresql").Start(ctx, "Withdrawals")
 	defer span.End()
+	defer monitorQuery("Withdrawals", time.Now())
 
 	tx := s.tx(ctx)
 	if tx == nil {
@@ -102,48 +120,87 @@ func (s *Service) Withdrawals(ctx context.Context, filter *chaindb.WithdrawalFil
 		tx = s.tx(ctx)
 	}
 
+	spec, err := s.ChainSpec(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain chain spec")
+	}
+	slotsPerEpoch, exists := spec["SLOTS_PER_EPOCH"].(uint64)
+	if !exists {
+		return nil, errors.New("SLOTS_PER_EPOCH not found in spec")
+	}
+
 	// Build the query.
 	queryBuilder := strings.Builder{}
 	queryVals := make([]interface{}, 0)
 
-	queryBuilder.WriteString(`
-SELECT f_block_root
-      ,f_block_number
-      ,f_index
-      ,f_withdrawal_index
-      ,f_validator_index
-      ,f_address
-      ,f_amount
-FROM t_block_withdrawals`)
+	queryVals = append(queryVals, slotsPerEpoch)
+	queryBuilder.WriteString(fmt.Sprintf(`
+SELECT t_block_withdrawals.f_block_root
+      ,t_block_withdrawals.f_block_number
+      ,t_block_withdrawals.f_index
+      ,t_block_withdrawals.f_withdrawal_index
+      ,t_block_withdrawals.f_validator_index
+      ,t_block_withdrawals.f_address
+      ,t_block_withdrawals.f_amount
+      ,withdrawal_types.f_type
+FROM t_block_withdrawals
+LEFT JOIN t_validators ON t_validators.f_index = t_block_withdrawals.f_validator_index
+CROSS JOIN LATERAL (SELECT %s AS f_type) AS withdrawal_types`, withdrawalTypeSQL))
 
 	wherestr := "WHERE"
 
 	if filter.From != nil {
 		queryVals = append(queryVals, *filter.From)
 		queryBuilder.WriteString(fmt.Sprintf(`
-%s f_block_number >= $%d`, wherestr, len(queryVals)))
+%s t_block_withdrawals.f_block_number >= $%d`, wherestr, len(queryVals)))
 		wherestr = "  AND"
 	}
 
 	if filter.To != nil {
 		queryVals = append(queryVals, *filter.To)
 		queryBuilder.WriteString(fmt.Sprintf(`
-%s f_block_number <= $%d`, wherestr, len(queryVals)))
+%s t_block_withdrawals.f_block_number <= $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
 	}
 
 	if len(filter.ValidatorIndices) > 0 {
 		queryVals = append(queryVals, filter.ValidatorIndices)
 		queryBuilder.WriteString(fmt.Sprintf(`
-%s f_validator_index = ANY($%d)`, wherestr, len(queryVals)))
+%s t_block_withdrawals.f_validator_index = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if len(filter.Addresses) > 0 {
+		addresses := make([][]byte, len(filter.Addresses))
+		for i := range filter.Addresses {
+			addresses[i] = filter.Addresses[i][:]
+		}
+		queryVals = append(queryVals, addresses)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s t_block_withdrawals.f_address = ANY($%d)`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.Type != nil {
+		queryVals = append(queryVals, *filter.Type)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s withdrawal_types.f_type = $%d`, wherestr, len(queryVals)))
+		wherestr = "  AND"
+	}
+
+	if filter.MinAmount != nil {
+		queryVals = append(queryVals, *filter.MinAmount)
+		queryBuilder.WriteString(fmt.Sprintf(`
+%s t_block_withdrawals.f_amount >= $%d`, wherestr, len(queryVals)))
 	}
 
 	switch filter.Order {
 	case chaindb.OrderEarliest:
 		queryBuilder.WriteString(`
-ORDER BY f_block_number, f_index`)
+ORDER BY t_block_withdrawals.f_block_number, t_block_withdrawals.f_index`)
 	case chaindb.OrderLatest:
 		queryBuilder.WriteString(`
-ORDER BY f_block_number DESC,f_index DESC`)
+ORDER BY t_block_withdrawals.f_block_number DESC,t_block_withdrawals.f_index DESC`)
 	default:
 		return nil, errors.New("no order specified")
 	}
@@ -184,6 +241,7 @@ LIMIT $%d`, len(queryVals)))
 			&withdrawal.ValidatorIndex,
 			&address,
 			&withdrawal.Amount,
+			&withdrawal.Type,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
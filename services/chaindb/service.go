@@ -15,6 +15,7 @@ package chaindb
 
 import (
 	"context"
+	"time"
 
 	api "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -51,6 +52,12 @@ type AttestationsSetter interface {
 	SetAttestations(ctx context.Context, attestations []*Attestation) error
 }
 
+// AttestationsPruner defines functions to prune attestations.
+type AttestationsPruner interface {
+	// PruneAttestations prunes attestations for slots up to (but not including) the given slot.
+	PruneAttestations(ctx context.Context, to phase0.Slot) error
+}
+
 // AttesterSlashingsProvider defines functions to obtain attester slashings.
 type AttesterSlashingsProvider interface {
 	// AttesterSlashingsForSlotRange fetches all attester slashings made for the given slot range.
@@ -87,8 +94,17 @@ type BeaconCommitteesSetter interface {
 	SetBeaconCommittee(ctx context.Context, beaconCommittee *BeaconCommittee) error
 }
 
+// BeaconCommitteesPruner defines functions to prune beacon committee information.
+type BeaconCommitteesPruner interface {
+	// PruneBeaconCommittees prunes beacon committees for slots up to (but not including) the given slot.
+	PruneBeaconCommittees(ctx context.Context, to phase0.Slot) error
+}
+
 // BlocksProvider defines functions to access blocks.
 type BlocksProvider interface {
+	// Blocks provides blocks according to the filter.
+	Blocks(ctx context.Context, filter *BlockFilter) ([]*Block, error)
+
 	// BlocksBySlot fetches all blocks with the given slot.
 	BlocksBySlot(ctx context.Context, slot phase0.Slot) ([]*Block, error)
 
@@ -179,6 +195,33 @@ type ETH1DepositsSetter interface {
 	SetETH1Deposit(ctx context.Context, deposit *ETH1Deposit) error
 }
 
+// PendingDepositsProvider defines functions to access deposits that have yet to be included in the beacon chain.
+type PendingDepositsProvider interface {
+	// PendingDeposits provides Ethereum 1 deposits that have yet to be included in the beacon chain, according to the filter.
+	PendingDeposits(ctx context.Context, filter *PendingDepositFilter) ([]*PendingDeposit, error)
+}
+
+// ETH1VotePeriodsProvider defines functions to access Ethereum 1 vote periods.
+type ETH1VotePeriodsProvider interface {
+	// ETH1VotePeriods provides Ethereum 1 vote periods according to the filter.
+	ETH1VotePeriods(ctx context.Context, filter *ETH1VotePeriodFilter) ([]*ETH1VotePeriod, error)
+}
+
+// ETH1VotePeriodsSetter defines functions to create and update Ethereum 1 vote periods.
+type ETH1VotePeriodsSetter interface {
+	// SetETH1VotePeriod sets an Ethereum 1 vote period.
+	SetETH1VotePeriod(ctx context.Context, period *ETH1VotePeriod) error
+}
+
+// ETH1DepositsPruner defines functions to prune Ethereum 1 deposits.
+type ETH1DepositsPruner interface {
+	// PruneETH1DepositsFromBlock prunes Ethereum 1 deposits from (and including) the given block number.
+	PruneETH1DepositsFromBlock(ctx context.Context, blockNumber uint64) error
+
+	// PruneProvisionalETH1Deposits prunes all provisional Ethereum 1 deposits.
+	PruneProvisionalETH1Deposits(ctx context.Context) error
+}
+
 // ProposerDutiesProvider defines functions to access proposer duties.
 type ProposerDutiesProvider interface {
 	// ProposerDutiesForSlotRange fetches all proposer duties for the given slot range.
@@ -225,6 +268,12 @@ type SyncAggregateSetter interface {
 	SetSyncAggregate(ctx context.Context, syncAggregate *SyncAggregate) error
 }
 
+// SyncAggregatePruner defines functions to prune sync aggregate information.
+type SyncAggregatePruner interface {
+	// PruneSyncAggregates prunes sync aggregates included in slots up to (but not including) the given slot.
+	PruneSyncAggregates(ctx context.Context, to phase0.Slot) error
+}
+
 // ValidatorsProvider defines functions to access validator information.
 type ValidatorsProvider interface {
 	// Validators fetches all validators.
@@ -346,6 +395,18 @@ type DepositsProvider interface {
 	DepositsForSlotRange(ctx context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]*Deposit, error)
 }
 
+// ReconciledDepositsProvider defines functions to access reconciled deposits.
+type ReconciledDepositsProvider interface {
+	// ReconciledDeposits provides reconciled deposits according to the filter.
+	ReconciledDeposits(ctx context.Context, filter *ReconciledDepositFilter) ([]*ReconciledDeposit, error)
+}
+
+// ReconciledDepositsSetter defines functions to create and update reconciled deposits.
+type ReconciledDepositsSetter interface {
+	// SetReconciledDeposits sets multiple reconciled deposits.
+	SetReconciledDeposits(ctx context.Context, deposits []*ReconciledDeposit) error
+}
+
 // DepositsSetter defines functions to create and update deposits.
 type DepositsSetter interface {
 	// SetDeposit sets a deposit.
@@ -364,6 +425,12 @@ type ValidatorDaySummariesProvider interface {
 	ValidatorDaySummaries(ctx context.Context, filter *ValidatorDaySummaryFilter) ([]*ValidatorDaySummary, error)
 }
 
+// AttestationEffectivenessProvider defines functions to fetch aggregate attestation effectiveness.
+type AttestationEffectivenessProvider interface {
+	// AttestationEffectiveness provides the aggregate attestation effectiveness of the validators and days matching the filter.
+	AttestationEffectiveness(ctx context.Context, filter *ValidatorDaySummaryFilter) (*AttestationEffectivenessSummary, error)
+}
+
 // ValidatorDaySummariesSetter defines functions to create and update validator day summaries.
 type ValidatorDaySummariesSetter interface {
 	// SetValidatorDaySummary sets a validator day summary.
@@ -389,6 +456,9 @@ type ValidatorEpochSummariesProvider interface {
 type ValidatorEpochSummariesPruner interface {
 	// PruneValidatorEpochSummaries prunes validator epoch summaries up to (but not including) the given point.
 	PruneValidatorEpochSummaries(ctx context.Context, to phase0.Epoch, retain []phase0.ValidatorIndex) error
+
+	// PruneProvisionalValidatorEpochSummaries prunes provisional validator epoch summaries up to and including the given epoch.
+	PruneProvisionalValidatorEpochSummaries(ctx context.Context, to phase0.Epoch) error
 }
 
 // ValidatorEpochSummariesSetter defines functions to create and update validator epoch summaries.
@@ -430,18 +500,93 @@ type SyncCommitteesSetter interface {
 	SetSyncCommittee(ctx context.Context, syncCommittee *SyncCommittee) error
 }
 
+// SyncCommitteesPruner defines functions to prune sync committee information.
+type SyncCommitteesPruner interface {
+	// PruneSyncCommittees prunes sync committees for periods up to (but not including) the given period.
+	PruneSyncCommittees(ctx context.Context, to uint64) error
+}
+
 // WithdrawalsProvider defines functions to fetch withdrawals.
 type WithdrawalsProvider interface {
 	// Withdrawals provides withdrawals according to the filter.
 	Withdrawals(ctx context.Context, filter *WithdrawalFilter) ([]*Withdrawal, error)
 }
 
+// ClientSummariesProvider defines functions to fetch client summaries.
+type ClientSummariesProvider interface {
+	// ClientEpochSummaries provides client epoch summaries according to the filter.
+	ClientEpochSummaries(ctx context.Context, filter *ClientEpochSummaryFilter) ([]*ClientEpochSummary, error)
+
+	// ClientDaySummaries provides client day summaries according to the filter.
+	ClientDaySummaries(ctx context.Context, filter *ClientDaySummaryFilter) ([]*ClientDaySummary, error)
+}
+
+// ClientSummariesSetter defines functions to create and update client summaries.
+type ClientSummariesSetter interface {
+	// SetClientEpochSummaries sets the client summaries for an epoch, replacing any existing summaries.
+	SetClientEpochSummaries(ctx context.Context, epoch phase0.Epoch, summaries []*ClientEpochSummary) error
+
+	// SetClientDaySummaries sets the client summaries for a day, replacing any existing summaries.
+	SetClientDaySummaries(ctx context.Context, startTimestamp time.Time, summaries []*ClientDaySummary) error
+}
+
+// WithdrawalDaySummariesProvider defines functions to fetch withdrawal day summaries.
+type WithdrawalDaySummariesProvider interface {
+	// WithdrawalDaySummaries provides per-validator summaries according to the filter.
+	WithdrawalDaySummaries(ctx context.Context, filter *WithdrawalDaySummaryFilter) ([]*WithdrawalDaySummary, error)
+
+	// AddressWithdrawalDaySummaries provides per-address summaries according to the filter.
+	AddressWithdrawalDaySummaries(ctx context.Context, filter *WithdrawalDaySummaryFilter) ([]*AddressWithdrawalDaySummary, error)
+}
+
+// WithdrawalDaySummariesSetter defines functions to create and update withdrawal day summaries.
+type WithdrawalDaySummariesSetter interface {
+	// SetWithdrawalDaySummaries sets multiple withdrawal day summaries.
+	SetWithdrawalDaySummaries(ctx context.Context, summaries []*WithdrawalDaySummary) error
+}
+
 // BLSToExecutionChangesProvider defines functions to fetch credential changes.
 type BLSToExecutionChangesProvider interface {
 	// BLSToExecutionChanges provides credential changes according to the filter.
 	BLSToExecutionChanges(ctx context.Context, filter *BLSToExecutionChangeFilter) ([]*BLSToExecutionChange, error)
 }
 
+// RelayPayloadsProvider defines functions to access MEV relay payloads.
+type RelayPayloadsProvider interface {
+	// RelayPayloads provides relay payloads according to the filter.
+	RelayPayloads(ctx context.Context, filter *RelayPayloadFilter) ([]*RelayPayload, error)
+
+	// BlockProductions provides canonical blocks with execution payloads, linked to any relay
+	// payloads that provided them, according to the filter.
+	BlockProductions(ctx context.Context, filter *BlockProductionFilter) ([]*BlockProduction, error)
+}
+
+// RelayPayloadsSetter defines functions to create and update MEV relay payloads.
+type RelayPayloadsSetter interface {
+	// SetRelayPayloads sets multiple relay payloads.
+	SetRelayPayloads(ctx context.Context, payloads []*RelayPayload) error
+}
+
+// ValidatorPeriodSummariesProvider defines functions to fetch validator period summaries.
+type ValidatorPeriodSummariesProvider interface {
+	// ValidatorPeriodSummaries provides summaries according to the filter.
+	ValidatorPeriodSummaries(ctx context.Context, filter *ValidatorPeriodSummaryFilter) ([]*ValidatorPeriodSummary, error)
+}
+
+// ValidatorPeriodSummariesSetter defines functions to create and update validator period summaries.
+type ValidatorPeriodSummariesSetter interface {
+	// SetValidatorPeriodSummaries sets the validator summaries for a period, replacing any existing summaries.
+	SetValidatorPeriodSummaries(ctx context.Context, period string, startTimestamp time.Time, summaries []*ValidatorPeriodSummary) error
+}
+
+// NestedTxBeginner defines functions to begin transactions that can contain nested transactions.
+type NestedTxBeginner interface {
+	// BeginNestedTx begins a transaction within which calls to BeginTx create nested transactions
+	// rather than independent transactions.  Nested transactions are only persisted when the
+	// outer transaction is committed.
+	BeginNestedTx(ctx context.Context) (context.Context, context.CancelFunc, error)
+}
+
 // Service defines a minimal chain database service.
 type Service interface {
 	// BeginTx begins a transaction.
@@ -60,6 +60,8 @@ type ValidatorBalance struct {
 	Epoch            phase0.Epoch
 	Balance          phase0.Gwei
 	EffectiveBalance phase0.Gwei
+	// InactivityScore is the validator's inactivity score, or nil if not known.
+	InactivityScore *uint64
 }
 
 // AggregateValidatorBalance holds aggreated information about validators' balances at a given epoch.
@@ -144,6 +146,60 @@ type ETH1Deposit struct {
 	WithdrawalCredentials []byte
 	Signature             phase0.BLSSignature
 	Amount                phase0.Gwei
+	// Provisional is true if the deposit's block has not yet reached the required number of confirmations.
+	Provisional bool
+}
+
+// PendingDeposit holds information about an Ethereum 1 deposit that has yet to be included in the beacon chain.
+type PendingDeposit struct {
+	ETH1Deposit *ETH1Deposit
+	// ETH1DepositCount is the deposit count voted in to the beacon chain as of the latest block.
+	ETH1DepositCount uint64
+	// EstimatedInclusion is the estimated time at which the deposit will be included in the beacon chain.
+	EstimatedInclusion time.Time
+}
+
+// DepositType is the type of a reconciled deposit.
+type DepositType uint8
+
+const (
+	// DepositTypeUnknown is a deposit whose type has not been determined.
+	DepositTypeUnknown DepositType = iota
+	// DepositTypeInitial is a deposit that creates a new validator.
+	DepositTypeInitial
+	// DepositTypeTopUp is a deposit that adds to the balance of an existing validator.
+	DepositTypeTopUp
+	// DepositTypeInvalid is a deposit for a new validator with an invalid signature, which is ignored by the beacon chain.
+	DepositTypeInvalid
+)
+
+// String returns a string representation of the deposit type.
+func (t DepositType) String() string {
+	switch t {
+	case DepositTypeInitial:
+		return "initial"
+	case DepositTypeTopUp:
+		return "top-up"
+	case DepositTypeInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconciledDeposit links an Ethereum 1 deposit to its inclusion in the beacon chain.
+type ReconciledDeposit struct {
+	DepositIndex       uint64
+	ValidatorPubKey    phase0.BLSPubKey
+	Amount             phase0.Gwei
+	ETH1BlockNumber    uint64
+	ETH1TxHash         []byte
+	ETH1LogIndex       uint64
+	InclusionSlot      phase0.Slot
+	InclusionBlockRoot phase0.Root
+	InclusionIndex     uint64
+	SignatureValid     bool
+	Type               DepositType
 }
 
 // VoluntaryExit holds information about a voluntary exit included in a block.
@@ -214,6 +270,15 @@ type ValidatorEpochSummary struct {
 	AttestationSourceTimely   *bool
 	AttestationTargetTimely   *bool
 	AttestationHeadTimely     *bool
+	// AttestationEffectiveness is the ratio of the earliest possible inclusion delay, given the
+	// canonical blocks following the attestation slot, to the actual inclusion delay.
+	AttestationEffectiveness *float64
+	// InactivityPenalty is the inactivity penalty applied to the validator for its attestation
+	// in this epoch, or nil if not known.
+	InactivityPenalty *phase0.Gwei
+	// Provisional is true if the summary was generated from the head chain before the epoch
+	// was finalized, in which case it will be replaced when the epoch is finalized.
+	Provisional bool
 }
 
 // ValidatorDaySummary provides a summary of a validator's operations for a day.
@@ -237,6 +302,51 @@ type ValidatorDaySummary struct {
 	AttestationsInclusionDelay    float64
 	SyncCommitteeMessages         int
 	SyncCommitteeMessagesIncluded int
+	// AttestationsEffectiveness is the mean attestation effectiveness of included attestations,
+	// or nil if not known.
+	AttestationsEffectiveness *float64
+	// InactivityPenalties is the total of the known inactivity penalties applied to the validator.
+	InactivityPenalties uint64
+}
+
+// ValidatorPeriodSummary provides a summary of a validator's operations for a period made up of whole days.
+type ValidatorPeriodSummary struct {
+	// Period is the length of the period as an ISO 8601 duration, for example "P1M".
+	Period                        string
+	Index                         phase0.ValidatorIndex
+	StartTimestamp                time.Time
+	EndTimestamp                  time.Time
+	StartBalance                  uint64
+	StartEffectiveBalance         uint64
+	CapitalChange                 int64
+	RewardChange                  int64
+	EffectiveBalanceChange        int64
+	Proposals                     int
+	ProposalsIncluded             int
+	Attestations                  int
+	AttestationsIncluded          int
+	AttestationsTargetCorrect     int
+	AttestationsHeadCorrect       int
+	AttestationsSourceTimely      int
+	AttestationsTargetTimely      int
+	AttestationsHeadTimely        int
+	AttestationsInclusionDelay    float64
+	SyncCommitteeMessages         int
+	SyncCommitteeMessagesIncluded int
+	// AttestationsEffectiveness is the mean attestation effectiveness of included attestations,
+	// or nil if not known.
+	AttestationsEffectiveness *float64
+	// InactivityPenalties is the total of the known inactivity penalties applied to the validator.
+	InactivityPenalties uint64
+}
+
+// AttestationEffectivenessSummary provides the aggregate attestation effectiveness of a set of validators.
+type AttestationEffectivenessSummary struct {
+	Validators           int
+	Attestations         int
+	AttestationsIncluded int
+	// Effectiveness is the mean effectiveness of included attestations, or nil if not known.
+	Effectiveness *float64
 }
 
 // BlockSummary provides a summary of an epoch.
@@ -246,6 +356,29 @@ type BlockSummary struct {
 	DuplicateAttestationsForBlock int
 	VotesForBlock                 int
 	ParentDistance                int
+	// ConsensusReward is the consensus reward obtained by the proposer for the block, or nil if not known.
+	ConsensusReward *BlockReward
+	// ConsensusClient and ExecutionClient are the clients that produced the block, as decoded
+	// from its graffiti, or empty if not classified.
+	ConsensusClient string
+	ExecutionClient string
+	// NewVotes is the number of attester votes included in the block that had not been included in
+	// an earlier canonical block.
+	NewVotes *int
+	// AvailableVotes is the number of attester votes within the inclusion window that had not been
+	// included in an earlier canonical block, and so were available for inclusion in this block.
+	AvailableVotes *int
+	// RedundantAggregates is the number of aggregates in the block that contained no new votes.
+	RedundantAggregates *int
+}
+
+// BlockReward provides the consensus reward obtained by a proposer for a block.
+type BlockReward struct {
+	Total             phase0.Gwei
+	Attestations      phase0.Gwei
+	SyncAggregate     phase0.Gwei
+	ProposerSlashings phase0.Gwei
+	AttesterSlashings phase0.Gwei
 }
 
 // EpochSummary provides a summary of an epoch.
@@ -270,6 +403,8 @@ type EpochSummary struct {
 	Deposits                      int
 	ExitingValidators             int
 	CanonicalBlocks               int
+	// InactivityLeak is true if the chain was in an inactivity leak, or nil if not known.
+	InactivityLeak *bool
 }
 
 // SyncCommittee holds information for sync committees.
@@ -307,6 +442,30 @@ type BLSToExecutionChange struct {
 	ToExecutionAddress [20]byte
 }
 
+// WithdrawalType is the type of a withdrawal.
+type WithdrawalType uint8
+
+const (
+	// WithdrawalTypeUnknown is a withdrawal for which the validator is not known.
+	WithdrawalTypeUnknown WithdrawalType = iota
+	// WithdrawalTypePartial is a withdrawal of excess balance from a validator that is not yet withdrawable.
+	WithdrawalTypePartial
+	// WithdrawalTypeFull is a withdrawal of the entire balance of a withdrawable validator.
+	WithdrawalTypeFull
+)
+
+// String returns a string representation of the withdrawal type.
+func (t WithdrawalType) String() string {
+	switch t {
+	case WithdrawalTypePartial:
+		return "partial"
+	case WithdrawalTypeFull:
+		return "full"
+	default:
+		return "unknown"
+	}
+}
+
 // Withdrawal holds information about a withdrawal from consensus to execution layer.
 type Withdrawal struct {
 	InclusionBlockRoot phase0.Root
@@ -316,4 +475,93 @@ type Withdrawal struct {
 	ValidatorIndex     phase0.ValidatorIndex
 	Address            [20]byte
 	Amount             phase0.Gwei
+	// Type is derived from the validator's withdrawable epoch when the withdrawal is fetched.
+	Type WithdrawalType
+}
+
+// WithdrawalDaySummary provides a summary of a validator's withdrawals for a day.
+type WithdrawalDaySummary struct {
+	ValidatorIndex     phase0.ValidatorIndex
+	StartTimestamp     time.Time
+	Address            [20]byte
+	PartialWithdrawals int
+	PartialAmount      phase0.Gwei
+	FullWithdrawals    int
+	FullAmount         phase0.Gwei
+}
+
+// AddressWithdrawalDaySummary provides a summary of the withdrawals to an address for a day.
+type AddressWithdrawalDaySummary struct {
+	Address            [20]byte
+	StartTimestamp     time.Time
+	Validators         int
+	PartialWithdrawals int
+	PartialAmount      phase0.Gwei
+	FullWithdrawals    int
+	FullAmount         phase0.Gwei
+}
+
+// ClientEpochSummary provides a summary of the clients that proposed canonical blocks in an epoch.
+type ClientEpochSummary struct {
+	Epoch           phase0.Epoch
+	ConsensusClient string
+	ExecutionClient string
+	Proposals       int
+}
+
+// ClientDaySummary provides a summary of the clients that proposed canonical blocks in a day.
+type ClientDaySummary struct {
+	StartTimestamp  time.Time
+	ConsensusClient string
+	ExecutionClient string
+	Proposals       int
+}
+
+// ETH1VotePeriod holds information about the votes for Ethereum 1 data in a voting period.
+type ETH1VotePeriod struct {
+	Period     uint64
+	StartEpoch phase0.Epoch
+	// Blocks is the number of canonical blocks, and hence votes, in the period.
+	Blocks        int
+	DistinctVotes int
+	// Winning values are those of the Ethereum 1 data with the most votes.
+	WinningETH1BlockHash    []byte
+	WinningETH1DepositCount uint64
+	WinningETH1DepositRoot  phase0.Root
+	WinningVotes            int
+	// MajorityEpoch is the epoch in which the winning vote obtained a majority, or nil if it did not.
+	MajorityEpoch *phase0.Epoch
+	// MinorityProposers are the proposers of blocks that voted for data other than the winning data.
+	MinorityProposers []phase0.ValidatorIndex
+	// StaleProposers are the proposers of blocks that voted for data with a lower deposit count than
+	// that already accepted by the chain.
+	StaleProposers []phase0.ValidatorIndex
+}
+
+// RelayPayload holds information about an execution payload delivered to a proposer by an MEV relay.
+type RelayPayload struct {
+	Relay                string
+	Slot                 phase0.Slot
+	BlockHash            [32]byte
+	BlockNumber          uint64
+	ParentHash           [32]byte
+	BuilderPubKey        phase0.BLSPubKey
+	ProposerPubKey       phase0.BLSPubKey
+	ProposerFeeRecipient [20]byte
+	GasLimit             uint64
+	GasUsed              uint64
+	Value                *big.Int
+	NumTx                uint64
+	// BidTimestamp is the time at which the relay received the bid from the builder, or nil if not known.
+	BidTimestamp *time.Time
+}
+
+// BlockProduction links a block to the MEV relay payloads, if any, that provided its execution payload.
+type BlockProduction struct {
+	Slot               phase0.Slot
+	Root               phase0.Root
+	ProposerIndex      phase0.ValidatorIndex
+	ExecutionBlockHash [32]byte
+	// RelayPayloads are the payloads delivered by relays for this block.  If empty the block was built locally.
+	RelayPayloads []*RelayPayload
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
+	"github.com/wealdtech/chaind/util"
 )
 
 // Service provides chain time services.
@@ -38,6 +39,9 @@ type Service struct {
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new controller.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -46,7 +50,8 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "chaintime").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "chaintime").Str("impl", "standard").Logger())
 
 	genesisTime, err := parameters.genesisTimeProvider.GenesisTime(ctx)
 	if err != nil {
@@ -305,3 +310,8 @@ func fetchCapellaForkEpoch(ctx context.Context,
 
 	return phase0.Epoch(epoch), nil
 }
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
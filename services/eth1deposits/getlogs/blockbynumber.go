@@ -0,0 +1,81 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package getlogs
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type blockByNumberResponse struct {
+	Result *blockByNumberBlockResponse `json:"result"`
+}
+type blockByNumberBlockResponse struct {
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+}
+
+// blockHeader contains the information about a block required to track reorgs.
+type blockHeader struct {
+	Hash       []byte
+	ParentHash []byte
+}
+
+// blockByNumber fetches the header information of a block given its number.
+func (s *Service) blockByNumber(ctx context.Context, blockNumber uint64) (*blockHeader, error) {
+	reference, err := url.Parse("")
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid endpoint")
+	}
+	url := s.base.ResolveReference(reference).String()
+
+	reqBody := bytes.NewBuffer([]byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["%#x",false],"id":1901}`, blockNumber)))
+	respBodyReader, err := s.post(ctx, url, reqBody)
+	if err != nil {
+		log.Trace().Str("url", url).Err(err).Msg("Request failed")
+		return nil, errors.Wrap(err, "request failed")
+	}
+	if respBodyReader == nil {
+		return nil, errors.New("empty response")
+	}
+
+	var response blockByNumberResponse
+	if err := json.NewDecoder(respBodyReader).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "invalid response")
+	}
+	if response.Result == nil {
+		return nil, errors.New("empty response")
+	}
+
+	hash, err := hex.DecodeString(strings.TrimPrefix(response.Result.Hash, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid hash")
+	}
+	parentHash, err := hex.DecodeString(strings.TrimPrefix(response.Result.ParentHash, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid parent hash")
+	}
+
+	return &blockHeader{
+		Hash:       hash,
+		ParentHash: parentHash,
+	}, nil
+}
@@ -25,57 +25,51 @@ import (
 )
 
 // handleBlocks handles a range of blocks.
-func (s *Service) handleBlocks(ctx context.Context, startBlock uint64, endBlock uint64) error {
+// If provisional is true the deposits are marked as provisional.
+// The context must contain a transaction, so that the deposits are committed along with the
+// metadata that records them; if this returns an error the transaction should be cancelled.
+func (s *Service) handleBlocks(ctx context.Context, md *metadata, startBlock uint64, endBlock uint64, provisional bool) error {
 	logs, err := s.getLogs(ctx, startBlock, endBlock)
 	if err != nil {
 		return errors.Wrap(err, "failed to obtain logs")
 	}
 
-	ctx, cancel, err := s.eth1DepositsSetter.(chaindb.Service).BeginTx(ctx)
-	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
-	}
-
 	for _, logEntry := range logs {
 		if len(logEntry.Data) == 0 {
 			continue
 		}
+		if !matchesRecentBlock(md, logEntry.BlockNumber, logEntry.BlockHash) {
+			return fmt.Errorf("log entry block hash %#x does not match recorded hash for block %d", logEntry.BlockHash, logEntry.BlockNumber)
+		}
 
 		tx, err := s.transactionByHash(ctx, logEntry.TransactionHash)
 		if err != nil {
-			cancel()
 			return errors.Wrap(err, "failed to obtain transaction from transaction hash")
 		}
 		if tx == nil {
-			cancel()
 			return fmt.Errorf("no transaction returned for hash %#x", logEntry.TransactionHash)
 		}
 		receipt, err := s.transactionReceiptByHash(ctx, logEntry.TransactionHash)
 		if err != nil {
-			cancel()
 			return errors.Wrap(err, "failed to obtain transaction receipt from transaction hash")
 		}
 
 		deposit, err := s.depositFromLogEntry(ctx, logEntry, tx, receipt)
 		if err != nil {
-			cancel()
 			return errors.Wrap(err, "failed to obtain ETH1 deposit from log entry")
 		}
+		deposit.Provisional = provisional
 
 		if err := s.eth1DepositsSetter.SetETH1Deposit(ctx, deposit); err != nil {
-			cancel()
 			return errors.Wrap(err, "failed to set ETH1 deposit")
 		}
 		log.Trace().Uint64("deposit_index", deposit.DepositIndex).Msg("Processed deposit")
 	}
 
-	if err := s.eth1DepositsSetter.(chaindb.Service).CommitTx(ctx); err != nil {
-		cancel()
-		return errors.Wrap(err, "failed to commit transaction")
-	}
-
-	for block := startBlock; block < endBlock; block++ {
-		monitorBlockProcessed(block)
+	if !provisional {
+		for block := startBlock; block < endBlock; block++ {
+			monitorBlockProcessed(block)
+		}
 	}
 
 	return nil
@@ -92,7 +86,7 @@ func (s *Service) handleMissed(ctx context.Context, md *metadata) {
 			return
 		}
 
-		if err := s.handleBlocks(ctx, md.MissedBlocks[i], md.MissedBlocks[i]); err != nil {
+		if err := s.handleBlocks(ctx, md, md.MissedBlocks[i], md.MissedBlocks[i], false); err != nil {
 			log.Warn().Err(err).Msg("Failed to update block")
 			failed++
 			cancel()
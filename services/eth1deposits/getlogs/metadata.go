@@ -22,8 +22,15 @@ import (
 
 // metadata stored about this service.
 type metadata struct {
-	LatestBlock  uint64   `json:"latest_block"`
-	MissedBlocks []uint64 `json:"missed_blocks,omitempty"`
+	LatestBlock  uint64         `json:"latest_block"`
+	MissedBlocks []uint64       `json:"missed_blocks,omitempty"`
+	RecentBlocks []*recentBlock `json:"recent_blocks,omitempty"`
+}
+
+// recentBlock is a block processed by this service, retained to detect reorgs.
+type recentBlock struct {
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
 }
 
 // metadataKey is the key for the metadata.
@@ -27,6 +27,8 @@ var (
 	highestBlock    uint64
 	latestBlock     prometheus.Gauge
 	blocksProcessed prometheus.Gauge
+	reorgs          prometheus.Counter
+	reorgDepth      prometheus.Gauge
 )
 
 func registerMetrics(_ context.Context, monitor metrics.Service) error {
@@ -63,6 +65,24 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register blocks_processed")
 	}
 
+	reorgs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reorgs_total",
+		Help:      "Number of Ethereum 1 reorgs that required deposits to be rolled back",
+	})
+	if err := prometheus.Register(reorgs); err != nil {
+		return errors.Wrap(err, "failed to register reorgs_total")
+	}
+
+	reorgDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "reorg_depth",
+		Help:      "Number of blocks rolled back by the latest Ethereum 1 reorg",
+	})
+	if err := prometheus.Register(reorgDepth); err != nil {
+		return errors.Wrap(err, "failed to register reorg_depth")
+	}
+
 	return nil
 }
 
@@ -75,3 +95,10 @@ func monitorBlockProcessed(block uint64) {
 		}
 	}
 }
+
+func monitorReorg(depth uint64) {
+	if reorgs != nil {
+		reorgs.Inc()
+		reorgDepth.Set(float64(depth))
+	}
+}
@@ -23,13 +23,15 @@ import (
 )
 
 type parameters struct {
-	logLevel           zerolog.Level
-	monitor            metrics.Service
-	connectionURL      string
-	chainDB            chaindb.Service
-	eth1DepositsSetter chaindb.ETH1DepositsSetter
-	eth1Confirmations  uint64
-	startBlock         string
+	logLevel            zerolog.Level
+	monitor             metrics.Service
+	connectionURL       string
+	chainDB             chaindb.Service
+	eth1DepositsSetter  chaindb.ETH1DepositsSetter
+	eth1Confirmations   uint64
+	reorgWindow         uint64
+	provisionalDeposits bool
+	startBlock          string
 }
 
 // Parameter is the interface for service parameters.
@@ -78,6 +80,20 @@ func WithETH1Confirmations(confirmations uint64) Parameter {
 	})
 }
 
+// WithReorgWindow sets the number of recent blocks whose hashes are retained to detect reorgs.
+func WithReorgWindow(blocks uint64) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.reorgWindow = blocks
+	})
+}
+
+// WithProvisionalDeposits sets if deposits in blocks without sufficient confirmations are stored as provisional.
+func WithProvisionalDeposits(enabled bool) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.provisionalDeposits = enabled
+	})
+}
+
 // WithConnectionURL sets the Ethereum 1 connection URL service for this module.
 func WithConnectionURL(url string) Parameter {
 	return parameterFunc(func(p *parameters) {
@@ -96,7 +112,8 @@ func WithStartBlock(block string) Parameter {
 func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	parameters := parameters{
 		logLevel:          zerolog.GlobalLevel(),
-		eth1Confirmations: 12, // Default number of confirmations.
+		eth1Confirmations: 12,  // Default number of confirmations.
+		reorgWindow:       128, // Default number of blocks to check for reorgs.
 	}
 	for _, p := range params {
 		if params != nil {
@@ -110,6 +127,9 @@ func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	if parameters.eth1DepositsSetter == nil {
 		return nil, errors.New("no Ethereum 1 deposits setter specified")
 	}
+	if parameters.reorgWindow == 0 {
+		return nil, errors.New("reorg window must be at least 1 block")
+	}
 	if parameters.connectionURL == "" {
 		return nil, errors.New("no connection URL specified")
 	}
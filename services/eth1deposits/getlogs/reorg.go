@@ -0,0 +1,215 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package getlogs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+)
+
+// errReorg is returned when a reorg is detected whilst recording blocks.
+var errReorg = errors.New("reorg detected")
+
+// checkReorg checks the recent blocks against the Ethereum 1 chain, and rolls back
+// to the latest common block if they no longer match.
+func (s *Service) checkReorg(ctx context.Context, md *metadata) error {
+	if len(md.RecentBlocks) == 0 {
+		return nil
+	}
+
+	latest := md.RecentBlocks[len(md.RecentBlocks)-1]
+	header, err := s.blockByNumber(ctx, latest.Number)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain latest recent block")
+	}
+	if fmt.Sprintf("%#x", header.Hash) == latest.Hash {
+		// No reorg.
+		return nil
+	}
+
+	// Walk back through recent blocks to find the latest block still on the chain.
+	// If none are found the reorg is deeper than our window, so roll back all of it.
+	commonBlock := int64(md.RecentBlocks[0].Number) - 1
+	for i := len(md.RecentBlocks) - 2; i >= 0; i-- {
+		header, err := s.blockByNumber(ctx, md.RecentBlocks[i].Number)
+		if err != nil {
+			return errors.Wrap(err, "failed to obtain recent block")
+		}
+		if fmt.Sprintf("%#x", header.Hash) == md.RecentBlocks[i].Hash {
+			commonBlock = int64(md.RecentBlocks[i].Number)
+			break
+		}
+	}
+	if commonBlock == int64(md.RecentBlocks[0].Number)-1 {
+		log.Warn().Uint64("window_start", md.RecentBlocks[0].Number).Msg("Reorg is deeper than reorg window; rolling back entire window")
+	}
+	if commonBlock < 0 {
+		commonBlock = 0
+	}
+
+	return s.rollback(ctx, md, uint64(commonBlock))
+}
+
+// rollback removes all deposits after the given block and resets the metadata so
+// that they will be refetched.
+func (s *Service) rollback(ctx context.Context, md *metadata, commonBlock uint64) error {
+	if commonBlock > md.LatestBlock {
+		// Blocks after the latest block have not been processed, so there is nothing to roll back.
+		commonBlock = md.LatestBlock
+	}
+	log.Info().Uint64("common_block", commonBlock).Uint64("latest_block", md.LatestBlock).Msg("Reorg detected; rolling back")
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	if err := s.chainDB.(chaindb.ETH1DepositsPruner).PruneETH1DepositsFromBlock(ctx, commonBlock+1); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to prune deposits")
+	}
+
+	depth := md.LatestBlock - commonBlock
+	md.LatestBlock = commonBlock
+	recentBlocks := make([]*recentBlock, 0, len(md.RecentBlocks))
+	for _, block := range md.RecentBlocks {
+		if block.Number <= commonBlock {
+			recentBlocks = append(recentBlocks, block)
+		}
+	}
+	md.RecentBlocks = recentBlocks
+	missedBlocks := make([]uint64, 0, len(md.MissedBlocks))
+	for _, block := range md.MissedBlocks {
+		// Blocks after the common block will be refetched.
+		if block <= commonBlock {
+			missedBlocks = append(missedBlocks, block)
+		}
+	}
+	md.MissedBlocks = missedBlocks
+
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set metadata")
+	}
+
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	monitorReorg(depth)
+
+	return nil
+}
+
+// recordBlocks records the hashes of the blocks in the given range that fall within the
+// reorg window of the given head, returning errReorg if they do not build on the blocks
+// already recorded. On error the records of any unprocessed blocks are dropped.
+func (s *Service) recordBlocks(ctx context.Context, md *metadata, startBlock uint64, endBlock uint64, headBlock uint64) error {
+	if err := s.recordBlockRange(ctx, md, startBlock, endBlock, headBlock); err != nil {
+		dropUnprocessedBlocks(md)
+		return err
+	}
+
+	return nil
+}
+
+// recordBlockRange records the hashes of the blocks in the given range.
+func (s *Service) recordBlockRange(ctx context.Context, md *metadata, startBlock uint64, endBlock uint64, headBlock uint64) error {
+	windowStart := uint64(0)
+	if headBlock+1 > s.reorgWindow {
+		windowStart = headBlock + 1 - s.reorgWindow
+	}
+	if startBlock < windowStart {
+		startBlock = windowStart
+	}
+
+	// Remove any previous record of the blocks, for example from a failed attempt to process them.
+	for len(md.RecentBlocks) > 0 && md.RecentBlocks[len(md.RecentBlocks)-1].Number >= startBlock {
+		md.RecentBlocks = md.RecentBlocks[:len(md.RecentBlocks)-1]
+	}
+
+	for block := startBlock; block <= endBlock; block++ {
+		header, err := s.blockByNumber(ctx, block)
+		if err != nil {
+			return errors.Wrap(err, "failed to obtain block")
+		}
+		if len(md.RecentBlocks) > 0 {
+			parent := md.RecentBlocks[len(md.RecentBlocks)-1]
+			if parent.Number == block-1 && parent.Hash != fmt.Sprintf("%#x", header.ParentHash) {
+				return errReorg
+			}
+		}
+		md.RecentBlocks = append(md.RecentBlocks, &recentBlock{
+			Number: block,
+			Hash:   fmt.Sprintf("%#x", header.Hash),
+		})
+	}
+
+	// Trim to the window.
+	if uint64(len(md.RecentBlocks)) > s.reorgWindow {
+		md.RecentBlocks = md.RecentBlocks[uint64(len(md.RecentBlocks))-s.reorgWindow:]
+	}
+
+	return nil
+}
+
+// dropUnprocessedBlocks removes the records of blocks after the latest processed block, as
+// they may be from an abandoned chain and would otherwise be used when finding a common block.
+func dropUnprocessedBlocks(md *metadata) {
+	for len(md.RecentBlocks) > 0 && md.RecentBlocks[len(md.RecentBlocks)-1].Number > md.LatestBlock {
+		md.RecentBlocks = md.RecentBlocks[:len(md.RecentBlocks)-1]
+	}
+}
+
+// updateProvisionalDeposits replaces provisional deposits with those in blocks after the
+// latest confirmed block up to the given head.
+func (s *Service) updateProvisionalDeposits(ctx context.Context, md *metadata, headBlock uint64) error {
+	// Provisional deposits are pruned and replaced in the same transaction.
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	if err := s.chainDB.(chaindb.ETH1DepositsPruner).PruneProvisionalETH1Deposits(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to prune provisional deposits")
+	}
+
+	if headBlock > md.LatestBlock {
+		if err := s.handleBlocks(ctx, md, md.LatestBlock+1, headBlock, true); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to handle provisional blocks")
+		}
+	}
+
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// matchesRecentBlock returns false if the hash differs from the recorded hash for the block.
+func matchesRecentBlock(md *metadata, block uint64, hash []byte) bool {
+	for _, recent := range md.RecentBlocks {
+		if recent.Number == block {
+			return recent.Hash == fmt.Sprintf("%#x", hash)
+		}
+	}
+	return true
+}
@@ -0,0 +1,364 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package getlogs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testHash returns the hash of a block on either the original or the forked chain.
+func testHash(number uint64, forked bool) string {
+	tag := 0
+	if forked {
+		tag = 1
+	}
+	return fmt.Sprintf("0x%02x%062x", tag, number)
+}
+
+// testChain returns the headers of a chain up to the given head, with blocks after the fork block
+// replaced by those of a different chain.
+func testChain(head uint64, forkBlock uint64) map[uint64]*blockByNumberBlockResponse {
+	blocks := make(map[uint64]*blockByNumberBlockResponse)
+	for number := uint64(1); number <= head; number++ {
+		blocks[number] = &blockByNumberBlockResponse{
+			Hash:       testHash(number, number > forkBlock),
+			ParentHash: testHash(number-1, number-1 > forkBlock),
+		}
+	}
+	return blocks
+}
+
+// testRecentBlocks returns the recent blocks of the original chain in the given range.
+func testRecentBlocks(start uint64, end uint64) []*recentBlock {
+	blocks := make([]*recentBlock, 0)
+	for number := start; number <= end; number++ {
+		blocks = append(blocks, &recentBlock{
+			Number: number,
+			Hash:   testHash(number, false),
+		})
+	}
+	return blocks
+}
+
+// testBlockServer serves eth_getBlockByNumber requests from the given blocks.
+func testBlockServer(t *testing.T, blocks map[uint64]*blockByNumberBlockResponse) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_getBlockByNumber" || len(req.Params) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var numberStr string
+		if err := json.Unmarshal(req.Params[0], &numberStr); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		number, err := strconv.ParseUint(strings.TrimPrefix(numberStr, "0x"), 16, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// A missing block results in a null result.
+		if err := json.NewEncoder(w).Encode(&blockByNumberResponse{Result: blocks[number]}); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+}
+
+// testChainDB is a minimal chain database that records metadata and pruning.
+type testChainDB struct {
+	metadata   map[string][]byte
+	prunedFrom uint64
+	pruned     bool
+}
+
+func (d *testChainDB) BeginTx(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	return ctx, func() {}, nil
+}
+
+func (*testChainDB) CommitTx(_ context.Context) error {
+	return nil
+}
+
+func (*testChainDB) BeginROTx(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (*testChainDB) CommitROTx(_ context.Context) {}
+
+func (d *testChainDB) SetMetadata(_ context.Context, key string, value []byte) error {
+	d.metadata[key] = value
+	return nil
+}
+
+func (d *testChainDB) Metadata(_ context.Context, key string) ([]byte, error) {
+	return d.metadata[key], nil
+}
+
+func (d *testChainDB) PruneETH1DepositsFromBlock(_ context.Context, blockNumber uint64) error {
+	d.prunedFrom = blockNumber
+	d.pruned = true
+	return nil
+}
+
+func (*testChainDB) PruneProvisionalETH1Deposits(_ context.Context) error {
+	return nil
+}
+
+func testService(t *testing.T, chainDB *testChainDB, server *httptest.Server) *Service {
+	t.Helper()
+
+	base, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	return &Service{
+		chainDB:     chainDB,
+		timeout:     time.Second,
+		base:        base,
+		client:      server.Client(),
+		reorgWindow: 10,
+	}
+}
+
+func TestCheckReorg(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		md           *metadata
+		forkBlock    uint64
+		latestBlock  uint64
+		recentBlocks []*recentBlock
+		prunedFrom   uint64
+	}{
+		{
+			name: "NoReorg",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: testRecentBlocks(91, 100),
+			},
+			forkBlock:    100,
+			latestBlock:  100,
+			recentBlocks: testRecentBlocks(91, 100),
+		},
+		{
+			name: "Shallow",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: testRecentBlocks(91, 100),
+			},
+			forkBlock:    99,
+			latestBlock:  99,
+			recentBlocks: testRecentBlocks(91, 99),
+			prunedFrom:   100,
+		},
+		{
+			name: "MidRange",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: testRecentBlocks(91, 100),
+				MissedBlocks: []uint64{94, 97},
+			},
+			forkBlock:    95,
+			latestBlock:  95,
+			recentBlocks: testRecentBlocks(91, 95),
+			prunedFrom:   96,
+		},
+		{
+			name: "DeeperThanWindow",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: testRecentBlocks(91, 100),
+			},
+			forkBlock:    80,
+			latestBlock:  90,
+			recentBlocks: []*recentBlock{},
+			prunedFrom:   91,
+		},
+		{
+			name: "UnprocessedBlocks",
+			md: &metadata{
+				LatestBlock:  95,
+				RecentBlocks: testRecentBlocks(91, 100),
+			},
+			forkBlock:    97,
+			latestBlock:  95,
+			recentBlocks: testRecentBlocks(91, 95),
+			prunedFrom:   96,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := testBlockServer(t, testChain(110, test.forkBlock))
+			defer server.Close()
+			chainDB := &testChainDB{metadata: make(map[string][]byte)}
+			s := testService(t, chainDB, server)
+
+			require.NoError(t, s.checkReorg(ctx, test.md))
+			require.Equal(t, test.latestBlock, test.md.LatestBlock)
+			require.Equal(t, test.recentBlocks, test.md.RecentBlocks)
+			for _, missedBlock := range test.md.MissedBlocks {
+				require.LessOrEqual(t, missedBlock, test.latestBlock)
+			}
+			if test.prunedFrom == 0 {
+				require.False(t, chainDB.pruned)
+			} else {
+				require.True(t, chainDB.pruned)
+				require.Equal(t, test.prunedFrom, chainDB.prunedFrom)
+				// Metadata should have been persisted.
+				md, err := s.getMetadata(ctx)
+				require.NoError(t, err)
+				require.Equal(t, test.latestBlock, md.LatestBlock)
+				require.Len(t, md.RecentBlocks, len(test.recentBlocks))
+			}
+		})
+	}
+}
+
+func TestRecordBlocks(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		md           *metadata
+		head         uint64
+		forkBlock    uint64
+		startBlock   uint64
+		endBlock     uint64
+		recentBlocks []*recentBlock
+		err          string
+	}{
+		{
+			name: "Good",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: testRecentBlocks(91, 100),
+			},
+			head:         105,
+			forkBlock:    105,
+			startBlock:   101,
+			endBlock:     105,
+			recentBlocks: testRecentBlocks(96, 105),
+		},
+		{
+			name: "OutsideWindow",
+			md: &metadata{
+				LatestBlock: 80,
+			},
+			head:         110,
+			forkBlock:    110,
+			startBlock:   81,
+			endBlock:     105,
+			recentBlocks: testRecentBlocks(101, 105),
+		},
+		{
+			name: "PreviousAttempt",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: append(testRecentBlocks(91, 100), &recentBlock{Number: 101, Hash: testHash(101, true)}),
+			},
+			head:         105,
+			forkBlock:    105,
+			startBlock:   101,
+			endBlock:     105,
+			recentBlocks: testRecentBlocks(96, 105),
+		},
+		{
+			name: "Reorg",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: testRecentBlocks(91, 100),
+			},
+			head:         105,
+			forkBlock:    98,
+			startBlock:   101,
+			endBlock:     105,
+			recentBlocks: testRecentBlocks(91, 100),
+			err:          errReorg.Error(),
+		},
+		{
+			name: "BlockUnavailable",
+			md: &metadata{
+				LatestBlock:  100,
+				RecentBlocks: testRecentBlocks(91, 100),
+			},
+			head:         103,
+			forkBlock:    103,
+			startBlock:   101,
+			endBlock:     105,
+			recentBlocks: testRecentBlocks(91, 100),
+			err:          "failed to obtain block: empty response",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := testBlockServer(t, testChain(test.head, test.forkBlock))
+			defer server.Close()
+			chainDB := &testChainDB{metadata: make(map[string][]byte)}
+			s := testService(t, chainDB, server)
+
+			err := s.recordBlocks(ctx, test.md, test.startBlock, test.endBlock, test.head)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.recentBlocks, test.md.RecentBlocks)
+		})
+	}
+}
+
+func TestReorgDuringRecord(t *testing.T) {
+	ctx := context.Background()
+
+	// Record blocks from the original chain, with the first batch processed.
+	server := testBlockServer(t, testChain(110, 110))
+	chainDB := &testChainDB{metadata: make(map[string][]byte)}
+	s := testService(t, chainDB, server)
+	md := &metadata{
+		LatestBlock:  100,
+		RecentBlocks: testRecentBlocks(91, 100),
+	}
+	require.NoError(t, s.recordBlocks(ctx, md, 101, 105, 110))
+	md.LatestBlock = 105
+	require.NoError(t, s.recordBlocks(ctx, md, 106, 110, 110))
+	server.Close()
+
+	// The second batch is not processed, and the chain reorgs within it.
+	server = testBlockServer(t, testChain(112, 107))
+	defer server.Close()
+	s = testService(t, chainDB, server)
+	require.NoError(t, s.checkReorg(ctx, md))
+	require.Equal(t, uint64(105), md.LatestBlock)
+	require.Equal(t, testRecentBlocks(101, 105), md.RecentBlocks)
+	require.Equal(t, uint64(106), chainDB.prunedFrom)
+}
@@ -27,12 +27,16 @@ import (
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
 	"golang.org/x/sync/semaphore"
 )
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // Service is an Ethereum 1 deposits service that fetches deposits through fetching logs.
 type Service struct {
 	chainDB                chaindb.Service
@@ -41,10 +45,13 @@ type Service struct {
 	client                 *http.Client
 	eth1DepositsSetter     chaindb.ETH1DepositsSetter
 	eth1Confirmations      uint64
+	reorgWindow            uint64
+	provisionalDeposits    bool
 	blockTimestamps        map[[32]byte]time.Time
 	blocksPerRequest       uint64
 	depositContractAddress []byte
 	activitySem            *semaphore.Weighted
+	activity               *util.Activity
 }
 
 // New creates a new Ethereum 1 deposit service.
@@ -55,10 +62,8 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "eth1deposits").Str("impl", "getlogs").Logger()
-	if parameters.logLevel != log.GetLevel() {
-		log = log.Level(parameters.logLevel)
-	}
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "eth1deposits").Str("impl", "getlogs").Logger())
 
 	if err := registerMetrics(ctx, parameters.monitor); err != nil {
 		return nil, errors.New("failed to register metrics")
@@ -87,6 +92,10 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		},
 	}
 
+	if _, isPruner := parameters.chainDB.(chaindb.ETH1DepositsPruner); !isPruner {
+		return nil, errors.New("chain DB does not support Ethereum 1 deposit pruning")
+	}
+
 	spec, err := parameters.chainDB.(chaindb.ChainSpecProvider).ChainSpec(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to obtain chain specification")
@@ -104,10 +113,13 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		base:                   base,
 		client:                 client,
 		eth1Confirmations:      parameters.eth1Confirmations,
+		reorgWindow:            parameters.reorgWindow,
+		provisionalDeposits:    parameters.provisionalDeposits,
 		blockTimestamps:        make(map[[32]byte]time.Time),
 		blocksPerRequest:       64,
 		depositContractAddress: depositContractAddress,
 		activitySem:            semaphore.NewWeighted(1),
+		activity:               util.NewActivity(),
 	}
 
 	chainID, err := s.chainID(ctx)
@@ -175,15 +187,16 @@ func (s *Service) updateAfterRestart(ctx context.Context, startBlock int64) {
 	}(ctx, s)
 }
 
-func (s *Service) getLatestHeadBlock(ctx context.Context) (uint64, error) {
+// getLatestHeadBlock returns the latest confirmed block, and the current head block.
+func (s *Service) getLatestHeadBlock(ctx context.Context) (uint64, uint64, error) {
 	head, err := s.blockNumber(ctx)
 	if err != nil {
-		return 0, errors.Wrap(err, "failed to obtain block number")
+		return 0, 0, errors.Wrap(err, "failed to obtain block number")
 	}
 	if head > s.eth1Confirmations {
-		return head - s.eth1Confirmations, nil
+		return head - s.eth1Confirmations, head, nil
 	}
-	return 0, nil
+	return 0, head, nil
 }
 
 func (s *Service) checkLatestBlock(ctx context.Context) {
@@ -204,7 +217,14 @@ func (s *Service) parseNewBlocks(ctx context.Context, md *metadata) {
 	}
 	defer s.activitySem.Release(1)
 
-	latestHeadBlock, err := s.getLatestHeadBlock(ctx)
+	ctx, done, ok := s.activity.Start(ctx, "deposits")
+	if !ok {
+		log.Debug().Msg("Service stopping; not fetching blocks")
+		return
+	}
+	defer done()
+
+	latestHeadBlock, headBlock, err := s.getLatestHeadBlock(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to obtain latest head block")
 		return
@@ -215,8 +235,16 @@ func (s *Service) parseNewBlocks(ctx context.Context, md *metadata) {
 		return
 	}
 
+	if err := s.checkReorg(ctx, md); err != nil {
+		log.Error().Err(err).Msg("Failed to check for reorg")
+		return
+	}
+
 	log.Trace().Uint64("start_block", md.LatestBlock+1).Uint64("end_block", latestHeadBlock).Msg("Fetching ETH1 logs in batches")
 	for block := md.LatestBlock + 1; block <= latestHeadBlock; block += s.blocksPerRequest {
+		if s.activity.Stopping() {
+			return
+		}
 		startBlock := block
 		endBlock := block + s.blocksPerRequest - 1
 		if endBlock > latestHeadBlock {
@@ -224,31 +252,62 @@ func (s *Service) parseNewBlocks(ctx context.Context, md *metadata) {
 		}
 
 		log := log.With().Uint64("start_block", startBlock).Uint64("end_block", endBlock).Logger()
+		if err := s.recordBlocks(ctx, md, startBlock, endBlock, latestHeadBlock); err != nil {
+			// A reorg will be handled on the next check.
+			log.Warn().Err(err).Msg("Failed to record blocks")
+			return
+		}
+
 		// Each update goes in to its own transaction, to make the data available sooner.
-		ctx, cancel, err := s.chainDB.BeginTx(ctx)
+		// The deposits are committed along with the metadata that records the blocks from which
+		// they came, so that the two cannot disagree when checking for reorgs.
+		dbCtx, cancel, err := s.chainDB.BeginTx(ctx)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to begin transaction on update after restart")
 			return
 		}
 
-		if err := s.handleBlocks(ctx, startBlock, endBlock); err != nil {
+		if err := s.handleBlocks(dbCtx, md, startBlock, endBlock, false); err != nil {
 			log.Warn().Err(err).Msg("Failed to update ETH1 deposits")
+			// Discard any deposits from the failed update; the blocks will be retried as missed blocks.
+			cancel()
+			dbCtx, cancel, err = s.chainDB.BeginTx(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to begin transaction to record missed blocks")
+				return
+			}
 			for missedBlock := block; missedBlock <= endBlock; missedBlock++ {
 				md.MissedBlocks = append(md.MissedBlocks, missedBlock)
 			}
 		}
 
 		md.LatestBlock = endBlock
-		if err := s.setMetadata(ctx, md); err != nil {
+		if err := s.setMetadata(dbCtx, md); err != nil {
 			log.Error().Err(err).Msg("Failed to set metadata")
 			cancel()
 			return
 		}
 
-		if err := s.chainDB.CommitTx(ctx); err != nil {
+		if err := s.chainDB.CommitTx(dbCtx); err != nil {
 			log.Error().Err(err).Msg("Failed to commit transaction")
 			cancel()
 			return
 		}
 	}
+
+	if s.provisionalDeposits {
+		if err := s.updateProvisionalDeposits(ctx, md, headBlock); err != nil {
+			log.Warn().Err(err).Msg("Failed to update provisional deposits")
+		}
+	}
+}
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
 }
@@ -25,6 +25,9 @@ import (
 )
 
 func TestService(t *testing.T) {
+	if os.Getenv("CHAINDB_URL") == "" || os.Getenv("EXECCLIENT_URL") == "" {
+		t.Skip("CHAINDB_URL and EXECCLIENT_URL are required")
+	}
 	ctx := context.Background()
 
 	chainDB, err := postgresqlchaindb.New(ctx,
@@ -56,6 +59,17 @@ func TestService(t *testing.T) {
 			},
 			err: "problem with parameters: no connection URL specified",
 		},
+		{
+			name: "ReorgWindowZero",
+			params: []getlogs.Parameter{
+				getlogs.WithLogLevel(zerolog.Disabled),
+				getlogs.WithChainDB(chainDB),
+				getlogs.WithETH1DepositsSetter(chainDB),
+				getlogs.WithConnectionURL(os.Getenv("EXECCLIENT_URL")),
+				getlogs.WithReorgWindow(0),
+			},
+			err: "problem with parameters: reorg window must be at least 1 block",
+		},
 		{
 			name: "Good",
 			params: []getlogs.Parameter{
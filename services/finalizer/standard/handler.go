@@ -23,8 +23,13 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/util"
 )
 
+// errStopping is returned when work halts because the service is stopping.
+var errStopping = errors.New("service stopping")
+
 // OnFinalityCheckpointReceived receives finality checkpoint notifications.
 func (s *Service) OnFinalityCheckpointReceived(
 	ctx context.Context,
@@ -39,6 +44,7 @@ func (s *Service) OnFinalityCheckpointReceived(
 		Str("justified_epoch", fmt.Sprintf("%d", justifiedEpoch)).
 		Str("justified_bock_root", fmt.Sprintf("%#x", justifiedBlockRoot)).
 		Msg("Finality checkpoint received")
+	monitorFinalizedEpoch(finalizedEpoch)
 
 	// Only allow 1 handler to be active.
 	acquired := s.activitySem.TryAcquire(1)
@@ -48,40 +54,130 @@ func (s *Service) OnFinalityCheckpointReceived(
 	}
 	defer s.activitySem.Release(1)
 
+	ctx, done, ok := s.activity.Start(ctx, "finality")
+	if !ok {
+		log.Debug().Msg("Service stopping; not handling")
+		return
+	}
+	defer done()
+	// Requests made whilst following the head of the chain take priority over catchup.
+	ctx = governor.WithPriority(ctx, governor.PriorityHead)
+
 	// We have been informed that epoch x has finalised.  At this point we can finalise
 	// all blocks up to the justified root, and all attestations within them.
+	if err := s.finalize(ctx, justifiedEpoch, justifiedBlockRoot); err != nil {
+		if !errors.Is(err, errStopping) {
+			log.Error().Err(err).Msg("Failed to update finality")
+		}
+		return
+	}
+
+	log.Trace().Msg("Finished handling finality checkpoint")
+
+	// Notify that finality has been updated.
+	for _, finalityHandler := range s.finalityHandlers {
+		go finalityHandler.OnFinalityUpdated(ctx, finalizedEpoch)
+	}
+}
+
+// Refinalize re-runs finality processing from the given epoch to the current finalized checkpoint.
+func (s *Service) Refinalize(ctx context.Context, fromEpoch phase0.Epoch) error {
+	ctx, done, ok := s.activity.Start(ctx, "refinalize")
+	if !ok {
+		return errStopping
+	}
+	defer done()
+
+	// Wait for any other handler to finish.
+	if err := s.activitySem.Acquire(ctx, 1); err != nil {
+		return errors.Wrap(err, "failed to acquire activity semaphore")
+	}
+	defer s.activitySem.Release(1)
+
+	// Move the latest canonical slot back so that finality is recalculated from the given epoch.
+	err := util.Retry(ctx, "finality", func(ctx context.Context) error {
+		ctx, cancel, err := s.chainDB.BeginTx(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to begin transaction")
+		}
+		md, err := s.getMetadata(ctx)
+		if err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to obtain metadata")
+		}
+		if slot := s.chainTime.FirstSlotOfEpoch(fromEpoch); slot < md.LatestCanonicalSlot {
+			md.LatestCanonicalSlot = slot
+		}
+		if err := s.setMetadata(ctx, md); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to set metadata")
+		}
+		if err := s.chainDB.CommitTx(ctx); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to commit transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	finality, err := s.eth2Client.(eth2client.FinalityProvider).Finality(ctx, "head")
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain finality")
+	}
+	log.Info().Uint64("from_epoch", uint64(fromEpoch)).Uint64("finalized_epoch", uint64(finality.Finalized.Epoch)).Msg("Refinalizing")
+	monitorFinalizedEpoch(finality.Finalized.Epoch)
+	if err := s.finalize(ctx, finality.Justified.Epoch, finality.Justified.Root); err != nil {
+		return err
+	}
+
+	// Notify that finality has been updated.
+	for _, finalityHandler := range s.finalityHandlers {
+		go finalityHandler.OnFinalityUpdated(ctx, finality.Finalized.Epoch)
+	}
+
+	return nil
+}
 
+// finalize finalizes all blocks up to the given justified checkpoint, and all attestations within them.
+// This requires the activity semaphore to be held.
+func (s *Service) finalize(ctx context.Context, justifiedEpoch phase0.Epoch, justifiedBlockRoot phase0.Root) error {
 	// Rather than attempt to update everything from here back to what could be
 	// the genesis block we break the process in to batches of ~1024 slots.  To do this,
 	// pick checkpoints from here backwards and act on each one individually.
-	stack, err := s.buildFinalityStack(ctx, justifiedBlockRoot, justifiedEpoch)
+	var stack []*phase0.Checkpoint
+	err := util.Retry(ctx, "finality stack", func(ctx context.Context) error {
+		var err error
+		stack, err = s.buildFinalityStack(ctx, justifiedBlockRoot, justifiedEpoch)
+		return err
+	})
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to build finality stack")
-		return
+		return errors.Wrap(err, "failed to build finality stack")
 	}
 
 	for {
 		if len(stack) == 0 {
 			break
 		}
+		if s.activity.Stopping() {
+			log.Debug().Int("remaining", len(stack)).Msg("Service stopping; halting finality updates")
+			return errStopping
+		}
 		index := len(stack) - 1
 		checkpoint := stack[index]
 		stack = stack[:index]
 
 		log.Trace().Uint64("update_epoch", uint64(checkpoint.Epoch)).Int("remaining", len(stack)).Msg("Updating to epoch")
-		if err := s.runFinalityTransaction(ctx, checkpoint); err != nil {
-			log.Error().Err(err).Msg("Failed to run finality transaction")
-			return
+		if err := util.Retry(ctx, "finality", func(ctx context.Context) error {
+			return s.runFinalityTransaction(ctx, checkpoint)
+		}); err != nil {
+			return errors.Wrap(err, "failed to run finality transaction")
 		}
 		monitorEpochProcessed(checkpoint.Epoch)
 	}
 
-	log.Trace().Msg("Finished handling finality checkpoint")
-
-	// Notify that finality has been updated.
-	for _, finalityHandler := range s.finalityHandlers {
-		go finalityHandler.OnFinalityUpdated(ctx, finalizedEpoch)
-	}
+	return nil
 }
 
 func (s *Service) buildFinalityStack(ctx context.Context,
@@ -20,12 +20,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/wealdtech/chaind/services/metrics"
+	"go.uber.org/atomic"
 )
 
 var metricsNamespace = "chaind_finalizer"
 
 var (
-	highestEpoch    phase0.Epoch
+	highestEpoch    atomic.Uint64
+	finalizedEpoch  atomic.Uint64
 	latestEpoch     prometheus.Gauge
 	epochsProcessed prometheus.Gauge
 )
@@ -64,6 +66,21 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register epochs_processed")
 	}
 
+	if err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "lag_epochs",
+		Help:      "Number of epochs between the latest finalized epoch and the latest epoch processed",
+	}, func() float64 {
+		finalized := finalizedEpoch.Load()
+		highest := highestEpoch.Load()
+		if finalized < highest {
+			return 0
+		}
+		return float64(finalized - highest)
+	})); err != nil {
+		return errors.Wrap(err, "failed to register lag_epochs")
+	}
+
 	return nil
 }
 
@@ -71,7 +88,7 @@ func registerPrometheusMetrics() error {
 // increase in epochs processed.  This does not usually need to be
 // called directly, as it is called as part ofr monitorEpochProcessed.
 func monitorLatestEpoch(epoch phase0.Epoch) {
-	highestEpoch = epoch
+	highestEpoch.Store(uint64(epoch))
 	if latestEpoch != nil {
 		latestEpoch.Set(float64(epoch))
 	}
@@ -80,8 +97,15 @@ func monitorLatestEpoch(epoch phase0.Epoch) {
 func monitorEpochProcessed(epoch phase0.Epoch) {
 	if epochsProcessed != nil {
 		epochsProcessed.Inc()
-		if epoch > highestEpoch {
+		if uint64(epoch) > highestEpoch.Load() {
 			monitorLatestEpoch(epoch)
 		}
 	}
 }
+
+// monitorFinalizedEpoch notes the latest finalized epoch, against which lag is measured.
+func monitorFinalizedEpoch(epoch phase0.Epoch) {
+	if uint64(epoch) > finalizedEpoch.Load() {
+		finalizedEpoch.Store(uint64(epoch))
+	}
+}
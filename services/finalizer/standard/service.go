@@ -26,6 +26,7 @@ import (
 	"github.com/wealdtech/chaind/services/blocks"
 	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/chaintime"
+	"github.com/wealdtech/chaind/util"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -39,11 +40,15 @@ type Service struct {
 	blocks           blocks.Service
 	finalityHandlers []handlers.FinalityHandler
 	activitySem      *semaphore.Weighted
+	activity         *util.Activity
 }
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -52,7 +57,8 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "finalizer").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "finalizer").Str("impl", "standard").Logger())
 
 	if err := registerMetrics(ctx, parameters.monitor); err != nil {
 		return nil, errors.New("failed to register metrics")
@@ -77,6 +83,7 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		blocks:           parameters.blocks,
 		finalityHandlers: parameters.finalityHandlers,
 		activitySem:      parameters.activitySem,
+		activity:         util.NewActivity(),
 	}
 
 	// Set up the handler for new finality checkpoint updates.
@@ -94,6 +101,7 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		finality, err := s.eth2Client.(eth2client.FinalityProvider).Finality(ctx, "head")
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to obtain finality data")
+			return
 		}
 
 		s.OnFinalityCheckpointReceived(ctx, finality.Finalized.Epoch, finality.Finalized.Root, finality.Justified.Epoch, finality.Justified.Root)
@@ -110,3 +118,13 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 
 	return s, nil
 }
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
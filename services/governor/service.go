@@ -0,0 +1,77 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package governor controls the rate and concurrency of requests to beacon nodes.
+package governor
+
+import (
+	"context"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// Class is a class of beacon node endpoint with similar cost.
+type Class string
+
+const (
+	// ClassBlocks is for block requests.
+	ClassBlocks Class = "blocks"
+	// ClassDuties is for committee and duty requests.
+	ClassDuties Class = "duties"
+	// ClassState is for requests that require the node to load a beacon state.
+	ClassState Class = "state"
+)
+
+// Classes are all of the endpoint classes that are governed.
+var Classes = []Class{ClassBlocks, ClassDuties, ClassState}
+
+// Priority is the priority of a request.
+type Priority int
+
+const (
+	// PriorityCatchup is for historical work, such as catching up or backfilling.
+	PriorityCatchup Priority = iota
+	// PriorityHead is for work that follows the head of the chain.
+	PriorityHead
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context that marks requests made with it as having the given priority.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext returns the priority of requests made with the given context.
+// If no priority has been set this is PriorityCatchup.
+func PriorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityCatchup
+}
+
+// BlockRewards are the consensus rewards obtained by the proposer of a block.
+type BlockRewards struct {
+	Total             phase0.Gwei
+	Attestations      phase0.Gwei
+	SyncAggregate     phase0.Gwei
+	ProposerSlashings phase0.Gwei
+	AttesterSlashings phase0.Gwei
+}
+
+// BlockRewardsProvider is the interface for clients that provide the consensus rewards for blocks.
+type BlockRewardsProvider interface {
+	// BlockRewards provides the consensus rewards for the proposer of the given block.
+	BlockRewards(ctx context.Context, blockID string) (*BlockRewards, error)
+}
@@ -0,0 +1,107 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/governor"
+)
+
+// blockRewardsResponse is the response from the beacon node block rewards endpoint.
+type blockRewardsResponse struct {
+	Data *blockRewardsJSON `json:"data"`
+}
+
+type blockRewardsJSON struct {
+	Total             string `json:"total"`
+	Attestations      string `json:"attestations"`
+	SyncAggregate     string `json:"sync_aggregate"`
+	ProposerSlashings string `json:"proposer_slashings"`
+	AttesterSlashings string `json:"attester_slashings"`
+}
+
+// BlockRewards provides the consensus rewards for the proposer of the given block.
+// The Ethereum 2 client does not support this endpoint, so the request is made directly.
+func (s *Service) BlockRewards(ctx context.Context, blockID string) (*governor.BlockRewards, error) {
+	var res *governor.BlockRewards
+	// The beacon node requires the state to calculate rewards.
+	err := s.govern(ctx, governor.ClassState, "BlockRewards", func() error {
+		var err error
+		res, err = s.blockRewards(ctx, blockID)
+		return err
+	})
+	return res, err
+}
+
+func (s *Service) blockRewards(ctx context.Context, blockID string) (*governor.BlockRewards, error) {
+	address := s.eth2Client.Address()
+	if !strings.HasPrefix(address, "http") {
+		address = fmt.Sprintf("http://%s", address)
+	}
+	url := fmt.Sprintf("%s/eth/v1/beacon/rewards/blocks/%s", strings.TrimSuffix(address, "/"), blockID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "request failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	var response blockRewardsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "invalid response")
+	}
+	if response.Data == nil {
+		return nil, errors.New("empty response")
+	}
+
+	values := []string{
+		response.Data.Total,
+		response.Data.Attestations,
+		response.Data.SyncAggregate,
+		response.Data.ProposerSlashings,
+		response.Data.AttesterSlashings,
+	}
+	amounts := make([]phase0.Gwei, len(values))
+	for i := range values {
+		amount, err := strconv.ParseUint(values[i], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid reward")
+		}
+		amounts[i] = phase0.Gwei(amount)
+	}
+
+	return &governor.BlockRewards{
+		Total:             amounts[0],
+		Attestations:      amounts[1],
+		SyncAggregate:     amounts[2],
+		ProposerSlashings: amounts[3],
+		AttesterSlashings: amounts[4],
+	}, nil
+}
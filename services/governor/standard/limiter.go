@@ -0,0 +1,264 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/util"
+)
+
+const (
+	// minThrottle is the lowest fraction of the configured limits to which a class is slowed.
+	minThrottle = 0.1
+	// throttleRecovery is the amount by which the throttle recovers for each healthy response.
+	throttleRecovery = 0.02
+	// throttleCooldown is the minimum time between slowdowns, to allow a slowdown to take effect.
+	throttleCooldown = 5 * time.Second
+	// latencyFactor is the multiple of the baseline latency at which a class is slowed.
+	latencyFactor = 3.0
+	// minSlowLatency is the latency, in seconds, below which a class is not considered slow.
+	minSlowLatency = 0.25
+	// errorRateThreshold is the error rate at which a class is slowed.
+	errorRateThreshold = 0.2
+	// unthrottledConcurrency is the concurrency used to scale a class with no concurrency limit
+	// when it is throttled.
+	unthrottledConcurrency = 16
+)
+
+// waiter is a request waiting to be sent.
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// limiter governs requests for a single class of endpoint.
+type limiter struct {
+	class governor.Class
+	limit *Limit
+
+	mutex   sync.Mutex
+	queues  [2][]*waiter
+	pending bool
+	// inFlight is the number of requests currently being sent.
+	inFlight int
+	// next is the earliest time at which the next request can be sent.
+	next time.Time
+	// throttle is the fraction of the configured limits currently allowed.
+	throttle     float64
+	lastSlowdown time.Time
+	// Smoothed recent latency, long-term baseline latency and error rate.
+	latency     float64
+	baseline    float64
+	errorRate   float64
+	initialized bool
+}
+
+func newLimiter(class governor.Class, limit *Limit) *limiter {
+	return &limiter{
+		class:    class,
+		limit:    limit,
+		throttle: 1,
+	}
+}
+
+// setLimit changes the limit, releasing any waiting requests that the new limit allows.
+func (l *limiter) setLimit(limit *Limit) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if *l.limit != *limit {
+		log.Info().
+			Str("class", string(l.class)).
+			Float64("rate", limit.Rate).
+			Int("concurrency", limit.Concurrency).
+			Msg("Updated limit")
+	}
+	l.limit = limit
+	// The next request is no longer held back by the previous rate.
+	l.next = time.Time{}
+	l.dispatch()
+}
+
+// acquire waits until a request may be sent.  It returns a function that must be called with the
+// result of the request once it completes.
+func (l *limiter) acquire(ctx context.Context) (func(error), error) {
+	started := time.Now()
+	priority := governor.PriorityFromContext(ctx)
+	if priority != governor.PriorityHead {
+		priority = governor.PriorityCatchup
+	}
+	w := &waiter{
+		ready: make(chan struct{}),
+	}
+
+	l.mutex.Lock()
+	l.queues[priority] = append(l.queues[priority], w)
+	l.dispatch()
+	l.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		l.mutex.Lock()
+		if !w.granted {
+			l.remove(priority, w)
+			l.mutex.Unlock()
+			return nil, ctx.Err()
+		}
+		// Granted at the same time as cancellation; hand the slot back.
+		l.inFlight--
+		l.dispatch()
+		l.mutex.Unlock()
+		return nil, ctx.Err()
+	}
+	monitorWait(l.class, priority, time.Since(started))
+
+	sent := time.Now()
+	return func(err error) {
+		l.complete(time.Since(sent), err)
+	}, nil
+}
+
+// remove removes a waiter from its queue.
+// This requires the mutex to be held.
+func (l *limiter) remove(priority governor.Priority, w *waiter) {
+	queue := l.queues[priority]
+	for i := range queue {
+		if queue[i] == w {
+			l.queues[priority] = append(queue[:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// concurrency returns the current maximum number of concurrent requests; 0 if unlimited.
+// This requires the mutex to be held.
+func (l *limiter) concurrency() int {
+	max := l.limit.Concurrency
+	if max == 0 {
+		if l.throttle == 1 {
+			return 0
+		}
+		max = unthrottledConcurrency
+	}
+	concurrency := int(math.Round(float64(max) * l.throttle))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return concurrency
+}
+
+// interval returns the current minimum interval between requests; 0 if unlimited.
+// This requires the mutex to be held.
+func (l *limiter) interval() time.Duration {
+	if l.limit.Rate == 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / (l.limit.Rate * l.throttle))
+}
+
+// dispatch releases as many waiting requests as the limits allow, head requests first.
+// This requires the mutex to be held.
+func (l *limiter) dispatch() {
+	for {
+		priority := governor.PriorityHead
+		if len(l.queues[priority]) == 0 {
+			priority = governor.PriorityCatchup
+			if len(l.queues[priority]) == 0 {
+				return
+			}
+		}
+		if concurrency := l.concurrency(); concurrency > 0 && l.inFlight >= concurrency {
+			// Will be dispatched when a request completes.
+			return
+		}
+		now := time.Now()
+		if now.Before(l.next) {
+			if !l.pending {
+				l.pending = true
+				time.AfterFunc(l.next.Sub(now), func() {
+					l.mutex.Lock()
+					l.pending = false
+					l.dispatch()
+					l.mutex.Unlock()
+				})
+			}
+			return
+		}
+
+		w := l.queues[priority][0]
+		l.queues[priority] = l.queues[priority][1:]
+		w.granted = true
+		l.inFlight++
+		if interval := l.interval(); interval > 0 {
+			l.next = now.Add(interval)
+		}
+		close(w.ready)
+	}
+}
+
+// complete notes the completion of a request, adapting the limits according to its outcome.
+func (l *limiter) complete(latency time.Duration, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.inFlight--
+	l.adapt(latency.Seconds(), err != nil && util.IsRetryable(err))
+	l.dispatch()
+	monitorRequest(l.class, err, l.throttle)
+}
+
+// adapt updates the throttle given the result of a request.
+// This requires the mutex to be held.
+func (l *limiter) adapt(latency float64, failed bool) {
+	errorSample := 0.0
+	if failed {
+		errorSample = 1
+	}
+	if !l.initialized {
+		l.latency = latency
+		l.baseline = latency
+		l.initialized = true
+	} else {
+		l.latency = 0.8*l.latency + 0.2*latency
+		l.baseline = 0.98*l.baseline + 0.02*latency
+	}
+	l.errorRate = 0.9*l.errorRate + 0.1*errorSample
+
+	slow := l.latency > latencyFactor*l.baseline && l.latency > minSlowLatency
+	if l.errorRate > errorRateThreshold || slow {
+		if time.Since(l.lastSlowdown) < throttleCooldown {
+			return
+		}
+		l.lastSlowdown = time.Now()
+		l.throttle = math.Max(minThrottle, l.throttle/2)
+		log.Debug().
+			Str("class", string(l.class)).
+			Float64("latency", l.latency).
+			Float64("baseline", l.baseline).
+			Float64("error_rate", l.errorRate).
+			Float64("throttle", l.throttle).
+			Msg("Beacon node struggling; slowing requests")
+		return
+	}
+
+	if !failed && l.throttle < 1 {
+		l.throttle = math.Min(1, l.throttle+throttleRecovery)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/services/metrics"
+)
+
+var metricsNamespace = "chaind_governor"
+
+var (
+	requestsMetric *prometheus.CounterVec
+	waitMetric     *prometheus.HistogramVec
+	throttleMetric *prometheus.GaugeVec
+)
+
+var (
+	endpointRequestsMetric *prometheus.CounterVec
+	endpointDurationMetric *prometheus.HistogramVec
+)
+
+func registerMetrics(_ context.Context, monitor metrics.Service) error {
+	if requestsMetric != nil {
+		// Already registered.
+		return nil
+	}
+	if monitor == nil {
+		// No monitor.
+		return nil
+	}
+	if monitor.Presenter() == "prometheus" {
+		return registerPrometheusMetrics()
+	}
+	return nil
+}
+
+// skipcq: RVV-B0012
+func registerPrometheusMetrics() error {
+	requestsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "requests_total",
+		Help:      "Number of governed requests made to the beacon node",
+	}, []string{"class", "result"})
+	if err := prometheus.Register(requestsMetric); err != nil {
+		return errors.Wrap(err, "failed to register requests_total")
+	}
+
+	waitMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "wait_seconds",
+		Help:      "Time requests waited before being sent to the beacon node",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2, 5, 10, 30, 60},
+	}, []string{"class", "priority"})
+	if err := prometheus.Register(waitMetric); err != nil {
+		return errors.Wrap(err, "failed to register wait_seconds")
+	}
+
+	throttleMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "throttle",
+		Help:      "Fraction of the configured limits currently allowed (1 is unthrottled)",
+	}, []string{"class"})
+	if err := prometheus.Register(throttleMetric); err != nil {
+		return errors.Wrap(err, "failed to register throttle")
+	}
+
+	endpointRequestsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "endpoint_requests_total",
+		Help:      "Number of requests made to each beacon node endpoint",
+	}, []string{"endpoint", "result"})
+	if err := prometheus.Register(endpointRequestsMetric); err != nil {
+		return errors.Wrap(err, "failed to register endpoint_requests_total")
+	}
+
+	endpointDurationMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "endpoint_request_duration_seconds",
+		Help:      "Time taken by the beacon node to respond to requests to each endpoint",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60},
+	}, []string{"endpoint"})
+	if err := prometheus.Register(endpointDurationMetric); err != nil {
+		return errors.Wrap(err, "failed to register endpoint_request_duration_seconds")
+	}
+
+	return nil
+}
+
+func monitorWait(class governor.Class, priority governor.Priority, wait time.Duration) {
+	if waitMetric == nil {
+		return
+	}
+	priorityName := "catchup"
+	if priority == governor.PriorityHead {
+		priorityName = "head"
+	}
+	waitMetric.WithLabelValues(string(class), priorityName).Observe(wait.Seconds())
+}
+
+func monitorRequest(class governor.Class, err error, throttle float64) {
+	if requestsMetric == nil {
+		return
+	}
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	requestsMetric.WithLabelValues(string(class), result).Inc()
+	throttleMetric.WithLabelValues(string(class)).Set(throttle)
+}
+
+func monitorEndpointRequest(endpoint string, duration time.Duration, err error) {
+	if endpointRequestsMetric == nil {
+		return
+	}
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	endpointRequestsMetric.WithLabelValues(endpoint, result).Inc()
+	endpointDurationMetric.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
@@ -0,0 +1,132 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"fmt"
+	"time"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/services/metrics"
+)
+
+// Limit is the limit on requests for a class of endpoint.
+type Limit struct {
+	// Rate is the maximum number of requests per second; 0 for no limit.
+	Rate float64
+	// Concurrency is the maximum number of concurrent requests; 0 for no limit.
+	Concurrency int
+}
+
+type parameters struct {
+	logLevel   zerolog.Level
+	monitor    metrics.Service
+	eth2Client eth2client.Service
+	timeout    time.Duration
+	limits     map[governor.Class]*Limit
+}
+
+// Parameter is the interface for service parameters.
+type Parameter interface {
+	apply(*parameters)
+}
+
+type parameterFunc func(*parameters)
+
+func (f parameterFunc) apply(p *parameters) {
+	f(p)
+}
+
+// WithLogLevel sets the log level for the module.
+func WithLogLevel(logLevel zerolog.Level) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.logLevel = logLevel
+	})
+}
+
+// WithMonitor sets the monitor for the module.
+func WithMonitor(monitor metrics.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.monitor = monitor
+	})
+}
+
+// WithETH2Client sets the Ethereum 2 client to govern.
+func WithETH2Client(eth2Client eth2client.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.eth2Client = eth2Client
+	})
+}
+
+// WithTimeout sets the timeout for requests that the governor makes itself.
+func WithTimeout(timeout time.Duration) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.timeout = timeout
+	})
+}
+
+// WithLimit sets the limit for a class of endpoint.
+func WithLimit(class governor.Class, limit *Limit) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.limits[class] = limit
+	})
+}
+
+// parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
+func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
+	parameters := parameters{
+		logLevel: zerolog.GlobalLevel(),
+		timeout:  2 * time.Minute,
+		limits:   make(map[governor.Class]*Limit),
+	}
+	for _, p := range params {
+		if params != nil {
+			p.apply(&parameters)
+		}
+	}
+
+	if parameters.eth2Client == nil {
+		return nil, errors.New("no Ethereum 2 client specified")
+	}
+	if parameters.timeout == 0 {
+		return nil, errors.New("no timeout specified")
+	}
+	for _, class := range governor.Classes {
+		limit, exists := parameters.limits[class]
+		if !exists {
+			parameters.limits[class] = &Limit{}
+			continue
+		}
+		if err := checkLimit(class, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return &parameters, nil
+}
+
+// checkLimit checks that a limit is valid.
+func checkLimit(class governor.Class, limit *Limit) error {
+	if limit.Rate < 0 {
+		return fmt.Errorf("rate for %s cannot be negative", class)
+	}
+	if limit.Concurrency < 0 {
+		return fmt.Errorf("concurrency for %s cannot be negative", class)
+	}
+
+	return nil
+}
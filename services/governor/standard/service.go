@@ -0,0 +1,334 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	zerologger "github.com/rs/zerolog/log"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/util"
+)
+
+// Service is an Ethereum 2 client that governs the requests made to the underlying client.
+// Requests that are cheap for the beacon node, such as those for configuration, are passed
+// straight through.
+type Service struct {
+	eth2Client eth2client.Service
+	limiters   map[governor.Class]*limiter
+	// httpClient is used for endpoints that the Ethereum 2 client does not support.
+	httpClient *http.Client
+}
+
+// module-wide log.
+var log zerolog.Logger
+
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
+// New creates a new governor.
+func New(ctx context.Context, params ...Parameter) (*Service, error) {
+	parameters, err := parseAndCheckParameters(params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem with parameters")
+	}
+
+	// Set logging.
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "governor").Str("impl", "standard").Logger())
+
+	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+		return nil, errors.New("failed to register metrics")
+	}
+
+	s := &Service{
+		eth2Client: parameters.eth2Client,
+		limiters:   make(map[governor.Class]*limiter),
+		httpClient: &http.Client{
+			Timeout: parameters.timeout,
+		},
+	}
+	for class, limit := range parameters.limits {
+		s.limiters[class] = newLimiter(class, limit)
+		log.Trace().Str("class", string(class)).Float64("rate", limit.Rate).Int("concurrency", limit.Concurrency).Msg("Governing class")
+	}
+
+	return s, nil
+}
+
+// govern runs the request to the given endpoint once its class allows.
+func (s *Service) govern(ctx context.Context, class governor.Class, endpoint string, request func() error) error {
+	done, err := s.limiters[class].acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, "request abandoned whilst waiting")
+	}
+	started := time.Now()
+	err = request()
+	monitorEndpointRequest(endpoint, time.Since(started), err)
+	done(err)
+
+	return err
+}
+
+// SetLimit sets the limit for a class of endpoint whilst the governor is running.
+// Requests that are already in flight are unaffected.
+func (s *Service) SetLimit(class governor.Class, limit *Limit) error {
+	limiter, exists := s.limiters[class]
+	if !exists {
+		return fmt.Errorf("unknown class %s", class)
+	}
+	if err := checkLimit(class, limit); err != nil {
+		return err
+	}
+	limiter.setLimit(limit)
+
+	return nil
+}
+
+// Name returns the name of the client implementation.
+func (s *Service) Name() string {
+	return s.eth2Client.Name()
+}
+
+// Address returns the address of the client.
+func (s *Service) Address() string {
+	return s.eth2Client.Address()
+}
+
+// GenesisTime provides the genesis time of the chain.
+func (s *Service) GenesisTime(ctx context.Context) (time.Time, error) {
+	provider, isProvider := s.eth2Client.(eth2client.GenesisTimeProvider)
+	if !isProvider {
+		return time.Time{}, errors.New("client does not provide genesis time")
+	}
+	return provider.GenesisTime(ctx)
+}
+
+// Genesis fetches genesis information for the chain.
+func (s *Service) Genesis(ctx context.Context) (*apiv1.Genesis, error) {
+	provider, isProvider := s.eth2Client.(eth2client.GenesisProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide genesis")
+	}
+	return provider.Genesis(ctx)
+}
+
+// Spec provides the spec information of the chain.
+func (s *Service) Spec(ctx context.Context) (map[string]interface{}, error) {
+	provider, isProvider := s.eth2Client.(eth2client.SpecProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide spec")
+	}
+	return provider.Spec(ctx)
+}
+
+// SlotsPerEpoch provides the slots per epoch of the chain.
+func (s *Service) SlotsPerEpoch(ctx context.Context) (uint64, error) {
+	provider, isProvider := s.eth2Client.(eth2client.SlotsPerEpochProvider)
+	if !isProvider {
+		return 0, errors.New("client does not provide slots per epoch")
+	}
+	return provider.SlotsPerEpoch(ctx)
+}
+
+// ForkSchedule provides details of past and future changes in the chain's fork version.
+func (s *Service) ForkSchedule(ctx context.Context) ([]*phase0.Fork, error) {
+	provider, isProvider := s.eth2Client.(eth2client.ForkScheduleProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide fork schedule")
+	}
+	return provider.ForkSchedule(ctx)
+}
+
+// NodeSyncing provides the state of the node's synchronization with the chain.
+func (s *Service) NodeSyncing(ctx context.Context) (*apiv1.SyncState, error) {
+	provider, isProvider := s.eth2Client.(eth2client.NodeSyncingProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide node syncing")
+	}
+	return provider.NodeSyncing(ctx)
+}
+
+// Events feeds requested events with the given topics to the supplied handler.
+func (s *Service) Events(ctx context.Context, topics []string, handler eth2client.EventHandlerFunc) error {
+	provider, isProvider := s.eth2Client.(eth2client.EventsProvider)
+	if !isProvider {
+		return errors.New("client does not provide events")
+	}
+	return provider.Events(ctx, topics, handler)
+}
+
+// SignedBeaconBlock fetches a signed beacon block given a block ID.
+func (s *Service) SignedBeaconBlock(ctx context.Context, blockID string) (*spec.VersionedSignedBeaconBlock, error) {
+	provider, isProvider := s.eth2Client.(eth2client.SignedBeaconBlockProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide signed beacon blocks")
+	}
+	var res *spec.VersionedSignedBeaconBlock
+	err := s.govern(ctx, governor.ClassBlocks, "SignedBeaconBlock", func() error {
+		var err error
+		res, err = provider.SignedBeaconBlock(ctx, blockID)
+		return err
+	})
+	return res, err
+}
+
+// BeaconCommittees fetches all beacon committees for the epoch at the given state.
+func (s *Service) BeaconCommittees(ctx context.Context, stateID string) ([]*apiv1.BeaconCommittee, error) {
+	provider, isProvider := s.eth2Client.(eth2client.BeaconCommitteesProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide beacon committees")
+	}
+	var res []*apiv1.BeaconCommittee
+	err := s.govern(ctx, governor.ClassDuties, "BeaconCommittees", func() error {
+		var err error
+		res, err = provider.BeaconCommittees(ctx, stateID)
+		return err
+	})
+	return res, err
+}
+
+// BeaconCommitteesAtEpoch fetches all beacon committees for the given epoch at the given state.
+func (s *Service) BeaconCommitteesAtEpoch(ctx context.Context, stateID string, epoch phase0.Epoch) ([]*apiv1.BeaconCommittee, error) {
+	provider, isProvider := s.eth2Client.(eth2client.BeaconCommitteesProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide beacon committees")
+	}
+	var res []*apiv1.BeaconCommittee
+	err := s.govern(ctx, governor.ClassDuties, "BeaconCommitteesAtEpoch", func() error {
+		var err error
+		res, err = provider.BeaconCommitteesAtEpoch(ctx, stateID, epoch)
+		return err
+	})
+	return res, err
+}
+
+// SyncCommittee fetches the sync committee for the given state.
+func (s *Service) SyncCommittee(ctx context.Context, stateID string) (*apiv1.SyncCommittee, error) {
+	provider, isProvider := s.eth2Client.(eth2client.SyncCommitteesProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide sync committees")
+	}
+	var res *apiv1.SyncCommittee
+	err := s.govern(ctx, governor.ClassDuties, "SyncCommittee", func() error {
+		var err error
+		res, err = provider.SyncCommittee(ctx, stateID)
+		return err
+	})
+	return res, err
+}
+
+// SyncCommitteeAtEpoch fetches the sync committee for the given epoch at the given state.
+func (s *Service) SyncCommitteeAtEpoch(ctx context.Context, stateID string, epoch phase0.Epoch) (*apiv1.SyncCommittee, error) {
+	provider, isProvider := s.eth2Client.(eth2client.SyncCommitteesProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide sync committees")
+	}
+	var res *apiv1.SyncCommittee
+	err := s.govern(ctx, governor.ClassDuties, "SyncCommitteeAtEpoch", func() error {
+		var err error
+		res, err = provider.SyncCommitteeAtEpoch(ctx, stateID, epoch)
+		return err
+	})
+	return res, err
+}
+
+// ProposerDuties obtains proposer duties for the given epoch.
+func (s *Service) ProposerDuties(ctx context.Context, epoch phase0.Epoch, validatorIndices []phase0.ValidatorIndex) ([]*apiv1.ProposerDuty, error) {
+	provider, isProvider := s.eth2Client.(eth2client.ProposerDutiesProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide proposer duties")
+	}
+	var res []*apiv1.ProposerDuty
+	err := s.govern(ctx, governor.ClassDuties, "ProposerDuties", func() error {
+		var err error
+		res, err = provider.ProposerDuties(ctx, epoch, validatorIndices)
+		return err
+	})
+	return res, err
+}
+
+// Finality provides the finality given a state ID.
+func (s *Service) Finality(ctx context.Context, stateID string) (*apiv1.Finality, error) {
+	provider, isProvider := s.eth2Client.(eth2client.FinalityProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide finality")
+	}
+	var res *apiv1.Finality
+	err := s.govern(ctx, governor.ClassState, "Finality", func() error {
+		var err error
+		res, err = provider.Finality(ctx, stateID)
+		return err
+	})
+	return res, err
+}
+
+// Validators provides the validators, with their balance and status, for a given state.
+func (s *Service) Validators(ctx context.Context, stateID string, validatorIndices []phase0.ValidatorIndex) (map[phase0.ValidatorIndex]*apiv1.Validator, error) {
+	provider, isProvider := s.eth2Client.(eth2client.ValidatorsProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide validators")
+	}
+	var res map[phase0.ValidatorIndex]*apiv1.Validator
+	err := s.govern(ctx, governor.ClassState, "Validators", func() error {
+		var err error
+		res, err = provider.Validators(ctx, stateID, validatorIndices)
+		return err
+	})
+	return res, err
+}
+
+// ValidatorsByPubKey provides the validators, with their balance and status, for a given state.
+func (s *Service) ValidatorsByPubKey(ctx context.Context, stateID string, validatorPubKeys []phase0.BLSPubKey) (map[phase0.ValidatorIndex]*apiv1.Validator, error) {
+	provider, isProvider := s.eth2Client.(eth2client.ValidatorsProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide validators")
+	}
+	var res map[phase0.ValidatorIndex]*apiv1.Validator
+	err := s.govern(ctx, governor.ClassState, "ValidatorsByPubKey", func() error {
+		var err error
+		res, err = provider.ValidatorsByPubKey(ctx, stateID, validatorPubKeys)
+		return err
+	})
+	return res, err
+}
+
+// BeaconState fetches a beacon state given a state ID.
+func (s *Service) BeaconState(ctx context.Context, stateID string) (*spec.VersionedBeaconState, error) {
+	provider, isProvider := s.eth2Client.(eth2client.BeaconStateProvider)
+	if !isProvider {
+		return nil, errors.New("client does not provide beacon state")
+	}
+	var res *spec.VersionedBeaconState
+	err := s.govern(ctx, governor.ClassState, "BeaconState", func() error {
+		var err error
+		res, err = provider.BeaconState(ctx, stateID)
+		return err
+	})
+	return res, err
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -0,0 +1,309 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/services/governor/standard"
+)
+
+// blockProvider is a client that records the order of block requests, and holds them until released.
+type blockProvider struct {
+	mutex    sync.Mutex
+	release  chan struct{}
+	started  chan string
+	inFlight int
+	maxSeen  int
+}
+
+func newBlockProvider() *blockProvider {
+	return &blockProvider{
+		release: make(chan struct{}),
+		started: make(chan string, 16),
+	}
+}
+
+func (*blockProvider) Name() string    { return "test" }
+func (*blockProvider) Address() string { return "test" }
+
+func (p *blockProvider) SignedBeaconBlock(_ context.Context, blockID string) (*spec.VersionedSignedBeaconBlock, error) {
+	p.mutex.Lock()
+	p.inFlight++
+	if p.inFlight > p.maxSeen {
+		p.maxSeen = p.inFlight
+	}
+	p.mutex.Unlock()
+
+	p.started <- blockID
+	<-p.release
+
+	p.mutex.Lock()
+	p.inFlight--
+	p.mutex.Unlock()
+
+	return &spec.VersionedSignedBeaconBlock{}, nil
+}
+
+// addressProvider is a client with the given address.
+type addressProvider struct {
+	address string
+}
+
+func (*addressProvider) Name() string      { return "test" }
+func (p *addressProvider) Address() string { return p.address }
+
+func TestService(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []standard.Parameter
+		err    string
+	}{
+		{
+			name: "ETH2ClientMissing",
+			params: []standard.Parameter{
+				standard.WithLogLevel(zerolog.Disabled),
+			},
+			err: "problem with parameters: no Ethereum 2 client specified",
+		},
+		{
+			name: "RateNegative",
+			params: []standard.Parameter{
+				standard.WithLogLevel(zerolog.Disabled),
+				standard.WithETH2Client(newBlockProvider()),
+				standard.WithLimit(governor.ClassBlocks, &standard.Limit{Rate: -1}),
+			},
+			err: "problem with parameters: rate for blocks cannot be negative",
+		},
+		{
+			name: "Good",
+			params: []standard.Parameter{
+				standard.WithLogLevel(zerolog.Disabled),
+				standard.WithETH2Client(newBlockProvider()),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := standard.New(context.Background(), test.params...)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				// Must still satisfy the interfaces that modules require of the client.
+				require.Implements(t, (*eth2client.SignedBeaconBlockProvider)(nil), s)
+				require.Implements(t, (*eth2client.ValidatorsProvider)(nil), s)
+				require.Implements(t, (*eth2client.BeaconStateProvider)(nil), s)
+				require.Implements(t, (*eth2client.EventsProvider)(nil), s)
+				require.Implements(t, (*governor.BlockRewardsProvider)(nil), s)
+			}
+		})
+	}
+}
+
+func TestConcurrencyAndPriority(t *testing.T) {
+	ctx := context.Background()
+	provider := newBlockProvider()
+	s, err := standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithETH2Client(provider),
+		standard.WithLimit(governor.ClassBlocks, &standard.Limit{Concurrency: 1}),
+	)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	request := func(ctx context.Context, blockID string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.SignedBeaconBlock(ctx, blockID)
+			require.NoError(t, err)
+		}()
+	}
+
+	// First request occupies the only slot.
+	request(ctx, "first")
+	require.Equal(t, "first", <-provider.started)
+
+	// Queue a catchup request followed by a head request.
+	request(ctx, "catchup")
+	time.Sleep(50 * time.Millisecond)
+	request(governor.WithPriority(ctx, governor.PriorityHead), "head")
+	time.Sleep(50 * time.Millisecond)
+
+	// The head request should jump the queue.
+	provider.release <- struct{}{}
+	require.Equal(t, "head", <-provider.started)
+	provider.release <- struct{}{}
+	require.Equal(t, "catchup", <-provider.started)
+	provider.release <- struct{}{}
+	wg.Wait()
+
+	require.Equal(t, 1, provider.maxSeen)
+}
+
+func TestRate(t *testing.T) {
+	ctx := context.Background()
+	provider := newBlockProvider()
+	close(provider.release)
+	s, err := standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithETH2Client(provider),
+		standard.WithLimit(governor.ClassBlocks, &standard.Limit{Rate: 20}),
+	)
+	require.NoError(t, err)
+
+	started := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := s.SignedBeaconBlock(ctx, "block")
+		require.NoError(t, err)
+		<-provider.started
+	}
+	// Five requests at 20 per second require at least four intervals of 50ms.
+	require.GreaterOrEqual(t, time.Since(started), 200*time.Millisecond)
+}
+
+func TestAbandoned(t *testing.T) {
+	provider := newBlockProvider()
+	s, err := standard.New(context.Background(),
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithETH2Client(provider),
+		standard.WithLimit(governor.ClassBlocks, &standard.Limit{Concurrency: 1}),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		_, _ = s.SignedBeaconBlock(context.Background(), "first")
+	}()
+	<-provider.started
+
+	// A queued request is abandoned when its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = s.SignedBeaconBlock(ctx, "second")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(provider.release)
+}
+
+func TestSetLimit(t *testing.T) {
+	ctx := context.Background()
+	provider := newBlockProvider()
+	s, err := standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithETH2Client(provider),
+		standard.WithLimit(governor.ClassBlocks, &standard.Limit{Concurrency: 1}),
+	)
+	require.NoError(t, err)
+
+	require.EqualError(t, s.SetLimit(governor.ClassBlocks, &standard.Limit{Concurrency: -1}), "concurrency for blocks cannot be negative")
+	require.EqualError(t, s.SetLimit(governor.Class("unknown"), &standard.Limit{}), "unknown class unknown")
+
+	var wg sync.WaitGroup
+	for _, blockID := range []string{"first", "second"} {
+		blockID := blockID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.SignedBeaconBlock(ctx, blockID)
+			require.NoError(t, err)
+		}()
+	}
+	<-provider.started
+
+	// Raising the concurrency releases the queued request.
+	require.NoError(t, s.SetLimit(governor.ClassBlocks, &standard.Limit{Concurrency: 2}))
+	select {
+	case <-provider.started:
+	case <-time.After(time.Second):
+		require.Fail(t, "queued request not released")
+	}
+	close(provider.release)
+	wg.Wait()
+
+	require.Equal(t, 2, provider.maxSeen)
+}
+
+func TestBlockRewards(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/rewards/blocks/100":
+			_, _ = w.Write([]byte(`{"data":{"proposer_index":"1","total":"15","attestations":"8","sync_aggregate":"4","proposer_slashings":"2","attester_slashings":"1"}}`))
+		case "/eth/v1/beacon/rewards/blocks/101":
+			_, _ = w.Write([]byte(`{"data":{"total":"invalid"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	s, err := standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithETH2Client(&addressProvider{address: server.URL}),
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		blockID string
+		res     *governor.BlockRewards
+		err     string
+	}{
+		{
+			name:    "Good",
+			blockID: "100",
+			res: &governor.BlockRewards{
+				Total:             15,
+				Attestations:      8,
+				SyncAggregate:     4,
+				ProposerSlashings: 2,
+				AttesterSlashings: 1,
+			},
+		},
+		{
+			name:    "Invalid",
+			blockID: "101",
+			err:     `invalid reward: strconv.ParseUint: parsing "invalid": invalid syntax`,
+		},
+		{
+			name:    "Unsupported",
+			blockID: "102",
+			err:     "request failed with status 404",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := s.BlockRewards(ctx, test.blockID)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.res, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/metrics"
+)
+
+var metricsNamespace = "chaind_mevrelays"
+
+var (
+	latestSlot        *prometheus.GaugeVec
+	payloadsProcessed *prometheus.CounterVec
+)
+
+func registerMetrics(_ context.Context, monitor metrics.Service) error {
+	if latestSlot != nil {
+		// Already registered.
+		return nil
+	}
+	if monitor == nil {
+		// No monitor.
+		return nil
+	}
+	if monitor.Presenter() == "prometheus" {
+		return registerPrometheusMetrics()
+	}
+	return nil
+}
+
+func registerPrometheusMetrics() error {
+	latestSlot = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "latest_slot",
+		Help:      "Latest slot for which a relay payload was processed",
+	}, []string{"relay"})
+	if err := prometheus.Register(latestSlot); err != nil {
+		return errors.Wrap(err, "failed to register latest_slot")
+	}
+
+	payloadsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "payloads_processed_total",
+		Help:      "Number of relay payloads processed",
+	}, []string{"relay"})
+	if err := prometheus.Register(payloadsProcessed); err != nil {
+		return errors.Wrap(err, "failed to register payloads_processed_total")
+	}
+
+	return nil
+}
+
+// monitorPayloadsProcessed is called when payloads have been processed for a relay.
+func monitorPayloadsProcessed(relay string, payloads int, slot uint64) {
+	if latestSlot != nil {
+		latestSlot.WithLabelValues(relay).Set(float64(slot))
+	}
+	if payloadsProcessed != nil {
+		payloadsProcessed.WithLabelValues(relay).Add(float64(payloads))
+	}
+}
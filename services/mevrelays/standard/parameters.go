@@ -0,0 +1,165 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/metrics"
+)
+
+type parameters struct {
+	logLevel            zerolog.Level
+	monitor             metrics.Service
+	chainDB             chaindb.Service
+	relays              []string
+	interval            time.Duration
+	timeout             time.Duration
+	payloadsPerRequest  uint64
+	startSlot           int64
+	bidTimestamps       bool
+	bidTimestampsDelay  time.Duration
+	relayPayloadsSetter chaindb.RelayPayloadsSetter
+}
+
+// Parameter is the interface for service parameters.
+type Parameter interface {
+	apply(*parameters)
+}
+
+type parameterFunc func(*parameters)
+
+func (f parameterFunc) apply(p *parameters) {
+	f(p)
+}
+
+// WithLogLevel sets the log level for the module.
+func WithLogLevel(logLevel zerolog.Level) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.logLevel = logLevel
+	})
+}
+
+// WithMonitor sets the monitor for the module.
+func WithMonitor(monitor metrics.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.monitor = monitor
+	})
+}
+
+// WithChainDB sets the chain database service for this module.
+func WithChainDB(chainDB chaindb.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.chainDB = chainDB
+	})
+}
+
+// WithRelays sets the URLs of the relays whose data APIs are polled.
+func WithRelays(relays []string) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.relays = relays
+	})
+}
+
+// WithInterval sets the interval between polls of the relays.
+func WithInterval(interval time.Duration) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.interval = interval
+	})
+}
+
+// WithTimeout sets the timeout for requests to the relays.
+func WithTimeout(timeout time.Duration) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.timeout = timeout
+	})
+}
+
+// WithPayloadsPerRequest sets the maximum number of payloads to request from a relay at a time.
+func WithPayloadsPerRequest(payloads uint64) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.payloadsPerRequest = payloads
+	})
+}
+
+// WithStartSlot sets the slot from which to start fetching payloads for relays that have not been polled before.
+// A negative value means that only recent payloads are fetched.
+func WithStartSlot(slot int64) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.startSlot = slot
+	})
+}
+
+// WithBidTimestamps sets if the time at which relays received the winning bids is fetched.
+func WithBidTimestamps(enabled bool) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.bidTimestamps = enabled
+	})
+}
+
+// WithBidTimestampsDelay sets the minimum time between requests to a relay for bid timestamps.
+func WithBidTimestampsDelay(delay time.Duration) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.bidTimestampsDelay = delay
+	})
+}
+
+// WithRelayPayloadsSetter sets the relay payloads setter for this module.
+func WithRelayPayloadsSetter(setter chaindb.RelayPayloadsSetter) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.relayPayloadsSetter = setter
+	})
+}
+
+// parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
+func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
+	parameters := parameters{
+		logLevel:           zerolog.GlobalLevel(),
+		interval:           time.Minute,
+		timeout:            30 * time.Second,
+		payloadsPerRequest: 100,
+		startSlot:          -1,
+		bidTimestamps:      true,
+		bidTimestampsDelay: 100 * time.Millisecond,
+	}
+	for _, p := range params {
+		if params != nil {
+			p.apply(&parameters)
+		}
+	}
+
+	if parameters.chainDB == nil {
+		return nil, errors.New("no chain database specified")
+	}
+	if parameters.relayPayloadsSetter == nil {
+		return nil, errors.New("no relay payloads setter specified")
+	}
+	if len(parameters.relays) == 0 {
+		return nil, errors.New("no relays specified")
+	}
+	if parameters.interval == 0 {
+		return nil, errors.New("no interval specified")
+	}
+	if parameters.timeout == 0 {
+		return nil, errors.New("no timeout specified")
+	}
+	if parameters.payloadsPerRequest == 0 {
+		return nil, errors.New("no payloads per request specified")
+	}
+
+	return &parameters, nil
+}
@@ -0,0 +1,271 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+)
+
+// relay is an MEV relay whose data API is polled.
+type relay struct {
+	// name is the name of the relay, used to identify it in the database.
+	name string
+	// base is the base URL of the relay's API.
+	base *url.URL
+	// bidTimestamps are the bid timestamps fetched for payloads that have yet to be stored, by slot.
+	bidTimestamps map[phase0.Slot]*time.Time
+	// lastBidTimestampRequest is the time of the latest request for a bid timestamp.
+	lastBidTimestampRequest time.Time
+}
+
+// bidTraceJSON is the JSON representation of a bid trace provided by the relay data API.
+type bidTraceJSON struct {
+	Slot                 string `json:"slot"`
+	ParentHash           string `json:"parent_hash"`
+	BlockHash            string `json:"block_hash"`
+	BuilderPubKey        string `json:"builder_pubkey"`
+	ProposerPubKey       string `json:"proposer_pubkey"`
+	ProposerFeeRecipient string `json:"proposer_fee_recipient"`
+	GasLimit             string `json:"gas_limit"`
+	GasUsed              string `json:"gas_used"`
+	Value                string `json:"value"`
+	BlockNumber          string `json:"block_number"`
+	NumTx                string `json:"num_tx"`
+	TimestampMs          string `json:"timestamp_ms,omitempty"`
+}
+
+// newRelay creates a relay from its URL.
+func newRelay(address string) (*relay, error) {
+	if !strings.HasPrefix(address, "http") {
+		address = fmt.Sprintf("https://%s", address)
+	}
+	base, err := url.Parse(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid relay URL")
+	}
+	if base.Host == "" {
+		return nil, errors.New("relay URL has no host")
+	}
+	// Relay URLs commonly contain the relay public key as user information; this is not required to access the data API.
+	base.User = nil
+
+	return &relay{
+		// Relays are identified by their full URL, as multiple relays can be served from a single host.
+		name:          strings.TrimSuffix(base.String(), "/"),
+		base:          base,
+		bidTimestamps: make(map[phase0.Slot]*time.Time),
+	}, nil
+}
+
+// proposerPayloadsDelivered fetches payloads delivered by the relay with slots up to and including the cursor.
+// If cursor is nil then the latest payloads are fetched.
+func (s *Service) proposerPayloadsDelivered(ctx context.Context,
+	relay *relay,
+	cursor *phase0.Slot,
+) (
+	[]*chaindb.RelayPayload,
+	error,
+) {
+	params := url.Values{}
+	params.Set("limit", fmt.Sprintf("%d", s.payloadsPerRequest))
+	if cursor != nil {
+		params.Set("cursor", fmt.Sprintf("%d", *cursor))
+	}
+
+	var bidTraces []*bidTraceJSON
+	if err := s.get(ctx, relay, "/relay/v1/data/bidtraces/proposer_payload_delivered", params, &bidTraces); err != nil {
+		return nil, err
+	}
+
+	payloads := make([]*chaindb.RelayPayload, 0, len(bidTraces))
+	for _, bidTrace := range bidTraces {
+		payload, err := bidTrace.toRelayPayload(relay.name)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload)
+	}
+
+	return payloads, nil
+}
+
+// bidTimestamp fetches the time at which the relay received the bid for the given block from the builder.
+// If the relay did not provide the bid it returns nil.
+func (s *Service) bidTimestamp(ctx context.Context,
+	relay *relay,
+	blockHash [32]byte,
+) (
+	*time.Time,
+	error,
+) {
+	params := url.Values{}
+	params.Set("block_hash", fmt.Sprintf("%#x", blockHash))
+
+	var bidTraces []*bidTraceJSON
+	if err := s.get(ctx, relay, "/relay/v1/data/bidtraces/builder_blocks_received", params, &bidTraces); err != nil {
+		return nil, err
+	}
+
+	// The same block can be submitted more than once; use the earliest submission.
+	var earliest int64 = -1
+	for _, bidTrace := range bidTraces {
+		if !strings.EqualFold(bidTrace.BlockHash, fmt.Sprintf("%#x", blockHash)) {
+			continue
+		}
+		timestampMs, err := strconv.ParseInt(bidTrace.TimestampMs, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid timestamp")
+		}
+		if earliest == -1 || timestampMs < earliest {
+			earliest = timestampMs
+		}
+	}
+	if earliest == -1 {
+		return nil, nil
+	}
+
+	timestamp := time.UnixMilli(earliest)
+	return &timestamp, nil
+}
+
+// get sends an HTTP get request to the relay and decodes the JSON response.
+func (s *Service) get(ctx context.Context,
+	relay *relay,
+	endpoint string,
+	params url.Values,
+	res interface{},
+) error {
+	reference, err := url.Parse(endpoint)
+	if err != nil {
+		return errors.Wrap(err, "invalid endpoint")
+	}
+	reference.RawQuery = params.Encode()
+	url := relay.base.ResolveReference(reference).String()
+	log.Trace().Str("url", url).Msg("GET request")
+
+	opCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(opCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create GET request")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call GET endpoint")
+	}
+	// skipcq:GO-S2307
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read GET response")
+	}
+
+	statusFamily := resp.StatusCode / 100
+	if statusFamily != 2 {
+		return fmt.Errorf("GET failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	log.Trace().Str("response", string(data)).Msg("GET response")
+
+	if err := json.Unmarshal(data, res); err != nil {
+		return errors.Wrap(err, "invalid response")
+	}
+
+	return nil
+}
+
+// toRelayPayload converts the JSON bid trace to a relay payload.
+func (b *bidTraceJSON) toRelayPayload(relay string) (*chaindb.RelayPayload, error) {
+	payload := &chaindb.RelayPayload{
+		Relay: relay,
+	}
+
+	slot, err := strconv.ParseUint(b.Slot, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid slot")
+	}
+	payload.Slot = phase0.Slot(slot)
+
+	if err := decodeFixedHex(b.ParentHash, payload.ParentHash[:]); err != nil {
+		return nil, errors.Wrap(err, "invalid parent hash")
+	}
+	if err := decodeFixedHex(b.BlockHash, payload.BlockHash[:]); err != nil {
+		return nil, errors.Wrap(err, "invalid block hash")
+	}
+	if err := decodeFixedHex(b.BuilderPubKey, payload.BuilderPubKey[:]); err != nil {
+		return nil, errors.Wrap(err, "invalid builder public key")
+	}
+	if err := decodeFixedHex(b.ProposerPubKey, payload.ProposerPubKey[:]); err != nil {
+		return nil, errors.Wrap(err, "invalid proposer public key")
+	}
+	if err := decodeFixedHex(b.ProposerFeeRecipient, payload.ProposerFeeRecipient[:]); err != nil {
+		return nil, errors.Wrap(err, "invalid proposer fee recipient")
+	}
+
+	payload.GasLimit, err = strconv.ParseUint(b.GasLimit, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gas limit")
+	}
+	payload.GasUsed, err = strconv.ParseUint(b.GasUsed, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gas used")
+	}
+	value, success := new(big.Int).SetString(b.Value, 10)
+	if !success {
+		return nil, errors.New("invalid value")
+	}
+	payload.Value = value
+	payload.BlockNumber, err = strconv.ParseUint(b.BlockNumber, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid block number")
+	}
+	if b.NumTx != "" {
+		payload.NumTx, err = strconv.ParseUint(b.NumTx, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid number of transactions")
+		}
+	}
+
+	return payload, nil
+}
+
+// decodeFixedHex decodes a hex string in to a fixed-length byte slice.
+func decodeFixedHex(input string, output []byte) error {
+	data, err := hex.DecodeString(strings.TrimPrefix(input, "0x"))
+	if err != nil {
+		return err
+	}
+	if len(data) != len(output) {
+		return fmt.Errorf("incorrect length %d", len(data))
+	}
+	copy(output, data)
+
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/chaindb"
+)
+
+const (
+	stubBlockHash = "0x1c5db0e5b4e8c2a0b5e1b7e6e0c1b3b2d3e5a1c6a2a0f5e1b3d4c5a6b7c8d9e0"
+	stubBidTrace  = `{"slot":"6000001","parent_hash":"0x0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9","block_hash":"` + stubBlockHash + `","builder_pubkey":"0xa1dead01e65f0a0eee7b5170223f20c8f0cbf122eac3324d61afbdb33a8885ff8cab2ef514ac2c7698ae0d6289ef27fc","proposer_pubkey":"0xb1dead01e65f0a0eee7b5170223f20c8f0cbf122eac3324d61afbdb33a8885ff8cab2ef514ac2c7698ae0d6289ef27fc","proposer_fee_recipient":"0x388c818ca8b9251b393131c08a736a67ccb19297","gas_limit":"30000000","gas_used":"12345678","value":"45678901234567890","block_number":"17000000","num_tx":"150"`
+)
+
+// stubRelay creates a stub relay that serves a single delivered payload.
+func stubRelay(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/relay/v1/data/bidtraces/proposer_payload_delivered", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") != "" {
+			// Only one page of results.
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf("[%s}]", stubBidTrace)))
+	})
+	mux.HandleFunc("/relay/v1/data/bidtraces/builder_blocks_received", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("block_hash") != stubBlockHash {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`[%s,"timestamp_ms":"1681000000500"},%s,"timestamp_ms":"1681000000250"}]`, stubBidTrace, stubBidTrace)))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestProposerPayloadsDelivered(t *testing.T) {
+	ctx := context.Background()
+	server := stubRelay(t)
+	defer server.Close()
+
+	relay, err := newRelay(server.URL)
+	require.NoError(t, err)
+	s := &Service{
+		client:             server.Client(),
+		timeout:            5 * time.Second,
+		payloadsPerRequest: 100,
+	}
+
+	payloads, err := s.proposerPayloadsDelivered(ctx, relay, nil)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	require.Equal(t, relay.name, payloads[0].Relay)
+	require.Equal(t, phase0.Slot(6000001), payloads[0].Slot)
+	require.Equal(t, stubBlockHash, fmt.Sprintf("%#x", payloads[0].BlockHash))
+	require.Equal(t, uint64(17000000), payloads[0].BlockNumber)
+	require.Equal(t, uint64(30000000), payloads[0].GasLimit)
+	require.Equal(t, uint64(12345678), payloads[0].GasUsed)
+	require.Equal(t, "45678901234567890", payloads[0].Value.String())
+	require.Equal(t, uint64(150), payloads[0].NumTx)
+
+	cursor := phase0.Slot(6000000)
+	payloads, err = s.proposerPayloadsDelivered(ctx, relay, &cursor)
+	require.NoError(t, err)
+	require.Len(t, payloads, 0)
+}
+
+func TestBidTimestamp(t *testing.T) {
+	ctx := context.Background()
+	server := stubRelay(t)
+	defer server.Close()
+
+	relay, err := newRelay(server.URL)
+	require.NoError(t, err)
+	s := &Service{
+		client:  server.Client(),
+		timeout: 5 * time.Second,
+	}
+
+	var blockHash [32]byte
+	require.NoError(t, decodeFixedHex(stubBlockHash, blockHash[:]))
+	timestamp, err := s.bidTimestamp(ctx, relay, blockHash)
+	require.NoError(t, err)
+	require.NotNil(t, timestamp)
+	require.Equal(t, int64(1681000000250), timestamp.UnixMilli())
+
+	timestamp, err = s.bidTimestamp(ctx, relay, [32]byte{})
+	require.NoError(t, err)
+	require.Nil(t, timestamp)
+}
+
+func TestNewRelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		relay   string
+		err     string
+	}{
+		{
+			name:    "Good",
+			address: "https://relay.example.com",
+			relay:   "https://relay.example.com",
+		},
+		{
+			name:    "PubKey",
+			address: "https://0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae@relay.example.com",
+			relay:   "https://relay.example.com",
+		},
+		{
+			name:    "NoScheme",
+			address: "relay.example.com",
+			relay:   "https://relay.example.com",
+		},
+		{
+			name:    "Path",
+			address: "https://relay.example.com/mainnet/",
+			relay:   "https://relay.example.com/mainnet",
+		},
+		{
+			name:    "NoHost",
+			address: "https:///path",
+			err:     "relay URL has no host",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			relay, err := newRelay(test.address)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.relay, relay.name)
+				require.Nil(t, relay.base.User)
+			}
+		})
+	}
+}
+
+func TestAddBidTimestamps(t *testing.T) {
+	ctx := context.Background()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`[%s,"timestamp_ms":"1681000000250"}]`, stubBidTrace)))
+	}))
+	defer server.Close()
+
+	relay, err := newRelay(server.URL)
+	require.NoError(t, err)
+	s := &Service{
+		client:             server.Client(),
+		timeout:            5 * time.Second,
+		bidTimestampsDelay: 10 * time.Millisecond,
+	}
+
+	payload := &chaindb.RelayPayload{
+		Slot: 6000001,
+	}
+	require.NoError(t, decodeFixedHex(stubBlockHash, payload.BlockHash[:]))
+	otherPayload := &chaindb.RelayPayload{
+		Slot: 6000002,
+	}
+
+	// Each slot should be fetched once.
+	require.NoError(t, s.addBidTimestamps(ctx, relay, []*chaindb.RelayPayload{payload, otherPayload}))
+	require.Equal(t, 2, requests)
+	require.NotNil(t, payload.BidTimestamp)
+	require.Equal(t, int64(1681000000250), payload.BidTimestamp.UnixMilli())
+	require.Nil(t, otherPayload.BidTimestamp)
+
+	// A retry should use the cached timestamps.
+	payload.BidTimestamp = nil
+	require.NoError(t, s.addBidTimestamps(ctx, relay, []*chaindb.RelayPayload{payload, otherPayload}))
+	require.Equal(t, 2, requests)
+	require.NotNil(t, payload.BidTimestamp)
+}
@@ -0,0 +1,314 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	zerologger "github.com/rs/zerolog/log"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+	"golang.org/x/sync/semaphore"
+)
+
+// module-wide log.
+var log zerolog.Logger
+
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
+// Service is an MEV relay service that fetches payloads delivered by relays through their data APIs.
+type Service struct {
+	chainDB             chaindb.Service
+	relayPayloadsSetter chaindb.RelayPayloadsSetter
+	relays              []*relay
+	client              *http.Client
+	timeout             time.Duration
+	interval            time.Duration
+	payloadsPerRequest  uint64
+	startSlot           int64
+	bidTimestamps       bool
+	bidTimestampsDelay  time.Duration
+	activitySem         *semaphore.Weighted
+	activity            *util.Activity
+}
+
+// New creates a new MEV relay service.
+func New(ctx context.Context, params ...Parameter) (*Service, error) {
+	parameters, err := parseAndCheckParameters(params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem with parameters")
+	}
+
+	// Set logging.
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "mevrelays").Str("impl", "standard").Logger())
+
+	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+		return nil, errors.New("failed to register metrics")
+	}
+
+	relays := make([]*relay, 0, len(parameters.relays))
+	for _, address := range parameters.relays {
+		relay, err := newRelay(address)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse relay")
+		}
+		relays = append(relays, relay)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:        64,
+			MaxIdleConnsPerHost: 64,
+			IdleConnTimeout:     384 * time.Second,
+		},
+	}
+
+	s := &Service{
+		chainDB:             parameters.chainDB,
+		relayPayloadsSetter: parameters.relayPayloadsSetter,
+		relays:              relays,
+		client:              client,
+		timeout:             parameters.timeout,
+		interval:            parameters.interval,
+		payloadsPerRequest:  parameters.payloadsPerRequest,
+		startSlot:           parameters.startSlot,
+		bidTimestamps:       parameters.bidTimestamps,
+		bidTimestampsDelay:  parameters.bidTimestampsDelay,
+		activitySem:         semaphore.NewWeighted(1),
+		activity:            util.NewActivity(),
+	}
+
+	go s.run(ctx)
+
+	return s, nil
+}
+
+// run polls the relays periodically.
+func (s *Service) run(ctx context.Context) {
+	s.updateRelays(ctx)
+	for {
+		select {
+		case <-time.After(s.interval):
+			s.updateRelays(ctx)
+		case <-ctx.Done():
+			log.Debug().Msg("Context done")
+			return
+		}
+	}
+}
+
+// updateRelays fetches new payloads from all relays.
+func (s *Service) updateRelays(ctx context.Context) {
+	// Only allow 1 update to be active.
+	acquired := s.activitySem.TryAcquire(1)
+	if !acquired {
+		log.Debug().Msg("Another update running")
+		return
+	}
+	defer s.activitySem.Release(1)
+
+	ctx, done, ok := s.activity.Start(ctx, "relays")
+	if !ok {
+		log.Debug().Msg("Service stopping; not updating")
+		return
+	}
+	defer done()
+
+	for _, relay := range s.relays {
+		if s.activity.Stopping() {
+			return
+		}
+		if err := s.updateRelay(ctx, relay); err != nil {
+			log.Warn().Str("relay", relay.name).Err(err).Msg("Failed to update relay payloads")
+		}
+	}
+}
+
+// updateRelay fetches and stores new payloads from a relay.
+func (s *Service) updateRelay(ctx context.Context, relay *relay) error {
+	log := log.With().Str("relay", relay.name).Logger()
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+	latestSlot, known := md.LatestSlots[relay.name]
+
+	// Payloads are provided latest first, so work backwards through pages
+	// until we reach payloads that have already been processed.
+	highestSlot := latestSlot
+	processed := 0
+	var cursor *phase0.Slot
+	for {
+		payloads, err := s.proposerPayloadsDelivered(ctx, relay, cursor)
+		if err != nil {
+			return errors.Wrap(err, "failed to obtain delivered payloads")
+		}
+		if len(payloads) == 0 {
+			break
+		}
+
+		finished := false
+		lowestSlot := payloads[0].Slot
+		newPayloads := make([]*chaindb.RelayPayload, 0, len(payloads))
+		for _, payload := range payloads {
+			if payload.Slot < lowestSlot {
+				lowestSlot = payload.Slot
+			}
+			if known && uint64(payload.Slot) <= latestSlot {
+				finished = true
+				continue
+			}
+			if !known && s.startSlot >= 0 && int64(payload.Slot) < s.startSlot {
+				finished = true
+				continue
+			}
+			if uint64(payload.Slot) > highestSlot {
+				highestSlot = uint64(payload.Slot)
+			}
+			newPayloads = append(newPayloads, payload)
+		}
+
+		if err := util.Retry(ctx, "relay payloads", func(ctx context.Context) error {
+			return s.storePayloads(ctx, relay, newPayloads)
+		}); err != nil {
+			return err
+		}
+		processed += len(newPayloads)
+
+		if finished ||
+			(!known && s.startSlot < 0) ||
+			lowestSlot == 0 ||
+			uint64(len(payloads)) < s.payloadsPerRequest {
+			break
+		}
+		nextCursor := lowestSlot - 1
+		cursor = &nextCursor
+	}
+
+	if processed == 0 {
+		log.Trace().Msg("No new payloads")
+		return nil
+	}
+
+	// Payloads are stored as they are fetched, so only the metadata needs to be updated here.
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	md, err = s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+	md.LatestSlots[relay.name] = highestSlot
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set metadata")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	monitorPayloadsProcessed(relay.name, processed, highestSlot)
+	log.Trace().Int("payloads", processed).Uint64("latest_slot", highestSlot).Msg("Processed relay payloads")
+
+	return nil
+}
+
+// storePayloads obtains additional information for, and stores, the given payloads.
+func (s *Service) storePayloads(ctx context.Context, relay *relay, payloads []*chaindb.RelayPayload) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	if s.bidTimestamps {
+		if err := s.addBidTimestamps(ctx, relay, payloads); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	if err := s.relayPayloadsSetter.SetRelayPayloads(ctx, payloads); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set relay payloads")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	for _, payload := range payloads {
+		delete(relay.bidTimestamps, payload.Slot)
+	}
+
+	return nil
+}
+
+// addBidTimestamps adds bid timestamps to the given payloads.
+// Timestamps are fetched at most once per slot, and retained until the payloads are stored, so
+// that retries do not refetch them.
+func (s *Service) addBidTimestamps(ctx context.Context, relay *relay, payloads []*chaindb.RelayPayload) error {
+	for _, payload := range payloads {
+		timestamp, exists := relay.bidTimestamps[payload.Slot]
+		if !exists {
+			// Throttle requests, as there can be many payloads to process when catching up.
+			if wait := time.Until(relay.lastBidTimestampRequest.Add(s.bidTimestampsDelay)); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			relay.lastBidTimestampRequest = time.Now()
+
+			var err error
+			timestamp, err = s.bidTimestamp(ctx, relay, payload.BlockHash)
+			if err != nil {
+				// Not all relays provide bid information, so this is not fatal.
+				log.Debug().Str("relay", relay.name).Uint64("slot", uint64(payload.Slot)).Err(err).Msg("Failed to obtain bid timestamp")
+			}
+			relay.bidTimestamps[payload.Slot] = timestamp
+		}
+		payload.BidTimestamp = timestamp
+	}
+
+	return nil
+}
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/governor"
 )
 
 // OnBeaconChainHeadUpdated receives beacon chain head updated notifications.
@@ -45,6 +46,8 @@ func (s *Service) OnBeaconChainHeadUpdated(
 
 	epoch := s.chainTime.SlotToEpoch(slot)
 	log := log.With().Uint64("epoch", uint64(epoch)).Logger()
+	// Requests made whilst following the head of the chain take priority over catchup.
+	ctx = governor.WithPriority(ctx, governor.PriorityHead)
 
 	md, err := s.getMetadata(ctx)
 	if err != nil {
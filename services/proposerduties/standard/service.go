@@ -24,6 +24,7 @@ import (
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/chaintime"
+	"github.com/wealdtech/chaind/util"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -37,11 +38,15 @@ type Service struct {
 	proposerDutiesSetter chaindb.ProposerDutiesSetter
 	chainTime            chaintime.Service
 	activitySem          *semaphore.Weighted
+	activity             *util.Activity
 }
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -50,9 +55,10 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "proposerduties").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "proposerduties").Str("impl", "standard").Logger())
 
-	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+	if err := registerMetrics(ctx, parameters.monitor, parameters.chainTime); err != nil {
 		return nil, errors.New("failed to register metrics")
 	}
 
@@ -67,6 +73,7 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		proposerDutiesSetter: proposerDutiesSetter,
 		chainTime:            parameters.chainTime,
 		activitySem:          semaphore.NewWeighted(1),
+		activity:             util.NewActivity(),
 	}
 
 	// Update to current epoch before starting (in the background).
@@ -86,6 +93,10 @@ func (s *Service) updateAfterRestart(ctx context.Context, startEpoch int64) {
 		md.LatestEpoch = startEpoch - 1
 	}
 
+	if md.LatestEpoch >= 0 {
+		monitorLatestEpoch(phase0.Epoch(md.LatestEpoch))
+	}
+
 	log.Info().Uint64("epoch", uint64(md.LatestEpoch)).Msg("Catching up from epoch")
 	s.catchup(ctx, md)
 	if len(md.MissedEpochs) > 0 {
@@ -104,6 +115,10 @@ func (s *Service) updateAfterRestart(ctx context.Context, startEpoch int64) {
 
 	// Set up the handler for new chain head updates.
 	if err := s.eth2Client.(eth2client.EventsProvider).Events(ctx, []string{"head"}, func(event *api.Event) {
+		if event.Data == nil {
+			// Happens when the channel shuts down, nothing to worry about.
+			return
+		}
 		eventData := event.Data.(*api.HeadEvent)
 		s.OnBeaconChainHeadUpdated(ctx, eventData.Slot, eventData.Block, eventData.State, eventData.EpochTransition)
 	}); err != nil {
@@ -112,8 +127,20 @@ func (s *Service) updateAfterRestart(ctx context.Context, startEpoch int64) {
 }
 
 func (s *Service) catchup(ctx context.Context, md *metadata) {
+	ctx, done, ok := s.activity.Start(ctx, "catchup")
+	if !ok {
+		log.Debug().Msg("Service stopping; not catching up")
+		return
+	}
+	defer done()
+
 	for epoch := phase0.Epoch(md.LatestEpoch + 1); epoch <= s.chainTime.CurrentEpoch(); epoch++ {
-		if err := s.UpdateEpoch(ctx, md, epoch); err != nil {
+		if s.activity.Stopping() {
+			return
+		}
+		if err := util.Retry(ctx, "proposer duties", func(ctx context.Context) error {
+			return s.UpdateEpoch(ctx, md, epoch)
+		}); err != nil {
 			log.Error().Uint64("epoch", uint64(epoch)).Err(err).Msg("Failed to catchup")
 			return
 		}
@@ -160,6 +187,31 @@ func (s *Service) UpdateEpoch(ctx context.Context,
 	return nil
 }
 
+// RefetchEpoch refetches the proposer duties for the given epoch, replacing any existing data.
+func (s *Service) RefetchEpoch(ctx context.Context, epoch phase0.Epoch) error {
+	ctx, done, ok := s.activity.Start(ctx, "refetch")
+	if !ok {
+		return errors.New("service stopping")
+	}
+	defer done()
+
+	return util.Retry(ctx, "proposer duties", func(ctx context.Context) error {
+		ctx, cancel, err := s.chainDB.BeginTx(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to begin transaction")
+		}
+		if err := s.updateProposerDutiesForEpoch(ctx, epoch); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to update proposer duties")
+		}
+		if err := s.chainDB.CommitTx(ctx); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to commit transaction")
+		}
+		return nil
+	})
+}
+
 func (s *Service) handleMissed(ctx context.Context, md *metadata) {
 	failed := 0
 	for i := 0; i < len(md.MissedEpochs); i++ {
@@ -197,3 +249,13 @@ func (s *Service) handleMissed(ctx context.Context, md *metadata) {
 		}
 	}
 }
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -0,0 +1,233 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/util"
+)
+
+// Schedule provides the times at which a recurring job runs.
+type Schedule interface {
+	// Next returns the first time after the given time at which the job should run.
+	// It returns the zero time if there is no such time.
+	Next(after time.Time) time.Time
+	// String returns the definition of the schedule.
+	String() string
+}
+
+// ParseSchedule parses a schedule.  This can be either a cron expression, for example
+// "0 3 * * *", a cron descriptor such as "@daily", or an ISO-8601 calendar duration such
+// as "P1D", which runs at the start of each calendar-aligned period.
+// All times are UTC.
+func ParseSchedule(definition string) (Schedule, error) {
+	definition = strings.TrimSpace(definition)
+	if strings.HasPrefix(definition, "P") {
+		duration, err := util.ParseCalendarDuration(definition)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid calendar duration")
+		}
+		if duration.ToDuration() <= 0 {
+			return nil, fmt.Errorf("calendar duration %q is empty", definition)
+		}
+		return &calendarSchedule{
+			definition: definition,
+			duration:   duration,
+		}, nil
+	}
+
+	return parseCronSchedule(definition)
+}
+
+// calendarSchedule runs at the start of each calendar-aligned period.
+type calendarSchedule struct {
+	definition string
+	duration   *util.CalendarDuration
+}
+
+// Next returns the first time after the given time at which the job should run.
+func (s *calendarSchedule) Next(after time.Time) time.Time {
+	after = after.UTC()
+	next := s.duration.Increment(s.duration.Truncate(after))
+	for !next.After(after) {
+		next = s.duration.Increment(next)
+	}
+	return next
+}
+
+// String returns the definition of the schedule.
+func (s *calendarSchedule) String() string {
+	return s.definition
+}
+
+// cronDescriptors are the supported shorthand cron expressions.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule runs at times matching a standard five-field cron expression.
+type cronSchedule struct {
+	definition string
+	minutes    uint64
+	hours      uint64
+	doms       uint64
+	months     uint64
+	dows       uint64
+	// domAny and dowAny are set if the respective field is "*", which affects how days match.
+	domAny bool
+	dowAny bool
+}
+
+func parseCronSchedule(definition string) (*cronSchedule, error) {
+	expression := definition
+	if descriptor, exists := cronDescriptors[definition]; exists {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", definition)
+	}
+
+	s := &cronSchedule{
+		definition: definition,
+		domAny:     fields[2] == "*",
+		dowAny:     fields[4] == "*",
+	}
+	var err error
+	if s.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrap(err, "invalid minutes")
+	}
+	if s.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrap(err, "invalid hours")
+	}
+	if s.doms, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrap(err, "invalid day of month")
+	}
+	if s.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrap(err, "invalid month")
+	}
+	if s.dows, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrap(err, "invalid day of week")
+	}
+	// Sunday can be either 0 or 7.
+	if s.dows&(1<<7) != 0 {
+		s.dows |= 1
+	}
+
+	return s, nil
+}
+
+// parseCronField parses a single cron field in to a bitmap of allowed values.
+func parseCronField(field string, low int, high int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepPart, found := strings.Cut(part, "/"); found {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			part = rangePart
+		}
+
+		start, end := low, high
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			startPart, endPart, _ := strings.Cut(part, "-")
+			var err error
+			if start, err = strconv.Atoi(startPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", startPart)
+			}
+			if end, err = strconv.Atoi(endPart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", endPart)
+			}
+		default:
+			var err error
+			if start, err = strconv.Atoi(part); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			if step == 1 {
+				end = start
+			}
+		}
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, low, high)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+// Next returns the first time after the given time at which the job should run.
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	next := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	// Any valid expression matches at least once within a few years.
+	limit := next.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		switch {
+		case s.months&(1<<uint(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hours&(1<<uint(next.Hour())) == 0:
+			next = next.Truncate(time.Hour).Add(time.Hour)
+		case s.minutes&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches returns true if the day of the given time matches the schedule.
+// As with cron, if both day of month and day of week are restricted then either can match.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.doms&(1<<uint(t.Day())) != 0
+	dowMatch := s.dows&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowMatch
+	case s.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// String returns the definition of the schedule.
+func (s *cronSchedule) String() string {
+	return s.definition
+}
@@ -0,0 +1,214 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/scheduler"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		err        string
+	}{
+		{
+			name:       "Empty",
+			definition: "",
+			err:        `cron expression "" must have 5 fields`,
+		},
+		{
+			name:       "ShortCron",
+			definition: "* * * *",
+			err:        `cron expression "* * * *" must have 5 fields`,
+		},
+		{
+			name:       "UnknownDescriptor",
+			definition: "@fortnightly",
+			err:        `cron expression "@fortnightly" must have 5 fields`,
+		},
+		{
+			name:       "MinuteOutOfRange",
+			definition: "60 * * * *",
+			err:        `invalid minutes: value "60" out of range 0-59`,
+		},
+		{
+			name:       "DayOfMonthZero",
+			definition: "0 0 0 * *",
+			err:        `invalid day of month: value "0" out of range 1-31`,
+		},
+		{
+			name:       "StepZero",
+			definition: "*/0 * * * *",
+			err:        `invalid minutes: invalid step "0"`,
+		},
+		{
+			name:       "RangeReversed",
+			definition: "0 5-1 * * *",
+			err:        `invalid hours: value "5-1" out of range 0-23`,
+		},
+		{
+			name:       "InvalidValue",
+			definition: "0 0 * jan *",
+			err:        `invalid month: invalid value "jan"`,
+		},
+		{
+			name:       "CalendarEmpty",
+			definition: "P0D",
+			err:        `calendar duration "P0D" is empty`,
+		},
+		{
+			name:       "Cron",
+			definition: "0,30 1-5/2 * * 1-5",
+		},
+		{
+			name:       "Descriptor",
+			definition: "@daily",
+		},
+		{
+			name:       "Calendar",
+			definition: "P1M",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := scheduler.ParseSchedule(test.definition)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.definition, schedule.String())
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		after      time.Time
+		next       time.Time
+	}{
+		{
+			name:       "CronDaily",
+			definition: "0 3 * * *",
+			after:      time.Date(2023, 1, 1, 5, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "CronSameDay",
+			definition: "0 3 * * *",
+			after:      time.Date(2023, 1, 1, 2, 59, 59, 0, time.UTC),
+			next:       time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "CronExact",
+			definition: "0 3 * * *",
+			after:      time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "CronStep",
+			definition: "*/15 * * * *",
+			after:      time.Date(2023, 1, 1, 10, 7, 30, 0, time.UTC),
+			next:       time.Date(2023, 1, 1, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:       "Hourly",
+			definition: "@hourly",
+			after:      time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 1, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "DayOfWeek",
+			definition: "0 0 * * 1",
+			after:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "SundayAsSeven",
+			definition: "0 0 * * 7",
+			after:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "DayOfMonthList",
+			definition: "0 0 1,15 * *",
+			after:      time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "DayOfMonthOrWeek",
+			definition: "0 0 13 * 5",
+			after:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "LeapDay",
+			definition: "0 0 29 2 *",
+			after:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:       time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Never",
+			definition: "0 0 31 2 *",
+			after:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:       time.Time{},
+		},
+		{
+			name:       "NonUTC",
+			definition: "0 0 * * *",
+			after:      time.Date(2023, 1, 1, 23, 0, 0, 0, time.FixedZone("EST", -5*60*60)),
+			next:       time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "CalendarDay",
+			definition: "P1D",
+			after:      time.Date(2023, 1, 1, 5, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "CalendarMonth",
+			definition: "P1M",
+			after:      time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "CalendarHours",
+			definition: "PT6H",
+			after:      time.Date(2023, 1, 1, 7, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "CalendarExact",
+			definition: "PT6H",
+			after:      time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+			next:       time.Date(2023, 1, 1, 18, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := scheduler.ParseSchedule(test.definition)
+			require.NoError(t, err)
+			require.Equal(t, test.next, schedule.Next(test.after))
+		})
+	}
+}
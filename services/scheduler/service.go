@@ -22,6 +22,20 @@ import (
 // JobFunc is the type for jobs.
 type JobFunc func(context.Context, interface{})
 
+// RecurringJobFunc is the type for recurring jobs.
+// The error returned is recorded in the job's history.
+type RecurringJobFunc func(context.Context, interface{}) error
+
+// JobRun is a record of a single run of a job.
+type JobRun struct {
+	// Started is the time at which the run started.
+	Started time.Time `json:"started"`
+	// Duration is the time taken by the run.
+	Duration time.Duration `json:"duration"`
+	// Error is the error returned by the run, if any.
+	Error string `json:"error,omitempty"`
+}
+
 // RuntimeFunc is the type of a function that generates the next runtime.
 type RuntimeFunc func(context.Context, interface{}) (time.Time, error)
 
@@ -46,9 +60,19 @@ var ErrNoJobName = errors.New("no job name")
 // ErrNoJobFunc is returned when an attempt is made to run a nil job.
 var ErrNoJobFunc = errors.New("no job function")
 
+// ErrNoSchedule is returned when an attempt is made to run a recurring job without a schedule.
+var ErrNoSchedule = errors.New("no schedule")
+
 // ErrNoRuntimeFunc is returned when an attempt is made to run a periodic job without a runtime function.
 var ErrNoRuntimeFunc = errors.New("no runtime function")
 
+// RecurringJobPruner is the interface for schedulers that persist recurring jobs.
+type RecurringJobPruner interface {
+	// PruneRecurringJobs removes the persisted information about recurring jobs that have not
+	// been scheduled since the scheduler started.
+	PruneRecurringJobs(ctx context.Context) error
+}
+
 // Service is the interface for schedulers.
 type Service interface {
 	// ScheduleJob schedules a one-off job for a given time.
@@ -63,6 +87,11 @@ type Service interface {
 	// Once jobFunc returns, go back to the beginning of the loop.
 	SchedulePeriodicJob(ctx context.Context, class string, name string, runtime RuntimeFunc, runtimeData interface{}, job JobFunc, jobData interface{}) error
 
+	// ScheduleRecurringJob schedules a job to run according to a schedule.
+	// If the scheduler persists jobs and a run of the job was missed, for example because the
+	// process was not running when it was due, the job runs immediately.
+	ScheduleRecurringJob(ctx context.Context, class string, name string, schedule Schedule, job RecurringJobFunc, data interface{}) error
+
 	// CancelJob cancels a known job.
 	// If this is a period job then all future instances are cancelled.
 	CancelJob(ctx context.Context, name string) error
@@ -88,4 +117,7 @@ type Service interface {
 
 	// ListJobs returns the names of all jobs.
 	ListJobs(ctx context.Context) []string
+
+	// JobHistory returns the recent runs of a job, oldest first.
+	JobHistory(ctx context.Context, name string) []*JobRun
 }
@@ -0,0 +1,69 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/scheduler"
+)
+
+// metadata stored about this service.
+type metadata struct {
+	Jobs map[string]*jobMetadata `json:"jobs"`
+}
+
+// jobMetadata is the metadata stored about a recurring job.
+type jobMetadata struct {
+	Class    string `json:"class"`
+	Schedule string `json:"schedule"`
+	// LastRun is the time at which the job last started; zero if it has never run.
+	LastRun time.Time           `json:"last_run"`
+	History []*scheduler.JobRun `json:"history,omitempty"`
+}
+
+// metadataKey is the key for the metadata.
+var metadataKey = "scheduler.standard"
+
+// getMetadata gets metadata for this service.
+func (s *Service) getMetadata(ctx context.Context) (*metadata, error) {
+	md := &metadata{
+		Jobs: make(map[string]*jobMetadata),
+	}
+	mdJSON, err := s.chainDB.Metadata(ctx, metadataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch metadata")
+	}
+	if mdJSON == nil {
+		return md, nil
+	}
+	if err := json.Unmarshal(mdJSON, md); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal metadata")
+	}
+	if md.Jobs == nil {
+		md.Jobs = make(map[string]*jobMetadata)
+	}
+	return md, nil
+}
+
+// setMetadata sets metadata for this service, given its JSON representation.
+func (s *Service) setMetadata(ctx context.Context, mdJSON []byte) error {
+	if err := s.chainDB.SetMetadata(ctx, metadataKey, mdJSON); err != nil {
+		return errors.Wrap(err, "failed to update metadata")
+	}
+	return nil
+}
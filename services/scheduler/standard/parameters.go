@@ -14,14 +14,18 @@
 package standard
 
 import (
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/metrics"
 	nullmetrics "github.com/wealdtech/chaind/services/metrics/null"
 )
 
 type parameters struct {
-	logLevel zerolog.Level
-	monitor  metrics.Service
+	logLevel      zerolog.Level
+	monitor       metrics.Service
+	chainDB       chaindb.Service
+	historyLength int
 }
 
 // Parameter is the interface for service parameters.
@@ -49,12 +53,26 @@ func WithMonitor(monitor metrics.Service) Parameter {
 	})
 }
 
+// WithChainDB sets the chain database in which recurring jobs are persisted.
+// If not set, recurring jobs are held in memory only.
+func WithChainDB(chainDB chaindb.Service) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.chainDB = chainDB
+	})
+}
+
+// WithHistoryLength sets the number of runs kept in the history of each job.
+func WithHistoryLength(historyLength int) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.historyLength = historyLength
+	})
+}
+
 // parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
-//
-//nolint:unparam
 func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	parameters := parameters{
-		logLevel: zerolog.GlobalLevel(),
+		logLevel:      zerolog.GlobalLevel(),
+		historyLength: 20,
 	}
 	for _, p := range params {
 		if params != nil {
@@ -65,6 +83,9 @@ func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	if parameters.monitor == nil {
 		parameters.monitor = &nullmetrics.Service{}
 	}
+	if parameters.historyLength < 1 {
+		return nil, errors.New("history length must be at least 1")
+	}
 
 	return &parameters, nil
 }
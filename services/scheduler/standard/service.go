@@ -15,6 +15,7 @@ package standard
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -22,13 +23,18 @@ import (
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/sasha-s/go-deadlock"
+	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/scheduler"
+	"github.com/wealdtech/chaind/util"
 	"go.uber.org/atomic"
 )
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // job contains control points for a job.
 type job struct {
 	// stateLock is required for active or finalised.
@@ -44,8 +50,20 @@ type job struct {
 // the state of each job, in an attempt to ensure additional robustness in the face
 // of high concurrent load.
 type Service struct {
-	jobs      map[string]*job
-	jobsMutex deadlock.RWMutex
+	jobs          map[string]*job
+	jobsMutex     deadlock.RWMutex
+	activity      *util.Activity
+	chainDB       chaindb.Service
+	historyLength int
+	// stateMutex is required for md, mdVersion, recurring and history.
+	stateMutex deadlock.Mutex
+	md         *metadata
+	mdVersion  uint64
+	recurring  map[string]bool
+	history    map[string][]*scheduler.JobRun
+	// persistMutex is required for persistedVersion.
+	persistMutex     deadlock.Mutex
+	persistedVersion uint64
 }
 
 // New creates a new scheduling service.
@@ -56,18 +74,37 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "scheduler").Str("impl", "advanced").Logger()
-	if parameters.logLevel != log.GetLevel() {
-		log = log.Level(parameters.logLevel)
-	}
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "scheduler").Str("impl", "advanced").Logger())
 
 	if err := registerMetrics(ctx, parameters.monitor); err != nil {
 		return nil, errors.New("failed to register metrics")
 	}
 
-	return &Service{
-		jobs: make(map[string]*job),
-	}, nil
+	s := &Service{
+		jobs:          make(map[string]*job),
+		activity:      util.NewActivity(),
+		chainDB:       parameters.chainDB,
+		historyLength: parameters.historyLength,
+		md: &metadata{
+			Jobs: make(map[string]*jobMetadata),
+		},
+		recurring: make(map[string]bool),
+		history:   make(map[string][]*scheduler.JobRun),
+	}
+
+	if s.chainDB != nil {
+		md, err := s.getMetadata(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to obtain metadata")
+		}
+		s.md = md
+		for name, jobMD := range md.Jobs {
+			s.history[name] = s.trimHistory(jobMD.History)
+		}
+	}
+
+	return s, nil
 }
 
 // ScheduleJob schedules a one-off job for a given time.
@@ -121,7 +158,7 @@ func (s *Service) ScheduleJob(ctx context.Context,
 			// If we receive this signal the job has already been deleted from the jobs list so no need to
 			// do so again here.
 			jobStartedOnSignal(class)
-			jobFunc(ctx, data)
+			s.runJobFunc(ctx, name, func(ctx context.Context) error { jobFunc(ctx, data); return nil })
 			log.Trace().Str("job", name).Time("scheduled", runtime).Msg("Job complete")
 			finaliseJob(job)
 			job.active.Store(false)
@@ -137,7 +174,7 @@ func (s *Service) ScheduleJob(ctx context.Context,
 			log.Trace().Str("job", name).Time("scheduled", runtime).Msg("Timer triggered; job running")
 			job.active.Store(true)
 			jobStartedOnTimer(class)
-			jobFunc(ctx, data)
+			s.runJobFunc(ctx, name, func(ctx context.Context) error { jobFunc(ctx, data); return nil })
 			log.Trace().Str("job", name).Time("scheduled", runtime).Msg("Job complete")
 			job.active.Store(false)
 			finaliseJob(job)
@@ -169,6 +206,80 @@ func (s *Service) SchedulePeriodicJob(ctx context.Context,
 		return scheduler.ErrNoJobFunc
 	}
 
+	return s.schedulePeriodicJob(ctx, class, name, runtimeFunc, runtimeData, func(ctx context.Context) error {
+		jobFunc(ctx, jobData)
+		return nil
+	})
+}
+
+// ScheduleRecurringJob schedules a job to run according to a schedule.
+// If the scheduler persists jobs and a run of the job was missed, for example because the
+// process was not running when it was due, the job runs immediately.
+func (s *Service) ScheduleRecurringJob(ctx context.Context,
+	class string,
+	name string,
+	schedule scheduler.Schedule,
+	jobFunc scheduler.RecurringJobFunc,
+	data interface{},
+) error {
+	if name == "" {
+		return scheduler.ErrNoJobName
+	}
+	if schedule == nil {
+		return scheduler.ErrNoSchedule
+	}
+	if jobFunc == nil {
+		return scheduler.ErrNoJobFunc
+	}
+
+	s.stateMutex.Lock()
+	jobMD, exists := s.md.Jobs[name]
+	if !exists {
+		jobMD = &jobMetadata{}
+		s.md.Jobs[name] = jobMD
+	}
+	if exists && jobMD.Schedule != schedule.String() {
+		log.Info().Str("job", name).Str("old_schedule", jobMD.Schedule).Str("new_schedule", schedule.String()).Msg("Schedule changed")
+	}
+	jobMD.Class = class
+	jobMD.Schedule = schedule.String()
+	s.recurring[name] = true
+	s.stateMutex.Unlock()
+	if err := s.persist(ctx); err != nil {
+		log.Warn().Str("job", name).Err(err).Msg("Failed to persist job")
+	}
+
+	runtimeFunc := func(_ context.Context, _ interface{}) (time.Time, error) {
+		s.stateMutex.Lock()
+		lastRun := s.md.Jobs[name].LastRun
+		s.stateMutex.Unlock()
+
+		// If the job has run before then the next run follows on from that, which will be in the
+		// past if a run was missed.
+		from := lastRun
+		if from.IsZero() {
+			from = time.Now()
+		}
+		next := schedule.Next(from)
+		if next.IsZero() {
+			return time.Time{}, scheduler.ErrNoMoreInstances
+		}
+		return next, nil
+	}
+
+	return s.schedulePeriodicJob(ctx, class, name, runtimeFunc, nil, func(ctx context.Context) error {
+		return jobFunc(ctx, data)
+	})
+}
+
+// schedulePeriodicJob schedules a job to run in a loop.
+func (s *Service) schedulePeriodicJob(ctx context.Context,
+	class string,
+	name string,
+	runtimeFunc scheduler.RuntimeFunc,
+	runtimeData interface{},
+	jobFunc func(context.Context) error,
+) error {
 	s.jobsMutex.Lock()
 	if _, exists := s.jobs[name]; exists {
 		s.jobsMutex.Unlock()
@@ -223,7 +334,7 @@ func (s *Service) SchedulePeriodicJob(ctx context.Context,
 			case <-job.runCh:
 				log.Trace().Str("job", name).Time("scheduled", runtime).Msg("Run triggered; job running")
 				jobStartedOnSignal(class)
-				jobFunc(ctx, jobData)
+				s.runJobFunc(ctx, name, jobFunc)
 				log.Trace().Str("job", name).Time("scheduled", runtime).Msg("Job complete")
 				job.active.Store(false)
 			case <-time.After(time.Until(runtime)):
@@ -234,7 +345,7 @@ func (s *Service) SchedulePeriodicJob(ctx context.Context,
 				job.active.Store(true)
 				log.Trace().Str("job", name).Time("scheduled", runtime).Msg("Timer triggered; job running")
 				jobStartedOnTimer(class)
-				jobFunc(ctx, jobData)
+				s.runJobFunc(ctx, name, jobFunc)
 				log.Trace().Str("job", name).Time("scheduled", runtime).Msg("Job complete")
 				job.active.Store(false)
 			}
@@ -291,6 +402,20 @@ func (s *Service) JobExists(_ context.Context, name string) bool {
 	return exists
 }
 
+// JobHistory returns the recent runs of a job, oldest first.
+func (s *Service) JobHistory(_ context.Context, name string) []*scheduler.JobRun {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
+	history := make([]*scheduler.JobRun, 0, len(s.history[name]))
+	for _, run := range s.history[name] {
+		runCopy := *run
+		history = append(history, &runCopy)
+	}
+
+	return history
+}
+
 // ListJobs returns the names of all jobs.
 func (s *Service) ListJobs(_ context.Context) []string {
 	s.jobsMutex.RLock()
@@ -353,6 +478,124 @@ func (s *Service) CancelJobs(ctx context.Context, prefix string) {
 	}
 }
 
+// Stop cancels all jobs, and waits for running jobs to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	s.CancelJobs(ctx, "")
+	return s.activity.Stop(ctx)
+}
+
+// runJobFunc runs the function for a job, tracking it until it completes and recording the run.
+func (s *Service) runJobFunc(ctx context.Context, name string, jobFunc func(context.Context) error) {
+	ctx, done, ok := s.activity.Start(ctx, name)
+	if !ok {
+		log.Trace().Str("job", name).Msg("Scheduler stopping; job not running")
+		return
+	}
+	defer done()
+
+	run := &scheduler.JobRun{
+		Started: time.Now(),
+	}
+	err := jobFunc(ctx)
+	run.Duration = time.Since(run.Started)
+	if err != nil {
+		log.Warn().Str("job", name).Err(err).Msg("Job failed")
+		run.Error = err.Error()
+	}
+
+	s.recordRun(ctx, name, run)
+}
+
+// recordRun records a run of a job in its history, persisting it for recurring jobs.
+func (s *Service) recordRun(ctx context.Context, name string, run *scheduler.JobRun) {
+	s.stateMutex.Lock()
+	history := s.trimHistory(append(s.history[name], run))
+	s.history[name] = history
+	recurring := s.recurring[name]
+	if recurring {
+		jobMD := s.md.Jobs[name]
+		jobMD.LastRun = run.Started
+		jobMD.History = history
+	}
+	s.stateMutex.Unlock()
+
+	if recurring {
+		if err := s.persist(ctx); err != nil {
+			log.Warn().Str("job", name).Err(err).Msg("Failed to persist job run")
+		}
+	}
+}
+
+// trimHistory trims a history to the configured length, keeping the most recent runs.
+func (s *Service) trimHistory(history []*scheduler.JobRun) []*scheduler.JobRun {
+	if len(history) <= s.historyLength {
+		return history
+	}
+	return history[len(history)-s.historyLength:]
+}
+
+// persist persists the state of recurring jobs, if the scheduler has a database.
+func (s *Service) persist(ctx context.Context) error {
+	if s.chainDB == nil {
+		return nil
+	}
+
+	// Snapshot the state, so that it is not locked whilst writing to the database.
+	s.stateMutex.Lock()
+	mdJSON, err := json.Marshal(s.md)
+	s.mdVersion++
+	version := s.mdVersion
+	s.stateMutex.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal metadata")
+	}
+
+	// Write snapshots one at a time, skipping any that have been overtaken by a later snapshot.
+	s.persistMutex.Lock()
+	defer s.persistMutex.Unlock()
+	if version < s.persistedVersion {
+		return nil
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	if err := s.setMetadata(ctx, mdJSON); err != nil {
+		cancel()
+		return err
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	s.persistedVersion = version
+
+	return nil
+}
+
+// PruneRecurringJobs removes the persisted information about recurring jobs that have not been
+// scheduled since the scheduler started, for example because they have been removed or renamed.
+func (s *Service) PruneRecurringJobs(ctx context.Context) error {
+	s.stateMutex.Lock()
+	pruned := 0
+	for name := range s.md.Jobs {
+		if !s.recurring[name] {
+			delete(s.md.Jobs, name)
+			delete(s.history, name)
+			pruned++
+		}
+	}
+	s.stateMutex.Unlock()
+
+	if pruned == 0 {
+		return nil
+	}
+	log.Debug().Int("jobs", pruned).Msg("Pruned recurring jobs that are no longer scheduled")
+
+	return s.persist(ctx)
+}
+
 // finaliseJob tidies up a job that is no longer in use.
 func finaliseJob(job *job) {
 	job.stateLock.Lock()
@@ -383,3 +626,8 @@ func (*Service) runJob(_ context.Context, job *job) error {
 
 	return nil
 }
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/chaindb"
 	nullmetrics "github.com/wealdtech/chaind/services/metrics/null"
 	"github.com/wealdtech/chaind/services/scheduler"
 	"github.com/wealdtech/chaind/services/scheduler/standard"
@@ -636,3 +637,228 @@ func TestCancelWhilstRunning(t *testing.T) {
 	time.Sleep(time.Duration(120) * time.Millisecond)
 	assert.Equal(t, 1, run)
 }
+
+func TestStop(t *testing.T) {
+	ctx := context.Background()
+	s, err := standard.New(ctx, standard.WithLogLevel(zerolog.Disabled), standard.WithMonitor(&nullmetrics.Service{}))
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	var run uint32
+	runFunc := func(ctx context.Context, data interface{}) {
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddUint32(&run, 1)
+	}
+
+	require.NoError(t, s.ScheduleJob(ctx, "Test", "Running job", time.Now(), runFunc, nil))
+	require.NoError(t, s.ScheduleJob(ctx, "Test", "Future job", time.Now().Add(time.Minute), runFunc, nil))
+	time.Sleep(10 * time.Millisecond)
+
+	// Stop should wait for the running job, and cancel the future job.
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.Empty(t, s.Stop(stopCtx))
+	require.Equal(t, uint32(1), atomic.LoadUint32(&run))
+	require.Empty(t, s.ListJobs(ctx))
+
+	// Jobs cannot run once stopped.
+	require.NoError(t, s.ScheduleJob(ctx, "Test", "Late job", time.Now(), runFunc, nil))
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, uint32(1), atomic.LoadUint32(&run))
+}
+
+// intervalSchedule is a schedule that runs at a fixed interval after the previous run.
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+func (s intervalSchedule) String() string {
+	return time.Duration(s).String()
+}
+
+// memoryDB is a chain database that holds metadata in memory.
+type memoryDB struct {
+	mutex    sync.Mutex
+	metadata map[string][]byte
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{
+		metadata: make(map[string][]byte),
+	}
+}
+
+func (*memoryDB) BeginTx(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	return ctx, func() {}, nil
+}
+
+func (*memoryDB) CommitTx(_ context.Context) error {
+	return nil
+}
+
+func (*memoryDB) BeginROTx(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (*memoryDB) CommitROTx(_ context.Context) {}
+
+func (d *memoryDB) SetMetadata(_ context.Context, key string, value []byte) error {
+	d.mutex.Lock()
+	d.metadata[key] = value
+	d.mutex.Unlock()
+	return nil
+}
+
+func (d *memoryDB) Metadata(_ context.Context, key string) ([]byte, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.metadata[key], nil
+}
+
+var _ chaindb.Service = (*memoryDB)(nil)
+
+func TestBadRecurringJobs(t *testing.T) {
+	ctx := context.Background()
+	s, err := standard.New(ctx, standard.WithLogLevel(zerolog.Disabled), standard.WithMonitor(&nullmetrics.Service{}))
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	runFunc := func(ctx context.Context, data interface{}) error {
+		return nil
+	}
+
+	assert.EqualError(t, s.ScheduleRecurringJob(ctx, "Test", "", intervalSchedule(time.Hour), runFunc, nil), scheduler.ErrNoJobName.Error())
+	assert.EqualError(t, s.ScheduleRecurringJob(ctx, "Test", "Test job", nil, runFunc, nil), scheduler.ErrNoSchedule.Error())
+	assert.EqualError(t, s.ScheduleRecurringJob(ctx, "Test", "Test job", intervalSchedule(time.Hour), nil, nil), scheduler.ErrNoJobFunc.Error())
+}
+
+func TestHistoryLength(t *testing.T) {
+	_, err := standard.New(context.Background(), standard.WithLogLevel(zerolog.Disabled), standard.WithHistoryLength(0))
+	require.EqualError(t, err, "problem with parameters: history length must be at least 1")
+}
+
+func TestRecurringJobHistory(t *testing.T) {
+	ctx := context.Background()
+	s, err := standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithMonitor(&nullmetrics.Service{}),
+		standard.WithHistoryLength(2),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	run := 0
+	runFunc := func(ctx context.Context, data interface{}) error {
+		run++
+		if run == 2 {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	require.NoError(t, s.ScheduleRecurringJob(ctx, "Test", "Test recurring job", intervalSchedule(time.Hour), runFunc, nil))
+	require.Empty(t, s.JobHistory(ctx, "Test recurring job"))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.RunJob(ctx, "Test recurring job"))
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, 3, run)
+
+	// History only holds the most recent runs.
+	history := s.JobHistory(ctx, "Test recurring job")
+	require.Len(t, history, 2)
+	require.Equal(t, "failed", history[0].Error)
+	require.Equal(t, "", history[1].Error)
+	require.True(t, history[0].Started.Before(history[1].Started))
+
+	require.NoError(t, s.CancelJob(ctx, "Test recurring job"))
+	require.Empty(t, s.JobHistory(ctx, "Unknown job"))
+}
+
+func TestRecurringJobPersistence(t *testing.T) {
+	ctx := context.Background()
+	db := newMemoryDB()
+	s, err := standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithMonitor(&nullmetrics.Service{}),
+		standard.WithChainDB(db),
+	)
+	require.NoError(t, err)
+
+	var run uint32
+	runFunc := func(ctx context.Context, data interface{}) error {
+		atomic.AddUint32(&run, 1)
+		return nil
+	}
+
+	require.NoError(t, s.ScheduleRecurringJob(ctx, "Test", "Test recurring job", intervalSchedule(100*time.Millisecond), runFunc, nil))
+	require.NoError(t, s.RunJob(ctx, "Test recurring job"))
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, uint32(1), atomic.LoadUint32(&run))
+	require.Empty(t, s.Stop(ctx))
+
+	// Wait until the next run has been missed.
+	time.Sleep(150 * time.Millisecond)
+
+	// A new scheduler picks up the history, and runs the missed job immediately.
+	s, err = standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithMonitor(&nullmetrics.Service{}),
+		standard.WithChainDB(db),
+	)
+	require.NoError(t, err)
+	require.Len(t, s.JobHistory(ctx, "Test recurring job"), 1)
+	require.NoError(t, s.ScheduleRecurringJob(ctx, "Test", "Test recurring job", intervalSchedule(100*time.Millisecond), runFunc, nil))
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, uint32(2), atomic.LoadUint32(&run))
+	require.Len(t, s.JobHistory(ctx, "Test recurring job"), 2)
+	require.Empty(t, s.Stop(ctx))
+}
+
+func TestPruneRecurringJobs(t *testing.T) {
+	ctx := context.Background()
+	db := newMemoryDB()
+	s, err := standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithMonitor(&nullmetrics.Service{}),
+		standard.WithChainDB(db),
+	)
+	require.NoError(t, err)
+
+	runFunc := func(ctx context.Context, data interface{}) error {
+		return nil
+	}
+
+	require.NoError(t, s.ScheduleRecurringJob(ctx, "Test", "Kept job", intervalSchedule(time.Hour), runFunc, nil))
+	require.NoError(t, s.ScheduleRecurringJob(ctx, "Test", "Removed job", intervalSchedule(time.Hour), runFunc, nil))
+	require.NoError(t, s.RunJob(ctx, "Kept job"))
+	require.NoError(t, s.RunJob(ctx, "Removed job"))
+	time.Sleep(10 * time.Millisecond)
+	require.Empty(t, s.Stop(ctx))
+
+	// A new scheduler that only schedules one of the jobs prunes the other.
+	s, err = standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithMonitor(&nullmetrics.Service{}),
+		standard.WithChainDB(db),
+	)
+	require.NoError(t, err)
+	require.NoError(t, s.ScheduleRecurringJob(ctx, "Test", "Kept job", intervalSchedule(time.Hour), runFunc, nil))
+	require.NoError(t, s.PruneRecurringJobs(ctx))
+	require.Len(t, s.JobHistory(ctx, "Kept job"), 1)
+	require.Empty(t, s.JobHistory(ctx, "Removed job"))
+	require.Empty(t, s.Stop(ctx))
+
+	// The pruning is persisted.
+	s, err = standard.New(ctx,
+		standard.WithLogLevel(zerolog.Disabled),
+		standard.WithMonitor(&nullmetrics.Service{}),
+		standard.WithChainDB(db),
+	)
+	require.NoError(t, err)
+	require.Len(t, s.JobHistory(ctx, "Kept job"), 1)
+	require.Empty(t, s.JobHistory(ctx, "Removed job"))
+	require.Empty(t, s.Stop(ctx))
+}
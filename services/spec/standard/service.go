@@ -15,13 +15,14 @@ package standard
 
 import (
 	"context"
-	"time"
 
 	eth2client "github.com/attestantio/go-eth2-client"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/scheduler"
+	"github.com/wealdtech/chaind/util"
 )
 
 // Service is a spec service.
@@ -36,6 +37,9 @@ type Service struct {
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -44,10 +48,8 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "spec").Str("impl", "standard").Logger()
-	if parameters.logLevel != log.GetLevel() {
-		log = log.Level(parameters.logLevel)
-	}
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "spec").Str("impl", "standard").Logger())
 
 	chainSpecSetter, isChainSpecSetter := parameters.chainDB.(chaindb.ChainSpecSetter)
 	if !isChainSpecSetter {
@@ -76,19 +78,19 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	// is available to other modules when they start.
 	s.updateSpec(ctx)
 
-	// Set up a periodic refresh of the spec information.
-	runtimeFunc := func(ctx context.Context, data interface{}) (time.Time, error) {
-		// Run daily.
-		return time.Now().AddDate(0, 0, 1), nil
+	// Set up a daily refresh of the spec information.
+	schedule, err := scheduler.ParseSchedule("@daily")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse schedule")
 	}
-	jobFunc := func(ctx context.Context, data interface{}) {
+	jobFunc := func(ctx context.Context, data interface{}) error {
 		log.Trace().Msg("Updating spec")
 		s := data.(*Service)
 		s.updateSpec(ctx)
+		return nil
 	}
-	if err := parameters.scheduler.SchedulePeriodicJob(ctx, "spec", "update spec",
-		runtimeFunc,
-		nil,
+	if err := parameters.scheduler.ScheduleRecurringJob(ctx, "spec", "update spec",
+		schedule,
 		jobFunc,
 		s,
 	); err != nil {
@@ -168,3 +170,8 @@ func (s *Service) updateForkSchedule(ctx context.Context) error {
 
 	return nil
 }
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
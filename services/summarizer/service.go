@@ -13,5 +13,17 @@
 
 package summarizer
 
+import (
+	"context"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
 // Service is a summarizer service.
 type Service interface{}
+
+// Resummarizer is the interface for a summarizer that can recompute existing summaries.
+type Resummarizer interface {
+	// Resummarize recomputes the summaries for the given range of epochs, inclusive.
+	Resummarize(ctx context.Context, fromEpoch phase0.Epoch, toEpoch phase0.Epoch) error
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
 )
 
 // summarizeBlocksInEpoch summarizes all blocks in the given epoch.
@@ -37,10 +38,26 @@ func (s *Service) summarizeBlocksInEpoch(ctx context.Context,
 	maxSlot := s.chainTime.LastSlotOfEpoch(epoch)
 	log.Trace().Uint64("min_slot", uint64(minSlot)).Uint64("max_slot", uint64(maxSlot)).Msg("Summarizing blocks for epoch")
 
+	clientSummaries := make(map[[2]string]*chaindb.ClientEpochSummary)
 	for slot := minSlot; slot <= maxSlot; slot++ {
-		if err := s.summarizeBlock(ctx, slot); err != nil {
+		summary, err := s.summarizeBlock(ctx, slot)
+		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to create summary for block %d", slot))
 		}
+		if summary == nil {
+			continue
+		}
+		key := [2]string{summary.ConsensusClient, summary.ExecutionClient}
+		clientSummary, exists := clientSummaries[key]
+		if !exists {
+			clientSummary = &chaindb.ClientEpochSummary{
+				Epoch:           epoch,
+				ConsensusClient: summary.ConsensusClient,
+				ExecutionClient: summary.ExecutionClient,
+			}
+			clientSummaries[key] = clientSummary
+		}
+		clientSummary.Proposals++
 	}
 	md.LastBlockEpoch = epoch
 
@@ -48,6 +65,14 @@ func (s *Service) summarizeBlocksInEpoch(ctx context.Context,
 	if err != nil {
 		return errors.Wrap(err, "failed to begin transaction to set summarizer metadata for block")
 	}
+	summaries := make([]*chaindb.ClientEpochSummary, 0, len(clientSummaries))
+	for _, summary := range clientSummaries {
+		summaries = append(summaries, summary)
+	}
+	if err := s.chainDB.(chaindb.ClientSummariesSetter).SetClientEpochSummaries(ctx, epoch, summaries); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set client epoch summaries")
+	}
 	if err := s.setMetadata(ctx, md); err != nil {
 		cancel()
 		return errors.Wrap(err, "failed to set summarizer metadata for block")
@@ -61,18 +86,19 @@ func (s *Service) summarizeBlocksInEpoch(ctx context.Context,
 }
 
 // summarizeBlock summarizes the block at the given slot.
-func (s *Service) summarizeBlock(ctx context.Context, slot phase0.Slot) error {
+// It returns the summary, or nil if there is no canonical block at the slot.
+func (s *Service) summarizeBlock(ctx context.Context, slot phase0.Slot) (*chaindb.BlockSummary, error) {
 	summary := &chaindb.BlockSummary{
 		Slot: slot,
 	}
 
 	blocks, err := s.blocksProvider.BlocksBySlot(ctx, slot)
 	if err != nil {
-		return errors.Wrap(err, "failed to obtain blocks for slot")
+		return nil, errors.Wrap(err, "failed to obtain blocks for slot")
 	}
 	if len(blocks) == 0 {
 		// No block for this slot.
-		return nil
+		return nil, nil
 	}
 
 	var block *chaindb.Block
@@ -84,32 +110,44 @@ func (s *Service) summarizeBlock(ctx context.Context, slot phase0.Slot) error {
 	}
 	if block == nil {
 		// No canonical block for this slot.
-		return nil
+		return nil, nil
 	}
 	log.Trace().Uint64("slot", uint64(slot)).Msg("Summarising block")
 
 	if err := s.attestationStatsForBlock(ctx, slot, summary, block); err != nil {
-		return errors.Wrap(err, "failed to calculate block attestation summary statistics for epoch")
+		return nil, errors.Wrap(err, "failed to calculate block attestation summary statistics for epoch")
 	}
 
 	if err := s.parentDistanceForBlock(ctx, slot, summary, block); err != nil {
-		return errors.Wrap(err, "failed to calculate parent distance summary statistics for epoch")
+		return nil, errors.Wrap(err, "failed to calculate parent distance summary statistics for epoch")
+	}
+
+	if err := s.packingForBlock(ctx, slot, summary, block); err != nil {
+		return nil, errors.Wrap(err, "failed to calculate packing summary statistics for block")
+	}
+
+	consensusReward, err := s.consensusRewardForBlock(ctx, block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain consensus reward for block")
 	}
+	summary.ConsensusReward = consensusReward
+
+	summary.ConsensusClient, summary.ExecutionClient = util.ClassifyGraffiti(block.Graffiti)
 
 	ctx, cancel, err := s.chainDB.BeginTx(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction to set block summary")
+		return nil, errors.Wrap(err, "failed to begin transaction to set block summary")
 	}
 	if err := s.chainDB.(chaindb.BlockSummariesSetter).SetBlockSummary(ctx, summary); err != nil {
 		cancel()
-		return errors.Wrap(err, "failed to set block summary")
+		return nil, errors.Wrap(err, "failed to set block summary")
 	}
 	if err := s.chainDB.CommitTx(ctx); err != nil {
 		cancel()
-		return errors.Wrap(err, "failed to set commit transaction to set block summary")
+		return nil, errors.Wrap(err, "failed to set commit transaction to set block summary")
 	}
 
-	return nil
+	return summary, nil
 }
 
 func (s *Service) attestationStatsForBlock(ctx context.Context,
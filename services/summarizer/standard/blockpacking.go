@@ -0,0 +1,110 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+)
+
+// attesterVote is a vote from a validator for a given slot.
+type attesterVote struct {
+	slot  phase0.Slot
+	index phase0.ValidatorIndex
+}
+
+// packingForBlock calculates how well the proposer packed attestations into the block.
+//
+// A vote is available to a block if it was made within the inclusion window before the block's slot
+// and had not been included in an earlier canonical block.  Votes are only known to exist if they
+// were included in a canonical block at some point, so votes that were never included are not
+// counted as available.
+func (s *Service) packingForBlock(ctx context.Context,
+	slot phase0.Slot,
+	summary *chaindb.BlockSummary,
+	block *chaindb.Block,
+) error {
+	if slot == 0 {
+		return nil
+	}
+
+	log.Trace().Uint64("slot", uint64(slot)).Str("root", fmt.Sprintf("%#x", block.Root)).Msg("Fetching attestations for inclusion window")
+
+	windowStart := phase0.Slot(0)
+	if uint64(slot) > s.chainTime.SlotsPerEpoch() {
+		windowStart = slot - phase0.Slot(s.chainTime.SlotsPerEpoch())
+	}
+	attestations, err := s.attestationsProvider.AttestationsForSlotRange(ctx, windowStart, slot)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain attestations for inclusion window")
+	}
+
+	// Find the first canonical inclusion of each vote, and the aggregates in this block.
+	firstInclusions := make(map[attesterVote]phase0.Slot)
+	blockAttestations := make([]*chaindb.Attestation, 0)
+	for _, attestation := range attestations {
+		if attestation.Canonical == nil || !*attestation.Canonical {
+			continue
+		}
+		if attestation.InclusionBlockRoot == block.Root {
+			blockAttestations = append(blockAttestations, attestation)
+		}
+		for _, index := range attestation.AggregationIndices {
+			vote := attesterVote{slot: attestation.Slot, index: index}
+			if inclusionSlot, exists := firstInclusions[vote]; !exists || attestation.InclusionSlot < inclusionSlot {
+				firstInclusions[vote] = attestation.InclusionSlot
+			}
+		}
+	}
+
+	availableVotes := 0
+	for _, inclusionSlot := range firstInclusions {
+		if inclusionSlot >= slot {
+			availableVotes++
+		}
+	}
+
+	sort.Slice(blockAttestations, func(i int, j int) bool {
+		return blockAttestations[i].InclusionIndex < blockAttestations[j].InclusionIndex
+	})
+	newVotes := 0
+	redundantAggregates := 0
+	includedVotes := make(map[attesterVote]bool)
+	for _, attestation := range blockAttestations {
+		redundant := true
+		for _, index := range attestation.AggregationIndices {
+			vote := attesterVote{slot: attestation.Slot, index: index}
+			if firstInclusions[vote] < slot || includedVotes[vote] {
+				continue
+			}
+			includedVotes[vote] = true
+			newVotes++
+			redundant = false
+		}
+		if redundant {
+			redundantAggregates++
+		}
+	}
+
+	summary.NewVotes = &newVotes
+	summary.AvailableVotes = &availableVotes
+	summary.RedundantAggregates = &redundantAggregates
+
+	return nil
+}
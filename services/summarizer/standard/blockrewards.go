@@ -0,0 +1,420 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/governor"
+)
+
+// Participation flag weights, as defined in the Altair specification.
+const (
+	timelySourceWeight = 14
+	timelyTargetWeight = 26
+	timelyHeadWeight   = 14
+	syncRewardWeight   = 2
+	proposerWeight     = 8
+	weightDenominator  = 64
+)
+
+// Participation flags.
+const (
+	timelySourceFlag = 1 << iota
+	timelyTargetFlag
+	timelyHeadFlag
+)
+
+// blockRewardsEpoch holds the per-epoch information required to calculate block rewards.
+type blockRewardsEpoch struct {
+	epoch                  phase0.Epoch
+	baseRewardPerIncrement phase0.Gwei
+	totalActiveIncrements  uint64
+	effectiveBalances      map[phase0.ValidatorIndex]phase0.Gwei
+	// slashedValidators are the validators that have been slashed at any point up to the present.
+	slashedValidators map[phase0.ValidatorIndex]bool
+}
+
+// consensusRewardForBlock obtains the consensus reward for the given block.
+// The reward is obtained from the beacon node if possible, otherwise it is calculated.
+// It returns nil if the reward cannot be obtained.
+func (s *Service) consensusRewardForBlock(ctx context.Context,
+	block *chaindb.Block,
+) (
+	*chaindb.BlockReward,
+	error,
+) {
+	if provider, isProvider := s.eth2Client.(governor.BlockRewardsProvider); isProvider {
+		rewards, err := provider.BlockRewards(ctx, fmt.Sprintf("%d", block.Slot))
+		if err == nil {
+			return &chaindb.BlockReward{
+				Total:             rewards.Total,
+				Attestations:      rewards.Attestations,
+				SyncAggregate:     rewards.SyncAggregate,
+				ProposerSlashings: rewards.ProposerSlashings,
+				AttesterSlashings: rewards.AttesterSlashings,
+			}, nil
+		}
+		log.Trace().Uint64("slot", uint64(block.Slot)).Err(err).Msg("Block reward not available from beacon node; calculating")
+	}
+
+	return s.calculateBlockReward(ctx, block)
+}
+
+// calculateBlockReward calculates the reward for the given block from the information in the database.
+// Rewards can only be calculated for Altair and later blocks; prior to that proposer rewards were paid
+// at the end of the epoch, so nil is returned.
+func (s *Service) calculateBlockReward(ctx context.Context,
+	block *chaindb.Block,
+) (
+	*chaindb.BlockReward,
+	error,
+) {
+	epoch := s.chainTime.SlotToEpoch(block.Slot)
+	if epoch < s.chainTime.AltairInitialEpoch() {
+		return nil, nil
+	}
+
+	rewardsEpoch, err := s.blockRewardsEpochInfo(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	if rewardsEpoch == nil {
+		// Balances not available.
+		return nil, nil
+	}
+
+	reward := &chaindb.BlockReward{}
+	reward.Attestations, err = s.attestationsReward(ctx, block, rewardsEpoch)
+	if err != nil {
+		return nil, err
+	}
+	reward.SyncAggregate, err = s.syncAggregateReward(ctx, block, rewardsEpoch)
+	if err != nil {
+		return nil, err
+	}
+	reward.ProposerSlashings, reward.AttesterSlashings, err = s.slashingsReward(ctx, block, rewardsEpoch)
+	if err != nil {
+		return nil, err
+	}
+	reward.Total = reward.Attestations + reward.SyncAggregate + reward.ProposerSlashings + reward.AttesterSlashings
+
+	return reward, nil
+}
+
+// blockRewardsEpochInfo obtains the information required to calculate block rewards for the given epoch.
+// It returns nil if validator balances are not available for the epoch.
+func (s *Service) blockRewardsEpochInfo(ctx context.Context, epoch phase0.Epoch) (*blockRewardsEpoch, error) {
+	// Blocks can be summarized concurrently, so the cached information is only accessed under the lock.
+	// The information itself is not altered once created, so can be used outside of the lock.
+	s.blockRewardsEpochMu.Lock()
+	cached := s.blockRewardsEpoch
+	s.blockRewardsEpochMu.Unlock()
+	if cached != nil && cached.epoch == epoch {
+		return cached, nil
+	}
+
+	validators, err := s.validatorsProvider.Validators(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain validators")
+	}
+	balances, err := s.validatorsProvider.ValidatorBalancesByEpoch(ctx, epoch)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain validator balances")
+	}
+	if len(balances) == 0 {
+		return nil, nil
+	}
+
+	effectiveBalances := make(map[phase0.ValidatorIndex]phase0.Gwei, len(balances))
+	for _, balance := range balances {
+		effectiveBalances[balance.Index] = balance.EffectiveBalance
+	}
+	totalActiveBalance := phase0.Gwei(0)
+	slashedValidators := make(map[phase0.ValidatorIndex]bool)
+	for _, validator := range validators {
+		if validator.ActivationEpoch <= epoch && validator.ExitEpoch > epoch {
+			totalActiveBalance += effectiveBalances[validator.Index]
+		}
+		if validator.Slashed {
+			slashedValidators[validator.Index] = true
+		}
+	}
+	if totalActiveBalance < s.effectiveBalanceIncrement {
+		totalActiveBalance = s.effectiveBalanceIncrement
+	}
+
+	rewardsEpoch := &blockRewardsEpoch{
+		epoch:                  epoch,
+		baseRewardPerIncrement: s.effectiveBalanceIncrement * phase0.Gwei(s.baseRewardFactor) / phase0.Gwei(integerSquareRoot(uint64(totalActiveBalance))),
+		totalActiveIncrements:  uint64(totalActiveBalance / s.effectiveBalanceIncrement),
+		effectiveBalances:      effectiveBalances,
+		slashedValidators:      slashedValidators,
+	}
+	s.blockRewardsEpochMu.Lock()
+	s.blockRewardsEpoch = rewardsEpoch
+	s.blockRewardsEpochMu.Unlock()
+
+	return rewardsEpoch, nil
+}
+
+// attestationsReward calculates the proposer reward for including attestations in the given block.
+func (s *Service) attestationsReward(ctx context.Context,
+	block *chaindb.Block,
+	rewardsEpoch *blockRewardsEpoch,
+) (
+	phase0.Gwei,
+	error,
+) {
+	attestations, err := s.attestationsProvider.AttestationsInBlock(ctx, block.Root)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to obtain attestations in block")
+	}
+	if len(attestations) == 0 {
+		return 0, nil
+	}
+	sort.Slice(attestations, func(i int, j int) bool {
+		return attestations[i].InclusionIndex < attestations[j].InclusionIndex
+	})
+
+	// Proposers are only rewarded for flags that were not already set by attestations in earlier blocks.
+	type participationKey struct {
+		index phase0.ValidatorIndex
+		epoch phase0.Epoch
+	}
+	participation := make(map[participationKey]int)
+	minSlot := block.Slot
+	for _, attestation := range attestations {
+		if attestation.Slot < minSlot {
+			minSlot = attestation.Slot
+		}
+	}
+	priorAttestations, err := s.attestationsProvider.AttestationsForSlotRange(ctx, minSlot, block.Slot)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to obtain prior attestations")
+	}
+	for _, attestation := range priorAttestations {
+		if attestation.InclusionSlot >= block.Slot {
+			continue
+		}
+		if attestation.Canonical != nil && !*attestation.Canonical {
+			continue
+		}
+		flags := s.participationFlags(attestation, attestation.InclusionSlot)
+		for _, index := range attestation.AggregationIndices {
+			participation[participationKey{index: index, epoch: attestation.TargetEpoch}] |= flags
+		}
+	}
+
+	numerator := uint64(0)
+	for _, attestation := range attestations {
+		flags := s.participationFlags(attestation, block.Slot)
+		for _, index := range attestation.AggregationIndices {
+			key := participationKey{index: index, epoch: attestation.TargetEpoch}
+			newFlags := flags &^ participation[key]
+			if newFlags == 0 {
+				continue
+			}
+			participation[key] |= newFlags
+			baseReward := uint64(rewardsEpoch.effectiveBalances[index]/s.effectiveBalanceIncrement) * uint64(rewardsEpoch.baseRewardPerIncrement)
+			if newFlags&timelySourceFlag != 0 {
+				numerator += baseReward * timelySourceWeight
+			}
+			if newFlags&timelyTargetFlag != 0 {
+				numerator += baseReward * timelyTargetWeight
+			}
+			if newFlags&timelyHeadFlag != 0 {
+				numerator += baseReward * timelyHeadWeight
+			}
+		}
+	}
+
+	return phase0.Gwei(numerator / ((weightDenominator - proposerWeight) * weightDenominator / proposerWeight)), nil
+}
+
+// participationFlags returns the participation flags earned by an attestation included at the given slot.
+func (s *Service) participationFlags(attestation *chaindb.Attestation, inclusionSlot phase0.Slot) int {
+	flags := 0
+	inclusionDelay := uint64(inclusionSlot - attestation.Slot)
+	targetCorrect := attestation.TargetCorrect != nil && *attestation.TargetCorrect
+	headCorrect := attestation.HeadCorrect != nil && *attestation.HeadCorrect
+	if inclusionDelay <= s.maxTimelyAttestationSourceDelay {
+		flags |= timelySourceFlag
+	}
+	if targetCorrect && inclusionDelay <= s.maxTimelyAttestationTargetDelay {
+		flags |= timelyTargetFlag
+	}
+	if targetCorrect && headCorrect && inclusionDelay == s.maxTimelyAttestationHeadDelay {
+		flags |= timelyHeadFlag
+	}
+
+	return flags
+}
+
+// syncAggregateReward calculates the proposer reward for including the sync aggregate in the given block.
+func (s *Service) syncAggregateReward(ctx context.Context,
+	block *chaindb.Block,
+	rewardsEpoch *blockRewardsEpoch,
+) (
+	phase0.Gwei,
+	error,
+) {
+	syncAggregates, err := s.chainDB.(chaindb.SyncAggregateProvider).SyncAggregates(ctx, &chaindb.SyncAggregateFilter{
+		From: &block.Slot,
+		To:   &block.Slot,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to obtain sync aggregates")
+	}
+
+	participants := 0
+	for _, syncAggregate := range syncAggregates {
+		if syncAggregate.InclusionBlockRoot == block.Root {
+			participants += len(syncAggregate.Indices)
+		}
+	}
+
+	return phase0.Gwei(uint64(participants) * proposerSyncReward(rewardsEpoch, s.chainTime.SlotsPerEpoch(), s.syncCommitteeSize)), nil
+}
+
+// proposerSyncReward calculates the proposer reward for each participant in a sync aggregate.
+func proposerSyncReward(rewardsEpoch *blockRewardsEpoch, slotsPerEpoch uint64, syncCommitteeSize uint64) uint64 {
+	if syncCommitteeSize == 0 || slotsPerEpoch == 0 {
+		return 0
+	}
+	totalBaseRewards := uint64(rewardsEpoch.baseRewardPerIncrement) * rewardsEpoch.totalActiveIncrements
+	maxParticipantRewards := totalBaseRewards * syncRewardWeight / weightDenominator / slotsPerEpoch
+	participantReward := maxParticipantRewards / syncCommitteeSize
+
+	return participantReward * proposerWeight / (weightDenominator - proposerWeight)
+}
+
+// slashingsReward calculates the proposer reward for including slashings in the given block.
+func (s *Service) slashingsReward(ctx context.Context,
+	block *chaindb.Block,
+	rewardsEpoch *blockRewardsEpoch,
+) (
+	phase0.Gwei,
+	phase0.Gwei,
+	error,
+) {
+	// The proposer is also the whistleblower, so obtains the full whistleblower reward.
+	// Proposer slashings are processed before attester slashings, so validators slashed by them are
+	// not slashed again.
+	slashed := make(map[phase0.ValidatorIndex]bool)
+	proposerSlashingsReward := phase0.Gwei(0)
+	proposerSlashings, err := s.proposerSlashingsProvider.ProposerSlashingsForSlotRange(ctx, block.Slot, block.Slot+1)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to obtain proposer slashings")
+	}
+	for _, slashing := range proposerSlashings {
+		if slashing.InclusionBlockRoot != block.Root {
+			continue
+		}
+		slashed[slashing.Header1ProposerIndex] = true
+		proposerSlashingsReward += rewardsEpoch.effectiveBalances[slashing.Header1ProposerIndex] / phase0.Gwei(s.whistleblowerRewardQuotient)
+	}
+
+	attesterSlashingsReward := phase0.Gwei(0)
+	attesterSlashings, err := s.attesterSlashingsProvider.AttesterSlashingsForSlotRange(ctx, block.Slot, block.Slot+1)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to obtain attester slashings")
+	}
+	for _, slashing := range attesterSlashings {
+		if slashing.InclusionBlockRoot != block.Root {
+			continue
+		}
+		attestation2Indices := make(map[phase0.ValidatorIndex]bool, len(slashing.Attestation2Indices))
+		for _, index := range slashing.Attestation2Indices {
+			attestation2Indices[index] = true
+		}
+		for _, index := range slashing.Attestation1Indices {
+			if !attestation2Indices[index] || slashed[index] {
+				continue
+			}
+			slashed[index] = true
+			// Validators that were already slashed are not slashed again, so provide no reward.
+			if rewardsEpoch.slashedValidators[index] {
+				previouslySlashed, err := s.slashedBeforeBlock(ctx, index, block)
+				if err != nil {
+					return 0, 0, err
+				}
+				if previouslySlashed {
+					continue
+				}
+			}
+			attesterSlashingsReward += rewardsEpoch.effectiveBalances[index] / phase0.Gwei(s.whistleblowerRewardQuotient)
+		}
+	}
+
+	return proposerSlashingsReward, attesterSlashingsReward, nil
+}
+
+// slashedBeforeBlock returns true if the given validator was slashed by a canonical block prior to the given block.
+func (s *Service) slashedBeforeBlock(ctx context.Context,
+	index phase0.ValidatorIndex,
+	block *chaindb.Block,
+) (
+	bool,
+	error,
+) {
+	inclusionBlockRoots := make([]phase0.Root, 0)
+	proposerSlashings, err := s.proposerSlashingsProvider.ProposerSlashingsForValidator(ctx, index)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to obtain proposer slashings for validator")
+	}
+	for _, slashing := range proposerSlashings {
+		if slashing.InclusionSlot < block.Slot {
+			inclusionBlockRoots = append(inclusionBlockRoots, slashing.InclusionBlockRoot)
+		}
+	}
+	attesterSlashings, err := s.attesterSlashingsProvider.AttesterSlashingsForValidator(ctx, index)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to obtain attester slashings for validator")
+	}
+	for _, slashing := range attesterSlashings {
+		if slashing.InclusionSlot < block.Slot {
+			inclusionBlockRoots = append(inclusionBlockRoots, slashing.InclusionBlockRoot)
+		}
+	}
+
+	for _, root := range inclusionBlockRoots {
+		inclusionBlock, err := s.blocksProvider.BlockByRoot(ctx, root)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to obtain block including slashing")
+		}
+		if inclusionBlock != nil && inclusionBlock.Canonical != nil && *inclusionBlock.Canonical {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// integerSquareRoot returns the largest integer whose square is not greater than n.
+func integerSquareRoot(n uint64) uint64 {
+	x := n
+	y := (x + 1) / 2
+	for y < x {
+		x = y
+		y = (x + n/x) / 2
+	}
+
+	return x
+}
@@ -0,0 +1,209 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/chaindb"
+)
+
+// slashingsDB provides slashings and blocks from memory.
+type slashingsDB struct {
+	chaindb.ProposerSlashingsProvider
+	chaindb.AttesterSlashingsProvider
+	chaindb.BlocksProvider
+	proposerSlashings []*chaindb.ProposerSlashing
+	attesterSlashings []*chaindb.AttesterSlashing
+	blocks            map[phase0.Root]*chaindb.Block
+}
+
+func (d *slashingsDB) ProposerSlashingsForSlotRange(_ context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]*chaindb.ProposerSlashing, error) {
+	res := make([]*chaindb.ProposerSlashing, 0)
+	for _, slashing := range d.proposerSlashings {
+		if slashing.InclusionSlot >= minSlot && slashing.InclusionSlot < maxSlot {
+			res = append(res, slashing)
+		}
+	}
+	return res, nil
+}
+
+func (d *slashingsDB) ProposerSlashingsForValidator(_ context.Context, index phase0.ValidatorIndex) ([]*chaindb.ProposerSlashing, error) {
+	res := make([]*chaindb.ProposerSlashing, 0)
+	for _, slashing := range d.proposerSlashings {
+		if slashing.Header1ProposerIndex == index {
+			res = append(res, slashing)
+		}
+	}
+	return res, nil
+}
+
+func (d *slashingsDB) AttesterSlashingsForSlotRange(_ context.Context, minSlot phase0.Slot, maxSlot phase0.Slot) ([]*chaindb.AttesterSlashing, error) {
+	res := make([]*chaindb.AttesterSlashing, 0)
+	for _, slashing := range d.attesterSlashings {
+		if slashing.InclusionSlot >= minSlot && slashing.InclusionSlot < maxSlot {
+			res = append(res, slashing)
+		}
+	}
+	return res, nil
+}
+
+func (d *slashingsDB) AttesterSlashingsForValidator(_ context.Context, index phase0.ValidatorIndex) ([]*chaindb.AttesterSlashing, error) {
+	res := make([]*chaindb.AttesterSlashing, 0)
+	for _, slashing := range d.attesterSlashings {
+		found := 0
+		for _, indices := range [][]phase0.ValidatorIndex{slashing.Attestation1Indices, slashing.Attestation2Indices} {
+			for _, i := range indices {
+				if i == index {
+					found++
+					break
+				}
+			}
+		}
+		if found == 2 {
+			res = append(res, slashing)
+		}
+	}
+	return res, nil
+}
+
+func (d *slashingsDB) BlockByRoot(_ context.Context, root phase0.Root) (*chaindb.Block, error) {
+	return d.blocks[root], nil
+}
+
+func TestSlashingsReward(t *testing.T) {
+	ctx := context.Background()
+	canonical := true
+	noncanonical := false
+
+	blocks := map[phase0.Root]*chaindb.Block{
+		{0x01}: {Slot: 10, Root: phase0.Root{0x01}, Canonical: &canonical},
+		{0x02}: {Slot: 11, Root: phase0.Root{0x02}, Canonical: &noncanonical},
+		{0x03}: {Slot: 20, Root: phase0.Root{0x03}, Canonical: &canonical},
+	}
+	rewardsEpoch := &blockRewardsEpoch{
+		effectiveBalances: map[phase0.ValidatorIndex]phase0.Gwei{
+			1: 32000000000,
+			2: 32000000000,
+			3: 32000000000,
+			4: 32000000000,
+		},
+		slashedValidators: map[phase0.ValidatorIndex]bool{
+			1: true,
+			2: true,
+			3: true,
+			4: true,
+		},
+	}
+
+	tests := []struct {
+		name                    string
+		proposerSlashings       []*chaindb.ProposerSlashing
+		attesterSlashings       []*chaindb.AttesterSlashing
+		proposerSlashingsReward phase0.Gwei
+		attesterSlashingsReward phase0.Gwei
+	}{
+		{
+			name: "AttesterSlashing",
+			attesterSlashings: []*chaindb.AttesterSlashing{
+				{
+					InclusionSlot:       20,
+					InclusionBlockRoot:  phase0.Root{0x03},
+					Attestation1Indices: []phase0.ValidatorIndex{1, 2, 3},
+					Attestation2Indices: []phase0.ValidatorIndex{2, 3, 4},
+				},
+			},
+			attesterSlashingsReward: 2 * 62500000,
+		},
+		{
+			name: "PreviouslySlashed",
+			attesterSlashings: []*chaindb.AttesterSlashing{
+				{
+					InclusionSlot:       10,
+					InclusionBlockRoot:  phase0.Root{0x01},
+					Attestation1Indices: []phase0.ValidatorIndex{2},
+					Attestation2Indices: []phase0.ValidatorIndex{2},
+				},
+				{
+					InclusionSlot:       20,
+					InclusionBlockRoot:  phase0.Root{0x03},
+					Attestation1Indices: []phase0.ValidatorIndex{2, 3},
+					Attestation2Indices: []phase0.ValidatorIndex{2, 3},
+				},
+			},
+			attesterSlashingsReward: 62500000,
+		},
+		{
+			name: "PreviouslySlashedNonCanonical",
+			attesterSlashings: []*chaindb.AttesterSlashing{
+				{
+					InclusionSlot:       11,
+					InclusionBlockRoot:  phase0.Root{0x02},
+					Attestation1Indices: []phase0.ValidatorIndex{2},
+					Attestation2Indices: []phase0.ValidatorIndex{2},
+				},
+				{
+					InclusionSlot:       20,
+					InclusionBlockRoot:  phase0.Root{0x03},
+					Attestation1Indices: []phase0.ValidatorIndex{2, 3},
+					Attestation2Indices: []phase0.ValidatorIndex{2, 3},
+				},
+			},
+			attesterSlashingsReward: 2 * 62500000,
+		},
+		{
+			name: "SlashedByProposerSlashingInBlock",
+			proposerSlashings: []*chaindb.ProposerSlashing{
+				{
+					InclusionSlot:        20,
+					InclusionBlockRoot:   phase0.Root{0x03},
+					Header1ProposerIndex: 2,
+				},
+			},
+			attesterSlashings: []*chaindb.AttesterSlashing{
+				{
+					InclusionSlot:       20,
+					InclusionBlockRoot:  phase0.Root{0x03},
+					Attestation1Indices: []phase0.ValidatorIndex{2, 3},
+					Attestation2Indices: []phase0.ValidatorIndex{2, 3},
+				},
+			},
+			proposerSlashingsReward: 62500000,
+			attesterSlashingsReward: 62500000,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := &slashingsDB{
+				proposerSlashings: test.proposerSlashings,
+				attesterSlashings: test.attesterSlashings,
+				blocks:            blocks,
+			}
+			s := &Service{
+				proposerSlashingsProvider:   db,
+				attesterSlashingsProvider:   db,
+				blocksProvider:              db,
+				whistleblowerRewardQuotient: 512,
+			}
+			proposerSlashingsReward, attesterSlashingsReward, err := s.slashingsReward(ctx, blocks[phase0.Root{0x03}], rewardsEpoch)
+			require.NoError(t, err)
+			require.Equal(t, test.proposerSlashingsReward, proposerSlashingsReward)
+			require.Equal(t, test.attesterSlashingsReward, attesterSlashingsReward)
+		})
+	}
+}
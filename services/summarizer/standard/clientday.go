@@ -0,0 +1,145 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// summarizeClientDays summarizes the clients that proposed blocks for all days that
+// have fully finalized by the given epoch.
+func (s *Service) summarizeClientDays(ctx context.Context, summaryEpoch phase0.Epoch) error {
+	if !s.blockSummaries {
+		return nil
+	}
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata for client day summarizer")
+	}
+
+	var startTime time.Time
+	if md.LastClientDay == -1 {
+		// Start at the beginning of the day in which genesis occurred.
+		genesis := s.chainTime.StartOfEpoch(0).In(time.UTC)
+		startTime = time.Date(genesis.Year(), genesis.Month(), genesis.Day(), 0, 0, 0, 0, time.UTC)
+	} else {
+		startTime = time.Unix(md.LastClientDay, 0).In(time.UTC).AddDate(0, 0, 1)
+	}
+	// Only summarize days that have completely finalized.
+	finalizedTime := s.chainTime.StartOfEpoch(summaryEpoch + 1)
+
+	days := uint64(0)
+	for timestamp := startTime; !timestamp.AddDate(0, 0, 1).After(finalizedTime); timestamp = timestamp.AddDate(0, 0, 1) {
+		if days == s.maxDaysPerRun.Load() {
+			log.Trace().Uint64("days", days).Msg("Reached maximum days for this run")
+			break
+		}
+		if err := util.Retry(ctx, "client day summaries", func(ctx context.Context) error {
+			return s.summarizeClientsInDay(ctx, timestamp)
+		}); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to update client summaries for day %s", timestamp.Format("2006-01-02")))
+		}
+		days++
+	}
+
+	return nil
+}
+
+// summarizeClientsInDay updates the client summaries in a given day.
+func (s *Service) summarizeClientsInDay(ctx context.Context,
+	startTime time.Time,
+) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "summarizeClientsInDay",
+		trace.WithAttributes(
+			attribute.Int64("start time", startTime.Unix()),
+		))
+	defer span.End()
+
+	log := log.With().Str("date", startTime.Format("2006-01-02")).Logger()
+	endTime := startTime.AddDate(0, 0, 1)
+	startSlot := s.chainTime.TimestampToSlot(startTime)
+	endSlot := s.chainTime.TimestampToSlot(endTime)
+	log.Trace().Uint64("start_slot", uint64(startSlot)).Uint64("end_slot", uint64(endSlot)).Msg("Summarizing client day")
+
+	blocks, err := s.blocksProvider.BlocksForSlotRange(ctx, startSlot, endSlot)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain blocks")
+	}
+	span.AddEvent("Obtained blocks")
+
+	daySummaries := make(map[[2]string]*chaindb.ClientDaySummary)
+	for _, block := range blocks {
+		if block.Canonical == nil || !*block.Canonical {
+			continue
+		}
+		consensusClient, executionClient := util.ClassifyGraffiti(block.Graffiti)
+		key := [2]string{consensusClient, executionClient}
+		summary, exists := daySummaries[key]
+		if !exists {
+			summary = &chaindb.ClientDaySummary{
+				StartTimestamp:  startTime,
+				ConsensusClient: consensusClient,
+				ExecutionClient: executionClient,
+			}
+			daySummaries[key] = summary
+		}
+		summary.Proposals++
+	}
+
+	summaries := make([]*chaindb.ClientDaySummary, 0, len(daySummaries))
+	for _, summary := range daySummaries {
+		summaries = append(summaries, summary)
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to set client day summaries")
+	}
+
+	if err := s.chainDB.(chaindb.ClientSummariesSetter).SetClientDaySummaries(ctx, startTime, summaries); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set client day summaries")
+	}
+
+	// Fetch updated metadata as it may have changed since we last obtained it.
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata for client day summarizer")
+	}
+	md.LastClientDay = startTime.Unix()
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set summarizer metadata for client day summary")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set commit transaction to set client day summary")
+	}
+
+	log.Trace().Int("clients", len(summaries)).Msg("Set client day summaries")
+
+	return nil
+}
@@ -0,0 +1,377 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// reconcileDeposits links Ethereum 1 deposits with the deposits included in
+// canonical beacon blocks up to the end of the given epoch.
+func (s *Service) reconcileDeposits(ctx context.Context, summaryEpoch phase0.Epoch) error {
+	if !s.depositReconciliation {
+		return nil
+	}
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata for deposit reconciliation")
+	}
+
+	startSlot := phase0.Slot(md.LastReconciledDepositSlot + 1)
+	endSlot := s.chainTime.FirstSlotOfEpoch(summaryEpoch + 1)
+	maxSlotsPerRun := phase0.Slot(s.maxDaysPerRun.Load() * uint64(s.epochsPerDay()) * s.chainTime.SlotsPerEpoch())
+	if endSlot-startSlot > maxSlotsPerRun {
+		endSlot = startSlot + maxSlotsPerRun
+	}
+	if startSlot >= endSlot {
+		log.Trace().Msg("No slots to reconcile deposits")
+		return nil
+	}
+
+	return util.Retry(ctx, "deposit reconciliation", func(ctx context.Context) error {
+		return s.reconcileDepositsInSlotRange(ctx, md, startSlot, endSlot)
+	})
+}
+
+// reconcileDepositsInSlotRange reconciles deposits in the given range.
+// Ranges are inclusive of start and exclusive of end.
+func (s *Service) reconcileDepositsInSlotRange(ctx context.Context,
+	md *metadata,
+	startSlot phase0.Slot,
+	endSlot phase0.Slot,
+) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "reconcileDepositsInSlotRange",
+		trace.WithAttributes(
+			attribute.Int64("start slot", int64(startSlot)),
+			attribute.Int64("end slot", int64(endSlot)),
+		))
+	defer span.End()
+
+	log := log.With().Uint64("start_slot", uint64(startSlot)).Uint64("end_slot", uint64(endSlot)).Logger()
+	log.Trace().Msg("Reconciling deposits")
+
+	deposits, err := s.canonicalDepositsForSlotRange(ctx, startSlot, endSlot)
+	if err != nil {
+		return err
+	}
+
+	reconciledDeposits := make([]*chaindb.ReconciledDeposit, 0, len(deposits))
+	nextDepositIndex := md.NextReconciledDepositIndex
+	if len(deposits) > 0 {
+		reconciledDeposits, nextDepositIndex, err = s.reconcileBeaconDeposits(ctx, deposits, nextDepositIndex)
+		if err != nil {
+			return err
+		}
+		if reconciledDeposits == nil {
+			log.Debug().Msg("Ethereum 1 deposits not yet available; cannot reconcile")
+			return nil
+		}
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to set reconciled deposits")
+	}
+
+	if err := s.chainDB.(chaindb.ReconciledDepositsSetter).SetReconciledDeposits(ctx, reconciledDeposits); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set reconciled deposits")
+	}
+
+	// Fetch updated metadata as it may have changed since we last obtained it.
+	md, err = s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata for deposit reconciliation")
+	}
+	md.LastReconciledDepositSlot = int64(endSlot) - 1
+	md.NextReconciledDepositIndex = nextDepositIndex
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set summarizer metadata for deposit reconciliation")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set commit transaction to set reconciled deposits")
+	}
+
+	log.Trace().Int("deposits", len(reconciledDeposits)).Msg("Reconciled deposits")
+
+	return nil
+}
+
+// canonicalDepositsForSlotRange returns the deposits in canonical blocks for the given range,
+// in the order in which they were processed by the beacon chain.
+func (s *Service) canonicalDepositsForSlotRange(ctx context.Context,
+	startSlot phase0.Slot,
+	endSlot phase0.Slot,
+) (
+	[]*chaindb.Deposit,
+	error,
+) {
+	blocks, err := s.blocksProvider.BlocksForSlotRange(ctx, startSlot, endSlot)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain blocks")
+	}
+	canonicalRoots := make(map[phase0.Root]bool, len(blocks))
+	for _, block := range blocks {
+		if block.Canonical != nil && *block.Canonical {
+			canonicalRoots[block.Root] = true
+		}
+	}
+
+	deposits, err := s.depositsProvider.DepositsForSlotRange(ctx, startSlot, endSlot)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain deposits")
+	}
+
+	canonicalDeposits := make([]*chaindb.Deposit, 0, len(deposits))
+	for _, deposit := range deposits {
+		if canonicalRoots[deposit.InclusionBlockRoot] {
+			canonicalDeposits = append(canonicalDeposits, deposit)
+		}
+	}
+	sort.Slice(canonicalDeposits, func(i int, j int) bool {
+		if canonicalDeposits[i].InclusionSlot != canonicalDeposits[j].InclusionSlot {
+			return canonicalDeposits[i].InclusionSlot < canonicalDeposits[j].InclusionSlot
+		}
+		return canonicalDeposits[i].InclusionIndex < canonicalDeposits[j].InclusionIndex
+	})
+
+	return canonicalDeposits, nil
+}
+
+// reconcileBeaconDeposits matches beacon chain deposits with their Ethereum 1 deposits.
+// If the Ethereum 1 deposits are not yet available this returns nil.
+func (s *Service) reconcileBeaconDeposits(ctx context.Context,
+	deposits []*chaindb.Deposit,
+	nextDepositIndex int64,
+) (
+	[]*chaindb.ReconciledDeposit,
+	int64,
+	error,
+) {
+	pubKeys := make([]phase0.BLSPubKey, 0, len(deposits))
+	seen := make(map[phase0.BLSPubKey]bool)
+	for _, deposit := range deposits {
+		if !seen[deposit.ValidatorPubKey] {
+			pubKeys = append(pubKeys, deposit.ValidatorPubKey)
+			seen[deposit.ValidatorPubKey] = true
+		}
+	}
+
+	eth1Deposits, err := s.eth1DepositsProvider.ETH1DepositsByPublicKey(ctx, pubKeys)
+	if err != nil {
+		return nil, -1, errors.Wrap(err, "failed to obtain Ethereum 1 deposits")
+	}
+	eth1DepositsByIndex := make(map[uint64]*chaindb.ETH1Deposit, len(eth1Deposits))
+	for _, eth1Deposit := range eth1Deposits {
+		eth1DepositsByIndex[eth1Deposit.DepositIndex] = eth1Deposit
+	}
+
+	if nextDepositIndex == -1 {
+		// Deposits included in the genesis state are not in blocks, so the index of
+		// the first deposit in a block is the number of deposits processed at genesis.
+		genesisDepositIndex, err := s.genesisDepositIndex(ctx)
+		if err != nil {
+			return nil, -1, err
+		}
+		mismatch := fmt.Errorf("first deposit in a block at slot %d does not match Ethereum 1 deposit %d following genesis deposits", deposits[0].InclusionSlot, genesisDepositIndex)
+		eth1Deposit, exists := eth1DepositsByIndex[genesisDepositIndex]
+		if !exists {
+			// Ethereum 1 deposits are obtained in order, so if a later deposit is present then this
+			// deposit is for a different validator.
+			for _, eth1Deposit := range eth1Deposits {
+				if eth1Deposit.DepositIndex > genesisDepositIndex {
+					return nil, -1, mismatch
+				}
+			}
+			return nil, -1, nil
+		}
+		// Identical deposits are possible, so this only confirms the index rather than finding it.
+		if eth1Deposit.ValidatorPubKey != deposits[0].ValidatorPubKey ||
+			eth1Deposit.Amount != deposits[0].Amount ||
+			!bytes.Equal(eth1Deposit.WithdrawalCredentials, deposits[0].WithdrawalCredentials) {
+			return nil, -1, mismatch
+		}
+		nextDepositIndex = int64(genesisDepositIndex)
+		log.Trace().Int64("deposit_index", nextDepositIndex).Msg("Obtained index of first deposit in a block")
+	}
+
+	knownPubKeys, err := s.knownDepositPubKeys(ctx, pubKeys)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	domain, err := s.depositDomain(ctx)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	reconciledDeposits := make([]*chaindb.ReconciledDeposit, 0, len(deposits))
+	for _, deposit := range deposits {
+		eth1Deposit, exists := eth1DepositsByIndex[uint64(nextDepositIndex)]
+		if !exists {
+			return nil, -1, nil
+		}
+		if eth1Deposit.ValidatorPubKey != deposit.ValidatorPubKey {
+			return nil, -1, fmt.Errorf("deposit at slot %d index %d does not match Ethereum 1 deposit %d", deposit.InclusionSlot, deposit.InclusionIndex, nextDepositIndex)
+		}
+
+		signatureValid, err := util.VerifyDepositSignature(eth1Deposit.ValidatorPubKey,
+			eth1Deposit.WithdrawalCredentials,
+			eth1Deposit.Amount,
+			eth1Deposit.Signature,
+			domain,
+		)
+		if err != nil {
+			return nil, -1, errors.Wrap(err, "failed to verify deposit signature")
+		}
+
+		// Follow the beacon chain's rules: a deposit for an existing validator is a
+		// top-up regardless of its signature, otherwise the signature must be valid.
+		var depositType chaindb.DepositType
+		switch {
+		case knownPubKeys[deposit.ValidatorPubKey]:
+			depositType = chaindb.DepositTypeTopUp
+		case signatureValid:
+			depositType = chaindb.DepositTypeInitial
+			knownPubKeys[deposit.ValidatorPubKey] = true
+		default:
+			depositType = chaindb.DepositTypeInvalid
+		}
+
+		reconciledDeposits = append(reconciledDeposits, &chaindb.ReconciledDeposit{
+			DepositIndex:       eth1Deposit.DepositIndex,
+			ValidatorPubKey:    deposit.ValidatorPubKey,
+			Amount:             eth1Deposit.Amount,
+			ETH1BlockNumber:    eth1Deposit.ETH1BlockNumber,
+			ETH1TxHash:         eth1Deposit.ETH1TxHash,
+			ETH1LogIndex:       eth1Deposit.ETH1LogIndex,
+			InclusionSlot:      deposit.InclusionSlot,
+			InclusionBlockRoot: deposit.InclusionBlockRoot,
+			InclusionIndex:     deposit.InclusionIndex,
+			SignatureValid:     signatureValid,
+			Type:               depositType,
+		})
+		nextDepositIndex++
+	}
+
+	return reconciledDeposits, nextDepositIndex, nil
+}
+
+// knownDepositPubKeys returns the public keys that already belong to validators
+// prior to the deposits being reconciled.
+func (s *Service) knownDepositPubKeys(ctx context.Context,
+	pubKeys []phase0.BLSPubKey,
+) (
+	map[phase0.BLSPubKey]bool,
+	error,
+) {
+	knownPubKeys := make(map[phase0.BLSPubKey]bool)
+
+	initialDeposits, err := s.chainDB.(chaindb.ReconciledDepositsProvider).ReconciledDeposits(ctx, &chaindb.ReconciledDepositFilter{
+		Order:   chaindb.OrderEarliest,
+		PubKeys: pubKeys,
+		Types:   []chaindb.DepositType{chaindb.DepositTypeInitial},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain initial deposits")
+	}
+	for _, deposit := range initialDeposits {
+		knownPubKeys[deposit.ValidatorPubKey] = true
+	}
+
+	// Validators created from genesis deposits have no initial deposit in a block.
+	validators, err := s.validatorsProvider.ValidatorsByPublicKey(ctx, pubKeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain validators")
+	}
+	for pubKey, validator := range validators {
+		if validator.ActivationEligibilityEpoch == 0 {
+			knownPubKeys[pubKey] = true
+		}
+	}
+
+	return knownPubKeys, nil
+}
+
+// genesisDepositIndex returns the index of the next deposit to be processed after those in the genesis state.
+func (s *Service) genesisDepositIndex(ctx context.Context) (uint64, error) {
+	state, err := s.eth2Client.(eth2client.BeaconStateProvider).BeaconState(ctx, "genesis")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to obtain genesis state")
+	}
+	if state == nil {
+		return 0, errors.New("genesis state not available")
+	}
+
+	switch state.Version {
+	case spec.DataVersionPhase0:
+		if state.Phase0 == nil {
+			return 0, errors.New("no phase0 genesis state")
+		}
+		return state.Phase0.ETH1DepositIndex, nil
+	case spec.DataVersionAltair:
+		if state.Altair == nil {
+			return 0, errors.New("no altair genesis state")
+		}
+		return state.Altair.ETH1DepositIndex, nil
+	case spec.DataVersionBellatrix:
+		if state.Bellatrix == nil {
+			return 0, errors.New("no bellatrix genesis state")
+		}
+		return state.Bellatrix.ETH1DepositIndex, nil
+	case spec.DataVersionCapella:
+		if state.Capella == nil {
+			return 0, errors.New("no capella genesis state")
+		}
+		return state.Capella.ETH1DepositIndex, nil
+	default:
+		return 0, fmt.Errorf("unhandled genesis state version %v", state.Version)
+	}
+}
+
+// depositDomain returns the signature domain for deposits.
+func (s *Service) depositDomain(ctx context.Context) (phase0.Domain, error) {
+	forkSchedule, err := s.chainDB.(chaindb.ForkScheduleProvider).ForkSchedule(ctx)
+	if err != nil {
+		return phase0.Domain{}, errors.Wrap(err, "failed to obtain fork schedule")
+	}
+	if len(forkSchedule) == 0 {
+		return phase0.Domain{}, errors.New("fork schedule is empty")
+	}
+
+	// Deposits are always signed with the genesis fork version and an empty genesis validators root.
+	domain, err := util.ComputeDomain(s.depositDomainType, forkSchedule[0].PreviousVersion, phase0.Root{})
+	if err != nil {
+		return phase0.Domain{}, errors.Wrap(err, "failed to compute deposit domain")
+	}
+
+	return domain, nil
+}
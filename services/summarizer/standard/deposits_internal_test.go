@@ -0,0 +1,125 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/chaindb"
+)
+
+// genesisStateProvider provides a genesis state with the given deposit index.
+type genesisStateProvider struct {
+	depositIndex uint64
+}
+
+func (*genesisStateProvider) Name() string    { return "test" }
+func (*genesisStateProvider) Address() string { return "test" }
+
+func (p *genesisStateProvider) BeaconState(_ context.Context, _ string) (*spec.VersionedBeaconState, error) {
+	return &spec.VersionedBeaconState{
+		Version: spec.DataVersionPhase0,
+		Phase0: &phase0.BeaconState{
+			ETH1DepositIndex: p.depositIndex,
+		},
+	}, nil
+}
+
+// eth1DepositsDB provides Ethereum 1 deposits from memory.
+type eth1DepositsDB struct {
+	deposits []*chaindb.ETH1Deposit
+}
+
+func (d *eth1DepositsDB) ETH1DepositsByPublicKey(_ context.Context, pubKeys []phase0.BLSPubKey) ([]*chaindb.ETH1Deposit, error) {
+	res := make([]*chaindb.ETH1Deposit, 0)
+	for _, deposit := range d.deposits {
+		for _, pubKey := range pubKeys {
+			if deposit.ValidatorPubKey == pubKey {
+				res = append(res, deposit)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func TestReconcileFirstDeposit(t *testing.T) {
+	ctx := context.Background()
+
+	// Two identical top-ups, either side of the genesis deposits.
+	eth1Deposits := &eth1DepositsDB{
+		deposits: []*chaindb.ETH1Deposit{
+			{DepositIndex: 1, ValidatorPubKey: phase0.BLSPubKey{0x01}, WithdrawalCredentials: []byte{0x01}, Amount: 1000000000},
+			{DepositIndex: 2, ValidatorPubKey: phase0.BLSPubKey{0x02}, WithdrawalCredentials: []byte{0x02}, Amount: 32000000000},
+			{DepositIndex: 3, ValidatorPubKey: phase0.BLSPubKey{0x01}, WithdrawalCredentials: []byte{0x01}, Amount: 1000000000},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		genesisDepositIndex uint64
+		deposit             *chaindb.Deposit
+		err                 string
+		unavailable         bool
+	}{
+		{
+			name:                "Mismatch",
+			genesisDepositIndex: 2,
+			deposit:             &chaindb.Deposit{InclusionSlot: 10, ValidatorPubKey: phase0.BLSPubKey{0x01}, WithdrawalCredentials: []byte{0x01}, Amount: 1000000000},
+			err:                 "first deposit in a block at slot 10 does not match Ethereum 1 deposit 2 following genesis deposits",
+		},
+		{
+			name:                "MismatchAmount",
+			genesisDepositIndex: 3,
+			deposit:             &chaindb.Deposit{InclusionSlot: 10, ValidatorPubKey: phase0.BLSPubKey{0x01}, WithdrawalCredentials: []byte{0x01}, Amount: 2000000000},
+			err:                 "first deposit in a block at slot 10 does not match Ethereum 1 deposit 3 following genesis deposits",
+		},
+		{
+			name:                "Unavailable",
+			genesisDepositIndex: 4,
+			deposit:             &chaindb.Deposit{InclusionSlot: 10, ValidatorPubKey: phase0.BLSPubKey{0x01}, WithdrawalCredentials: []byte{0x01}, Amount: 1000000000},
+			unavailable:         true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Service{
+				eth2Client:           &genesisStateProvider{depositIndex: test.genesisDepositIndex},
+				eth1DepositsProvider: eth1Deposits,
+			}
+			reconciled, nextDepositIndex, err := s.reconcileBeaconDeposits(ctx, []*chaindb.Deposit{test.deposit}, -1)
+			switch {
+			case test.err != "":
+				require.EqualError(t, err, test.err)
+			case test.unavailable:
+				require.NoError(t, err)
+				require.Nil(t, reconciled)
+				require.Equal(t, int64(-1), nextDepositIndex)
+			}
+		})
+	}
+
+	// The genesis state picks out the later of the identical deposits.
+	s := &Service{
+		eth2Client: &genesisStateProvider{depositIndex: 3},
+	}
+	genesisDepositIndex, err := s.genesisDepositIndex(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), genesisDepositIndex)
+}
@@ -100,6 +100,9 @@ func (s *Service) summarizeEpoch(ctx context.Context,
 	}
 	log.Trace().Dur("elapsed", time.Since(started)).Msg("Set deposit stats")
 
+	summary.InactivityLeak = s.inactivityLeakForEpoch(ctx, epoch)
+	log.Trace().Dur("elapsed", time.Since(started)).Msg("Set inactivity leak")
+
 	ctx, cancel, err := s.chainDB.BeginTx(ctx)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to begin transaction to set epoch summary")
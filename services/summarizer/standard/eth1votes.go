@@ -0,0 +1,214 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// eth1VoteLookback is the number of previous periods to search for the last accepted vote.
+const eth1VoteLookback = 16
+
+// eth1Vote is the data for which a block votes.
+type eth1Vote struct {
+	blockHash    string
+	depositCount uint64
+	depositRoot  phase0.Root
+}
+
+// summarizeETH1VotePeriods summarizes Ethereum 1 data votes for all voting periods that
+// have fully finalized by the given epoch.
+func (s *Service) summarizeETH1VotePeriods(ctx context.Context, summaryEpoch phase0.Epoch) error {
+	if !s.eth1VoteSummaries {
+		return nil
+	}
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata for Ethereum 1 vote summarizer")
+	}
+
+	maxPeriods := s.maxDaysPerRun.Load() * uint64(s.epochsPerDay()) / s.epochsPerETH1VotingPeriod
+	if maxPeriods == 0 {
+		maxPeriods = 1
+	}
+
+	periods := uint64(0)
+	for period := uint64(md.LastETH1VotePeriod + 1); ; period++ {
+		// Only summarize periods that have completely finalized.
+		if phase0.Epoch((period+1)*s.epochsPerETH1VotingPeriod-1) > summaryEpoch {
+			break
+		}
+		if periods == maxPeriods {
+			log.Trace().Uint64("periods", periods).Msg("Reached maximum Ethereum 1 vote periods for this run")
+			break
+		}
+		if err := util.Retry(ctx, "Ethereum 1 vote summaries", func(ctx context.Context) error {
+			return s.summarizeETH1VotePeriod(ctx, period)
+		}); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to update Ethereum 1 vote summary for period %d", period))
+		}
+		periods++
+	}
+
+	return nil
+}
+
+// summarizeETH1VotePeriod summarizes the Ethereum 1 data votes in a given voting period.
+func (s *Service) summarizeETH1VotePeriod(ctx context.Context, period uint64) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "summarizeETH1VotePeriod",
+		trace.WithAttributes(
+			attribute.Int64("period", int64(period)),
+		))
+	defer span.End()
+
+	log := log.With().Uint64("period", period).Logger()
+	startEpoch := phase0.Epoch(period * s.epochsPerETH1VotingPeriod)
+	endEpoch := phase0.Epoch((period + 1) * s.epochsPerETH1VotingPeriod)
+	startSlot := s.chainTime.FirstSlotOfEpoch(startEpoch)
+	endSlot := s.chainTime.FirstSlotOfEpoch(endEpoch)
+	periodSlots := uint64(endSlot - startSlot)
+	log.Trace().Uint64("start_slot", uint64(startSlot)).Uint64("end_slot", uint64(endSlot)).Msg("Summarizing Ethereum 1 vote period")
+
+	acceptedDepositCount, err := s.acceptedETH1DepositCount(ctx, period)
+	if err != nil {
+		return err
+	}
+
+	blocks, err := s.blocksProvider.BlocksForSlotRange(ctx, startSlot, endSlot)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain blocks")
+	}
+	span.AddEvent("Obtained blocks")
+
+	summary := &chaindb.ETH1VotePeriod{
+		Period:            period,
+		StartEpoch:        startEpoch,
+		MinorityProposers: make([]phase0.ValidatorIndex, 0),
+		StaleProposers:    make([]phase0.ValidatorIndex, 0),
+	}
+
+	canonicalBlocks := make([]*chaindb.Block, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Canonical == nil || !*block.Canonical {
+			// Periods are only summarized once finalized, so blocks must be known to be canonical.
+			continue
+		}
+		canonicalBlocks = append(canonicalBlocks, block)
+	}
+
+	votes := make(map[eth1Vote]int)
+	var winner eth1Vote
+	for _, block := range canonicalBlocks {
+		vote := eth1Vote{
+			blockHash:    fmt.Sprintf("%#x", block.ETH1BlockHash),
+			depositCount: block.ETH1DepositCount,
+			depositRoot:  block.ETH1DepositRoot,
+		}
+		votes[vote]++
+		if votes[vote] > summary.WinningVotes {
+			winner = vote
+			summary.WinningVotes = votes[vote]
+			summary.WinningETH1BlockHash = block.ETH1BlockHash
+			summary.WinningETH1DepositCount = block.ETH1DepositCount
+			summary.WinningETH1DepositRoot = block.ETH1DepositRoot
+		}
+		if summary.MajorityEpoch == nil && uint64(votes[vote])*2 > periodSlots {
+			majorityEpoch := s.chainTime.SlotToEpoch(block.Slot)
+			summary.MajorityEpoch = &majorityEpoch
+		}
+		if block.ETH1DepositCount < acceptedDepositCount {
+			summary.StaleProposers = append(summary.StaleProposers, block.ProposerIndex)
+		}
+	}
+	summary.Blocks = len(canonicalBlocks)
+	summary.DistinctVotes = len(votes)
+
+	for _, block := range canonicalBlocks {
+		vote := eth1Vote{
+			blockHash:    fmt.Sprintf("%#x", block.ETH1BlockHash),
+			depositCount: block.ETH1DepositCount,
+			depositRoot:  block.ETH1DepositRoot,
+		}
+		if vote != winner {
+			summary.MinorityProposers = append(summary.MinorityProposers, block.ProposerIndex)
+		}
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to set Ethereum 1 vote period")
+	}
+
+	if err := s.chainDB.(chaindb.ETH1VotePeriodsSetter).SetETH1VotePeriod(ctx, summary); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set Ethereum 1 vote period")
+	}
+
+	// Fetch updated metadata as it may have changed since we last obtained it.
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata for Ethereum 1 vote summarizer")
+	}
+	md.LastETH1VotePeriod = int64(period)
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set summarizer metadata for Ethereum 1 vote period")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set commit transaction to set Ethereum 1 vote period")
+	}
+
+	log.Trace().Int("blocks", summary.Blocks).Int("distinct_votes", summary.DistinctVotes).Msg("Set Ethereum 1 vote period")
+
+	return nil
+}
+
+// acceptedETH1DepositCount returns the deposit count of the most recent vote to have obtained a
+// majority prior to the given period.  Votes with a lower deposit count than this are stale.
+func (s *Service) acceptedETH1DepositCount(ctx context.Context, period uint64) (uint64, error) {
+	if period == 0 {
+		return 0, nil
+	}
+
+	to := period - 1
+	previousPeriods, err := s.chainDB.(chaindb.ETH1VotePeriodsProvider).ETH1VotePeriods(ctx, &chaindb.ETH1VotePeriodFilter{
+		Limit: eth1VoteLookback,
+		Order: chaindb.OrderLatest,
+		To:    &to,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to obtain previous Ethereum 1 vote periods")
+	}
+
+	// Periods are returned in increasing order, so work backwards.
+	for i := len(previousPeriods) - 1; i >= 0; i-- {
+		if previousPeriods[i].MajorityEpoch != nil {
+			return previousPeriods[i].WinningETH1DepositCount, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/util"
 )
 
 // OnFinalityUpdated is called when finality has been updated in the database.
@@ -30,6 +31,7 @@ func (s *Service) OnFinalityUpdated(
 ) {
 	log := log.With().Uint64("finalized_epoch", uint64(finalizedEpoch)).Logger()
 	log.Trace().Msg("Handler called")
+	monitorFinalizedEpoch(finalizedEpoch)
 
 	// Only allow 1 handler to be active.
 	acquired := s.activitySem.TryAcquire(1)
@@ -39,6 +41,13 @@ func (s *Service) OnFinalityUpdated(
 	}
 	defer s.activitySem.Release(1)
 
+	ctx, done, ok := s.activity.Start(ctx, "finality")
+	if !ok {
+		log.Debug().Msg("Service stopping; not handling")
+		return
+	}
+	defer done()
+
 	if finalizedEpoch == 0 {
 		log.Debug().Msg("Not summarizing on epoch 0")
 		return
@@ -57,6 +66,10 @@ func (s *Service) OnFinalityUpdated(
 		log.Warn().Err(err).Msg("Failed to update validators")
 		return
 	}
+	if s.activity.Stopping() {
+		log.Debug().Msg("Service stopping; halting summarization")
+		return
+	}
 
 	md, err := s.getMetadata(ctx)
 	if err != nil {
@@ -67,6 +80,15 @@ func (s *Service) OnFinalityUpdated(
 			log.Warn().Err(err).Msg("Failed to update validator days")
 			return
 		}
+		if s.activity.Stopping() {
+			log.Debug().Msg("Service stopping; halting summarization")
+			return
+		}
+
+		if err := s.summarizeValidatorPeriods(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to update validator periods")
+			return
+		}
 
 		if err := s.prune(ctx, summaryEpoch); err != nil {
 			log.Warn().Err(err).Msg("Failed to prune summaries")
@@ -74,10 +96,87 @@ func (s *Service) OnFinalityUpdated(
 		}
 	}
 
+	if err := s.pruneRawData(ctx, summaryEpoch); err != nil {
+		log.Warn().Err(err).Msg("Failed to prune raw data")
+		return
+	}
+
+	// Optional summaries run after the core summaries and pruning, and are independent of
+	// each other, so a failure in one does not hold up the others.
+	s.summarizeOptional(ctx, summaryEpoch)
+
 	monitorEpochProcessed(finalizedEpoch)
 	log.Trace().Msg("Finished handling finality checkpoint")
 }
 
+// summarizeOptional runs the optional summaries up to the given epoch.
+// Failures are logged, and the summaries will be retried when finality is next updated.
+func (s *Service) summarizeOptional(ctx context.Context, summaryEpoch phase0.Epoch) {
+	if s.activity.Stopping() {
+		log.Debug().Msg("Service stopping; halting summarization")
+		return
+	}
+	if err := s.summarizeWithdrawalDays(ctx, summaryEpoch); err != nil {
+		log.Warn().Err(err).Msg("Failed to update withdrawal days")
+	}
+	if err := s.summarizeClientDays(ctx, summaryEpoch); err != nil {
+		log.Warn().Err(err).Msg("Failed to update client days")
+	}
+	if err := s.reconcileDeposits(ctx, summaryEpoch); err != nil {
+		log.Warn().Err(err).Msg("Failed to reconcile deposits")
+	}
+	if err := s.summarizeETH1VotePeriods(ctx, summaryEpoch); err != nil {
+		log.Warn().Err(err).Msg("Failed to update Ethereum 1 vote periods")
+	}
+}
+
+// OnBeaconChainHeadUpdated receives beacon chain head updated notifications.
+// It is used to generate provisional validator summaries for epochs that are yet to be finalized.
+func (s *Service) OnBeaconChainHeadUpdated(
+	ctx context.Context,
+	slot phase0.Slot,
+	blockRoot phase0.Root,
+	_ phase0.Root,
+	_ bool,
+) {
+	epoch := s.chainTime.SlotToEpoch(slot)
+	log := log.With().Uint64("epoch", uint64(epoch)).Logger()
+
+	if epoch == 0 {
+		// No completed epochs to summarize.
+		return
+	}
+
+	// Only allow 1 provisional handler to be active.  This uses its own semaphore so that
+	// provisional summaries never hold up summarization of finalized epochs.
+	acquired := s.provisionalSem.TryAcquire(1)
+	if !acquired {
+		log.Debug().Msg("Another provisional handler running")
+		return
+	}
+	defer s.provisionalSem.Release(1)
+
+	ctx, done, ok := s.activity.Start(ctx, "provisional")
+	if !ok {
+		return
+	}
+	defer done()
+
+	if blockRoot == s.lastProvisionalHeadRoot {
+		// Already summarized for this head.
+		return
+	}
+
+	// Summarize up to the previous epoch, as it is the latest that is complete.  Failures are
+	// commonly due to the head block not yet being in the database, so retry on the next head.
+	if err := s.summarizeProvisionalValidators(ctx, epoch-1, blockRoot); err != nil {
+		log.Debug().Err(err).Msg("Failed to update provisional validator summaries; will retry")
+		return
+	}
+	s.lastProvisionalHeadRoot = blockRoot
+	log.Trace().Msg("Finished handling head update")
+}
+
 func (s *Service) summarizeEpochs(ctx context.Context, summaryEpoch phase0.Epoch) error {
 	if !s.epochSummaries {
 		return nil
@@ -94,15 +193,23 @@ func (s *Service) summarizeEpochs(ctx context.Context, summaryEpoch phase0.Epoch
 	}
 
 	// Limit the number of epochs summarised per pass, if we are also pruning.
-	maxEpochsPerRun := phase0.Epoch(s.maxDaysPerRun) * s.epochsPerDay()
-	if s.validatorEpochRetention != nil && maxEpochsPerRun > 0 && summaryEpoch-lastEpoch > maxEpochsPerRun {
+	maxEpochsPerRun := phase0.Epoch(s.maxDaysPerRun.Load()) * s.epochsPerDay()
+	if s.retentions.Load().validatorEpoch != nil && maxEpochsPerRun > 0 && summaryEpoch-lastEpoch > maxEpochsPerRun {
 		summaryEpoch = lastEpoch + maxEpochsPerRun
 	}
 
 	log.Trace().Uint64("last_epoch", uint64(lastEpoch)).Uint64("summary_epoch", uint64(summaryEpoch)).Msg("Epochs catchup bounds")
 
 	for epoch := lastEpoch; epoch <= summaryEpoch; epoch++ {
-		updated, err := s.summarizeEpoch(ctx, md, epoch)
+		if s.activity.Stopping() {
+			return nil
+		}
+		var updated bool
+		err := util.Retry(ctx, "epoch summary", func(ctx context.Context) error {
+			var err error
+			updated, err = s.summarizeEpoch(ctx, md, epoch)
+			return err
+		})
 		if err != nil {
 			return errors.Wrapf(err, "failed to update summary for epoch %d", epoch)
 		}
@@ -143,7 +250,12 @@ func (s *Service) summarizeBlocks(ctx context.Context,
 	}
 
 	for epoch := lastBlockEpoch; epoch <= summaryEpoch; epoch++ {
-		if err := s.summarizeBlocksInEpoch(ctx, md, epoch); err != nil {
+		if s.activity.Stopping() {
+			return nil
+		}
+		if err := util.Retry(ctx, "block summaries", func(ctx context.Context) error {
+			return s.summarizeBlocksInEpoch(ctx, md, epoch)
+		}); err != nil {
 			return errors.Wrap(err, "failed to update block summaries for epoch")
 		}
 	}
@@ -176,14 +288,19 @@ func (s *Service) summarizeValidators(ctx context.Context, summaryEpoch phase0.E
 	}
 
 	// Limit the number of epochs summarised per pass, if we are also pruning.
-	maxEpochsPerRun := phase0.Epoch(s.maxDaysPerRun) * s.epochsPerDay()
-	if s.validatorEpochRetention != nil && maxEpochsPerRun > 0 && summaryEpoch-lastValidatorEpoch > maxEpochsPerRun {
+	maxEpochsPerRun := phase0.Epoch(s.maxDaysPerRun.Load()) * s.epochsPerDay()
+	if s.retentions.Load().validatorEpoch != nil && maxEpochsPerRun > 0 && summaryEpoch-lastValidatorEpoch > maxEpochsPerRun {
 		summaryEpoch = lastValidatorEpoch + maxEpochsPerRun
 	}
 	log.Trace().Uint64("last_epoch", uint64(lastValidatorEpoch)).Uint64("summary_epoch", uint64(summaryEpoch)).Msg("Validators catchup bounds")
 
 	for epoch := lastValidatorEpoch; epoch <= summaryEpoch; epoch++ {
-		if err := s.summarizeValidatorsInEpoch(ctx, md, epoch); err != nil {
+		if s.activity.Stopping() {
+			return nil
+		}
+		if err := util.Retry(ctx, "validator summaries", func(ctx context.Context) error {
+			return s.summarizeValidatorsInEpoch(ctx, md, epoch)
+		}); err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to update validator summaries in epoch %d", epoch))
 		}
 	}
@@ -213,7 +330,12 @@ func (s *Service) summarizeValidatorDays(ctx context.Context) error {
 		endTimestamp := epochSummariesTime.AddDate(0, 0, -1)
 
 		for timestamp := startTime; timestamp.Before(endTimestamp); timestamp = timestamp.AddDate(0, 0, 1) {
-			if err := s.summarizeValidatorsInDay(ctx, timestamp); err != nil {
+			if s.activity.Stopping() {
+				return nil
+			}
+			if err := util.Retry(ctx, "validator day summaries", func(ctx context.Context) error {
+				return s.summarizeValidatorsInDay(ctx, timestamp)
+			}); err != nil {
 				return errors.Wrap(err, fmt.Sprintf("failed to update validator summaries for day %s", timestamp.Format("2006-01-02")))
 			}
 		}
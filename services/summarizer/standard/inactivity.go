@@ -0,0 +1,111 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+
+	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+)
+
+// inactivityLeakForEpoch returns true if the chain was in an inactivity leak when rewards and
+// penalties were applied at the end of the given epoch, or nil if this cannot be determined.
+func (s *Service) inactivityLeakForEpoch(ctx context.Context,
+	epoch phase0.Epoch,
+) *bool {
+	finalityProvider, isProvider := s.eth2Client.(eth2client.FinalityProvider)
+	if !isProvider || epoch == 0 {
+		return nil
+	}
+
+	// The finalized checkpoint used when processing the end of the epoch is that in the state
+	// at the start of the following epoch.
+	finality, err := finalityProvider.Finality(ctx, fmt.Sprintf("%d", s.chainTime.FirstSlotOfEpoch(epoch+1)))
+	if err != nil {
+		log.Debug().Err(err).Uint64("epoch", uint64(epoch)).Msg("Failed to obtain finality; cannot determine inactivity leak")
+		return nil
+	}
+	if finality == nil || finality.Finalized == nil {
+		return nil
+	}
+
+	// Finality delay is measured from the previous epoch as seen at the end of the epoch.
+	previousEpoch := epoch - 1
+	inactivityLeak := previousEpoch > finality.Finalized.Epoch &&
+		uint64(previousEpoch-finality.Finalized.Epoch) > s.minEpochsToInactivityPenalty
+
+	return &inactivityLeak
+}
+
+// addInactivityPenalties adds the inactivity penalties applied for each validator's attestation
+// in the given epoch to its summary.
+// Penalties for an epoch's attestations are applied at the end of the following epoch, using
+// the effective balance during that epoch and the inactivity score after it has been updated.
+// If inactivity scores are not stored, or the required balances are not available, then
+// penalties are left unset.
+func (s *Service) addInactivityPenalties(ctx context.Context,
+	epoch phase0.Epoch,
+	summaries []*chaindb.ValidatorEpochSummary,
+) error {
+	if !s.inactivityScores {
+		// Inactivity scores not stored.
+		return nil
+	}
+	if s.inactivityScoreBias == 0 || epoch < s.chainTime.AltairInitialEpoch() {
+		// Inactivity scores not present.
+		return nil
+	}
+
+	balances, err := s.validatorsProvider.ValidatorBalancesByEpoch(ctx, epoch+1)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain validator balances for inactivity penalties")
+	}
+	scores, err := s.validatorsProvider.ValidatorBalancesByEpoch(ctx, epoch+2)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain validator inactivity scores for inactivity penalties")
+	}
+	if len(balances) == 0 || len(scores) == 0 {
+		log.Trace().Uint64("epoch", uint64(epoch)).Msg("Balances not available; not calculating inactivity penalties")
+		return nil
+	}
+
+	quotient := s.inactivityPenaltyQuotientAltair
+	if epoch+1 >= s.chainTime.BellatrixInitialEpoch() {
+		quotient = s.inactivityPenaltyQuotientBellatrix
+	}
+	denominator := s.inactivityScoreBias * quotient
+	if denominator == 0 {
+		return nil
+	}
+
+	for _, summary := range summaries {
+		if int(summary.Index) >= len(balances) || int(summary.Index) >= len(scores) {
+			continue
+		}
+		if scores[summary.Index].InactivityScore == nil {
+			continue
+		}
+		penalty := phase0.Gwei(0)
+		if summary.AttestationTargetTimely == nil || !*summary.AttestationTargetTimely {
+			penalty = phase0.Gwei(uint64(balances[summary.Index].EffectiveBalance) * *scores[summary.Index].InactivityScore / denominator)
+		}
+		summary.InactivityPenalty = &penalty
+	}
+
+	return nil
+}
@@ -22,8 +22,5 @@ import (
 
 func TestMain(m *testing.M) {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
-	if os.Getenv("CHAINDB_URL") != "" &&
-		os.Getenv("ETH2CLIENT_ADDRESS") != "" {
-		os.Exit(m.Run())
-	}
+	os.Exit(m.Run())
 }
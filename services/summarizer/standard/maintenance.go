@@ -0,0 +1,99 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+)
+
+// Prune prunes data according to the configured retention policies, as would happen
+// after the next finality update.
+func (s *Service) Prune(ctx context.Context) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "Prune")
+	defer span.End()
+
+	ctx, done, ok := s.activity.Start(ctx, "prune")
+	if !ok {
+		return errors.New("service stopping")
+	}
+	defer done()
+
+	// Ensure that we do not run alongside the finality handler.
+	if err := s.activitySem.Acquire(ctx, 1); err != nil {
+		return errors.Wrap(err, "failed to acquire summarizer")
+	}
+	defer s.activitySem.Release(1)
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+	summaryEpoch := md.LastEpoch
+	if md.LastBlockEpoch > summaryEpoch {
+		summaryEpoch = md.LastBlockEpoch
+	}
+	if md.LastValidatorEpoch > summaryEpoch {
+		summaryEpoch = md.LastValidatorEpoch
+	}
+
+	if md.PeriodicValidatorRollups {
+		if err := s.prune(ctx, summaryEpoch); err != nil {
+			return errors.Wrap(err, "failed to prune summaries")
+		}
+	}
+	if err := s.pruneRawData(ctx, summaryEpoch); err != nil {
+		return errors.Wrap(err, "failed to prune raw data")
+	}
+
+	return nil
+}
+
+// Rollup rolls up validator summaries in to days and longer periods, as would happen
+// after the next finality update.
+func (s *Service) Rollup(ctx context.Context) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "Rollup")
+	defer span.End()
+
+	ctx, done, ok := s.activity.Start(ctx, "rollup")
+	if !ok {
+		return errors.New("service stopping")
+	}
+	defer done()
+
+	// Ensure that we do not run alongside the finality handler.
+	if err := s.activitySem.Acquire(ctx, 1); err != nil {
+		return errors.Wrap(err, "failed to acquire summarizer")
+	}
+	defer s.activitySem.Release(1)
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+	if !md.PeriodicValidatorRollups {
+		return errors.New("initial rollup has not completed")
+	}
+
+	if err := s.summarizeValidatorDays(ctx); err != nil {
+		return errors.Wrap(err, "failed to update validator days")
+	}
+	if err := s.summarizeValidatorPeriods(ctx); err != nil {
+		return errors.Wrap(err, "failed to update validator periods")
+	}
+
+	return nil
+}
@@ -23,11 +23,18 @@ import (
 
 // metadata stored about this service.
 type metadata struct {
-	LastValidatorEpoch       phase0.Epoch `json:"latest_validator_epoch"`
-	LastBlockEpoch           phase0.Epoch `json:"latest_block_epoch"`
-	LastEpoch                phase0.Epoch `json:"latest_epoch"`
-	LastValidatorDay         int64        `json:"last_validator_day"`
-	PeriodicValidatorRollups bool         `json:"periodic_validator_rollups"`
+	LastValidatorEpoch         phase0.Epoch            `json:"latest_validator_epoch"`
+	LastBlockEpoch             phase0.Epoch            `json:"latest_block_epoch"`
+	LastEpoch                  phase0.Epoch            `json:"latest_epoch"`
+	LastValidatorDay           int64                   `json:"last_validator_day"`
+	PeriodicValidatorRollups   bool                    `json:"periodic_validator_rollups"`
+	LastWithdrawalDay          int64                   `json:"last_withdrawal_day"`
+	LastReconciledDepositSlot  int64                   `json:"last_reconciled_deposit_slot"`
+	NextReconciledDepositIndex int64                   `json:"next_reconciled_deposit_index"`
+	LastETH1VotePeriod         int64                   `json:"last_eth1_vote_period"`
+	LastClientDay              int64                   `json:"last_client_day"`
+	LastValidatorPeriods       map[string]int64        `json:"last_validator_periods,omitempty"`
+	PrunedEpochs               map[string]phase0.Epoch `json:"pruned_epochs,omitempty"`
 }
 
 // metadataKey is the key for the metadata.
@@ -36,7 +43,12 @@ var metadataKey = "summarizer.standard"
 // getMetadata gets metadata for this service.
 func (s *Service) getMetadata(ctx context.Context) (*metadata, error) {
 	md := &metadata{
-		LastValidatorDay: -1,
+		LastValidatorDay:           -1,
+		LastWithdrawalDay:          -1,
+		LastReconciledDepositSlot:  -1,
+		NextReconciledDepositIndex: -1,
+		LastETH1VotePeriod:         -1,
+		LastClientDay:              -1,
 	}
 	mdJSON, err := s.chainDB.Metadata(ctx, metadataKey)
 	if err != nil {
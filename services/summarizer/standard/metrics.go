@@ -20,12 +20,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/wealdtech/chaind/services/metrics"
+	"go.uber.org/atomic"
 )
 
 var metricsNamespace = "chaind_summarizer"
 
 var (
-	highestEpoch    phase0.Epoch
+	highestEpoch    atomic.Uint64
+	finalizedEpoch  atomic.Uint64
 	latestEpoch     prometheus.Gauge
 	epochsProcessed prometheus.Counter
 )
@@ -36,9 +38,12 @@ var (
 	daysProcessed prometheus.Counter
 )
 
+var latestProvisionalEpoch prometheus.Gauge
+
 var (
 	lastEpochPrune   prometheus.Gauge
 	lastBalancePrune prometheus.Gauge
+	rawDataPruned    *prometheus.GaugeVec
 )
 
 func registerMetrics(_ context.Context, monitor metrics.Service) error {
@@ -75,6 +80,31 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register epochs_processed_total")
 	}
 
+	if err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "lag_epochs",
+		Help:      "Number of finalized epochs that have yet to be summarized",
+	}, func() float64 {
+		// The latest epoch that can be summarized is that before the finalized epoch.
+		finalized := finalizedEpoch.Load()
+		highest := highestEpoch.Load()
+		if finalized <= highest+1 {
+			return 0
+		}
+		return float64(finalized - 1 - highest)
+	})); err != nil {
+		return errors.Wrap(err, "failed to register lag_epochs")
+	}
+
+	latestProvisionalEpoch = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "latest_provisional_epoch",
+		Help:      "Latest epoch provisionally summarized for summarizer",
+	})
+	if err := prometheus.Register(latestProvisionalEpoch); err != nil {
+		return errors.Wrap(err, "failed to register latest_provisional_epoch")
+	}
+
 	latestDay = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Name:      "latest_day",
@@ -112,6 +142,15 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register epoch_prune_ts")
 	}
 
+	rawDataPruned = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "raw_data_pruned_epoch",
+		Help:      "Epoch up to which raw data has been pruned",
+	}, []string{"table"})
+	if err := prometheus.Register(rawDataPruned); err != nil {
+		return errors.Wrap(err, "failed to register raw_data_pruned_epoch")
+	}
+
 	return nil
 }
 
@@ -119,7 +158,7 @@ func registerPrometheusMetrics() error {
 // increase in epochs processed.  This does not usually need to be
 // called directly, as it is called as part of monitorEpochProcessed.
 func monitorLatestEpoch(epoch phase0.Epoch) {
-	highestEpoch = epoch
+	highestEpoch.Store(uint64(epoch))
 	if latestEpoch != nil {
 		latestEpoch.Set(float64(epoch))
 	}
@@ -128,12 +167,18 @@ func monitorLatestEpoch(epoch phase0.Epoch) {
 func monitorEpochProcessed(epoch phase0.Epoch) {
 	if epochsProcessed != nil {
 		epochsProcessed.Inc()
-		if epoch > highestEpoch {
+		if uint64(epoch) > highestEpoch.Load() {
 			monitorLatestEpoch(epoch)
 		}
 	}
 }
 
+func monitorProvisionalEpoch(epoch phase0.Epoch) {
+	if latestProvisionalEpoch != nil {
+		latestProvisionalEpoch.Set(float64(epoch))
+	}
+}
+
 // monitorLatestDay sets the latest day without registering an
 // increase in days processed.  This does not usually need to be
 // called directly, as it is called as part of monitorDayProcessed.
@@ -164,3 +209,16 @@ func monitorEpochPruned() {
 		lastEpochPrune.SetToCurrentTime()
 	}
 }
+
+func monitorRawDataPruned(table string, epoch phase0.Epoch) {
+	if rawDataPruned != nil {
+		rawDataPruned.WithLabelValues(table).Set(float64(epoch))
+	}
+}
+
+// monitorFinalizedEpoch notes the latest finalized epoch, against which lag is measured.
+func monitorFinalizedEpoch(epoch phase0.Epoch) {
+	if uint64(epoch) > finalizedEpoch.Load() {
+		finalizedEpoch.Store(uint64(epoch))
+	}
+}
@@ -32,9 +32,16 @@ type parameters struct {
 	epochSummaries            bool
 	blockSummaries            bool
 	validatorSummaries        bool
+	withdrawalSummaries       bool
+	depositReconciliation     bool
+	eth1VoteSummaries         bool
+	inactivityScores          bool
 	validatorEpochRetention   string
 	maxDaysPerRun             uint64
 	validatorBalanceRetention string
+	validatorPeriods          []string
+	rawRetentions             map[string]string
+	provisionalEpochs         uint64
 }
 
 // Parameter is the interface for service parameters.
@@ -104,6 +111,35 @@ func WithValidatorSummaries(enabled bool) Parameter {
 	})
 }
 
+// WithWithdrawalSummaries states if the module should generate withdrawal summaries.
+func WithWithdrawalSummaries(enabled bool) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.withdrawalSummaries = enabled
+	})
+}
+
+// WithDepositReconciliation states if the module should reconcile Ethereum 1 and beacon chain deposits.
+func WithDepositReconciliation(enabled bool) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.depositReconciliation = enabled
+	})
+}
+
+// WithETH1VoteSummaries states if the module should generate Ethereum 1 vote summaries.
+func WithETH1VoteSummaries(enabled bool) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.eth1VoteSummaries = enabled
+	})
+}
+
+// WithInactivityScores states if validator inactivity scores are being stored, allowing inactivity penalties
+// to be calculated.
+func WithInactivityScores(enabled bool) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.inactivityScores = enabled
+	})
+}
+
 // WithMaxDaysPerRun provides the maximum number of days to process in a single run of the summarizer.
 func WithMaxDaysPerRun(maxDaysPerRun uint64) Parameter {
 	return parameterFunc(func(p *parameters) {
@@ -125,6 +161,29 @@ func WithValidatorBalanceRetention(retention string) Parameter {
 	})
 }
 
+// WithValidatorPeriods provides the periods, as ISO 8601 durations, for which to roll up validator day summaries.
+func WithValidatorPeriods(periods []string) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.validatorPeriods = periods
+	})
+}
+
+// WithRawRetentions provides the amount of raw data to retain, keyed by table.
+// Supported tables are "attestations", "beacon-committees", "sync-aggregates" and "sync-committees".
+func WithRawRetentions(retentions map[string]string) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.rawRetentions = retentions
+	})
+}
+
+// WithProvisionalEpochs provides the number of recent unfinalized epochs for which to generate
+// provisional validator epoch summaries.  0 disables provisional summaries.
+func WithProvisionalEpochs(epochs uint64) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.provisionalEpochs = epochs
+	})
+}
+
 // parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
 func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	parameters := parameters{
@@ -0,0 +1,203 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/chaintime"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headChain is a view of the canonical chain as seen from a head block, used to
+// summarize epochs that have not yet been finalized.
+type headChain struct {
+	// roots are the roots of the blocks in the chain, keyed by slot.
+	roots map[phase0.Slot]phase0.Root
+	// earliestSlot is the slot of the earliest block in the chain.
+	earliestSlot phase0.Slot
+}
+
+// rootAtSlot returns the root of the latest block in the chain at or before the given slot.
+func (c *headChain) rootAtSlot(slot phase0.Slot) (phase0.Root, bool) {
+	for ; slot >= c.earliestSlot; slot-- {
+		if root, exists := c.roots[slot]; exists {
+			return root, true
+		}
+		if slot == 0 {
+			break
+		}
+	}
+
+	return phase0.Root{}, false
+}
+
+// presence returns the presence of blocks in the chain for the given slot range.
+// Ranges are inclusive of start and exclusive of end.
+func (c *headChain) presence(startSlot phase0.Slot, endSlot phase0.Slot) []bool {
+	presence := make([]bool, endSlot-startSlot)
+	for slot := startSlot; slot < endSlot; slot++ {
+		_, presence[slot-startSlot] = c.roots[slot]
+	}
+
+	return presence
+}
+
+// markAttestations marks the canonical state and vote correctness of attestations according to the chain,
+// in the same way that the finalizer does for finalized attestations.
+func (c *headChain) markAttestations(attestations []*chaindb.Attestation, chainTime chaintime.Service) {
+	for _, attestation := range attestations {
+		root, exists := c.roots[attestation.InclusionSlot]
+		canonical := exists && root == attestation.InclusionBlockRoot
+		attestation.Canonical = &canonical
+		if !canonical {
+			continue
+		}
+
+		targetRoot, exists := c.rootAtSlot(chainTime.FirstSlotOfEpoch(attestation.TargetEpoch))
+		targetCorrect := exists && targetRoot == attestation.TargetRoot
+		attestation.TargetCorrect = &targetCorrect
+
+		headRoot, exists := c.rootAtSlot(attestation.Slot)
+		headCorrect := exists && headRoot == attestation.BeaconBlockRoot
+		attestation.HeadCorrect = &headCorrect
+	}
+}
+
+// headChainFrom builds the chain from the given head block back to the latest block at or before the given slot.
+func (s *Service) headChainFrom(ctx context.Context,
+	headRoot phase0.Root,
+	earliestSlot phase0.Slot,
+) (
+	*headChain,
+	error,
+) {
+	chain := &headChain{
+		roots: make(map[phase0.Slot]phase0.Root),
+	}
+
+	root := headRoot
+	for {
+		block, err := s.blocksProvider.BlockByRoot(ctx, root)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to obtain block %#x", root))
+		}
+		chain.roots[block.Slot] = block.Root
+		chain.earliestSlot = block.Slot
+		if block.Slot <= earliestSlot || block.Slot == 0 {
+			break
+		}
+		root = block.ParentRoot
+	}
+
+	return chain, nil
+}
+
+// summarizeProvisionalValidators generates provisional validator epoch summaries for recent
+// epochs that have not yet been summarized by the finality handler, using the chain as seen
+// from the given head block.
+func (s *Service) summarizeProvisionalValidators(ctx context.Context,
+	toEpoch phase0.Epoch,
+	headRoot phase0.Root,
+) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "summarizeProvisionalValidators",
+		trace.WithAttributes(
+			attribute.Int64("to epoch", int64(toEpoch)),
+		))
+	defer span.End()
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+
+	fromEpoch := md.LastValidatorEpoch + 1
+	if uint64(toEpoch)+1 > s.provisionalEpochs && toEpoch+1-phase0.Epoch(s.provisionalEpochs) > fromEpoch {
+		fromEpoch = toEpoch + 1 - phase0.Epoch(s.provisionalEpochs)
+	}
+	if fromEpoch > toEpoch {
+		log.Trace().Msg("No unfinalized epochs to summarize provisionally")
+		return nil
+	}
+	log := log.With().Uint64("from_epoch", uint64(fromEpoch)).Uint64("to_epoch", uint64(toEpoch)).Logger()
+
+	chain, err := s.headChainFrom(ctx, headRoot, s.chainTime.FirstSlotOfEpoch(fromEpoch))
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain head chain")
+	}
+
+	summaries := make([]*chaindb.ValidatorEpochSummary, 0)
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		epochSummaries, err := s.validatorEpochSummaries(ctx, epoch, chain)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to calculate provisional validator summaries for epoch %d", epoch))
+		}
+		for _, summary := range epochSummaries {
+			summary.Provisional = true
+		}
+		summaries = append(summaries, epochSummaries...)
+	}
+
+	// Final summaries are written concurrently, so ensure that they do not interleave.
+	s.validatorSummariesMu.Lock()
+	defer s.validatorSummariesMu.Unlock()
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to set provisional validator epoch summaries")
+	}
+
+	// Final summaries may have been written whilst these were being calculated; they must not be overwritten.
+	md, err = s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+	if md.LastValidatorEpoch >= fromEpoch {
+		unfinalized := make([]*chaindb.ValidatorEpochSummary, 0, len(summaries))
+		for _, summary := range summaries {
+			if summary.Epoch > md.LastValidatorEpoch {
+				unfinalized = append(unfinalized, summary)
+			}
+		}
+		summaries = unfinalized
+	}
+
+	// Replace any existing provisional summaries, as the head chain may have changed since they were generated.
+	if err := s.chainDB.(chaindb.ValidatorEpochSummariesPruner).PruneProvisionalValidatorEpochSummaries(ctx, toEpoch); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to prune provisional validator epoch summaries")
+	}
+
+	if err := s.chainDB.(chaindb.ValidatorEpochSummariesSetter).SetValidatorEpochSummaries(ctx, summaries); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set provisional validator epoch summaries")
+	}
+
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction to set provisional validator epoch summaries")
+	}
+
+	monitorProvisionalEpoch(toEpoch)
+	log.Trace().Int("summaries", len(summaries)).Msg("Set provisional validator epoch summaries")
+
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/services/chaindb"
+	mockchaintime "github.com/wealdtech/chaind/services/chaintime/mock"
+)
+
+func TestHeadChain(t *testing.T) {
+	chain := &headChain{
+		roots: map[phase0.Slot]phase0.Root{
+			0: {0x00},
+			2: {0x02},
+			3: {0x03},
+			6: {0x06},
+		},
+		earliestSlot: 0,
+	}
+
+	tests := []struct {
+		name   string
+		slot   phase0.Slot
+		root   phase0.Root
+		exists bool
+	}{
+		{
+			name:   "Genesis",
+			slot:   0,
+			root:   phase0.Root{0x00},
+			exists: true,
+		},
+		{
+			name:   "Present",
+			slot:   3,
+			root:   phase0.Root{0x03},
+			exists: true,
+		},
+		{
+			name:   "Missing",
+			slot:   5,
+			root:   phase0.Root{0x03},
+			exists: true,
+		},
+		{
+			name:   "Future",
+			slot:   10,
+			root:   phase0.Root{0x06},
+			exists: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root, exists := chain.rootAtSlot(test.slot)
+			require.Equal(t, test.exists, exists)
+			require.Equal(t, test.root, root)
+		})
+	}
+
+	require.Equal(t, []bool{false, true, true, false, false, true, false}, chain.presence(1, 8))
+
+	truncated := &headChain{
+		roots: map[phase0.Slot]phase0.Root{
+			5: {0x05},
+		},
+		earliestSlot: 5,
+	}
+	_, exists := truncated.rootAtSlot(4)
+	require.False(t, exists)
+}
+
+func TestHeadChainMarkAttestations(t *testing.T) {
+	chain := &headChain{
+		roots: map[phase0.Slot]phase0.Root{
+			0: {0x00},
+			1: {0x01},
+			2: {0x02},
+		},
+		earliestSlot: 0,
+	}
+
+	attestations := []*chaindb.Attestation{
+		{
+			// Included in the chain, correct votes.
+			InclusionSlot:      2,
+			InclusionBlockRoot: phase0.Root{0x02},
+			Slot:               1,
+			BeaconBlockRoot:    phase0.Root{0x01},
+			TargetRoot:         phase0.Root{0x00},
+		},
+		{
+			// Included in the chain, incorrect votes.
+			InclusionSlot:      2,
+			InclusionBlockRoot: phase0.Root{0x02},
+			Slot:               1,
+			BeaconBlockRoot:    phase0.Root{0x00},
+			TargetRoot:         phase0.Root{0x01},
+		},
+		{
+			// Included in a block not on the chain.
+			InclusionSlot:      2,
+			InclusionBlockRoot: phase0.Root{0xff},
+			Slot:               1,
+			BeaconBlockRoot:    phase0.Root{0x01},
+			TargetRoot:         phase0.Root{0x00},
+		},
+	}
+
+	chain.markAttestations(attestations, mockchaintime.New())
+
+	require.True(t, *attestations[0].Canonical)
+	require.True(t, *attestations[0].HeadCorrect)
+	require.True(t, *attestations[0].TargetCorrect)
+	require.True(t, *attestations[1].Canonical)
+	require.False(t, *attestations[1].HeadCorrect)
+	require.False(t, *attestations[1].TargetCorrect)
+	require.False(t, *attestations[2].Canonical)
+	require.Nil(t, attestations[2].HeadCorrect)
+	require.Nil(t, attestations[2].TargetCorrect)
+}
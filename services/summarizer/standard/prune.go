@@ -15,23 +15,26 @@ package standard
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
 )
 
 func (s *Service) prune(ctx context.Context, summaryEpoch phase0.Epoch) error {
-	if s.validatorBalanceRetention != nil {
-		if err := s.pruneBalances(ctx, summaryEpoch); err != nil {
+	retentions := s.retentions.Load()
+	if retentions.validatorBalance != nil {
+		if err := s.pruneBalances(ctx, summaryEpoch, retentions.validatorBalance); err != nil {
 			return err
 		}
 		monitorBalancePruned()
 	}
 
-	if s.validatorEpochRetention != nil {
-		if err := s.pruneEpochs(ctx, summaryEpoch); err != nil {
+	if retentions.validatorEpoch != nil {
+		if err := s.pruneEpochs(ctx, summaryEpoch, retentions.validatorEpoch); err != nil {
 			return err
 		}
 		monitorEpochPruned()
@@ -40,9 +43,9 @@ func (s *Service) prune(ctx context.Context, summaryEpoch phase0.Epoch) error {
 	return nil
 }
 
-func (s *Service) pruneBalances(ctx context.Context, summaryEpoch phase0.Epoch) error {
+func (s *Service) pruneBalances(ctx context.Context, summaryEpoch phase0.Epoch, retention *util.CalendarDuration) error {
 	summaryTime := s.chainTime.StartOfEpoch(summaryEpoch)
-	pruneTime := s.validatorBalanceRetention.Decrement(summaryTime)
+	pruneTime := retention.Decrement(summaryTime)
 
 	// Ensure that we're not pruning to a point before the summary.
 	daySummaries, err := s.chainDB.(chaindb.ValidatorDaySummariesProvider).ValidatorDaySummaries(ctx, &chaindb.ValidatorDaySummaryFilter{
@@ -63,7 +66,7 @@ func (s *Service) pruneBalances(ctx context.Context, summaryEpoch phase0.Epoch)
 	}
 
 	pruneEpoch := s.chainTime.TimestampToEpoch(pruneTime)
-	log.Trace().Stringer("retention", s.validatorEpochRetention).Time("summary_time", summaryTime).Time("summarized_time", summarizedTime).Time("prune_time", pruneTime).Uint64("prune_epoch", uint64(pruneEpoch)).Msg("Prune parameters for balances")
+	log.Trace().Stringer("retention", retention).Time("summary_time", summaryTime).Time("summarized_time", summarizedTime).Time("prune_time", pruneTime).Uint64("prune_epoch", uint64(pruneEpoch)).Msg("Prune parameters for balances")
 
 	ctx, cancel, err := s.chainDB.BeginTx(ctx)
 	if err != nil {
@@ -84,9 +87,9 @@ func (s *Service) pruneBalances(ctx context.Context, summaryEpoch phase0.Epoch)
 	return nil
 }
 
-func (s *Service) pruneEpochs(ctx context.Context, summaryEpoch phase0.Epoch) error {
+func (s *Service) pruneEpochs(ctx context.Context, summaryEpoch phase0.Epoch, retention *util.CalendarDuration) error {
 	summaryTime := s.chainTime.StartOfEpoch(summaryEpoch)
-	pruneTime := s.validatorEpochRetention.Decrement(summaryTime)
+	pruneTime := retention.Decrement(summaryTime)
 
 	// Ensure that we're not pruning to a point before the summary.
 	daySummaries, err := s.chainDB.(chaindb.ValidatorDaySummariesProvider).ValidatorDaySummaries(ctx, &chaindb.ValidatorDaySummaryFilter{
@@ -107,7 +110,7 @@ func (s *Service) pruneEpochs(ctx context.Context, summaryEpoch phase0.Epoch) er
 	}
 
 	pruneEpoch := s.chainTime.TimestampToEpoch(pruneTime)
-	log.Trace().Stringer("retention", s.validatorEpochRetention).Time("summary_time", summaryTime).Time("summarized_time", summarizedTime).Time("prune_time", pruneTime).Uint64("prune_epoch", uint64(pruneEpoch)).Msg("Prune parameters for epochs")
+	log.Trace().Stringer("retention", retention).Time("summary_time", summaryTime).Time("summarized_time", summarizedTime).Time("prune_time", pruneTime).Uint64("prune_epoch", uint64(pruneEpoch)).Msg("Prune parameters for epochs")
 
 	ctx, cancel, err := s.chainDB.BeginTx(ctx)
 	if err != nil {
@@ -127,3 +130,171 @@ func (s *Service) pruneEpochs(ctx context.Context, summaryEpoch phase0.Epoch) er
 
 	return nil
 }
+
+// Raw data tables that can be pruned once the summaries that use them have been generated.
+const (
+	attestationsRetention     = "attestations"
+	beaconCommitteesRetention = "beacon-committees"
+	syncAggregatesRetention   = "sync-aggregates"
+	syncCommitteesRetention   = "sync-committees"
+)
+
+// rawRetentions are the raw data tables that can be pruned, in the order in which they are pruned.
+var rawRetentions = []string{
+	attestationsRetention,
+	beaconCommitteesRetention,
+	syncAggregatesRetention,
+	syncCommitteesRetention,
+}
+
+// pruneRawData prunes raw data tables according to their retention policies, ensuring that
+// no data is pruned that is still required to generate summaries.
+func (s *Service) pruneRawData(ctx context.Context, summaryEpoch phase0.Epoch) error {
+	retentions := s.retentions.Load()
+	for _, table := range rawRetentions {
+		retention, exists := retentions.raw[table]
+		if !exists {
+			continue
+		}
+		if err := s.pruneRawTable(ctx, summaryEpoch, table, retention); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to prune %s", table))
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) pruneRawTable(ctx context.Context,
+	summaryEpoch phase0.Epoch,
+	table string,
+	retention *util.CalendarDuration,
+) error {
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+
+	summaryTime := s.chainTime.StartOfEpoch(summaryEpoch)
+	pruneTime := retention.Decrement(summaryTime)
+	pruneEpoch := s.chainTime.TimestampToEpoch(pruneTime)
+
+	// Ensure that we're not pruning data that is still required for summaries.
+	requiredEpoch, summarized := s.rawDataRequiredEpoch(md, table)
+	if !summarized {
+		log.Trace().Str("table", table).Msg("No summaries generated from data, not pruning")
+		return nil
+	}
+	if requiredEpoch < pruneEpoch {
+		pruneEpoch = requiredEpoch
+	}
+
+	// Limit the amount of data pruned in a single pass.
+	prunedEpoch := md.PrunedEpochs[table]
+	maxEpochsPerRun := phase0.Epoch(s.maxDaysPerRun.Load()) * s.epochsPerDay()
+	if maxEpochsPerRun > 0 && pruneEpoch > prunedEpoch+maxEpochsPerRun {
+		pruneEpoch = prunedEpoch + maxEpochsPerRun
+	}
+	log.Trace().Str("table", table).Stringer("retention", retention).Time("summary_time", summaryTime).Time("prune_time", pruneTime).Uint64("required_epoch", uint64(requiredEpoch)).Uint64("pruned_epoch", uint64(prunedEpoch)).Uint64("prune_epoch", uint64(pruneEpoch)).Msg("Prune parameters for raw data")
+	if pruneEpoch <= prunedEpoch {
+		return nil
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to prune raw data")
+	}
+
+	pruneSlot := s.chainTime.FirstSlotOfEpoch(pruneEpoch)
+	switch table {
+	case attestationsRetention:
+		err = s.chainDB.(chaindb.AttestationsPruner).PruneAttestations(ctx, pruneSlot)
+	case beaconCommitteesRetention:
+		err = s.chainDB.(chaindb.BeaconCommitteesPruner).PruneBeaconCommittees(ctx, pruneSlot)
+	case syncAggregatesRetention:
+		err = s.chainDB.(chaindb.SyncAggregatePruner).PruneSyncAggregates(ctx, pruneSlot)
+	case syncCommitteesRetention:
+		err = s.chainDB.(chaindb.SyncCommitteesPruner).PruneSyncCommittees(ctx, s.chainTime.EpochToSyncCommitteePeriod(pruneEpoch))
+	default:
+		err = fmt.Errorf("unknown table %s", table)
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	// Fetch updated metadata as it may have changed since we last obtained it.
+	md, err = s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+	if md.PrunedEpochs == nil {
+		md.PrunedEpochs = make(map[string]phase0.Epoch)
+	}
+	md.PrunedEpochs[table] = pruneEpoch
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set metadata")
+	}
+
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction to prune raw data")
+	}
+	log.Trace().Str("table", table).Uint64("from_epoch", uint64(prunedEpoch)).Uint64("to_epoch", uint64(pruneEpoch)).Msg("Pruned raw data")
+	monitorRawDataPruned(table, pruneEpoch)
+
+	return nil
+}
+
+// rawDataRequiredEpoch returns the earliest epoch of raw data in the given table that is still
+// required to generate summaries.  It returns false if no enabled summaries use the table.
+func (s *Service) rawDataRequiredEpoch(md *metadata, table string) (phase0.Epoch, bool) {
+	requiredEpochs := make([]phase0.Epoch, 0)
+
+	switch table {
+	case attestationsRetention, beaconCommitteesRetention:
+		if s.epochSummaries {
+			requiredEpochs = append(requiredEpochs, md.LastEpoch)
+		}
+		if s.blockSummaries {
+			// Block summaries look back over the inclusion window, which can stretch in to the
+			// previous epoch; the last summarized epoch covers this for the next block epoch.
+			requiredEpochs = append(requiredEpochs, md.LastBlockEpoch)
+		}
+		if s.validatorSummaries {
+			requiredEpochs = append(requiredEpochs, md.LastValidatorEpoch)
+		}
+	case syncAggregatesRetention:
+		if s.blockSummaries {
+			requiredEpochs = append(requiredEpochs, md.LastBlockEpoch)
+		}
+		if s.validatorSummaries {
+			requiredEpochs = append(requiredEpochs, s.validatorDayRequiredEpoch(md))
+		}
+	case syncCommitteesRetention:
+		if s.validatorSummaries {
+			requiredEpochs = append(requiredEpochs, s.validatorDayRequiredEpoch(md))
+		}
+	}
+
+	if len(requiredEpochs) == 0 {
+		return 0, false
+	}
+	requiredEpoch := requiredEpochs[0]
+	for _, epoch := range requiredEpochs[1:] {
+		if epoch < requiredEpoch {
+			requiredEpoch = epoch
+		}
+	}
+
+	return requiredEpoch, true
+}
+
+// validatorDayRequiredEpoch returns the first epoch of the next validator day to be summarized.
+func (s *Service) validatorDayRequiredEpoch(md *metadata) phase0.Epoch {
+	if md.LastValidatorDay == -1 {
+		return 0
+	}
+	return s.chainTime.TimestampToEpoch(time.Unix(md.LastValidatorDay, 0).In(time.UTC).AddDate(0, 0, 1))
+}
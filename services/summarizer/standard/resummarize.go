@@ -0,0 +1,217 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Resummarize recomputes the summaries for the given range of epochs, inclusive.
+//
+// Only summaries that have already been generated are recomputed.  Day summaries, and the
+// period summaries built from them, are recomputed for days that fall entirely within the
+// range.  All changes are made in a single transaction, so either every summary in the range
+// is recomputed or none are; ranges longer than the maximum days per run are rejected.  The
+// summarizer's metadata is left unchanged.
+func (s *Service) Resummarize(ctx context.Context, fromEpoch phase0.Epoch, toEpoch phase0.Epoch) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "Resummarize",
+		trace.WithAttributes(
+			attribute.Int64("from epoch", int64(fromEpoch)),
+			attribute.Int64("to epoch", int64(toEpoch)),
+		))
+	defer span.End()
+
+	if fromEpoch > toEpoch {
+		return errors.New("from epoch must not be after to epoch")
+	}
+	if maxDays := s.maxDaysPerRun.Load(); maxDays > 0 && toEpoch-fromEpoch+1 > phase0.Epoch(maxDays)*s.epochsPerDay() {
+		return fmt.Errorf("range is longer than the maximum of %d days per run; resummarize in smaller ranges or increase summarizer.max-days-per-run", maxDays)
+	}
+	beginner, isBeginner := s.chainDB.(chaindb.NestedTxBeginner)
+	if !isBeginner {
+		return errors.New("chain database does not support nested transactions")
+	}
+
+	// Ensure that we do not run alongside the finality handler.
+	if err := s.activitySem.Acquire(ctx, 1); err != nil {
+		return errors.Wrap(err, "failed to acquire summarizer")
+	}
+	defer s.activitySem.Release(1)
+
+	// The individual summarizers update metadata as they go, so keep the original to restore
+	// at the end.
+	mdJSON, err := s.chainDB.Metadata(ctx, metadataKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+	if mdJSON == nil {
+		return errors.New("no summaries generated; nothing to resummarize")
+	}
+	original, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+
+	// Raw data is required to recompute summaries, so ensure that it has not been pruned.
+	for table, prunedEpoch := range original.PrunedEpochs {
+		if fromEpoch < prunedEpoch {
+			return fmt.Errorf("%s have been pruned up to epoch %d", table, prunedEpoch)
+		}
+	}
+
+	// The summarizers' own transactions are nested within this one, so nothing is persisted
+	// until it is committed.
+	ctx, cancel, err := beginner.BeginNestedTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to resummarize")
+	}
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata")
+	}
+
+	if err := s.resummarizeEpochs(ctx, md, original, fromEpoch, toEpoch); err != nil {
+		cancel()
+		return err
+	}
+
+	days := s.resummarizeDayRange(fromEpoch, toEpoch)
+	if len(days) > 0 {
+		if err := s.resummarizeDays(ctx, original, days); err != nil {
+			cancel()
+			return err
+		}
+		if err := s.resummarizePeriods(ctx, original, days[0], days[len(days)-1]); err != nil {
+			cancel()
+			return err
+		}
+	}
+
+	if err := s.chainDB.SetMetadata(ctx, metadataKey, mdJSON); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to restore metadata")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction to resummarize")
+	}
+
+	log.Info().Uint64("from_epoch", uint64(fromEpoch)).Uint64("to_epoch", uint64(toEpoch)).Msg("Resummarized epochs")
+
+	return nil
+}
+
+// resummarizeEpochs recomputes the epoch, block and validator epoch summaries in the given range.
+func (s *Service) resummarizeEpochs(ctx context.Context,
+	md *metadata,
+	original *metadata,
+	fromEpoch phase0.Epoch,
+	toEpoch phase0.Epoch,
+) error {
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		if s.epochSummaries && epoch <= original.LastEpoch {
+			updated, err := s.summarizeEpoch(ctx, md, epoch)
+			if err != nil {
+				return errors.Wrapf(err, "failed to resummarize epoch %d", epoch)
+			}
+			if !updated {
+				return fmt.Errorf("not enough data to resummarize epoch %d", epoch)
+			}
+		}
+		if s.blockSummaries && epoch <= original.LastBlockEpoch {
+			if err := s.summarizeBlocksInEpoch(ctx, md, epoch); err != nil {
+				return errors.Wrapf(err, "failed to resummarize blocks in epoch %d", epoch)
+			}
+		}
+		if s.validatorSummaries && epoch <= original.LastValidatorEpoch {
+			if err := s.summarizeValidatorsInEpoch(ctx, md, epoch); err != nil {
+				return errors.Wrapf(err, "failed to resummarize validators in epoch %d", epoch)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resummarizeDayRange returns the days that fall entirely within the given range of epochs.
+func (s *Service) resummarizeDayRange(fromEpoch phase0.Epoch, toEpoch phase0.Epoch) []time.Time {
+	start := s.chainTime.StartOfEpoch(fromEpoch).In(time.UTC)
+	firstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	if s.chainTime.TimestampToEpoch(firstDay) < fromEpoch {
+		firstDay = firstDay.AddDate(0, 0, 1)
+	}
+
+	days := make([]time.Time, 0)
+	for day := firstDay; s.chainTime.TimestampToEpoch(day.AddDate(0, 0, 1))-1 <= toEpoch; day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	return days
+}
+
+// resummarizeDays recomputes the day summaries for the given days.
+func (s *Service) resummarizeDays(ctx context.Context,
+	original *metadata,
+	days []time.Time,
+) error {
+	for _, day := range days {
+		if s.validatorSummaries && original.LastValidatorDay != -1 && day.Unix() <= original.LastValidatorDay {
+			if err := s.summarizeValidatorsInDay(ctx, day); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to resummarize validators for day %s", day.Format("2006-01-02")))
+			}
+		}
+		if s.blockSummaries && original.LastClientDay != -1 && day.Unix() <= original.LastClientDay {
+			if err := s.summarizeClientsInDay(ctx, day); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to resummarize clients for day %s", day.Format("2006-01-02")))
+			}
+		}
+	}
+
+	return nil
+}
+
+// resummarizePeriods recomputes the period summaries that contain any of the given days.
+func (s *Service) resummarizePeriods(ctx context.Context,
+	original *metadata,
+	firstDay time.Time,
+	lastDay time.Time,
+) error {
+	for _, period := range s.validatorPeriods {
+		lastPeriod, exists := original.LastValidatorPeriods[period.String()]
+		if !exists {
+			continue
+		}
+		for timestamp := period.Truncate(s.chainTime.GenesisTime().In(time.UTC)); !timestamp.After(lastDay) && timestamp.Unix() <= lastPeriod; timestamp = period.Increment(timestamp) {
+			if !period.Increment(timestamp).After(firstDay) {
+				continue
+			}
+			if err := s.summarizeValidatorsInPeriod(ctx, period, timestamp); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to resummarize validator period %s starting %s", period.String(), timestamp.Format("2006-01-02")))
+			}
+		}
+	}
+
+	return nil
+}
@@ -15,9 +15,12 @@ package standard
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"sync"
 
 	eth2client "github.com/attestantio/go-eth2-client"
+	api "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -25,37 +28,64 @@ import (
 	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/chaintime"
 	"github.com/wealdtech/chaind/util"
+	"go.uber.org/atomic"
 	"golang.org/x/sync/semaphore"
 )
 
 // Service is a summarizer service.
 type Service struct {
-	eth2Client                      eth2client.Service
-	chainDB                         chaindb.Service
-	farFutureEpoch                  phase0.Epoch
-	proposerDutiesProvider          chaindb.ProposerDutiesProvider
-	attestationsProvider            chaindb.AttestationsProvider
-	blocksProvider                  chaindb.BlocksProvider
-	depositsProvider                chaindb.DepositsProvider
-	validatorsProvider              chaindb.ValidatorsProvider
-	attesterSlashingsProvider       chaindb.AttesterSlashingsProvider
-	proposerSlashingsProvider       chaindb.ProposerSlashingsProvider
-	chainTime                       chaintime.Service
-	maxTimelyAttestationSourceDelay uint64
-	maxTimelyAttestationTargetDelay uint64
-	maxTimelyAttestationHeadDelay   uint64
-	epochSummaries                  bool
-	blockSummaries                  bool
-	validatorSummaries              bool
-	maxDaysPerRun                   uint64
-	validatorEpochRetention         *util.CalendarDuration
-	validatorBalanceRetention       *util.CalendarDuration
-	activitySem                     *semaphore.Weighted
+	eth2Client                         eth2client.Service
+	chainDB                            chaindb.Service
+	farFutureEpoch                     phase0.Epoch
+	proposerDutiesProvider             chaindb.ProposerDutiesProvider
+	attestationsProvider               chaindb.AttestationsProvider
+	blocksProvider                     chaindb.BlocksProvider
+	depositsProvider                   chaindb.DepositsProvider
+	validatorsProvider                 chaindb.ValidatorsProvider
+	attesterSlashingsProvider          chaindb.AttesterSlashingsProvider
+	proposerSlashingsProvider          chaindb.ProposerSlashingsProvider
+	withdrawalsProvider                chaindb.WithdrawalsProvider
+	eth1DepositsProvider               chaindb.ETH1DepositsProvider
+	chainTime                          chaintime.Service
+	maxTimelyAttestationSourceDelay    uint64
+	maxTimelyAttestationTargetDelay    uint64
+	maxTimelyAttestationHeadDelay      uint64
+	epochSummaries                     bool
+	blockSummaries                     bool
+	validatorSummaries                 bool
+	withdrawalSummaries                bool
+	depositReconciliation              bool
+	depositDomainType                  phase0.DomainType
+	eth1VoteSummaries                  bool
+	inactivityScores                   bool
+	epochsPerETH1VotingPeriod          uint64
+	effectiveBalanceIncrement          phase0.Gwei
+	baseRewardFactor                   uint64
+	whistleblowerRewardQuotient        uint64
+	syncCommitteeSize                  uint64
+	minEpochsToInactivityPenalty       uint64
+	inactivityScoreBias                uint64
+	inactivityPenaltyQuotientAltair    uint64
+	inactivityPenaltyQuotientBellatrix uint64
+	blockRewardsEpochMu                sync.Mutex
+	blockRewardsEpoch                  *blockRewardsEpoch
+	maxDaysPerRun                      atomic.Uint64
+	retentions                         atomic.Pointer[retentions]
+	validatorPeriods                   []*util.CalendarDuration
+	provisionalEpochs                  uint64
+	lastProvisionalHeadRoot            phase0.Root
+	activitySem                        *semaphore.Weighted
+	provisionalSem                     *semaphore.Weighted
+	validatorSummariesMu               sync.Mutex
+	activity                           *util.Activity
 }
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -64,7 +94,8 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "summarizer").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "summarizer").Str("impl", "standard").Logger())
 
 	if err := registerMetrics(ctx, parameters.monitor); err != nil {
 		return nil, errors.New("failed to register metrics")
@@ -105,6 +136,55 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		return nil, errors.New("chain DB does not provide proposer slashings")
 	}
 
+	withdrawalsProvider, isProvider := parameters.chainDB.(chaindb.WithdrawalsProvider)
+	if !isProvider {
+		return nil, errors.New("chain DB does not provide withdrawals")
+	}
+
+	if parameters.blockSummaries {
+		if _, isProvider := parameters.chainDB.(chaindb.SyncAggregateProvider); !isProvider {
+			return nil, errors.New("chain DB does not provide sync aggregates")
+		}
+		if _, isSetter := parameters.chainDB.(chaindb.ClientSummariesSetter); !isSetter {
+			return nil, errors.New("chain DB does not support client summary setting")
+		}
+	}
+
+	if parameters.withdrawalSummaries {
+		if _, isSetter := parameters.chainDB.(chaindb.WithdrawalDaySummariesSetter); !isSetter {
+			return nil, errors.New("chain DB does not support withdrawal day summary setting")
+		}
+	}
+
+	var eth1DepositsProvider chaindb.ETH1DepositsProvider
+	if parameters.depositReconciliation {
+		eth1DepositsProvider, isProvider = parameters.chainDB.(chaindb.ETH1DepositsProvider)
+		if !isProvider {
+			return nil, errors.New("chain DB does not provide Ethereum 1 deposits")
+		}
+		if _, isProvider := parameters.chainDB.(chaindb.ReconciledDepositsProvider); !isProvider {
+			return nil, errors.New("chain DB does not provide reconciled deposits")
+		}
+		if _, isSetter := parameters.chainDB.(chaindb.ReconciledDepositsSetter); !isSetter {
+			return nil, errors.New("chain DB does not support reconciled deposit setting")
+		}
+		if _, isProvider := parameters.chainDB.(chaindb.ForkScheduleProvider); !isProvider {
+			return nil, errors.New("chain DB does not provide fork schedule")
+		}
+		if _, isProvider := parameters.eth2Client.(eth2client.BeaconStateProvider); !isProvider {
+			return nil, errors.New("client does not provide beacon state")
+		}
+	}
+
+	if parameters.eth1VoteSummaries {
+		if _, isProvider := parameters.chainDB.(chaindb.ETH1VotePeriodsProvider); !isProvider {
+			return nil, errors.New("chain DB does not provide Ethereum 1 vote periods")
+		}
+		if _, isSetter := parameters.chainDB.(chaindb.ETH1VotePeriodsSetter); !isSetter {
+			return nil, errors.New("chain DB does not support Ethereum 1 vote period setting")
+		}
+	}
+
 	spec, err := parameters.eth2Client.(eth2client.SpecProvider).Spec(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to obtain spec")
@@ -128,45 +208,206 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		return nil, errors.New("SLOTS_PER_EPOCH of unexpected type")
 	}
 
-	var validatorEpochRetention *util.CalendarDuration
-	if parameters.validatorEpochRetention != "" {
-		validatorEpochRetention, err = util.ParseCalendarDuration(parameters.validatorEpochRetention)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse validator epoch retention")
+	var depositDomainType phase0.DomainType
+	if parameters.depositReconciliation {
+		tmp, exists = spec["DOMAIN_DEPOSIT"]
+		if !exists {
+			return nil, errors.New("DOMAIN_DEPOSIT not found in spec")
+		}
+		depositDomainType, ok = tmp.(phase0.DomainType)
+		if !ok {
+			return nil, errors.New("DOMAIN_DEPOSIT of unexpected type")
+		}
+	}
+
+	var effectiveBalanceIncrement phase0.Gwei
+	var baseRewardFactor uint64
+	var whistleblowerRewardQuotient uint64
+	var syncCommitteeSize uint64
+	if parameters.blockSummaries {
+		tmp, exists = spec["EFFECTIVE_BALANCE_INCREMENT"]
+		if !exists {
+			return nil, errors.New("EFFECTIVE_BALANCE_INCREMENT not found in spec")
+		}
+		tmpUint, ok := tmp.(uint64)
+		if !ok {
+			return nil, errors.New("EFFECTIVE_BALANCE_INCREMENT of unexpected type")
+		}
+		if tmpUint == 0 {
+			return nil, errors.New("EFFECTIVE_BALANCE_INCREMENT cannot be 0")
+		}
+		effectiveBalanceIncrement = phase0.Gwei(tmpUint)
+
+		tmp, exists = spec["BASE_REWARD_FACTOR"]
+		if !exists {
+			return nil, errors.New("BASE_REWARD_FACTOR not found in spec")
+		}
+		baseRewardFactor, ok = tmp.(uint64)
+		if !ok {
+			return nil, errors.New("BASE_REWARD_FACTOR of unexpected type")
+		}
+
+		tmp, exists = spec["WHISTLEBLOWER_REWARD_QUOTIENT"]
+		if !exists {
+			return nil, errors.New("WHISTLEBLOWER_REWARD_QUOTIENT not found in spec")
+		}
+		whistleblowerRewardQuotient, ok = tmp.(uint64)
+		if !ok {
+			return nil, errors.New("WHISTLEBLOWER_REWARD_QUOTIENT of unexpected type")
+		}
+		if whistleblowerRewardQuotient == 0 {
+			return nil, errors.New("WHISTLEBLOWER_REWARD_QUOTIENT cannot be 0")
+		}
+
+		// Sync committee size is not present prior to Altair.
+		tmp, exists = spec["SYNC_COMMITTEE_SIZE"]
+		if exists {
+			syncCommitteeSize, ok = tmp.(uint64)
+			if !ok {
+				return nil, errors.New("SYNC_COMMITTEE_SIZE of unexpected type")
+			}
 		}
 	}
 
-	var validatorBalanceRetention *util.CalendarDuration
-	if parameters.validatorBalanceRetention != "" {
-		validatorBalanceRetention, err = util.ParseCalendarDuration(parameters.validatorBalanceRetention)
+	var minEpochsToInactivityPenalty uint64
+	if parameters.epochSummaries {
+		tmp, exists = spec["MIN_EPOCHS_TO_INACTIVITY_PENALTY"]
+		if !exists {
+			return nil, errors.New("MIN_EPOCHS_TO_INACTIVITY_PENALTY not found in spec")
+		}
+		minEpochsToInactivityPenalty, ok = tmp.(uint64)
+		if !ok {
+			return nil, errors.New("MIN_EPOCHS_TO_INACTIVITY_PENALTY of unexpected type")
+		}
+	}
+
+	// Inactivity scores are not present prior to Altair.
+	var inactivityScoreBias uint64
+	var inactivityPenaltyQuotientAltair uint64
+	var inactivityPenaltyQuotientBellatrix uint64
+	if parameters.validatorSummaries {
+		for name, value := range map[string]*uint64{
+			"INACTIVITY_SCORE_BIAS":                 &inactivityScoreBias,
+			"INACTIVITY_PENALTY_QUOTIENT_ALTAIR":    &inactivityPenaltyQuotientAltair,
+			"INACTIVITY_PENALTY_QUOTIENT_BELLATRIX": &inactivityPenaltyQuotientBellatrix,
+		} {
+			tmp, exists = spec[name]
+			if !exists {
+				continue
+			}
+			*value, ok = tmp.(uint64)
+			if !ok {
+				return nil, fmt.Errorf("%s of unexpected type", name)
+			}
+		}
+	}
+
+	var epochsPerETH1VotingPeriod uint64
+	if parameters.eth1VoteSummaries {
+		tmp, exists = spec["EPOCHS_PER_ETH1_VOTING_PERIOD"]
+		if !exists {
+			return nil, errors.New("EPOCHS_PER_ETH1_VOTING_PERIOD not found in spec")
+		}
+		epochsPerETH1VotingPeriod, ok = tmp.(uint64)
+		if !ok {
+			return nil, errors.New("EPOCHS_PER_ETH1_VOTING_PERIOD of unexpected type")
+		}
+		if epochsPerETH1VotingPeriod == 0 {
+			return nil, errors.New("EPOCHS_PER_ETH1_VOTING_PERIOD cannot be 0")
+		}
+	}
+
+	retentions, err := parseRetentions(parameters.chainDB,
+		parameters.validatorEpochRetention,
+		parameters.validatorBalanceRetention,
+		parameters.rawRetentions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	validatorPeriods := make([]*util.CalendarDuration, 0, len(parameters.validatorPeriods))
+	validatorPeriodNames := make(map[string]bool)
+	for _, validatorPeriod := range parameters.validatorPeriods {
+		period, err := util.ParseCalendarDuration(validatorPeriod)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse validator balance retention")
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to parse validator period %s", validatorPeriod))
+		}
+		if period.Hours() != 0 || period.Minutes() != 0 || period.Seconds() != 0 {
+			return nil, fmt.Errorf("validator period %s must be made up of whole days", validatorPeriod)
+		}
+		if validatorPeriodNames[period.String()] {
+			return nil, fmt.Errorf("duplicate validator period %s", validatorPeriod)
+		}
+		validatorPeriodNames[period.String()] = true
+		validatorPeriods = append(validatorPeriods, period)
+	}
+	if len(validatorPeriods) > 0 {
+		if !parameters.validatorSummaries {
+			return nil, errors.New("validator periods require validator summaries")
+		}
+		if _, isProvider := parameters.chainDB.(chaindb.ValidatorDaySummariesProvider); !isProvider {
+			return nil, errors.New("chain DB does not provide validator day summaries")
+		}
+		if _, isSetter := parameters.chainDB.(chaindb.ValidatorPeriodSummariesSetter); !isSetter {
+			return nil, errors.New("chain DB does not support validator period summary setting")
+		}
+	}
+
+	if parameters.provisionalEpochs > 0 {
+		if !parameters.validatorSummaries {
+			return nil, errors.New("provisional summaries require validator summaries")
+		}
+		if _, isPruner := parameters.chainDB.(chaindb.ValidatorEpochSummariesPruner); !isPruner {
+			return nil, errors.New("chain DB does not support validator epoch summary pruning")
+		}
+		if _, isProvider := parameters.eth2Client.(eth2client.EventsProvider); !isProvider {
+			return nil, errors.New("client does not provide events")
 		}
 	}
 
 	s := &Service{
-		eth2Client:                      parameters.eth2Client,
-		chainDB:                         parameters.chainDB,
-		farFutureEpoch:                  phase0.Epoch(0xffffffffffffffff),
-		proposerDutiesProvider:          proposerDutiesProvider,
-		attestationsProvider:            attestationsProvider,
-		blocksProvider:                  blocksProvider,
-		depositsProvider:                depositsProvider,
-		validatorsProvider:              validatorsProvider,
-		attesterSlashingsProvider:       attesterSlashingsProvider,
-		proposerSlashingsProvider:       proposerSlashingsProvider,
-		chainTime:                       parameters.chainTime,
-		maxTimelyAttestationSourceDelay: uint64(math.Sqrt(float64(slotsPerEpoch))),
-		maxTimelyAttestationTargetDelay: slotsPerEpoch,
-		maxTimelyAttestationHeadDelay:   minAttestationInclusionDelay,
-		epochSummaries:                  parameters.epochSummaries,
-		blockSummaries:                  parameters.blockSummaries,
-		validatorSummaries:              parameters.validatorSummaries,
-		maxDaysPerRun:                   parameters.maxDaysPerRun,
-		validatorEpochRetention:         validatorEpochRetention,
-		validatorBalanceRetention:       validatorBalanceRetention,
-		activitySem:                     semaphore.NewWeighted(1),
+		eth2Client:                         parameters.eth2Client,
+		chainDB:                            parameters.chainDB,
+		farFutureEpoch:                     phase0.Epoch(0xffffffffffffffff),
+		proposerDutiesProvider:             proposerDutiesProvider,
+		attestationsProvider:               attestationsProvider,
+		blocksProvider:                     blocksProvider,
+		depositsProvider:                   depositsProvider,
+		validatorsProvider:                 validatorsProvider,
+		attesterSlashingsProvider:          attesterSlashingsProvider,
+		proposerSlashingsProvider:          proposerSlashingsProvider,
+		withdrawalsProvider:                withdrawalsProvider,
+		eth1DepositsProvider:               eth1DepositsProvider,
+		chainTime:                          parameters.chainTime,
+		maxTimelyAttestationSourceDelay:    uint64(math.Sqrt(float64(slotsPerEpoch))),
+		maxTimelyAttestationTargetDelay:    slotsPerEpoch,
+		maxTimelyAttestationHeadDelay:      minAttestationInclusionDelay,
+		epochSummaries:                     parameters.epochSummaries,
+		blockSummaries:                     parameters.blockSummaries,
+		validatorSummaries:                 parameters.validatorSummaries,
+		withdrawalSummaries:                parameters.withdrawalSummaries,
+		depositReconciliation:              parameters.depositReconciliation,
+		depositDomainType:                  depositDomainType,
+		eth1VoteSummaries:                  parameters.eth1VoteSummaries,
+		inactivityScores:                   parameters.inactivityScores,
+		epochsPerETH1VotingPeriod:          epochsPerETH1VotingPeriod,
+		effectiveBalanceIncrement:          effectiveBalanceIncrement,
+		baseRewardFactor:                   baseRewardFactor,
+		whistleblowerRewardQuotient:        whistleblowerRewardQuotient,
+		syncCommitteeSize:                  syncCommitteeSize,
+		minEpochsToInactivityPenalty:       minEpochsToInactivityPenalty,
+		inactivityScoreBias:                inactivityScoreBias,
+		inactivityPenaltyQuotientAltair:    inactivityPenaltyQuotientAltair,
+		inactivityPenaltyQuotientBellatrix: inactivityPenaltyQuotientBellatrix,
+		validatorPeriods:                   validatorPeriods,
+		provisionalEpochs:                  parameters.provisionalEpochs,
+		activitySem:                        semaphore.NewWeighted(1),
+		provisionalSem:                     semaphore.NewWeighted(1),
+		activity:                           util.NewActivity(),
 	}
+	s.maxDaysPerRun.Store(parameters.maxDaysPerRun)
+	s.retentions.Store(retentions)
 
 	// Note the current highest summarized epoch for the monitor.
 	md, err := s.getMetadata(ctx)
@@ -180,6 +421,20 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		s.catchup(ctx)
 	}
 
+	if s.provisionalEpochs > 0 {
+		// Set up the handler for new chain head updates.
+		if err := s.eth2Client.(eth2client.EventsProvider).Events(ctx, []string{"head"}, func(event *api.Event) {
+			if event.Data == nil {
+				// Happens when the channel shuts down, nothing to worry about.
+				return
+			}
+			eventData := event.Data.(*api.HeadEvent)
+			s.OnBeaconChainHeadUpdated(ctx, eventData.Slot, eventData.Block, eventData.State, eventData.EpochTransition)
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to add beacon chain head updated handler")
+		}
+	}
+
 	return s, nil
 }
 
@@ -190,9 +445,15 @@ func (s *Service) catchup(ctx context.Context) {
 	if err != nil || finality.Finalized.Epoch <= 2 {
 		return
 	}
+	monitorFinalizedEpoch(finality.Finalized.Epoch)
 
+	ctx, done, ok := s.activity.Start(ctx, "rollup")
+	if !ok {
+		return
+	}
 	go func(ctx context.Context,
 	) {
+		defer done()
 		if err := s.summarizeValidatorDays(ctx); err != nil {
 			log.Error().Err(err).Msg("Failed to summarize validator days")
 			return
@@ -202,6 +463,10 @@ func (s *Service) catchup(ctx context.Context) {
 			log.Error().Err(err).Msg("Failed to obtain metadata after initial rollup")
 			return
 		}
+		if s.activity.Stopping() {
+			// Rollup may be incomplete, so leave it to be picked up on restart.
+			return
+		}
 		md.PeriodicValidatorRollups = true
 		ctx, cancel, err := s.chainDB.BeginTx(ctx)
 		if err != nil {
@@ -218,3 +483,13 @@ func (s *Service) catchup(ctx context.Context) {
 		}
 	}(ctx)
 }
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -0,0 +1,135 @@
+// Copyright © 2021 - 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+)
+
+// retentions are the amounts of data retained before pruning.
+type retentions struct {
+	validatorEpoch   *util.CalendarDuration
+	validatorBalance *util.CalendarDuration
+	raw              map[string]*util.CalendarDuration
+}
+
+// parseRetentions parses retentions, ensuring that the chain database can prune the data.
+func parseRetentions(chainDB chaindb.Service,
+	validatorEpochRetention string,
+	validatorBalanceRetention string,
+	rawRetentions map[string]string,
+) (
+	*retentions,
+	error,
+) {
+	res := &retentions{
+		raw: make(map[string]*util.CalendarDuration),
+	}
+
+	var err error
+	if validatorEpochRetention != "" {
+		res.validatorEpoch, err = util.ParseCalendarDuration(validatorEpochRetention)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse validator epoch retention")
+		}
+	}
+
+	if validatorBalanceRetention != "" {
+		res.validatorBalance, err = util.ParseCalendarDuration(validatorBalanceRetention)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse validator balance retention")
+		}
+	}
+
+	for table, rawRetention := range rawRetentions {
+		if rawRetention == "" {
+			continue
+		}
+		retention, err := util.ParseCalendarDuration(rawRetention)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to parse %s retention", table))
+		}
+		var isPruner bool
+		switch table {
+		case attestationsRetention:
+			_, isPruner = chainDB.(chaindb.AttestationsPruner)
+		case beaconCommitteesRetention:
+			_, isPruner = chainDB.(chaindb.BeaconCommitteesPruner)
+		case syncAggregatesRetention:
+			_, isPruner = chainDB.(chaindb.SyncAggregatePruner)
+		case syncCommitteesRetention:
+			_, isPruner = chainDB.(chaindb.SyncCommitteesPruner)
+		default:
+			return nil, fmt.Errorf("unknown retention table %s", table)
+		}
+		if !isPruner {
+			return nil, fmt.Errorf("chain DB does not support %s pruning", table)
+		}
+		res.raw[table] = retention
+	}
+
+	return res, nil
+}
+
+// SetMaxDaysPerRun sets the maximum number of days' of data to summarize in a single run.
+// The change takes effect from the next run.
+func (s *Service) SetMaxDaysPerRun(maxDaysPerRun uint64) error {
+	if maxDaysPerRun == 0 {
+		return errors.New("no max days per run specified")
+	}
+
+	if previous := s.maxDaysPerRun.Swap(maxDaysPerRun); previous != maxDaysPerRun {
+		log.Info().Uint64("max_days_per_run", maxDaysPerRun).Msg("Updated max days per run")
+	}
+
+	return nil
+}
+
+// SetRetentions sets the amounts of validator epoch summaries, validator balances and raw data,
+// keyed by table, retained before pruning.  An empty retention disables pruning.
+// The change takes effect from the next run.
+func (s *Service) SetRetentions(validatorEpochRetention string,
+	validatorBalanceRetention string,
+	rawRetentions map[string]string,
+) error {
+	retentions, err := parseRetentions(s.chainDB, validatorEpochRetention, validatorBalanceRetention, rawRetentions)
+	if err != nil {
+		return err
+	}
+
+	previous := s.retentions.Swap(retentions)
+	if retentionString(previous.validatorEpoch) != retentionString(retentions.validatorEpoch) ||
+		retentionString(previous.validatorBalance) != retentionString(retentions.validatorBalance) ||
+		fmt.Sprint(previous.raw) != fmt.Sprint(retentions.raw) {
+		log.Info().
+			Str("validator_epoch_retention", retentionString(retentions.validatorEpoch)).
+			Str("validator_balance_retention", retentionString(retentions.validatorBalance)).
+			Str("raw_retentions", fmt.Sprint(retentions.raw)).
+			Msg("Updated retentions")
+	}
+
+	return nil
+}
+
+// retentionString returns a string representation of a retention, which may be nil.
+func retentionString(retention *util.CalendarDuration) string {
+	if retention == nil {
+		return ""
+	}
+	return retention.String()
+}
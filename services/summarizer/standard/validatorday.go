@@ -140,6 +140,7 @@ func (s *Service) addValidatorEpochSummaries(ctx context.Context,
 	}
 	log.Trace().Msg("Fetched validator epoch summaries")
 
+	effectivenessCounts := make(map[phase0.ValidatorIndex]int)
 	for _, epochSummary := range epochSummaries {
 		if _, exists := daySummaries[epochSummary.Index]; !exists {
 			daySummaries[epochSummary.Index] = &chaindb.ValidatorDaySummary{
@@ -150,6 +151,9 @@ func (s *Service) addValidatorEpochSummaries(ctx context.Context,
 		daySummaries[epochSummary.Index].Proposals += epochSummary.ProposerDuties
 		daySummaries[epochSummary.Index].ProposalsIncluded += epochSummary.ProposalsIncluded
 		daySummaries[epochSummary.Index].Attestations++
+		if epochSummary.InactivityPenalty != nil {
+			daySummaries[epochSummary.Index].InactivityPenalties += uint64(*epochSummary.InactivityPenalty)
+		}
 		if !epochSummary.AttestationIncluded {
 			continue
 		}
@@ -172,11 +176,22 @@ func (s *Service) addValidatorEpochSummaries(ctx context.Context,
 		if epochSummary.AttestationInclusionDelay != nil {
 			daySummaries[epochSummary.Index].AttestationsInclusionDelay += float64(*epochSummary.AttestationInclusionDelay)
 		}
+		if epochSummary.AttestationEffectiveness != nil {
+			if daySummaries[epochSummary.Index].AttestationsEffectiveness == nil {
+				effectiveness := float64(0)
+				daySummaries[epochSummary.Index].AttestationsEffectiveness = &effectiveness
+			}
+			*daySummaries[epochSummary.Index].AttestationsEffectiveness += *epochSummary.AttestationEffectiveness
+			effectivenessCounts[epochSummary.Index]++
+		}
 	}
 
-	// Fix up inclusion delay.
-	for _, daySummary := range daySummaries {
+	// Fix up inclusion delay and effectiveness.
+	for index, daySummary := range daySummaries {
 		daySummary.AttestationsInclusionDelay /= float64(daySummary.AttestationsIncluded)
+		if daySummary.AttestationsEffectiveness != nil {
+			*daySummary.AttestationsEffectiveness /= float64(effectivenessCounts[index])
+		}
 	}
 
 	return nil
@@ -36,25 +36,79 @@ func (s *Service) summarizeValidatorsInEpoch(ctx context.Context,
 	}
 	log.Trace().Msg("Summarizing validator epoch")
 
-	proposerDuties, validatorProposerDuties, err := s.validatorProposerDutiesForEpoch(ctx, epoch)
+	summaries, err := s.validatorEpochSummaries(ctx, epoch, nil)
 	if err != nil {
 		return err
 	}
+
+	// Provisional summaries are written concurrently, so ensure that they do not interleave.
+	s.validatorSummariesMu.Lock()
+	defer s.validatorSummariesMu.Unlock()
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to set validator epoch summary")
+	}
+
+	// Remove any provisional summaries for the epoch, as they are replaced by the final values.
+	if pruner, isPruner := s.chainDB.(chaindb.ValidatorEpochSummariesPruner); isPruner {
+		if err := pruner.PruneProvisionalValidatorEpochSummaries(ctx, epoch); err != nil {
+			cancel()
+			return errors.Wrap(err, "failed to prune provisional validator epoch summaries")
+		}
+	}
+
+	if err := s.chainDB.(chaindb.ValidatorEpochSummariesSetter).SetValidatorEpochSummaries(ctx, summaries); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set validator epoch summary")
+	}
+
+	log.Trace().Dur("elapsed", time.Since(started)).Msg("Set summary")
+	md.LastValidatorEpoch = epoch
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set summarizer metadata for validator epoch summary")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set commit transaction to set validator epoch summary")
+	}
+
+	return nil
+}
+
+// validatorEpochSummaries calculates the validator summaries for a given epoch.
+// If chain is supplied then it is used to decide canonical blocks and attestations,
+// otherwise the canonical state held in the database is used.
+func (s *Service) validatorEpochSummaries(ctx context.Context,
+	epoch phase0.Epoch,
+	chain *headChain,
+) (
+	[]*chaindb.ValidatorEpochSummary,
+	error,
+) {
+	started := time.Now()
+	log := log.With().Uint64("epoch", uint64(epoch)).Logger()
+
+	proposerDuties, validatorProposerDuties, err := s.validatorProposerDutiesForEpoch(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
 	log.Trace().Dur("elapsed", time.Since(started)).Msg("Fetched proposer duties")
 
-	validatorProposals, err := s.validatorProposalsForEpoch(ctx, epoch, proposerDuties, validatorProposerDuties)
+	validatorProposals, err := s.validatorProposalsForEpoch(ctx, epoch, proposerDuties, validatorProposerDuties, chain)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	log.Trace().Dur("elapsed", time.Since(started)).Msg("Fetched proposals")
 
-	attestationsIncluded, attestationsTargetCorrect, attestationsHeadCorrect, attestationsInclusionDelay, attestationsSourceTimely, attestationsTargetTimely, attestationsHeadTimely, err := s.attestationsForEpoch(ctx, epoch)
+	attestationsIncluded, attestationsTargetCorrect, attestationsHeadCorrect, attestationsInclusionDelay, attestationsSourceTimely, attestationsTargetTimely, attestationsHeadTimely, attestationsEffectiveness, err := s.attestationsForEpoch(ctx, epoch, chain)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	log.Trace().Dur("elapsed", time.Since(started)).Msg("Fetched attestations")
 
-	// Store the data.
+	// Build the summaries.
 	summaries := make([]*chaindb.ValidatorEpochSummary, 0, len(attestationsIncluded))
 	for index := range attestationsIncluded {
 		summary := &chaindb.ValidatorEpochSummary{
@@ -71,6 +125,9 @@ func (s *Service) summarizeValidatorsInEpoch(ctx context.Context,
 			summary.AttestationHeadCorrect = &attestationHeadCorrect
 			attestationInclusionDelay := int(attestationsInclusionDelay[index])
 			summary.AttestationInclusionDelay = &attestationInclusionDelay
+			if attestationEffectiveness, exists := attestationsEffectiveness[index]; exists {
+				summary.AttestationEffectiveness = &attestationEffectiveness
+			}
 			if epoch >= s.chainTime.AltairInitialEpoch() {
 				if attestationSourceTimely, exists := attestationsSourceTimely[index]; exists {
 					summary.AttestationSourceTimely = &attestationSourceTimely
@@ -86,28 +143,11 @@ func (s *Service) summarizeValidatorsInEpoch(ctx context.Context,
 		summaries = append(summaries, summary)
 	}
 
-	ctx, cancel, err := s.chainDB.BeginTx(ctx)
-	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction to set validator epoch summary")
-	}
-
-	if err := s.chainDB.(chaindb.ValidatorEpochSummariesSetter).SetValidatorEpochSummaries(ctx, summaries); err != nil {
-		cancel()
-		return errors.Wrap(err, "failed to set validator epoch summary")
-	}
-
-	log.Trace().Dur("elapsed", time.Since(started)).Msg("Set summary")
-	md.LastValidatorEpoch = epoch
-	if err := s.setMetadata(ctx, md); err != nil {
-		cancel()
-		return errors.Wrap(err, "failed to set summarizer metadata for validator epoch summary")
-	}
-	if err := s.chainDB.CommitTx(ctx); err != nil {
-		cancel()
-		return errors.Wrap(err, "failed to set commit transaction to set validator epoch summary")
+	if err := s.addInactivityPenalties(ctx, epoch, summaries); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return summaries, nil
 }
 
 func (s *Service) validatorProposerDutiesForEpoch(ctx context.Context,
@@ -153,6 +193,7 @@ func (s *Service) validatorProposalsForEpoch(ctx context.Context,
 	epoch phase0.Epoch,
 	proposerDuties []*chaindb.ProposerDuty,
 	validatorProposerDuties map[phase0.ValidatorIndex]int,
+	chain *headChain,
 ) (
 	map[phase0.ValidatorIndex]int,
 	error,
@@ -160,7 +201,7 @@ func (s *Service) validatorProposalsForEpoch(ctx context.Context,
 	minSlot := s.chainTime.FirstSlotOfEpoch(epoch)
 	maxSlot := s.chainTime.LastSlotOfEpoch(epoch)
 	// Fetch the block presence for the epoch.
-	presence, err := s.blocksProvider.CanonicalBlockPresenceForSlotRange(ctx, minSlot, maxSlot+1)
+	presence, err := s.canonicalBlockPresence(ctx, minSlot, maxSlot+1, chain)
 	if err != nil {
 		return nil, err
 	}
@@ -183,6 +224,7 @@ func (s *Service) validatorProposalsForEpoch(ctx context.Context,
 
 func (s *Service) attestationsForEpoch(ctx context.Context,
 	epoch phase0.Epoch,
+	chain *headChain,
 ) (
 	map[phase0.ValidatorIndex]bool,
 	map[phase0.ValidatorIndex]bool,
@@ -191,6 +233,7 @@ func (s *Service) attestationsForEpoch(ctx context.Context,
 	map[phase0.ValidatorIndex]bool,
 	map[phase0.ValidatorIndex]bool,
 	map[phase0.ValidatorIndex]bool,
+	map[phase0.ValidatorIndex]float64,
 	error,
 ) {
 	minSlot := s.chainTime.FirstSlotOfEpoch(epoch)
@@ -198,7 +241,10 @@ func (s *Service) attestationsForEpoch(ctx context.Context,
 	// Fetch all attestations for the epoch.
 	attestations, err := s.attestationsProvider.AttestationsForSlotRange(ctx, minSlot, maxSlot+1)
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, errors.Wrap(err, "failed to obtain attestations for slot range")
+		return nil, nil, nil, nil, nil, nil, nil, nil, errors.Wrap(err, "failed to obtain attestations for slot range")
+	}
+	if chain != nil {
+		chain.markAttestations(attestations, s.chainTime)
 	}
 	log.Trace().Int("attestations", len(attestations)).Uint64("epoch", uint64(epoch)).Uint64("first_slot", uint64(s.chainTime.FirstSlotOfEpoch(epoch))).Uint64("last_slot", uint64(s.chainTime.FirstSlotOfEpoch(epoch+1)-1)).Msg("Fetched attestations")
 
@@ -210,6 +256,7 @@ func (s *Service) attestationsForEpoch(ctx context.Context,
 	attestationsSourceTimely := make(map[phase0.ValidatorIndex]bool)
 	attestationsTargetTimely := make(map[phase0.ValidatorIndex]bool)
 	attestationsHeadTimely := make(map[phase0.ValidatorIndex]bool)
+	attestationsSlot := make(map[phase0.ValidatorIndex]phase0.Slot)
 	attestationsForSlots := make(map[phase0.Slot]struct{})
 	attestationsInSlots := make(map[phase0.Slot]struct{})
 	for _, attestation := range attestations {
@@ -242,6 +289,7 @@ func (s *Service) attestationsForEpoch(ctx context.Context,
 			shortestDelay, exists := attestationsInclusionDelay[index]
 			if !exists || inclusionDelay < shortestDelay {
 				attestationsInclusionDelay[index] = inclusionDelay
+				attestationsSlot[index] = attestation.Slot
 				attestationsSourceTimely[index] = attestationSourceTimely
 				attestationsTargetTimely[index] = attestationTargetTimely
 				attestationsHeadTimely[index] = attestationHeadTimely
@@ -249,10 +297,15 @@ func (s *Service) attestationsForEpoch(ctx context.Context,
 		}
 	}
 
+	attestationsEffectiveness, err := s.attestationsEffectiveness(ctx, attestationsSlot, attestationsInclusionDelay, chain)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, errors.Wrap(err, "failed to calculate attestation effectiveness")
+	}
+
 	// Add in any validators that did not attest.
 	validators, err := s.chainDB.(chaindb.ValidatorsProvider).Validators(ctx)
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, errors.Wrap(err, "failed to obtain validators")
+		return nil, nil, nil, nil, nil, nil, nil, nil, errors.Wrap(err, "failed to obtain validators")
 	}
 	for _, validator := range validators {
 		// Confirm active.
@@ -263,5 +316,73 @@ func (s *Service) attestationsForEpoch(ctx context.Context,
 			attestationsIncluded[validator.Index] = false
 		}
 	}
-	return attestationsIncluded, attestationsTargetCorrect, attestationsHeadCorrect, attestationsInclusionDelay, attestationsSourceTimely, attestationsTargetTimely, attestationsHeadTimely, nil
+	return attestationsIncluded, attestationsTargetCorrect, attestationsHeadCorrect, attestationsInclusionDelay, attestationsSourceTimely, attestationsTargetTimely, attestationsHeadTimely, attestationsEffectiveness, nil
+}
+
+// attestationsEffectiveness calculates the effectiveness of included attestations.  Effectiveness is the
+// ratio of the earliest possible inclusion delay, being the distance to the first canonical block after the
+// attestation slot, to the actual inclusion delay.  This avoids penalising validators for empty slots.
+func (s *Service) attestationsEffectiveness(ctx context.Context,
+	attestationsSlot map[phase0.ValidatorIndex]phase0.Slot,
+	attestationsInclusionDelay map[phase0.ValidatorIndex]phase0.Slot,
+	chain *headChain,
+) (
+	map[phase0.ValidatorIndex]float64,
+	error,
+) {
+	attestationsEffectiveness := make(map[phase0.ValidatorIndex]float64, len(attestationsSlot))
+	if len(attestationsSlot) == 0 {
+		return attestationsEffectiveness, nil
+	}
+
+	// Obtain the presence of canonical blocks over all slots in which the attestations could be included.
+	minSlot := phase0.Slot(0xffffffffffffffff)
+	maxSlot := phase0.Slot(0)
+	for index, slot := range attestationsSlot {
+		if slot+1 < minSlot {
+			minSlot = slot + 1
+		}
+		if slot+attestationsInclusionDelay[index] > maxSlot {
+			maxSlot = slot + attestationsInclusionDelay[index]
+		}
+	}
+	presence, err := s.canonicalBlockPresence(ctx, minSlot, maxSlot+1, chain)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain canonical block presence")
+	}
+
+	for index, slot := range attestationsSlot {
+		inclusionDelay := attestationsInclusionDelay[index]
+		if inclusionDelay == 0 {
+			continue
+		}
+		// Default to the actual delay, in case the block presence is incomplete.
+		optimalDelay := inclusionDelay
+		for delay := phase0.Slot(1); delay < inclusionDelay; delay++ {
+			if i := int(slot + delay - minSlot); i < len(presence) && presence[i] {
+				optimalDelay = delay
+				break
+			}
+		}
+		attestationsEffectiveness[index] = float64(optimalDelay) / float64(inclusionDelay)
+	}
+
+	return attestationsEffectiveness, nil
+}
+
+// canonicalBlockPresence provides the presence of canonical blocks in the given slot range, either from
+// the supplied chain or, if not supplied, from the database.
+func (s *Service) canonicalBlockPresence(ctx context.Context,
+	startSlot phase0.Slot,
+	endSlot phase0.Slot,
+	chain *headChain,
+) (
+	[]bool,
+	error,
+) {
+	if chain != nil {
+		return chain.presence(startSlot, endSlot), nil
+	}
+
+	return s.blocksProvider.CanonicalBlockPresenceForSlotRange(ctx, startSlot, endSlot)
 }
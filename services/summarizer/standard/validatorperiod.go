@@ -0,0 +1,207 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// summarizeValidatorPeriods rolls up validator day summaries in to each of the configured
+// periods, for all periods whose days have been fully summarized.
+func (s *Service) summarizeValidatorPeriods(ctx context.Context) error {
+	for _, period := range s.validatorPeriods {
+		if err := s.summarizeValidatorPeriod(ctx, period); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to summarize validator period %s", period.String()))
+		}
+	}
+
+	return nil
+}
+
+// summarizeValidatorPeriod rolls up validator day summaries in to a single configured period.
+func (s *Service) summarizeValidatorPeriod(ctx context.Context, period *util.CalendarDuration) error {
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata for validator period summarizer")
+	}
+	if md.LastValidatorDay == -1 {
+		// No days summarized, so no periods to summarize.
+		return nil
+	}
+
+	var startTime time.Time
+	lastPeriod, exists := md.LastValidatorPeriods[period.String()]
+	if !exists {
+		// Start at the beginning of the period in which genesis occurred.
+		startTime = period.Truncate(s.chainTime.GenesisTime().In(time.UTC))
+	} else {
+		startTime = period.Increment(time.Unix(lastPeriod, 0).In(time.UTC))
+	}
+	// Only summarize periods for which all days have been summarized.
+	summarizedTime := time.Unix(md.LastValidatorDay, 0).In(time.UTC).AddDate(0, 0, 1)
+
+	// Limit the number of days rolled up in a single run.  At least one period is always summarized,
+	// otherwise periods longer than the maximum would never be summarized.
+	days := uint64(0)
+	for timestamp := startTime; !period.Increment(timestamp).After(summarizedTime); timestamp = period.Increment(timestamp) {
+		periodDays := uint64(period.Increment(timestamp).Sub(timestamp) / (24 * time.Hour))
+		if days > 0 && days+periodDays > s.maxDaysPerRun.Load() {
+			log.Trace().Str("period", period.String()).Uint64("days", days).Msg("Reached maximum days for this run")
+			break
+		}
+		if err := util.Retry(ctx, "validator period summaries", func(ctx context.Context) error {
+			return s.summarizeValidatorsInPeriod(ctx, period, timestamp)
+		}); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to update validator summaries for period starting %s", timestamp.Format("2006-01-02")))
+		}
+		days += periodDays
+	}
+
+	return nil
+}
+
+// summarizeValidatorsInPeriod updates the validator summaries for the period starting at the given time.
+func (s *Service) summarizeValidatorsInPeriod(ctx context.Context,
+	period *util.CalendarDuration,
+	startTime time.Time,
+) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "summarizeValidatorsInPeriod",
+		trace.WithAttributes(
+			attribute.String("period", period.String()),
+			attribute.Int64("start time", startTime.Unix()),
+		))
+	defer span.End()
+
+	endTime := period.Increment(startTime)
+	log := log.With().Str("period", period.String()).Str("date", startTime.Format("2006-01-02")).Logger()
+	log.Trace().Stringer("start_time", startTime).Stringer("end_time", endTime).Msg("Summarizing validator period")
+
+	periodSummaries := make(map[phase0.ValidatorIndex]*chaindb.ValidatorPeriodSummary)
+	// Weights for the averaged values.
+	inclusionDelayWeights := make(map[phase0.ValidatorIndex]int)
+	effectivenessWeights := make(map[phase0.ValidatorIndex]int)
+
+	// Fetch a day at a time to keep memory use bounded.
+	for timestamp := startTime; timestamp.Before(endTime); timestamp = timestamp.AddDate(0, 0, 1) {
+		day := timestamp
+		daySummaries, err := s.chainDB.(chaindb.ValidatorDaySummariesProvider).ValidatorDaySummaries(ctx, &chaindb.ValidatorDaySummaryFilter{
+			Order: chaindb.OrderEarliest,
+			From:  &day,
+			To:    &day,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to obtain validator day summaries")
+		}
+
+		for _, daySummary := range daySummaries {
+			periodSummary, exists := periodSummaries[daySummary.Index]
+			if !exists {
+				periodSummary = &chaindb.ValidatorPeriodSummary{
+					Period:                period.String(),
+					Index:                 daySummary.Index,
+					StartTimestamp:        startTime,
+					EndTimestamp:          endTime,
+					StartBalance:          daySummary.StartBalance,
+					StartEffectiveBalance: daySummary.StartEffectiveBalance,
+				}
+				periodSummaries[daySummary.Index] = periodSummary
+			}
+			periodSummary.CapitalChange += daySummary.CapitalChange
+			periodSummary.RewardChange += daySummary.RewardChange
+			periodSummary.EffectiveBalanceChange += daySummary.EffectiveBalanceChange
+			periodSummary.Proposals += daySummary.Proposals
+			periodSummary.ProposalsIncluded += daySummary.ProposalsIncluded
+			periodSummary.Attestations += daySummary.Attestations
+			periodSummary.AttestationsIncluded += daySummary.AttestationsIncluded
+			periodSummary.AttestationsTargetCorrect += daySummary.AttestationsTargetCorrect
+			periodSummary.AttestationsHeadCorrect += daySummary.AttestationsHeadCorrect
+			periodSummary.AttestationsSourceTimely += daySummary.AttestationsSourceTimely
+			periodSummary.AttestationsTargetTimely += daySummary.AttestationsTargetTimely
+			periodSummary.AttestationsHeadTimely += daySummary.AttestationsHeadTimely
+			periodSummary.SyncCommitteeMessages += daySummary.SyncCommitteeMessages
+			periodSummary.SyncCommitteeMessagesIncluded += daySummary.SyncCommitteeMessagesIncluded
+			periodSummary.InactivityPenalties += daySummary.InactivityPenalties
+			if daySummary.AttestationsIncluded == 0 {
+				continue
+			}
+			// Averages are weighted by the number of attestations included each day.
+			periodSummary.AttestationsInclusionDelay += daySummary.AttestationsInclusionDelay * float64(daySummary.AttestationsIncluded)
+			inclusionDelayWeights[daySummary.Index] += daySummary.AttestationsIncluded
+			if daySummary.AttestationsEffectiveness != nil {
+				if periodSummary.AttestationsEffectiveness == nil {
+					effectiveness := float64(0)
+					periodSummary.AttestationsEffectiveness = &effectiveness
+				}
+				*periodSummary.AttestationsEffectiveness += *daySummary.AttestationsEffectiveness * float64(daySummary.AttestationsIncluded)
+				effectivenessWeights[daySummary.Index] += daySummary.AttestationsIncluded
+			}
+		}
+		span.AddEvent("Added day", trace.WithAttributes(attribute.Int64("timestamp", timestamp.Unix())))
+	}
+
+	// Turn in to array, fixing up inclusion delay and effectiveness.
+	summaries := make([]*chaindb.ValidatorPeriodSummary, 0, len(periodSummaries))
+	for index, periodSummary := range periodSummaries {
+		if inclusionDelayWeights[index] > 0 {
+			periodSummary.AttestationsInclusionDelay /= float64(inclusionDelayWeights[index])
+		}
+		if periodSummary.AttestationsEffectiveness != nil {
+			*periodSummary.AttestationsEffectiveness /= float64(effectivenessWeights[index])
+		}
+		summaries = append(summaries, periodSummary)
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to set validator period summaries")
+	}
+
+	if err := s.chainDB.(chaindb.ValidatorPeriodSummariesSetter).SetValidatorPeriodSummaries(ctx, period.String(), startTime, summaries); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set validator period summaries")
+	}
+
+	// Fetch updated metadata as it may have changed since we last obtained it.
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata for validator period summarizer")
+	}
+	if md.LastValidatorPeriods == nil {
+		md.LastValidatorPeriods = make(map[string]int64)
+	}
+	md.LastValidatorPeriods[period.String()] = startTime.Unix()
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set summarizer metadata for validator period summary")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set commit transaction to set validator period summary")
+	}
+
+	log.Trace().Int("validators", len(summaries)).Msg("Set validator period summaries")
+
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// summarizeWithdrawalDays summarizes withdrawals for all days that have
+// fully finalized by the given epoch.
+func (s *Service) summarizeWithdrawalDays(ctx context.Context, summaryEpoch phase0.Epoch) error {
+	if !s.withdrawalSummaries {
+		return nil
+	}
+	capellaEpoch := s.chainTime.CapellaInitialEpoch()
+	if summaryEpoch < capellaEpoch {
+		log.Trace().Msg("Capella not yet active; not summarizing withdrawals")
+		return nil
+	}
+
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain metadata for withdrawal day summarizer")
+	}
+
+	var startTime time.Time
+	if md.LastWithdrawalDay == -1 {
+		// Start at the beginning of the day in which capella occurred.
+		capella := s.chainTime.StartOfEpoch(capellaEpoch).In(time.UTC)
+		startTime = time.Date(capella.Year(), capella.Month(), capella.Day(), 0, 0, 0, 0, time.UTC)
+	} else {
+		startTime = time.Unix(md.LastWithdrawalDay, 0).In(time.UTC).AddDate(0, 0, 1)
+	}
+	// Only summarize days that have completely finalized.
+	finalizedTime := s.chainTime.StartOfEpoch(summaryEpoch + 1)
+
+	days := uint64(0)
+	for timestamp := startTime; !timestamp.AddDate(0, 0, 1).After(finalizedTime); timestamp = timestamp.AddDate(0, 0, 1) {
+		if days == s.maxDaysPerRun.Load() {
+			log.Trace().Uint64("days", days).Msg("Reached maximum days for this run")
+			break
+		}
+		if err := util.Retry(ctx, "withdrawal day summaries", func(ctx context.Context) error {
+			return s.summarizeWithdrawalsInDay(ctx, timestamp)
+		}); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to update withdrawal summaries for day %s", timestamp.Format("2006-01-02")))
+		}
+		days++
+	}
+
+	return nil
+}
+
+// summarizeWithdrawalsInDay updates the withdrawal summaries in a given day.
+func (s *Service) summarizeWithdrawalsInDay(ctx context.Context,
+	startTime time.Time,
+) error {
+	ctx, span := otel.Tracer("wealdtech.chaind.services.summarizer.standard").Start(ctx, "summarizeWithdrawalsInDay",
+		trace.WithAttributes(
+			attribute.Int64("start time", startTime.Unix()),
+		))
+	defer span.End()
+
+	log := log.With().Str("date", startTime.Format("2006-01-02")).Logger()
+	endTime := startTime.AddDate(0, 0, 1)
+	startSlot := s.chainTime.TimestampToSlot(startTime)
+	endSlot := s.chainTime.TimestampToSlot(endTime) - 1
+	log.Trace().Uint64("start_slot", uint64(startSlot)).Uint64("end_slot", uint64(endSlot)).Msg("Summarizing withdrawal day")
+
+	// Withdrawals are only counted if they are in canonical blocks.
+	blocks, err := s.blocksProvider.BlocksForSlotRange(ctx, startSlot, endSlot+1)
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain blocks")
+	}
+	canonicalRoots := make(map[phase0.Root]bool, len(blocks))
+	for _, block := range blocks {
+		if block.Canonical != nil && *block.Canonical {
+			canonicalRoots[block.Root] = true
+		}
+	}
+
+	withdrawals, err := s.withdrawalsProvider.Withdrawals(ctx, &chaindb.WithdrawalFilter{
+		Order: chaindb.OrderEarliest,
+		From:  &startSlot,
+		To:    &endSlot,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain withdrawals")
+	}
+	span.AddEvent("Obtained withdrawals")
+
+	daySummaries := make(map[phase0.ValidatorIndex]*chaindb.WithdrawalDaySummary)
+	for _, withdrawal := range withdrawals {
+		if !canonicalRoots[withdrawal.InclusionBlockRoot] {
+			continue
+		}
+		summary, exists := daySummaries[withdrawal.ValidatorIndex]
+		if !exists {
+			summary = &chaindb.WithdrawalDaySummary{
+				ValidatorIndex: withdrawal.ValidatorIndex,
+				StartTimestamp: startTime,
+			}
+			daySummaries[withdrawal.ValidatorIndex] = summary
+		}
+		// Withdrawals are ordered, so the address is that of the latest withdrawal.
+		summary.Address = withdrawal.Address
+		switch withdrawal.Type {
+		case chaindb.WithdrawalTypeFull:
+			summary.FullWithdrawals++
+			summary.FullAmount += withdrawal.Amount
+		case chaindb.WithdrawalTypePartial:
+			summary.PartialWithdrawals++
+			summary.PartialAmount += withdrawal.Amount
+		default:
+			log.Debug().Uint64("validator_index", uint64(withdrawal.ValidatorIndex)).Msg("Withdrawal type unknown; counting as partial")
+			summary.PartialWithdrawals++
+			summary.PartialAmount += withdrawal.Amount
+		}
+	}
+
+	summaries := make([]*chaindb.WithdrawalDaySummary, 0, len(daySummaries))
+	for _, summary := range daySummaries {
+		summaries = append(summaries, summary)
+	}
+
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction to set withdrawal day summaries")
+	}
+
+	if err := s.chainDB.(chaindb.WithdrawalDaySummariesSetter).SetWithdrawalDaySummaries(ctx, summaries); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set withdrawal day summaries")
+	}
+
+	// Fetch updated metadata as it may have changed since we last obtained it.
+	md, err := s.getMetadata(ctx)
+	if err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to obtain metadata for withdrawal day summarizer")
+	}
+	md.LastWithdrawalDay = startTime.Unix()
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set summarizer metadata for withdrawal day summary")
+	}
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set commit transaction to set withdrawal day summary")
+	}
+
+	log.Trace().Int("validators", len(summaries)).Msg("Set withdrawal day summaries")
+
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/governor"
 )
 
 // OnBeaconChainHeadUpdated receives beacon chain head updated notifications.
@@ -55,6 +56,8 @@ func (s *Service) OnBeaconChainHeadUpdated(
 		return
 	}
 
+	// Requests made whilst following the head of the chain take priority over catchup.
+	ctx = governor.WithPriority(ctx, governor.PriorityHead)
 	s.catchup(ctx, md)
 }
 
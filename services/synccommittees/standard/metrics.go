@@ -18,18 +18,20 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/chaintime"
 	"github.com/wealdtech/chaind/services/metrics"
+	"go.uber.org/atomic"
 )
 
 var metricsNamespace = "chaind_synccommittees"
 
 var (
-	highestPeriod    uint64
+	highestPeriod    atomic.Uint64
 	latestPeriod     prometheus.Gauge
 	periodsProcessed prometheus.Gauge
 )
 
-func registerMetrics(ctx context.Context, monitor metrics.Service) error {
+func registerMetrics(ctx context.Context, monitor metrics.Service, chainTime chaintime.Service) error {
 	if latestPeriod != nil {
 		// Already registered.
 		return nil
@@ -39,12 +41,12 @@ func registerMetrics(ctx context.Context, monitor metrics.Service) error {
 		return nil
 	}
 	if monitor.Presenter() == "prometheus" {
-		return registerPrometheusMetrics(ctx)
+		return registerPrometheusMetrics(ctx, chainTime)
 	}
 	return nil
 }
 
-func registerPrometheusMetrics(_ context.Context) error {
+func registerPrometheusMetrics(_ context.Context, chainTime chaintime.Service) error {
 	latestPeriod = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Name:      "latest_period",
@@ -63,15 +65,39 @@ func registerPrometheusMetrics(_ context.Context) error {
 		return errors.Wrap(err, "failed to register periods_processed")
 	}
 
+	if err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "lag_periods",
+		Help:      "Number of sync committee periods between the current period and the latest period processed",
+	}, func() float64 {
+		current := chainTime.CurrentSyncCommitteePeriod()
+		highest := highestPeriod.Load()
+		if current < highest {
+			return 0
+		}
+		return float64(current - highest)
+	})); err != nil {
+		return errors.Wrap(err, "failed to register lag_periods")
+	}
+
 	return nil
 }
 
+// monitorLatestPeriod sets the latest period without registering an
+// increase in periods processed.  This does not usually need to be
+// called directly, as it is called as part of monitorPeriodProcessed.
+func monitorLatestPeriod(period uint64) {
+	highestPeriod.Store(period)
+	if latestPeriod != nil {
+		latestPeriod.Set(float64(period))
+	}
+}
+
 func monitorPeriodProcessed(period uint64) {
 	if periodsProcessed != nil {
 		periodsProcessed.Inc()
-		if period > highestPeriod {
-			latestPeriod.Set(float64(period))
-			highestPeriod = period
+		if period > highestPeriod.Load() {
+			monitorLatestPeriod(period)
 		}
 	}
 }
@@ -23,6 +23,7 @@ import (
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/chaintime"
+	"github.com/wealdtech/chaind/util"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -34,12 +35,16 @@ type Service struct {
 	syncCommitteesSetter         chaindb.SyncCommitteesSetter
 	chainTime                    chaintime.Service
 	activitySem                  *semaphore.Weighted
+	activity                     *util.Activity
 	epochsPerSyncCommitteePeriod uint64
 }
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -48,9 +53,10 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "synccommittees").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "synccommittees").Str("impl", "standard").Logger())
 
-	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+	if err := registerMetrics(ctx, parameters.monitor, parameters.chainTime); err != nil {
 		return nil, errors.New("failed to register metrics")
 	}
 
@@ -84,6 +90,7 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		syncCommitteesSetter:         syncCommitteesSetter,
 		chainTime:                    parameters.chainTime,
 		activitySem:                  semaphore.NewWeighted(1),
+		activity:                     util.NewActivity(),
 		epochsPerSyncCommitteePeriod: epochsPerSyncCommitteePeriod,
 	}
 
@@ -108,12 +115,20 @@ func (s *Service) updateAfterRestart(ctx context.Context, startPeriod int64) {
 		md.LatestPeriod = int64(s.chainTime.AltairInitialSyncCommitteePeriod()) - 1
 	}
 
+	if md.LatestPeriod >= 0 {
+		monitorLatestPeriod(uint64(md.LatestPeriod))
+	}
+
 	log.Info().Int64("period", md.LatestPeriod).Msg("Catching up from period")
 	s.catchup(ctx, md)
 	log.Info().Msg("Caught up")
 
 	// Set up the handler for new chain head updates.
 	if err := s.eventsProvider.Events(ctx, []string{"head"}, func(event *api.Event) {
+		if event.Data == nil {
+			// Happens when the channel shuts down, nothing to worry about.
+			return
+		}
 		eventData := event.Data.(*api.HeadEvent)
 		s.OnBeaconChainHeadUpdated(ctx, eventData.Slot)
 	}); err != nil {
@@ -122,33 +137,61 @@ func (s *Service) updateAfterRestart(ctx context.Context, startPeriod int64) {
 }
 
 func (s *Service) catchup(ctx context.Context, md *metadata) {
+	ctx, done, ok := s.activity.Start(ctx, "catchup")
+	if !ok {
+		log.Debug().Msg("Service stopping; not catching up")
+		return
+	}
+	defer done()
+
 	for period := uint64(md.LatestPeriod + 1); period <= s.chainTime.CurrentSyncCommitteePeriod(); period++ {
-		log := log.With().Uint64("period", period).Logger()
-		// Each update goes in to its own transaction, to make the data available sooner.
-		ctx, cancel, err := s.chainDB.BeginTx(ctx)
-		if err != nil {
-			log.Error().Err(err).Msg("Failed to begin transaction on update after restart")
+		if s.activity.Stopping() {
 			return
 		}
-
-		if err := s.updateSyncCommitteeForPeriod(ctx, period); err != nil {
+		log := log.With().Uint64("period", period).Logger()
+		if err := util.Retry(ctx, "sync committees", func(ctx context.Context) error {
+			return s.updatePeriod(ctx, md, period)
+		}); err != nil {
 			log.Warn().Err(err).Msg("Failed to update sync committee")
-			cancel()
 			return
 		}
+		log.Trace().Msg("Added sync committee")
+	}
+}
 
-		md.LatestPeriod = int64(period)
-		if err := s.setMetadata(ctx, md); err != nil {
-			log.Error().Err(err).Msg("Failed to set metadata")
-			cancel()
-			return
-		}
+// updatePeriod updates the sync committee for the given period.
+func (s *Service) updatePeriod(ctx context.Context, md *metadata, period uint64) error {
+	// Each update goes in to its own transaction, to make the data available sooner.
+	ctx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
 
-		if err := s.chainDB.CommitTx(ctx); err != nil {
-			log.Error().Err(err).Msg("Failed to commit transaction")
-			cancel()
-			return
-		}
-		log.Trace().Msg("Added sync committee")
+	if err := s.updateSyncCommitteeForPeriod(ctx, period); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to update sync committee")
+	}
+
+	md.LatestPeriod = int64(period)
+	if err := s.setMetadata(ctx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set metadata")
 	}
+
+	if err := s.chainDB.CommitTx(ctx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
 }
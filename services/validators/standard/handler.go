@@ -19,9 +19,12 @@ import (
 	"fmt"
 
 	eth2client "github.com/attestantio/go-eth2-client"
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/wealdtech/chaind/services/chaindb"
+	"github.com/wealdtech/chaind/services/governor"
+	"github.com/wealdtech/chaind/util"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -57,6 +60,16 @@ func (s *Service) OnBeaconChainHeadUpdated(
 		return
 	}
 
+	ctx, done, ok := s.activity.Start(ctx, "epoch transition")
+	if !ok {
+		s.activitySem.Release(1)
+		log.Debug().Msg("Service stopping; not handling")
+		return
+	}
+	defer done()
+	// Requests made whilst following the head of the chain take priority over catchup.
+	ctx = governor.WithPriority(ctx, governor.PriorityHead)
+
 	log.Trace().Msg("Handling epoch transition")
 
 	md, err := s.getMetadata(ctx)
@@ -66,7 +79,9 @@ func (s *Service) OnBeaconChainHeadUpdated(
 		return
 	}
 
-	if err := s.onEpochTransitionValidators(ctx, md, epoch); err != nil {
+	if err := util.Retry(ctx, "validators", func(ctx context.Context) error {
+		return s.onEpochTransitionValidators(ctx, md, epoch)
+	}); err != nil {
 		log.Warn().Err(err).Msg("Failed to update validators")
 	}
 	if err := s.onEpochTransitionValidatorBalances(ctx, md, epoch); err != nil {
@@ -161,59 +176,156 @@ func (s *Service) onEpochTransitionValidatorBalances(ctx context.Context,
 		firstEpoch++
 	}
 	for epoch := firstEpoch; epoch <= transitionedEpoch; epoch++ {
-		log := log.With().Uint64("epoch", uint64(epoch)).Logger()
-		stateID := fmt.Sprintf("%d", s.chainTime.FirstSlotOfEpoch(epoch))
-		log.Trace().Uint64("slot", uint64(s.chainTime.FirstSlotOfEpoch(epoch))).Msg("Fetching validators")
-		validators, err := s.eth2Client.(eth2client.ValidatorsProvider).Validators(ctx, stateID, nil)
-		if err != nil {
-			return errors.Wrap(err, "failed to obtain validators for validator balances")
+		if s.activity.Stopping() {
+			return nil
 		}
-
-		dbCtx, cancel, err := s.chainDB.BeginTx(ctx)
-		if err != nil {
-			return errors.Wrap(err, "failed to begin transaction for validator balances")
+		if err := util.Retry(ctx, "validator balances", func(ctx context.Context) error {
+			return s.updateValidatorBalancesForEpoch(ctx, md, epoch)
+		}); err != nil {
+			return err
 		}
-		if s.balances {
-			dbValidatorBalances := make([]*chaindb.ValidatorBalance, 0, len(validators))
-			for index, validator := range validators {
-				dbValidatorBalances = append(dbValidatorBalances, &chaindb.ValidatorBalance{
-					Index:            index,
-					Epoch:            epoch,
-					Balance:          validator.Balance,
-					EffectiveBalance: validator.Validator.EffectiveBalance,
-				})
+		monitorBalancesEpochProcessed(epoch)
+	}
+
+	return nil
+}
+
+// updateValidatorBalancesForEpoch fetches and stores the validator balances for the given epoch.
+func (s *Service) updateValidatorBalancesForEpoch(ctx context.Context,
+	md *metadata,
+	epoch phase0.Epoch,
+) error {
+	log := log.With().Uint64("epoch", uint64(epoch)).Logger()
+	dbValidatorBalances, err := s.validatorBalancesForEpoch(ctx, epoch)
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel, err := s.chainDB.BeginTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction for validator balances")
+	}
+	if s.balances {
+		if err := s.validatorsSetter.SetValidatorBalances(dbCtx, dbValidatorBalances); err != nil {
+			log.Trace().Err(err).Msg("Bulk insert failed; falling back to individual insert")
+			// This error will have caused the transaction to fail, so cancel it and start a new one.
+			cancel()
+			dbCtx, cancel, err = s.chainDB.BeginTx(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to begin transaction for validator balances (2)")
 			}
-			if err := s.validatorsSetter.SetValidatorBalances(dbCtx, dbValidatorBalances); err != nil {
-				log.Trace().Err(err).Msg("Bulk insert failed; falling back to individual insert")
-				// This error will have caused the transaction to fail, so cancel it and start a new one.
-				cancel()
-				dbCtx, cancel, err = s.chainDB.BeginTx(ctx)
-				if err != nil {
-					return errors.Wrap(err, "failed to begin transaction for validator balances (2)")
-				}
-				for _, dbValidatorBalance := range dbValidatorBalances {
-					if err := s.validatorsSetter.SetValidatorBalance(dbCtx, dbValidatorBalance); err != nil {
-						cancel()
-						return errors.Wrap(err, "failed to set validator balance")
-					}
+			for _, dbValidatorBalance := range dbValidatorBalances {
+				if err := s.validatorsSetter.SetValidatorBalance(dbCtx, dbValidatorBalance); err != nil {
+					cancel()
+					return errors.Wrap(err, "failed to set validator balance")
 				}
 			}
-			md.LatestBalancesEpoch = epoch
 		}
+		md.LatestBalancesEpoch = epoch
+	}
 
-		if err := s.setMetadata(dbCtx, md); err != nil {
-			cancel()
-			return errors.Wrap(err, "failed to set metadata for validator balances")
+	if err := s.setMetadata(dbCtx, md); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set metadata for validator balances")
+	}
+
+	if err := s.chainDB.CommitTx(dbCtx); err != nil {
+		cancel()
+		return errors.Wrap(err, "failed to set commit transaction for validator balances")
+	}
+	return nil
+}
+
+// validatorBalancesForEpoch fetches the validator balances at the start of the given epoch, along
+// with inactivity scores if required.
+func (s *Service) validatorBalancesForEpoch(ctx context.Context,
+	epoch phase0.Epoch,
+) (
+	[]*chaindb.ValidatorBalance,
+	error,
+) {
+	stateID := fmt.Sprintf("%d", s.chainTime.FirstSlotOfEpoch(epoch))
+
+	if !s.inactivityScores || epoch < s.chainTime.AltairInitialEpoch() {
+		log.Trace().Uint64("slot", uint64(s.chainTime.FirstSlotOfEpoch(epoch))).Msg("Fetching validators")
+		validators, err := s.eth2Client.(eth2client.ValidatorsProvider).Validators(ctx, stateID, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to obtain validators for validator balances")
+		}
+		dbValidatorBalances := make([]*chaindb.ValidatorBalance, 0, len(validators))
+		for index, validator := range validators {
+			dbValidatorBalances = append(dbValidatorBalances, &chaindb.ValidatorBalance{
+				Index:            index,
+				Epoch:            epoch,
+				Balance:          validator.Balance,
+				EffectiveBalance: validator.Validator.EffectiveBalance,
+			})
 		}
+		return dbValidatorBalances, nil
+	}
 
-		if err := s.chainDB.CommitTx(dbCtx); err != nil {
-			cancel()
-			return errors.Wrap(err, "failed to set commit transaction for validator balances")
+	// Inactivity scores are only available from the full beacon state, which also contains
+	// the balances, so fetch it instead of the validators.
+	log.Trace().Uint64("slot", uint64(s.chainTime.FirstSlotOfEpoch(epoch))).Msg("Fetching beacon state")
+	state, err := s.eth2Client.(eth2client.BeaconStateProvider).BeaconState(ctx, stateID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain beacon state for validator balances")
+	}
+	if state == nil {
+		return nil, errors.New("no beacon state for validator balances")
+	}
+	validators, err := state.Validators()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain validators from beacon state")
+	}
+	balances, err := state.ValidatorBalances()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to obtain validator balances from beacon state")
+	}
+	inactivityScores, err := stateInactivityScores(state)
+	if err != nil {
+		return nil, err
+	}
+	if len(balances) != len(validators) || len(inactivityScores) != len(validators) {
+		return nil, errors.New("inconsistent validator information in beacon state")
+	}
+
+	dbValidatorBalances := make([]*chaindb.ValidatorBalance, len(validators))
+	for i := range validators {
+		inactivityScore := inactivityScores[i]
+		dbValidatorBalances[i] = &chaindb.ValidatorBalance{
+			Index:            phase0.ValidatorIndex(i),
+			Epoch:            epoch,
+			Balance:          balances[i],
+			EffectiveBalance: validators[i].EffectiveBalance,
+			InactivityScore:  &inactivityScore,
 		}
-		monitorBalancesEpochProcessed(epoch)
 	}
 
-	return nil
+	return dbValidatorBalances, nil
+}
+
+// stateInactivityScores returns the inactivity scores from the beacon state.
+func stateInactivityScores(state *spec.VersionedBeaconState) ([]uint64, error) {
+	switch state.Version {
+	case spec.DataVersionAltair:
+		if state.Altair == nil {
+			return nil, errors.New("no Altair state")
+		}
+		return state.Altair.InactivityScores, nil
+	case spec.DataVersionBellatrix:
+		if state.Bellatrix == nil {
+			return nil, errors.New("no Bellatrix state")
+		}
+		return state.Bellatrix.InactivityScores, nil
+	case spec.DataVersionCapella:
+		if state.Capella == nil {
+			return nil, errors.New("no Capella state")
+		}
+		return state.Capella.InactivityScores, nil
+	default:
+		return nil, fmt.Errorf("no inactivity scores for %s state", state.Version)
+	}
 }
 
 // needsUpdate returns true if the validator needs an update according to our database information.
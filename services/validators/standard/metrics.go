@@ -19,24 +19,26 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wealdtech/chaind/services/chaintime"
 	"github.com/wealdtech/chaind/services/metrics"
+	"go.uber.org/atomic"
 )
 
 var metricsNamespace = "chaind_validators"
 
 var (
-	highestEpoch    phase0.Epoch
+	highestEpoch    atomic.Uint64
 	latestEpoch     prometheus.Gauge
 	epochsProcessed prometheus.Gauge
 )
 
 var (
-	balancesHighestEpoch    phase0.Epoch
+	balancesHighestEpoch    atomic.Uint64
 	balancesLatestEpoch     prometheus.Gauge
 	balancesEpochsProcessed prometheus.Gauge
 )
 
-func registerMetrics(_ context.Context, monitor metrics.Service) error {
+func registerMetrics(_ context.Context, monitor metrics.Service, chainTime chaintime.Service, balances bool) error {
 	if latestEpoch != nil {
 		// Already registered.
 		return nil
@@ -46,12 +48,12 @@ func registerMetrics(_ context.Context, monitor metrics.Service) error {
 		return nil
 	}
 	if monitor.Presenter() == "prometheus" {
-		return registerPrometheusMetrics()
+		return registerPrometheusMetrics(chainTime, balances)
 	}
 	return nil
 }
 
-func registerPrometheusMetrics() error {
+func registerPrometheusMetrics(chainTime chaintime.Service, balances bool) error {
 	latestEpoch = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Name:      "latest_epoch",
@@ -70,6 +72,21 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register epochs_processed")
 	}
 
+	if err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "lag_epochs",
+		Help:      "Number of epochs between the current epoch and the latest epoch processed",
+	}, func() float64 {
+		current := uint64(chainTime.CurrentEpoch())
+		highest := highestEpoch.Load()
+		if current < highest {
+			return 0
+		}
+		return float64(current - highest)
+	})); err != nil {
+		return errors.Wrap(err, "failed to register lag_epochs")
+	}
+
 	balancesLatestEpoch = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Name:      "balances_latest_epoch",
@@ -88,25 +105,61 @@ func registerPrometheusMetrics() error {
 		return errors.Wrap(err, "failed to register balances_epochs_processed")
 	}
 
+	if balances {
+		// Balances lag is only meaningful if balances are being fetched.
+		if err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "balances_lag_epochs",
+			Help:      "Number of epochs between the current epoch and the latest epoch for which balances have been processed",
+		}, func() float64 {
+			current := uint64(chainTime.CurrentEpoch())
+			highest := balancesHighestEpoch.Load()
+			if current < highest {
+				return 0
+			}
+			return float64(current - highest)
+		})); err != nil {
+			return errors.Wrap(err, "failed to register balances_lag_epochs")
+		}
+	}
+
 	return nil
 }
 
+// monitorLatestEpoch sets the latest epoch without registering an
+// increase in epochs processed.  This does not usually need to be
+// called directly, as it is called as part of monitorEpochProcessed.
+func monitorLatestEpoch(epoch phase0.Epoch) {
+	highestEpoch.Store(uint64(epoch))
+	if latestEpoch != nil {
+		latestEpoch.Set(float64(epoch))
+	}
+}
+
 func monitorEpochProcessed(epoch phase0.Epoch) {
 	if epochsProcessed != nil {
 		epochsProcessed.Inc()
-		if epoch > highestEpoch {
-			latestEpoch.Set(float64(epoch))
-			highestEpoch = epoch
+		if uint64(epoch) > highestEpoch.Load() {
+			monitorLatestEpoch(epoch)
 		}
 	}
 }
 
+// monitorBalancesLatestEpoch sets the latest balances epoch without
+// registering an increase in epochs processed.  This does not usually need
+// to be called directly, as it is called as part of monitorBalancesEpochProcessed.
+func monitorBalancesLatestEpoch(epoch phase0.Epoch) {
+	balancesHighestEpoch.Store(uint64(epoch))
+	if balancesLatestEpoch != nil {
+		balancesLatestEpoch.Set(float64(epoch))
+	}
+}
+
 func monitorBalancesEpochProcessed(epoch phase0.Epoch) {
 	if balancesEpochsProcessed != nil {
 		balancesEpochsProcessed.Inc()
-		if epoch > balancesHighestEpoch {
-			balancesLatestEpoch.Set(float64(epoch))
-			balancesHighestEpoch = epoch
+		if uint64(epoch) > balancesHighestEpoch.Load() {
+			monitorBalancesLatestEpoch(epoch)
 		}
 	}
 }
@@ -24,13 +24,14 @@ import (
 )
 
 type parameters struct {
-	logLevel   zerolog.Level
-	monitor    metrics.Service
-	eth2Client eth2client.Service
-	chainDB    chaindb.Service
-	chainTime  chaintime.Service
-	balances   bool
-	startEpoch int64
+	logLevel         zerolog.Level
+	monitor          metrics.Service
+	eth2Client       eth2client.Service
+	chainDB          chaindb.Service
+	chainTime        chaintime.Service
+	balances         bool
+	inactivityScores bool
+	startEpoch       int64
 }
 
 // Parameter is the interface for service parameters.
@@ -93,6 +94,13 @@ func WithBalances(balances bool) Parameter {
 	})
 }
 
+// WithInactivityScores states if the module should fetch validator inactivity scores along with balances.
+func WithInactivityScores(inactivityScores bool) Parameter {
+	return parameterFunc(func(p *parameters) {
+		p.inactivityScores = inactivityScores
+	})
+}
+
 // parseAndCheckParameters parses and checks parameters to ensure that mandatory parameters are present and correct.
 func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	parameters := parameters{
@@ -115,6 +123,9 @@ func parseAndCheckParameters(params ...Parameter) (*parameters, error) {
 	if parameters.chainTime == nil {
 		return nil, errors.New("no chain time specified")
 	}
+	if parameters.inactivityScores && !parameters.balances {
+		return nil, errors.New("inactivity scores require balances")
+	}
 
 	return &parameters, nil
 }
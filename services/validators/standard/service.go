@@ -23,6 +23,7 @@ import (
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/wealdtech/chaind/services/chaindb"
 	"github.com/wealdtech/chaind/services/chaintime"
+	"github.com/wealdtech/chaind/util"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -34,12 +35,17 @@ type Service struct {
 	validatorsSetter   chaindb.ValidatorsSetter
 	chainTime          chaintime.Service
 	balances           bool
+	inactivityScores   bool
 	activitySem        *semaphore.Weighted
+	activity           *util.Activity
 }
 
 // module-wide log.
 var log zerolog.Logger
 
+// module-wide log level, which can be changed whilst running.
+var logLevel = util.NewDynamicLevel(zerolog.GlobalLevel())
+
 // New creates a new service.
 func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	parameters, err := parseAndCheckParameters(params...)
@@ -48,9 +54,10 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 	}
 
 	// Set logging.
-	log = zerologger.With().Str("service", "validators").Str("impl", "standard").Logger().Level(parameters.logLevel)
+	logLevel.SetLevel(parameters.logLevel)
+	log = logLevel.Apply(zerologger.With().Str("service", "validators").Str("impl", "standard").Logger())
 
-	if err := registerMetrics(ctx, parameters.monitor); err != nil {
+	if err := registerMetrics(ctx, parameters.monitor, parameters.chainTime, parameters.balances); err != nil {
 		return nil, errors.New("failed to register metrics")
 	}
 
@@ -64,6 +71,12 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		return nil, errors.New("chain DB does not support validator setting")
 	}
 
+	if parameters.inactivityScores {
+		if _, isProvider := parameters.eth2Client.(eth2client.BeaconStateProvider); !isProvider {
+			return nil, errors.New("client does not provide beacon state")
+		}
+	}
+
 	s := &Service{
 		eth2Client:         parameters.eth2Client,
 		chainDB:            parameters.chainDB,
@@ -71,7 +84,9 @@ func New(ctx context.Context, params ...Parameter) (*Service, error) {
 		validatorsSetter:   validatorsSetter,
 		chainTime:          parameters.chainTime,
 		balances:           parameters.balances,
+		inactivityScores:   parameters.inactivityScores,
 		activitySem:        semaphore.NewWeighted(1),
+		activity:           util.NewActivity(),
 	}
 
 	// Update to current epoch (in the background).
@@ -88,14 +103,21 @@ func (s *Service) updateAfterRestart(ctx context.Context, startEpoch int64) {
 		return
 	}
 
-	md, err := s.getMetadata(ctx)
+	workCtx, done, ok := s.activity.Start(ctx, "catchup")
+	if !ok {
+		s.activitySem.Release(1)
+		log.Debug().Msg("Service stopping; not catching up")
+		return
+	}
+
+	md, err := s.getMetadata(workCtx)
 	if err != nil {
 		s.activitySem.Release(1)
 		log.Fatal().Err(err).Msg("Failed to obtain metadata before catchup")
 	}
 	if startEpoch >= 0 {
 		// Explicit requirement to start at a given epoch; update metadata accordingly.
-		ctx, cancel, err := s.chainDB.BeginTx(ctx)
+		ctx, cancel, err := s.chainDB.BeginTx(workCtx)
 		if err != nil {
 			s.activitySem.Release(1)
 			log.Fatal().Err(err).Msg("Failed to begin transaction to set start epoch")
@@ -112,23 +134,43 @@ func (s *Service) updateAfterRestart(ctx context.Context, startEpoch int64) {
 		}
 	}
 
+	monitorLatestEpoch(md.LatestEpoch)
+	monitorBalancesLatestEpoch(md.LatestBalancesEpoch)
+
 	log.Info().Uint64("epoch", uint64(md.LatestEpoch)).Msg("Catching up from epoch")
 	currentEpoch := s.chainTime.CurrentEpoch()
-	if err := s.onEpochTransitionValidators(ctx, md, currentEpoch); err != nil {
+	if err := util.Retry(workCtx, "validators", func(ctx context.Context) error {
+		return s.onEpochTransitionValidators(ctx, md, currentEpoch)
+	}); err != nil {
 		log.Warn().Err(err).Msg("Failed to update to head; will retry")
 	}
-	if err := s.onEpochTransitionValidatorBalances(ctx, md, currentEpoch); err != nil {
+	if err := s.onEpochTransitionValidatorBalances(workCtx, md, currentEpoch); err != nil {
 		log.Warn().Err(err).Msg("Failed to update validators")
 	}
+	done()
 	s.activitySem.Release(1)
 
 	log.Info().Uint64("epoch", uint64(md.LatestEpoch)).Msg("Caught up")
 
 	// Set up the handler for new chain head updates.
 	if err := s.eth2Client.(eth2client.EventsProvider).Events(ctx, []string{"head"}, func(event *api.Event) {
+		if event.Data == nil {
+			// Happens when the channel shuts down, nothing to worry about.
+			return
+		}
 		eventData := event.Data.(*api.HeadEvent)
 		s.OnBeaconChainHeadUpdated(ctx, eventData.Slot, eventData.Block, eventData.State, eventData.EpochTransition)
 	}); err != nil {
 		log.Fatal().Err(err).Msg("Failed to add beacon chain head updated handler")
 	}
 }
+
+// Stop stops the service, waiting for in-flight work to finish.
+func (s *Service) Stop(ctx context.Context) []string {
+	return s.activity.Stop(ctx)
+}
+
+// SetLogLevel sets the log level of the module whilst it is running.
+func (s *Service) SetLogLevel(level zerolog.Level) {
+	logLevel.SetLevel(level)
+}
@@ -0,0 +1,110 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/wealdtech/chaind/util"
+)
+
+// exitInterrupted is the exit code when in-flight work was interrupted on shutdown.
+const exitInterrupted = 2
+
+// stoppable is a running service that can be stopped gracefully.
+type stoppable struct {
+	name    string
+	stopper util.Stopper
+}
+
+var (
+	stoppablesMu sync.Mutex
+	stoppables   []*stoppable
+)
+
+// registerStoppable registers a service to be stopped on shutdown, if it supports it.
+func registerStoppable(name string, service interface{}) {
+	stopper, isStopper := service.(util.Stopper)
+	if !isStopper {
+		return
+	}
+
+	stoppablesMu.Lock()
+	stoppables = append(stoppables, &stoppable{
+		name:    name,
+		stopper: stopper,
+	})
+	stoppablesMu.Unlock()
+}
+
+// shutdown stops all registered services, waiting for in-flight work to finish up to the configured
+// timeout.  Work that has not finished by the abandon grace period before the timeout is cancelled,
+// leaving the rest of the timeout for it to roll back.  It is expected that the root context has already been cancelled, so that no new work is
+// triggered by events or timers.  A second signal received whilst waiting abandons in-flight work
+// immediately.
+// It returns the exit code for the process.
+func shutdown(sigCh <-chan os.Signal) int {
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("shutdown.timeout"))
+	defer cancel()
+	ctx = util.WithAbandonGrace(ctx, viper.GetDuration("shutdown.abandon-grace"))
+
+	go func() {
+		select {
+		case <-sigCh:
+			log.Warn().Msg("Received second signal; abandoning in-flight work")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	stoppablesMu.Lock()
+	services := make([]*stoppable, len(stoppables))
+	copy(services, stoppables)
+	stoppablesMu.Unlock()
+
+	var wg sync.WaitGroup
+	var interruptedMu sync.Mutex
+	interrupted := make([]string, 0)
+	for _, service := range services {
+		wg.Add(1)
+		go func(service *stoppable) {
+			defer wg.Done()
+			work := service.stopper.Stop(ctx)
+			if len(work) == 0 {
+				log.Trace().Str("service", service.name).Msg("Service stopped")
+				return
+			}
+			interruptedMu.Lock()
+			for _, name := range work {
+				interrupted = append(interrupted, fmt.Sprintf("%s/%s", service.name, name))
+			}
+			interruptedMu.Unlock()
+		}(service)
+	}
+	wg.Wait()
+
+	if len(interrupted) > 0 {
+		sort.Strings(interrupted)
+		log.Warn().Strs("interrupted", interrupted).Msg("In-flight work was interrupted and rolled back")
+		return exitInterrupted
+	}
+
+	log.Info().Msg("All in-flight work completed")
+	return 0
+}
@@ -0,0 +1,191 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultAbandonGrace is the time allowed for work to finish after it has been cancelled, if not
+// supplied with WithAbandonGrace.
+const defaultAbandonGrace = 10 * time.Second
+
+type abandonGraceKey struct{}
+
+// WithAbandonGrace returns a context that, when passed to Stop, allows work the given time to
+// finish after it has been cancelled.  The time is taken from that allowed by the context's
+// deadline, rather than added to it, and is limited to half of the time remaining.
+func WithAbandonGrace(ctx context.Context, grace time.Duration) context.Context {
+	return context.WithValue(ctx, abandonGraceKey{}, grace)
+}
+
+// abandonGraceFromContext returns the time allowed for work to finish after it has been cancelled.
+func abandonGraceFromContext(ctx context.Context) time.Duration {
+	if grace, ok := ctx.Value(abandonGraceKey{}).(time.Duration); ok {
+		return grace
+	}
+	return defaultAbandonGrace
+}
+
+// Stopper is the interface for services that can be stopped gracefully.
+type Stopper interface {
+	// Stop stops the service from starting new work, and waits for in-flight work to finish.
+	// If ctx is done before in-flight work finishes then the work is cancelled, and the names
+	// of the interrupted work are returned.
+	Stop(ctx context.Context) []string
+}
+
+// Activity tracks the in-flight work of a service, allowing it to be drained on shutdown.
+type Activity struct {
+	mutex    sync.Mutex
+	stopping bool
+	inFlight map[string]int
+	wg       sync.WaitGroup
+	lifetime context.Context
+	cancel   context.CancelFunc
+}
+
+// NewActivity creates a new activity tracker.
+func NewActivity() *Activity {
+	lifetime, cancel := context.WithCancel(context.Background())
+
+	return &Activity{
+		inFlight: make(map[string]int),
+		lifetime: lifetime,
+		cancel:   cancel,
+	}
+}
+
+// workContext carries the values of its parent, but is only cancelled if its work is abandoned.
+type workContext struct {
+	//nolint:containedctx
+	context.Context
+	lifetime context.Context
+}
+
+// Deadline returns the deadline of the work.
+func (c *workContext) Deadline() (time.Time, bool) {
+	return c.lifetime.Deadline()
+}
+
+// Done returns a channel that is closed when the work is abandoned.
+func (c *workContext) Done() <-chan struct{} {
+	return c.lifetime.Done()
+}
+
+// Err returns an error if the work has been abandoned.
+func (c *workContext) Err() error {
+	return c.lifetime.Err()
+}
+
+// Start notes the start of a named piece of work.
+// It returns a context for the work, which is not cancelled along with the parent context but only
+// if the work is abandoned on shutdown, and a function that must be called when the work is done.
+// If the service is stopping then no context is returned, and the work should not start.
+func (a *Activity) Start(ctx context.Context, name string) (context.Context, func(), bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stopping {
+		return nil, nil, false
+	}
+	a.inFlight[name]++
+	a.wg.Add(1)
+
+	return &workContext{
+		Context:  ctx,
+		lifetime: a.lifetime,
+	}, func() {
+		a.mutex.Lock()
+		a.inFlight[name]--
+		if a.inFlight[name] == 0 {
+			delete(a.inFlight, name)
+		}
+		a.mutex.Unlock()
+		a.wg.Done()
+	}, true
+}
+
+// Stopping returns true if the service has been asked to stop.
+// Long-running work should check this at convenient points, and return early if it is set.
+func (a *Activity) Stopping() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.stopping
+}
+
+// InFlight returns the names of the work currently in flight.
+func (a *Activity) InFlight() []string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	names := make([]string, 0, len(a.inFlight))
+	for name := range a.inFlight {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Stop stops new work from starting, and waits for in-flight work to finish.
+// If in-flight work has not finished by the abandon grace period before ctx's deadline, or ctx is
+// cancelled, then the work is cancelled and the names of the interrupted work are returned.
+// Cancelled work is given until ctx is done to roll back.
+func (a *Activity) Stop(ctx context.Context) []string {
+	a.mutex.Lock()
+	a.stopping = true
+	a.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(drained)
+	}()
+
+	// Leave time within the deadline for cancelled work to roll back, but no more than half of
+	// the time available so that work always has a chance to finish.
+	abandonCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		grace := abandonGraceFromContext(ctx)
+		if remaining := time.Until(deadline); grace > remaining/2 {
+			grace = remaining / 2
+		}
+		var cancel context.CancelFunc
+		abandonCtx, cancel = context.WithDeadline(ctx, deadline.Add(-grace))
+		defer cancel()
+	}
+
+	select {
+	case <-drained:
+		a.cancel()
+		return nil
+	case <-abandonCtx.Done():
+	}
+
+	interrupted := a.InFlight()
+	a.cancel()
+
+	// Give the cancelled work a chance to roll back before returning.
+	select {
+	case <-drained:
+	case <-ctx.Done():
+	}
+
+	return interrupted
+}
@@ -0,0 +1,98 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/util"
+)
+
+type testKey struct{}
+
+func TestActivityDrains(t *testing.T) {
+	activity := util.NewActivity()
+
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), testKey{}, "value"))
+	ctx, done, ok := activity.Start(parent, "work")
+	require.True(t, ok)
+	require.Equal(t, []string{"work"}, activity.InFlight())
+
+	// Cancelling the parent should not cancel the work.
+	cancel()
+	require.NoError(t, ctx.Err())
+	require.Equal(t, "value", ctx.Value(testKey{}))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		done()
+	}()
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), time.Second)
+	defer stopCancel()
+	require.Empty(t, activity.Stop(stopCtx))
+	require.True(t, activity.Stopping())
+	require.Empty(t, activity.InFlight())
+
+	// No new work once stopped.
+	_, _, ok = activity.Start(context.Background(), "late")
+	require.False(t, ok)
+}
+
+func TestActivityInterrupted(t *testing.T) {
+	activity := util.NewActivity()
+
+	ctx, done, ok := activity.Start(context.Background(), "work")
+	require.True(t, ok)
+	go func() {
+		// Work that only finishes when it is abandoned.
+		<-ctx.Done()
+		done()
+	}()
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer stopCancel()
+	require.Equal(t, []string{"work"}, activity.Stop(stopCtx))
+	require.Error(t, ctx.Err())
+}
+
+func TestActivityAbandonGrace(t *testing.T) {
+	activity := util.NewActivity()
+
+	ctx, done, ok := activity.Start(context.Background(), "work")
+	require.True(t, ok)
+	abandoned := make(chan time.Time, 1)
+	go func() {
+		// Work that only finishes when it is abandoned, and takes a while to roll back.
+		<-ctx.Done()
+		abandoned <- time.Now()
+		time.Sleep(50 * time.Millisecond)
+		done()
+	}()
+
+	started := time.Now()
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer stopCancel()
+	stopCtx = util.WithAbandonGrace(stopCtx, 100*time.Millisecond)
+	require.Equal(t, []string{"work"}, activity.Stop(stopCtx))
+
+	// The work is abandoned with the grace period remaining, and has rolled back within the timeout.
+	abandonedAt := <-abandoned
+	require.InDelta(t, 200*time.Millisecond, abandonedAt.Sub(started), float64(50*time.Millisecond))
+	require.Less(t, time.Since(started), 300*time.Millisecond)
+	require.Empty(t, activity.InFlight())
+}
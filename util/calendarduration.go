@@ -117,6 +117,34 @@ func (d *CalendarDuration) Decrement(date time.Time) time.Time {
 	return shiftMonths(decrementedTime, -d.months)
 }
 
+// Truncate returns the start of the calendar-aligned period of this duration in which the date falls.
+// Periods made up solely of years start on 1 January, periods made up solely of months start on the
+// first day of a month (aligned to the start of the year if the number of months divides a year), and
+// periods made up solely of whole weeks start on a Monday.  All other periods start at the beginning
+// of the day in which the date falls.
+func (d *CalendarDuration) Truncate(date time.Time) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	if d.hours != 0 || d.minutes != 0 || d.seconds != 0 {
+		return day
+	}
+
+	switch {
+	case d.years > 0 && d.months == 0 && d.days == 0:
+		return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, date.Location())
+	case d.years == 0 && d.months > 0 && d.days == 0:
+		month := date.Month()
+		if 12%d.months == 0 {
+			month -= (month - 1) % time.Month(d.months)
+		}
+		return time.Date(date.Year(), month, 1, 0, 0, 0, 0, date.Location())
+	case d.years == 0 && d.months == 0 && d.days > 0 && d.days%7 == 0:
+		// Weekday() has Sunday as 0; we want Monday as the start of the week.
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return day
+	}
+}
+
 // Seconds returns this duration's seconds.
 func (d *CalendarDuration) Seconds() int {
 	return d.seconds
@@ -422,3 +422,68 @@ func TestCalendarDurationComponents(t *testing.T) {
 		})
 	}
 }
+
+func TestCalendarDurationTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration string
+		date     time.Time
+		expected time.Time
+	}{
+		{
+			name:     "Year",
+			duration: "P1Y",
+			date:     timeInLocation("2021-05-17T13:14:15", "Etc/UTC"),
+			expected: timeInLocation("2021-01-01T00:00:00", "Etc/UTC"),
+		},
+		{
+			name:     "Month",
+			duration: "P1M",
+			date:     timeInLocation("2021-05-17T13:14:15", "Etc/UTC"),
+			expected: timeInLocation("2021-05-01T00:00:00", "Etc/UTC"),
+		},
+		{
+			name:     "Quarter",
+			duration: "P3M",
+			date:     timeInLocation("2021-05-17T13:14:15", "Etc/UTC"),
+			expected: timeInLocation("2021-04-01T00:00:00", "Etc/UTC"),
+		},
+		{
+			name:     "FiveMonths",
+			duration: "P5M",
+			date:     timeInLocation("2021-05-17T13:14:15", "Etc/UTC"),
+			expected: timeInLocation("2021-05-01T00:00:00", "Etc/UTC"),
+		},
+		{
+			name:     "Week",
+			duration: "P7D",
+			date:     timeInLocation("2021-05-16T13:14:15", "Etc/UTC"),
+			expected: timeInLocation("2021-05-10T00:00:00", "Etc/UTC"),
+		},
+		{
+			name:     "WeekOnMonday",
+			duration: "P14D",
+			date:     timeInLocation("2021-05-17T00:00:00", "Etc/UTC"),
+			expected: timeInLocation("2021-05-17T00:00:00", "Etc/UTC"),
+		},
+		{
+			name:     "Days",
+			duration: "P10D",
+			date:     timeInLocation("2021-05-17T13:14:15", "Etc/UTC"),
+			expected: timeInLocation("2021-05-17T00:00:00", "Etc/UTC"),
+		},
+		{
+			name:     "Mixed",
+			duration: "P1M7D",
+			date:     timeInLocation("2021-05-17T13:14:15", "Etc/UTC"),
+			expected: timeInLocation("2021-05-17T00:00:00", "Etc/UTC"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cd, err := util.ParseCalendarDuration(test.duration)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, cd.Truncate(test.date))
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// DepositInclusionParams are the chain parameters required to estimate deposit inclusion.
+type DepositInclusionParams struct {
+	GenesisTime         time.Time
+	SlotDuration        time.Duration
+	VotingPeriod        time.Duration
+	FollowDistance      time.Duration
+	MaxDepositsPerBlock uint64
+}
+
+// blsDST is the domain separation tag for Ethereum consensus signatures.
+var blsDST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+// ComputeDomain computes a signature domain given its type, the fork version and the genesis validators root.
+func ComputeDomain(domainType phase0.DomainType,
+	forkVersion phase0.Version,
+	genesisValidatorsRoot phase0.Root,
+) (
+	phase0.Domain,
+	error,
+) {
+	forkData := &phase0.ForkData{
+		CurrentVersion:        forkVersion,
+		GenesisValidatorsRoot: genesisValidatorsRoot,
+	}
+	root, err := forkData.HashTreeRoot()
+	if err != nil {
+		return phase0.Domain{}, errors.Wrap(err, "failed to calculate fork data root")
+	}
+
+	var domain phase0.Domain
+	copy(domain[:], domainType[:])
+	copy(domain[4:], root[:28])
+
+	return domain, nil
+}
+
+// VerifyDepositSignature returns true if the signature of the deposit is valid for the given deposit domain.
+// Deposits are signed with a domain that uses the genesis fork version and an empty genesis validators root.
+// Malformed public keys or signatures result in a response of false rather than an error.
+func VerifyDepositSignature(pubKey phase0.BLSPubKey,
+	withdrawalCredentials []byte,
+	amount phase0.Gwei,
+	signature phase0.BLSSignature,
+	domain phase0.Domain,
+) (
+	bool,
+	error,
+) {
+	depositMessage := &phase0.DepositMessage{
+		PublicKey:             pubKey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+	}
+	messageRoot, err := depositMessage.HashTreeRoot()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to calculate deposit message root")
+	}
+	signingData := &phase0.SigningData{
+		ObjectRoot: messageRoot,
+		Domain:     domain,
+	}
+	signingRoot, err := signingData.HashTreeRoot()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to calculate signing root")
+	}
+
+	pk := new(blst.P1Affine).Uncompress(pubKey[:])
+	if pk == nil {
+		return false, nil
+	}
+	sig := new(blst.P2Affine).Uncompress(signature[:])
+	if sig == nil {
+		return false, nil
+	}
+
+	return sig.Verify(true, pk, true, signingRoot[:], blsDST), nil
+}
+
+// EstimateDepositInclusion estimates the time at which a deposit will be included in the beacon chain.
+// nextDepositIndex is the index of the next deposit to be processed by the beacon chain, and
+// eth1DepositCount the number of deposits voted in to the beacon chain, both as of the latest block.
+func EstimateDepositInclusion(params *DepositInclusionParams,
+	depositIndex uint64,
+	eth1BlockTimestamp time.Time,
+	nextDepositIndex uint64,
+	eth1DepositCount uint64,
+	latestBlockTime time.Time,
+) time.Time {
+	// Deposits are included in order, a block at a time.
+	queuedBlocks := time.Duration(1)
+	if depositIndex > nextDepositIndex {
+		queuedBlocks += time.Duration((depositIndex - nextDepositIndex) / params.MaxDepositsPerBlock)
+	}
+	queueDuration := queuedBlocks * params.SlotDuration
+
+	if depositIndex < eth1DepositCount {
+		// The beacon chain already knows about the deposit, so it is just waiting its turn.
+		return latestBlockTime.Add(queueDuration)
+	}
+
+	// The deposit must be voted in.  It can first be voted on in the voting period that starts
+	// after its block passes the follow distance, and is accepted once a majority of the
+	// voting period has voted for it.
+	eligibleTime := eth1BlockTimestamp.Add(params.FollowDistance)
+	period := eligibleTime.Sub(params.GenesisTime) / params.VotingPeriod
+	periodStart := params.GenesisTime.Add(period * params.VotingPeriod)
+	if periodStart.Before(eligibleTime) {
+		periodStart = periodStart.Add(params.VotingPeriod)
+	}
+	votedTime := periodStart.Add(params.VotingPeriod / 2)
+	for votedTime.Before(latestBlockTime) {
+		// Voting has not succeeded in the expected period, so assume the next period.
+		votedTime = votedTime.Add(params.VotingPeriod)
+	}
+
+	return votedTime.Add(queueDuration)
+}
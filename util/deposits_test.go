@@ -0,0 +1,192 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
+	"github.com/wealdtech/chaind/util"
+)
+
+func TestComputeDomain(t *testing.T) {
+	// Mainnet deposit domain.
+	domain, err := util.ComputeDomain(phase0.DomainType{0x03, 0x00, 0x00, 0x00}, phase0.Version{}, phase0.Root{})
+	require.NoError(t, err)
+	require.Equal(t, phase0.Domain{
+		0x03, 0x00, 0x00, 0x00, 0xf5, 0xa5, 0xfd, 0x42, 0xd1, 0x6a, 0x20, 0x30, 0x27, 0x98, 0xef, 0x6e,
+		0xd3, 0x09, 0x97, 0x9b, 0x43, 0x00, 0x3d, 0x23, 0x20, 0xd9, 0xf0, 0xe8, 0xea, 0x98, 0x31, 0xa9,
+	}, domain)
+}
+
+func TestVerifyDepositSignature(t *testing.T) {
+	domain, err := util.ComputeDomain(phase0.DomainType{0x03, 0x00, 0x00, 0x00}, phase0.Version{}, phase0.Root{})
+	require.NoError(t, err)
+
+	sk := blst.KeyGen([]byte("chaind deposit signature test key material"))
+	var pubKey phase0.BLSPubKey
+	copy(pubKey[:], new(blst.P1Affine).From(sk).Compress())
+	withdrawalCredentials := make([]byte, 32)
+	amount := phase0.Gwei(32000000000)
+
+	depositMessage := &phase0.DepositMessage{
+		PublicKey:             pubKey,
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                amount,
+	}
+	messageRoot, err := depositMessage.HashTreeRoot()
+	require.NoError(t, err)
+	signingRoot, err := (&phase0.SigningData{ObjectRoot: messageRoot, Domain: domain}).HashTreeRoot()
+	require.NoError(t, err)
+	var signature phase0.BLSSignature
+	copy(signature[:], new(blst.P2Affine).Sign(sk, signingRoot[:], []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")).Compress())
+
+	tests := []struct {
+		name      string
+		pubKey    phase0.BLSPubKey
+		amount    phase0.Gwei
+		signature phase0.BLSSignature
+		domain    phase0.Domain
+		valid     bool
+	}{
+		{
+			name:      "Good",
+			pubKey:    pubKey,
+			amount:    amount,
+			signature: signature,
+			domain:    domain,
+			valid:     true,
+		},
+		{
+			name:      "AmountIncorrect",
+			pubKey:    pubKey,
+			amount:    amount + 1,
+			signature: signature,
+			domain:    domain,
+		},
+		{
+			name:      "DomainIncorrect",
+			pubKey:    pubKey,
+			amount:    amount,
+			signature: signature,
+			domain:    phase0.Domain{},
+		},
+		{
+			name:      "PubKeyInvalid",
+			pubKey:    phase0.BLSPubKey{0x01},
+			amount:    amount,
+			signature: signature,
+			domain:    domain,
+		},
+		{
+			name:      "SignatureInvalid",
+			pubKey:    pubKey,
+			amount:    amount,
+			signature: phase0.BLSSignature{0x01},
+			domain:    domain,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			valid, err := util.VerifyDepositSignature(test.pubKey, withdrawalCredentials, test.amount, test.signature, test.domain)
+			require.NoError(t, err)
+			require.Equal(t, test.valid, valid)
+		})
+	}
+}
+
+func TestEstimateDepositInclusion(t *testing.T) {
+	params := &util.DepositInclusionParams{
+		GenesisTime:         time.Unix(1606824023, 0),
+		SlotDuration:        12 * time.Second,
+		VotingPeriod:        64 * 32 * 12 * time.Second,
+		FollowDistance:      2048 * 14 * time.Second,
+		MaxDepositsPerBlock: 16,
+	}
+	// periodStart returns the start of the given voting period.
+	periodStart := func(period int64) time.Time {
+		return params.GenesisTime.Add(time.Duration(period) * params.VotingPeriod)
+	}
+
+	tests := []struct {
+		name               string
+		depositIndex       uint64
+		eth1BlockTimestamp time.Time
+		nextDepositIndex   uint64
+		eth1DepositCount   uint64
+		latestBlockTime    time.Time
+		expected           time.Time
+	}{
+		{
+			name:             "QueuedNext",
+			depositIndex:     68,
+			nextDepositIndex: 68,
+			eth1DepositCount: 200,
+			latestBlockTime:  periodStart(10),
+			expected:         periodStart(10).Add(12 * time.Second),
+		},
+		{
+			name:             "Queued",
+			depositIndex:     100,
+			nextDepositIndex: 68,
+			eth1DepositCount: 200,
+			latestBlockTime:  periodStart(10),
+			expected:         periodStart(10).Add(3 * 12 * time.Second),
+		},
+		{
+			name:               "VotingPeriod",
+			depositIndex:       300,
+			eth1BlockTimestamp: periodStart(10).Add(-params.FollowDistance).Add(time.Second),
+			nextDepositIndex:   200,
+			eth1DepositCount:   200,
+			latestBlockTime:    periodStart(10),
+			expected:           periodStart(11).Add(params.VotingPeriod / 2).Add(7 * 12 * time.Second),
+		},
+		{
+			name:               "VotingPeriodBoundary",
+			depositIndex:       300,
+			eth1BlockTimestamp: periodStart(10).Add(-params.FollowDistance),
+			nextDepositIndex:   200,
+			eth1DepositCount:   200,
+			latestBlockTime:    periodStart(9),
+			expected:           periodStart(10).Add(params.VotingPeriod / 2).Add(7 * 12 * time.Second),
+		},
+		{
+			name:               "Overdue",
+			depositIndex:       300,
+			eth1BlockTimestamp: periodStart(10).Add(-params.FollowDistance).Add(time.Second),
+			nextDepositIndex:   200,
+			eth1DepositCount:   200,
+			latestBlockTime:    periodStart(12),
+			expected:           periodStart(12).Add(params.VotingPeriod / 2).Add(7 * 12 * time.Second),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			estimate := util.EstimateDepositInclusion(params,
+				test.depositIndex,
+				test.eth1BlockTimestamp,
+				test.nextDepositIndex,
+				test.eth1DepositCount,
+				test.latestBlockTime,
+			)
+			require.Equal(t, test.expected, estimate)
+		})
+	}
+}
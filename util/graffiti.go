@@ -0,0 +1,119 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// ClientUnknown is the label for a client that cannot be identified from graffiti.
+const ClientUnknown = "unknown"
+
+// consensusClientVersionCodes and executionClientVersionCodes are the two-letter client
+// codes used in client version graffiti, as defined for engine_getClientVersionV1.
+var consensusClientVersionCodes = map[string]string{
+	"GR": "grandine",
+	"LH": "lighthouse",
+	"LS": "lodestar",
+	"NB": "nimbus",
+	"PM": "prysm",
+	"TK": "teku",
+}
+
+var executionClientVersionCodes = map[string]string{
+	"BU": "besu",
+	"EG": "erigon",
+	"EJ": "ethereumjs",
+	"GE": "geth",
+	"NM": "nethermind",
+	"RH": "reth",
+}
+
+// rocketPoolConsensusCodes and rocketPoolExecutionCodes are the single-letter client
+// codes used in Rocket Pool graffiti of the form "RP-XY", where X is the execution client
+// and Y is the consensus client.
+var rocketPoolConsensusCodes = map[byte]string{
+	'L': "lighthouse",
+	'N': "nimbus",
+	'P': "prysm",
+	'S': "lodestar",
+	'T': "teku",
+}
+
+var rocketPoolExecutionCodes = map[byte]string{
+	'B': "besu",
+	'G': "geth",
+	'N': "nethermind",
+	'R': "reth",
+}
+
+var (
+	clientVersionRegex = regexp.MustCompile(`^([A-Z]{2})[0-9a-f]{0,4}([A-Z]{2})[0-9a-f]{0,4}`)
+	rocketPoolRegex    = regexp.MustCompile(`^RP-([A-Z])([A-Z])\b`)
+	consensusNameRegex = regexp.MustCompile(`(?i)\b(grandine|lighthouse|lodestar|nimbus|prysm|teku)\b`)
+	executionNameRegex = regexp.MustCompile(`(?i)\b(besu|erigon|ethereumjs|geth|go-ethereum|nethermind|reth)\b`)
+)
+
+// GraffitiText returns the graffiti as text, with trailing padding removed and
+// invalid UTF-8 sequences replaced.
+func GraffitiText(graffiti []byte) string {
+	return strings.ToValidUTF8(string(bytes.TrimRight(graffiti, "\x00")), "�")
+}
+
+// ClassifyGraffiti decodes common client tags in graffiti, returning the consensus and
+// execution clients that produced the block.  Clients that cannot be identified are
+// returned as ClientUnknown.
+func ClassifyGraffiti(graffiti []byte) (string, string) {
+	text := GraffitiText(graffiti)
+
+	// Client version graffiti, for example "GE1a2bLH3c4d", is checked first as it is
+	// unambiguous.
+	if matches := clientVersionRegex.FindStringSubmatch(text); matches != nil {
+		executionClient, executionExists := executionClientVersionCodes[matches[1]]
+		consensusClient, consensusExists := consensusClientVersionCodes[matches[2]]
+		if executionExists && consensusExists {
+			return consensusClient, executionClient
+		}
+	}
+
+	consensusClient := ClientUnknown
+	executionClient := ClientUnknown
+
+	if matches := rocketPoolRegex.FindStringSubmatch(text); matches != nil {
+		if client, exists := rocketPoolExecutionCodes[matches[1][0]]; exists {
+			executionClient = client
+		}
+		if client, exists := rocketPoolConsensusCodes[matches[2][0]]; exists {
+			consensusClient = client
+		}
+	}
+
+	if consensusClient == ClientUnknown {
+		if match := consensusNameRegex.FindString(text); match != "" {
+			consensusClient = strings.ToLower(match)
+		}
+	}
+	if executionClient == ClientUnknown {
+		if match := executionNameRegex.FindString(text); match != "" {
+			executionClient = strings.ToLower(match)
+			if executionClient == "go-ethereum" {
+				executionClient = "geth"
+			}
+		}
+	}
+
+	return consensusClient, executionClient
+}
@@ -0,0 +1,123 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/util"
+)
+
+func graffiti(text string) []byte {
+	res := make([]byte, 32)
+	copy(res, text)
+	return res
+}
+
+func TestGraffitiText(t *testing.T) {
+	require.Equal(t, "", util.GraffitiText(nil))
+	require.Equal(t, "", util.GraffitiText(graffiti("")))
+	require.Equal(t, "Lighthouse/v4.0.1", util.GraffitiText(graffiti("Lighthouse/v4.0.1")))
+	require.Equal(t, "a�b", util.GraffitiText([]byte{'a', 0xff, 'b', 0x00}))
+}
+
+func TestClassifyGraffiti(t *testing.T) {
+	tests := []struct {
+		name      string
+		graffiti  []byte
+		consensus string
+		execution string
+	}{
+		{
+			name:      "Nil",
+			consensus: util.ClientUnknown,
+			execution: util.ClientUnknown,
+		},
+		{
+			name:      "Empty",
+			graffiti:  graffiti(""),
+			consensus: util.ClientUnknown,
+			execution: util.ClientUnknown,
+		},
+		{
+			name:      "Custom",
+			graffiti:  graffiti("hello world"),
+			consensus: util.ClientUnknown,
+			execution: util.ClientUnknown,
+		},
+		{
+			name:      "ClientVersion",
+			graffiti:  graffiti("GE1a2bLH3c4d"),
+			consensus: "lighthouse",
+			execution: "geth",
+		},
+		{
+			name:      "ClientVersionShort",
+			graffiti:  graffiti("NMTK"),
+			consensus: "teku",
+			execution: "nethermind",
+		},
+		{
+			name:      "ClientVersionWithSuffix",
+			graffiti:  graffiti("RH12PM34 my validator"),
+			consensus: "prysm",
+			execution: "reth",
+		},
+		{
+			name:      "ClientVersionUnknownCodes",
+			graffiti:  graffiti("ABCD"),
+			consensus: util.ClientUnknown,
+			execution: util.ClientUnknown,
+		},
+		{
+			name:      "RocketPool",
+			graffiti:  graffiti("RP-NS v1.10.0 (my node)"),
+			consensus: "lodestar",
+			execution: "nethermind",
+		},
+		{
+			name:      "RocketPoolUnknownCode",
+			graffiti:  graffiti("RP-XL v1.10.0"),
+			consensus: "lighthouse",
+			execution: util.ClientUnknown,
+		},
+		{
+			name:      "ConsensusName",
+			graffiti:  graffiti("Lighthouse/v4.0.1-693886b"),
+			consensus: "lighthouse",
+			execution: util.ClientUnknown,
+		},
+		{
+			name:      "BothNames",
+			graffiti:  graffiti("teku + go-ethereum"),
+			consensus: "teku",
+			execution: "geth",
+		},
+		{
+			name:      "NameNotWord",
+			graffiti:  graffiti("brethren"),
+			consensus: util.ClientUnknown,
+			execution: util.ClientUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			consensus, execution := util.ClassifyGraffiti(test.graffiti)
+			require.Equal(t, test.consensus, consensus)
+			require.Equal(t, test.execution, execution)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/rs/zerolog"
 	zerologger "github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"go.uber.org/atomic"
 )
 
 // LogLevel returns the best log level for the path.
@@ -62,3 +63,36 @@ func stringToLevel(input string) zerolog.Level {
 		return zerologger.Logger.GetLevel()
 	}
 }
+
+// DynamicLevel is a log level that can be changed whilst the loggers that use it are running.
+type DynamicLevel struct {
+	level atomic.Int32
+}
+
+// NewDynamicLevel creates a new dynamic log level.
+func NewDynamicLevel(level zerolog.Level) *DynamicLevel {
+	d := &DynamicLevel{}
+	d.level.Store(int32(level))
+	return d
+}
+
+// SetLevel sets the log level.
+func (d *DynamicLevel) SetLevel(level zerolog.Level) {
+	d.level.Store(int32(level))
+}
+
+// Level returns the log level.
+func (d *DynamicLevel) Level() zerolog.Level {
+	return zerolog.Level(d.level.Load())
+}
+
+// Sample returns true if events at the given level should be logged.
+// It implements zerolog.Sampler, which allows it to be checked before an event is built.
+func (d *DynamicLevel) Sample(level zerolog.Level) bool {
+	return level >= d.Level()
+}
+
+// Apply returns a copy of the logger whose level is governed by the dynamic level.
+func (d *DynamicLevel) Apply(logger zerolog.Logger) zerolog.Logger {
+	return logger.Level(zerolog.TraceLevel).Sample(d)
+}
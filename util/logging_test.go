@@ -0,0 +1,49 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/util"
+)
+
+func TestDynamicLevel(t *testing.T) {
+	output := new(bytes.Buffer)
+	level := util.NewDynamicLevel(zerolog.InfoLevel)
+	log := level.Apply(zerolog.New(output))
+	// Child loggers follow the dynamic level.
+	child := log.With().Str("child", "true").Logger()
+
+	log.Debug().Msg("suppressed")
+	child.Debug().Msg("suppressed")
+	require.Empty(t, output.String())
+	log.Info().Msg("logged")
+	require.Contains(t, output.String(), "logged")
+
+	output.Reset()
+	level.SetLevel(zerolog.DebugLevel)
+	require.Equal(t, zerolog.DebugLevel, level.Level())
+	child.Debug().Msg("now logged")
+	require.Contains(t, output.String(), "now logged")
+
+	output.Reset()
+	level.SetLevel(zerolog.Disabled)
+	log.Error().Msg("suppressed")
+	log.Log().Msg("suppressed")
+	require.Empty(t, output.String())
+}
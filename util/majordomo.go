@@ -59,7 +59,7 @@ func InitMajordomo(ctx context.Context) (majordomo.Service, error) {
 	if viper.GetString("majordomo.asm.region") != "" {
 		var asmCredentials *credentials.Credentials
 		if viper.GetString("majordomo.asm.id") != "" {
-			asmCredentials = credentials.NewStaticCredentials(viper.GetString("majordomo.asm.id"), viper.GetString("ma  jordomo.asm.secret"), "")
+			asmCredentials = credentials.NewStaticCredentials(viper.GetString("majordomo.asm.id"), viper.GetString("majordomo.asm.secret"), "")
 		}
 		asmConfidant, err := asmconfidant.New(ctx,
 			asmconfidant.WithLogLevel(LogLevel("majordomo.confidants.asm")),
@@ -0,0 +1,220 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	eth2http "github.com/attestantio/go-eth2-client/http"
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	zerologger "github.com/rs/zerolog/log"
+	"github.com/wealdtech/chaind/services/metrics"
+)
+
+// RetryPolicy defines how failed work is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	MaxAttempts int
+	// InitialDelay is the delay before the first retry.
+	InitialDelay time.Duration
+	// MaxDelay is the maximum delay between attempts.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used if none is configured.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  5,
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+}
+
+var (
+	retryPolicyMu sync.RWMutex
+	retryPolicy   = DefaultRetryPolicy
+)
+
+var (
+	retriesMetric *prometheus.CounterVec
+	giveupsMetric *prometheus.CounterVec
+)
+
+// SetRetryPolicy sets the retry policy used by Retry.
+func SetRetryPolicy(policy RetryPolicy) error {
+	if policy.MaxAttempts < 1 {
+		return errors.New("retry policy must allow at least one attempt")
+	}
+	if policy.InitialDelay < 0 || policy.MaxDelay < policy.InitialDelay {
+		return errors.New("retry policy delays are invalid")
+	}
+
+	retryPolicyMu.Lock()
+	retryPolicy = policy
+	retryPolicyMu.Unlock()
+
+	return nil
+}
+
+// RegisterRetryMetrics registers metrics for retries.
+func RegisterRetryMetrics(_ context.Context, monitor metrics.Service) error {
+	if retriesMetric != nil {
+		// Already registered.
+		return nil
+	}
+	if monitor == nil {
+		// No monitor.
+		return nil
+	}
+	if monitor.Presenter() != "prometheus" {
+		return nil
+	}
+
+	retriesMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "chaind",
+		Subsystem: "retry",
+		Name:      "retries_total",
+		Help:      "The number of times work has been retried.",
+	}, []string{"operation"})
+	if err := prometheus.Register(retriesMetric); err != nil {
+		return errors.Wrap(err, "failed to register retries_total")
+	}
+
+	giveupsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "chaind",
+		Subsystem: "retry",
+		Name:      "giveups_total",
+		Help:      "The number of times retryable work has failed on all attempts.",
+	}, []string{"operation"})
+	if err := prometheus.Register(giveupsMetric); err != nil {
+		return errors.Wrap(err, "failed to register giveups_total")
+	}
+
+	return nil
+}
+
+// Retry runs the supplied work, retrying it with exponential backoff if it fails with a
+// retryable error.  The work should be a complete unit, for example starting and committing
+// its own database transaction, as it is run again from the start on each attempt.
+// It should not be called with a context that already holds a database transaction, as a
+// failed transaction cannot be retried from within.
+func Retry(ctx context.Context, operation string, work func(ctx context.Context) error) error {
+	retryPolicyMu.RLock()
+	policy := retryPolicy
+	retryPolicyMu.RUnlock()
+
+	log := zerologger.With().Str("operation", operation).Logger()
+
+	delay := policy.InitialDelay
+	for attempt := 1; ; attempt++ {
+		err := work(ctx)
+		if err == nil {
+			return nil
+		}
+		if !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= policy.MaxAttempts {
+			log.Warn().Int("attempts", attempt).Err(err).Msg("Giving up after retries")
+			if giveupsMetric != nil {
+				giveupsMetric.WithLabelValues(operation).Inc()
+			}
+			return errors.Wrapf(err, "failed after %d attempts", attempt)
+		}
+
+		wait := jitter(delay)
+		log.Debug().Int("attempt", attempt).Dur("wait", wait).Err(err).Msg("Retryable failure; will retry")
+		if retriesMetric != nil {
+			retriesMetric.WithLabelValues(operation).Inc()
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+		if delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
+
+// jitter returns a random duration between half of and the full supplied delay.
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 1 {
+		return delay
+	}
+	// #nosec G404
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// IsRetryable returns true if the error is transient, and the work that caused it could succeed
+// if tried again.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// Database errors.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"53300", // too_many_connections
+			"55P03", // lock_not_available
+			"57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now
+			return true
+		}
+		// Class 08 is connection exceptions.
+		return len(pgErr.Code) == 5 && pgErr.Code[:2] == "08"
+	}
+	if pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	// Beacon node errors.
+	var httpErr eth2http.Error
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError ||
+			httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	// Network errors.
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
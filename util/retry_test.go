@@ -0,0 +1,144 @@
+// Copyright © 2023 Weald Technology Limited.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	eth2http "github.com/attestantio/go-eth2-client/http"
+	"github.com/jackc/pgconn"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/wealdtech/chaind/util"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{
+			name: "Nil",
+		},
+		{
+			name: "Plain",
+			err:  errors.New("bad"),
+		},
+		{
+			name: "Canceled",
+			err:  errors.Wrap(context.Canceled, "wrapped"),
+		},
+		{
+			name:      "DeadlineExceeded",
+			err:       errors.Wrap(context.DeadlineExceeded, "wrapped"),
+			retryable: true,
+		},
+		{
+			name:      "SerializationFailure",
+			err:       errors.Wrap(&pgconn.PgError{Code: "40001"}, "wrapped"),
+			retryable: true,
+		},
+		{
+			name:      "ConnectionFailure",
+			err:       &pgconn.PgError{Code: "08006"},
+			retryable: true,
+		},
+		{
+			name: "UniqueViolation",
+			err:  &pgconn.PgError{Code: "23505"},
+		},
+		{
+			name:      "ServerError",
+			err:       errors.Wrap(eth2http.Error{StatusCode: 503}, "wrapped"),
+			retryable: true,
+		},
+		{
+			name: "ClientError",
+			err:  errors.Wrap(eth2http.Error{StatusCode: 400}, "wrapped"),
+		},
+		{
+			name:      "ConnectionReset",
+			err:       errors.Wrap(syscall.ECONNRESET, "wrapped"),
+			retryable: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.retryable, util.IsRetryable(test.err))
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	require.NoError(t, util.SetRetryPolicy(util.RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     2 * time.Millisecond,
+	}))
+	defer func() {
+		require.NoError(t, util.SetRetryPolicy(util.DefaultRetryPolicy))
+	}()
+
+	retryableErr := &pgconn.PgError{Code: "40001"}
+
+	// Succeeds after a transient failure.
+	attempts := 0
+	require.NoError(t, util.Retry(context.Background(), "test", func(_ context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return retryableErr
+		}
+		return nil
+	}))
+	require.Equal(t, 2, attempts)
+
+	// Gives up after the maximum number of attempts.
+	attempts = 0
+	err := util.Retry(context.Background(), "test", func(_ context.Context) error {
+		attempts++
+		return retryableErr
+	})
+	require.ErrorIs(t, err, retryableErr)
+	require.Equal(t, 3, attempts)
+
+	// Does not retry permanent failures.
+	attempts = 0
+	err = util.Retry(context.Background(), "test", func(_ context.Context) error {
+		attempts++
+		return errors.New("permanent")
+	})
+	require.EqualError(t, err, "permanent")
+	require.Equal(t, 1, attempts)
+
+	// Stops when the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts = 0
+	err = util.Retry(ctx, "test", func(_ context.Context) error {
+		attempts++
+		cancel()
+		return retryableErr
+	})
+	require.ErrorIs(t, err, retryableErr)
+	require.Equal(t, 1, attempts)
+}
+
+func TestSetRetryPolicy(t *testing.T) {
+	require.Error(t, util.SetRetryPolicy(util.RetryPolicy{}))
+	require.Error(t, util.SetRetryPolicy(util.RetryPolicy{MaxAttempts: 1, InitialDelay: time.Second}))
+}